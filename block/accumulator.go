@@ -0,0 +1,347 @@
+package block
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/trie/mta"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	keyAccumulatorState = "block.accumulator"
+	keyAccumulatorBase  = "block.accumulatorBase"
+
+	// accumulatorSyncFlushInterval is the number of blocks added between
+	// flushes of the accumulator while it's synchronized with the blocks.
+	accumulatorSyncFlushInterval = 1000
+)
+
+// blockAccumulator accumulates hashes of finalized blocks. The first block
+// added to the accumulator is at base, which is the lowest block in the
+// database, so blocks before it (pruned ones) can't be proved with the
+// accumulator.
+//
+// Blocks finalized before are added to the accumulator in background (see
+// start), and it returns NotFound error until it has all finalized blocks.
+// It has its own lock, so it doesn't block the block manager while it's
+// built.
+type blockAccumulator struct {
+	lock sync.Mutex
+	acc  mta.Accumulator
+	prop *db.CodedBucket
+	base int64
+
+	// height is the height of the last finalized block.
+	height  int64
+	syncing bool
+	err     error
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newBlockAccumulator(dbase db.Database) (*blockAccumulator, error) {
+	bk, err := dbase.GetBucket(db.BlockAccumulator)
+	if err != nil {
+		return nil, err
+	}
+	prop, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return nil, err
+	}
+	a := &blockAccumulator{
+		acc: mta.Accumulator{
+			KeyForState: []byte(keyAccumulatorState),
+			Bucket:      bk,
+		},
+		prop: prop,
+	}
+	if err := a.acc.Recover(); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidAccumulatorState")
+	}
+	if a.acc.Len() > 0 {
+		err := prop.Get(db.Raw(keyAccumulatorBase), &a.base)
+		if errors.NotFoundError.Equals(err) {
+			return a, a.reset()
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// reset drops all blocks in the accumulator.
+func (a *blockAccumulator) reset() error {
+	if err := a.acc.Bucket.Delete(a.acc.KeyForState); err != nil {
+		return err
+	}
+	a.base = 0
+	return a.acc.Recover()
+}
+
+// next returns the height of the block to be added next.
+func (a *blockAccumulator) next() int64 {
+	return a.base + a.acc.Len()
+}
+
+// add adds the block finalized. The block is added later by the builder in
+// background if it's running.
+func (a *blockAccumulator) add(height int64, id []byte) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.height = height
+	if a.syncing {
+		return nil
+	}
+	if err := a.addHash(height, id); err != nil {
+		return err
+	}
+	return a.acc.Flush()
+}
+
+// addHash adds the block to the accumulator without flushing it.
+func (a *blockAccumulator) addHash(height int64, id []byte) error {
+	if a.acc.Len() == 0 {
+		if err := a.prop.Set(db.Raw(keyAccumulatorBase), height); err != nil {
+			return err
+		}
+		a.base = height
+	} else if height != a.next() {
+		return errors.InvalidStateError.Errorf(
+			"InvalidBlockForAccumulator(height=%d,next=%d)", height, a.next())
+	}
+	a.acc.AddHash(id)
+	return nil
+}
+
+// lowestBlockHeight returns the lowest height of the blocks in the database
+// up to the height. Blocks are kept from the genesis, or from the height
+// where the database is pruned, to the last block.
+func lowestBlockHeight(hb *db.CodedBucket, height int64) (int64, error) {
+	low, high := int64(0), height
+	for low < high {
+		mid := low + (high-low)/2
+		if _, err := hb.GetBytes(mid); err == nil {
+			high = mid
+		} else if errors.NotFoundError.Equals(err) {
+			low = mid + 1
+		} else {
+			return 0, err
+		}
+	}
+	return low, nil
+}
+
+// prepareInLock makes the accumulator ready to add blocks up to the height.
+// The accumulator starts from the lowest block in the database, so base of
+// it is same on the nodes having same blocks. The accumulator is built again
+// if it has blocks over the height, which happens on reset of the chain, or
+// if it starts after the lowest block.
+func (a *blockAccumulator) prepareInLock(hb *db.CodedBucket, height int64) error {
+	if a.next() > height+1 {
+		if err := a.reset(); err != nil {
+			return err
+		}
+	}
+	if a.acc.Len() == 0 || a.base > 0 {
+		lowest, err := lowestBlockHeight(hb, height)
+		if err != nil {
+			return err
+		}
+		if a.acc.Len() > 0 && a.base > lowest {
+			if err := a.reset(); err != nil {
+				return err
+			}
+		}
+		if a.acc.Len() == 0 {
+			a.base = lowest
+		}
+	}
+	return nil
+}
+
+// syncInLock adds up to accumulatorSyncFlushInterval blocks finalized but
+// not added to the accumulator, then flushes it. It returns true if the
+// accumulator has all finalized blocks.
+func (a *blockAccumulator) syncInLock(hb *db.CodedBucket) (bool, error) {
+	for i := 0; i < accumulatorSyncFlushInterval && a.next() <= a.height; i++ {
+		h := a.next()
+		id, err := hb.GetBytes(h)
+		if err != nil {
+			return false, err
+		}
+		if err := a.addHash(h, id); err != nil {
+			return false, err
+		}
+	}
+	if err := a.acc.Flush(); err != nil {
+		return false, err
+	}
+	return a.next() > a.height, nil
+}
+
+// sync adds blocks finalized but not added to the accumulator up to the
+// height.
+func (a *blockAccumulator) sync(dbase db.Database, height int64) error {
+	hb, err := db.NewCodedBucket(dbase, db.BlockHeaderHashByHeight, nil)
+	if err != nil {
+		return err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.height = height
+	if err := a.prepareInLock(hb, height); err != nil {
+		return err
+	}
+	for {
+		if done, err := a.syncInLock(hb); err != nil || done {
+			return err
+		}
+	}
+}
+
+// start adds blocks finalized but not added to the accumulator up to the
+// height in background. Blocks finalized meanwhile are also added by it.
+// Building the accumulator from the lowest block takes long time on a chain
+// having many blocks, so it doesn't block the caller.
+func (a *blockAccumulator) start(dbase db.Database, height int64, logger log.Logger) error {
+	hb, err := db.NewCodedBucket(dbase, db.BlockHeaderHashByHeight, nil)
+	if err != nil {
+		return err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.height = height
+	if err := a.prepareInLock(hb, height); err != nil {
+		return err
+	}
+	if a.next() > height {
+		return nil
+	}
+	a.syncing = true
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	logger.Infof("Start to build block accumulator from=%d to=%d", a.next(), height)
+	go a.run(hb, logger)
+	return nil
+}
+
+func (a *blockAccumulator) run(hb *db.CodedBucket, logger log.Logger) {
+	defer close(a.done)
+	for {
+		select {
+		case <-a.stop:
+			return
+		default:
+		}
+		a.lock.Lock()
+		done, err := a.syncInLock(hb)
+		if err != nil {
+			// blocks finalized later can't be added without this block,
+			// so it stays syncing and returns the error for requests.
+			a.err = err
+		} else if done {
+			a.syncing = false
+			logger.Infof("Block accumulator is built base=%d height=%d", a.base, a.height)
+		}
+		a.lock.Unlock()
+		if err != nil {
+			logger.Errorf("Fail to build block accumulator err=%+v", err)
+			return
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// term stops the builder running in background.
+func (a *blockAccumulator) term() {
+	a.lock.Lock()
+	stop, done := a.stop, a.done
+	a.stop = nil
+	a.lock.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (a *blockAccumulator) checkReadyInLock() error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.syncing {
+		return errors.NotFoundError.Errorf(
+			"AccumulatorNotReady(next=%d,height=%d)", a.next(), a.height)
+	}
+	return nil
+}
+
+func (a *blockAccumulator) lengthAt(height int64) (int64, error) {
+	if height < a.base || height >= a.next() {
+		return 0, errors.NotFoundError.Errorf(
+			"NoAccumulator(height=%d,base=%d,next=%d)", height, a.base, a.next())
+	}
+	return height - a.base + 1, nil
+}
+
+func (a *blockAccumulator) get(height int64) (*module.BlockAccumulator, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if err := a.checkReadyInLock(); err != nil {
+		return nil, err
+	}
+	length, err := a.lengthAt(height)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := a.acc.RootsAt(length)
+	if err != nil {
+		return nil, err
+	}
+	return &module.BlockAccumulator{
+		Height: height,
+		Base:   a.base,
+		Roots:  roots,
+	}, nil
+}
+
+func (a *blockAccumulator) witness(height, at int64) ([][]byte, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if err := a.checkReadyInLock(); err != nil {
+		return nil, err
+	}
+	length, err := a.lengthAt(at)
+	if err != nil {
+		return nil, err
+	}
+	if height < a.base || height > at {
+		return nil, errors.NotFoundError.Errorf(
+			"NoBlockInAccumulator(height=%d,base=%d,at=%d)", height, a.base, at)
+	}
+	w, err := a.acc.WitnessAt(height-a.base, length)
+	if err != nil {
+		return nil, err
+	}
+	return mta.WitnessesToHashes(w), nil
+}
+
+// AccumulatorRootHash returns the hash representing the accumulator.
+func AccumulatorRootHash(a *module.BlockAccumulator) []byte {
+	return mta.RootHash(a.Height-a.Base+1, a.Roots)
+}
+
+// VerifyBlockWitness verifies that the block with the hash at the height is
+// in the accumulator with the witness.
+func VerifyBlockWitness(a *module.BlockAccumulator, height int64, hash []byte, witness [][]byte) error {
+	return mta.VerifyHashes(a.Height-a.Base+1, a.Roots, height-a.Base, witness, hash)
+}
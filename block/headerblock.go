@@ -0,0 +1,107 @@
+package block
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+// NewHeaderBlock returns a block built from its header, the votes in the
+// header and its next validators. Transactions of the block are read from
+// the database on demand, so they are available only if they are stored
+// in the database.
+func NewHeaderBlock(
+	dbase db.Database, vld module.CommitVoteSetDecoder,
+	header, votes, nextValidators []byte,
+) (base.Block, error) {
+	hf, err := decodeV2Header(header)
+	if err != nil {
+		return nil, err
+	}
+	cvs := vld(votes)
+	if cvs == nil || !bytes.Equal(cvs.Hash(), hf.VotesHash) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidVotes(exp=%#x)", hf.VotesHash)
+	}
+	nvs, err := state.ValidatorSnapshotFromBytes(dbase, nextValidators)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(nvs.Hash(), hf.NextValidatorsHash) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidNextValidators(hash=%#x,exp=%#x)",
+			nvs.Hash(), hf.NextValidatorsHash)
+	}
+	return newHeaderBlock(dbase, hf, cvs, nvs)
+}
+
+// GetHeaderBlock returns the block with the id from the database. Only the
+// header, the votes in the header and the next validators of the block are
+// required in the database.
+func GetHeaderBlock(
+	dbase db.Database, vld module.CommitVoteSetDecoder, id []byte,
+) (base.Block, error) {
+	header, err := db.DoGetWithBucketID(dbase, db.BytesByHash, id)
+	if err != nil {
+		return nil, err
+	}
+	hf, err := decodeV2Header(header)
+	if err != nil {
+		return nil, err
+	}
+	votes, err := db.DoGetWithBucketID(dbase, db.BytesByHash, hf.VotesHash)
+	if err != nil {
+		return nil, err
+	}
+	cvs := vld(votes)
+	if cvs == nil {
+		return nil, errors.CriticalFormatError.Errorf(
+			"InvalidVotes(hash=%#x)", hf.VotesHash)
+	}
+	nvs, err := state.ValidatorSnapshotFromHash(dbase, hf.NextValidatorsHash)
+	if err != nil {
+		return nil, err
+	}
+	return newHeaderBlock(dbase, hf, cvs, nvs)
+}
+
+func decodeV2Header(header []byte) (*blockV2HeaderFormat, error) {
+	hf := new(blockV2HeaderFormat)
+	if _, err := v2Codec.UnmarshalFromBytes(header, hf); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidHeader")
+	}
+	if hf.Version != module.BlockVersion2 {
+		return nil, errors.UnsupportedError.Errorf(
+			"UnsupportedBlockVersion(ver=%d)", hf.Version)
+	}
+	return hf, nil
+}
+
+func newHeaderBlock(
+	dbase db.Database, hf *blockV2HeaderFormat,
+	votes module.CommitVoteSet, nextValidators module.ValidatorList,
+) (base.Block, error) {
+	proposer, err := newProposer(hf.Proposer)
+	if err != nil {
+		return nil, err
+	}
+	return &blockV2{
+		height:             hf.Height,
+		timestamp:          hf.Timestamp,
+		proposer:           proposer,
+		prevID:             hf.PrevID,
+		logsBloom:          txresult.NewLogsBloomFromCompressed(hf.LogsBloom),
+		result:             hf.Result,
+		patchTransactions:  transaction.NewTransactionListFromHash(dbase, hf.PatchTransactionsHash),
+		normalTransactions: transaction.NewTransactionListFromHash(dbase, hf.NormalTransactionsHash),
+		nextValidatorsHash: hf.NextValidatorsHash,
+		_nextValidators:    nextValidators,
+		votes:              votes,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package block
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+func TestHeaderBlock(t *testing.T) {
+	dbase := newMapDB()
+	addr := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	v, err := state.ValidatorFromAddress(addr)
+	assert.NoError(t, err)
+	nvs, err := state.ValidatorSnapshotFromSlice(dbase, []module.Validator{v})
+	assert.NoError(t, err)
+	votes := newCommitVoteSetWithTimestamp(true, 10)
+	blk := &blockV2{
+		height:             1,
+		timestamp:          10,
+		proposer:           addr,
+		prevID:             make([]byte, 32),
+		logsBloom:          txresult.NewLogsBloom(nil),
+		patchTransactions:  transaction.NewTransactionListFromSlice(dbase, nil),
+		normalTransactions: transaction.NewTransactionListFromSlice(dbase, nil),
+		nextValidatorsHash: nvs.Hash(),
+		_nextValidators:    nvs,
+		votes:              votes,
+	}
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, blk.MarshalHeader(buf))
+	header := buf.Bytes()
+
+	dbase2 := newMapDB()
+	hb, err := NewHeaderBlock(dbase2, newCommitVoteSetFromBytes,
+		header, votes.Bytes(), nvs.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, blk.ID(), hb.ID())
+	assert.Equal(t, votes.Hash(), hb.Votes().Hash())
+	assert.Equal(t, 1, hb.NextValidators().Len())
+	assert.Equal(t, blk.NormalTransactions().Hash(), hb.NormalTransactions().Hash())
+
+	_, err = NewHeaderBlock(dbase2, newCommitVoteSetFromBytes,
+		header, newCommitVoteSetWithTimestamp(true, 11).Bytes(), nvs.Bytes())
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+	_, err = NewHeaderBlock(dbase2, newCommitVoteSetFromBytes,
+		header, votes.Bytes(), nil)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	_, err = GetHeaderBlock(dbase2, newCommitVoteSetFromBytes, blk.ID())
+	assert.True(t, errors.NotFoundError.Equals(err))
+	assert.NoError(t, hb.FinalizeHeader(dbase2))
+	assert.NoError(t, hb.NextValidators().Flush())
+	hb2, err := GetHeaderBlock(dbase2, newCommitVoteSetFromBytes, blk.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, blk.ID(), hb2.ID())
+	assert.Equal(t, nvs.Hash(), hb2.NextValidators().Hash())
+}
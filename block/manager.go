@@ -122,6 +122,8 @@ type manager struct {
 	handlers       handlerList
 	activeHandlers handlerList
 	handlerContext handlerContext
+
+	accumulator *blockAccumulator
 }
 
 type handlerList []base.BlockHandler
@@ -618,13 +620,23 @@ func NewManager(
 	if err != nil {
 		return nil, err
 	}
+	if m.accumulator, err = newBlockAccumulator(m.db()); err != nil {
+		return nil, err
+	}
 
 	var height int64
 	err = chainPropBucket.Get(db.Raw(keyLastBlockHeight), &height)
 	if errors.NotFoundError.Equals(err) || (err == nil && height == 0) {
+		// genesis is finalized again
+		if err := m.accumulator.reset(); err != nil {
+			return nil, err
+		}
 		if err := m.finalizeGenesis(); err != nil {
 			return nil, err
 		}
+		if err := m.accumulator.start(m.db(), m.finalized.block.Height(), m.log); err != nil {
+			return nil, err
+		}
 		return m, nil
 	} else if err != nil {
 		return nil, err
@@ -693,6 +705,9 @@ func NewManager(
 		m.bntr.TraceNew(bn)
 	}
 	m.nmap[string(lastFinalized.ID())] = bn
+	if err := m.accumulator.start(m.db(), height, m.log); err != nil {
+		return nil, err
+	}
 	return m, nil
 }
 
@@ -702,6 +717,8 @@ func (m *manager) Term() {
 
 	m.log.Debugf("Term block manager\n")
 
+	m.accumulator.term()
+
 	m.removeNode(m.finalized)
 	m.finalized = nil
 	m.running = false
@@ -799,7 +816,9 @@ func (m *manager) ImportBlock(
 	m.syncer.begin()
 	defer m.syncer.end()
 
-	m.log.Debugf("ImportBlock(%x)\n", block.ID())
+	m.log.WithFields(log.Fields{
+		log.FieldKeyHeight: block.Height(),
+	}).Debugf("ImportBlock(%x)", block.ID())
 
 	it, err := m._import(block, flags, cb)
 	if err != nil {
@@ -1111,8 +1130,13 @@ func (m *manager) finalize(bn *bnode) error {
 	if err = chainProp.Set(db.Raw(keyLastBlockHeight), block.Height()); err != nil {
 		return err
 	}
+	if err = m.accumulator.add(block.Height(), block.ID()); err != nil {
+		return err
+	}
 
-	m.log.Debugf("Finalize(%x)\n", block.ID())
+	m.log.WithFields(log.Fields{
+		log.FieldKeyHeight: block.Height(),
+	}).Debugf("Finalize(%x)", block.ID())
 	for i := 0; i < len(m.finalizationCBs); {
 		cb := m.finalizationCBs[i]
 		if cb(block) {
@@ -1443,6 +1467,14 @@ func (m *manager) doGetBlockByHeight(
 	return blk, err
 }
 
+func (m *manager) GetBlockAccumulator(height int64) (*module.BlockAccumulator, error) {
+	return m.accumulator.get(height)
+}
+
+func (m *manager) GetBlockWitness(height, at int64) ([][]byte, error) {
+	return m.accumulator.witness(height, at)
+}
+
 func (m *manager) GetLastBlock() (module.Block, error) {
 	m.syncer.begin()
 	defer m.syncer.end()
@@ -7,7 +7,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -397,3 +400,95 @@ func TestBlockManager_WaitForBlock_Block(t *testing.T) {
 		assert.Equal(t, blk.ID(), br.blk.ID())
 	}
 }
+
+func TestBlockManager_BlockAccumulator(t *testing.T) {
+	s := newBlockManagerTestSetUp(t)
+	const height = int64(9)
+	for i := int64(1); i <= height; i++ {
+		br := importSync(s.bm, s.bg.getReaderForBlock(i))
+		br.assertOK(t)
+		assert.NoError(t, s.bm.Finalize(br.blk))
+	}
+
+	assertWitnesses := func(bm module.BlockManager, base int64) {
+		for at := base; at <= height; at++ {
+			acc, err := bm.GetBlockAccumulator(at)
+			assert.NoError(t, err)
+			assert.Equal(t, base, acc.Base)
+			for h := base; h <= at; h++ {
+				blk, err := bm.GetBlockByHeight(h)
+				assert.NoError(t, err)
+				w, err := bm.GetBlockWitness(h, at)
+				assert.NoError(t, err)
+				assert.NoError(t, VerifyBlockWitness(acc, h, blk.ID(), w))
+				if h != at {
+					next, err := bm.GetBlockByHeight(h + 1)
+					assert.NoError(t, err)
+					assert.Error(t, VerifyBlockWitness(acc, h, next.ID(), w))
+				}
+			}
+			_, err = bm.GetBlockWitness(at+1, at)
+			assert.Error(t, err)
+		}
+		_, err := bm.GetBlockAccumulator(height + 1)
+		assert.True(t, errors.NotFoundError.Equals(err))
+	}
+	assertWitnesses(s.bm, 0)
+	acc, err := s.bm.GetBlockAccumulator(height)
+	assert.NoError(t, err)
+	accAtReset, err := s.bm.GetBlockAccumulator(height - 2)
+	assert.NoError(t, err)
+
+	// the accumulator is kept in the database
+	a, err := newBlockAccumulator(s.chain.Database())
+	assert.NoError(t, err)
+	assert.NoError(t, a.sync(s.chain.Database(), height))
+	acc2, err := a.get(height)
+	assert.NoError(t, err)
+	assert.Equal(t, acc, acc2)
+	assert.Equal(t, AccumulatorRootHash(acc), AccumulatorRootHash(acc2))
+
+	// the accumulator is built again from the genesis after reset
+	a, err = newBlockAccumulator(s.chain.Database())
+	assert.NoError(t, err)
+	assert.NoError(t, a.sync(s.chain.Database(), height-2))
+	acc, err = a.get(height - 2)
+	assert.NoError(t, err)
+	assert.Equal(t, accAtReset, acc)
+
+	// the accumulator starting after the genesis is built again
+	assert.NoError(t, a.reset())
+	hb, err := db.NewCodedBucket(s.chain.Database(), db.BlockHeaderHashByHeight, nil)
+	assert.NoError(t, err)
+	id, err := hb.GetBytes(height)
+	assert.NoError(t, err)
+	assert.NoError(t, a.add(height, id))
+	assert.NoError(t, a.sync(s.chain.Database(), height))
+	acc, err = a.get(height)
+	assert.NoError(t, err)
+	assert.Equal(t, acc2, acc)
+
+	// the accumulator is built in background with blocks finalized meanwhile
+	assert.NoError(t, a.reset())
+	assert.NoError(t, a.start(s.chain.Database(), height-1, log.New()))
+	assert.NoError(t, a.add(height, id))
+	<-a.done
+	acc, err = a.get(height)
+	assert.NoError(t, err)
+	assert.Equal(t, acc2, acc)
+	a.term()
+
+	// the accumulator starts from the lowest block if blocks are pruned
+	bk, err := s.chain.Database().GetBucket(db.BlockHeaderHashByHeight)
+	assert.NoError(t, err)
+	for h := int64(0); h < 3; h++ {
+		assert.NoError(t, bk.Delete(codec.BC.MustMarshalToBytes(h)))
+	}
+	assert.NoError(t, a.reset())
+	assert.NoError(t, a.sync(s.chain.Database(), height))
+	acc, err = a.get(height)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, acc.Base)
+	_, err = a.get(2)
+	assert.True(t, errors.NotFoundError.Equals(err))
+}
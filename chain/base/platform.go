@@ -28,11 +28,19 @@ import (
 	"github.com/icon-project/goloop/service/txresult"
 )
 
+// FlagRewardHistory is the name of the database flag set if the chain is
+// configured to keep the history of rewards for each term.
+const FlagRewardHistory = "reward_history"
+
+// FlagValidationHistory is the name of the database flag set if the chain is
+// configured to keep the history of block validation of validators.
+const FlagValidationHistory = "validation_history"
+
 type Platform interface {
 	NewContractManager(dbase db.Database, dir string, logger log.Logger) (contract.ContractManager, error)
 	NewExtensionSnapshot(dbase db.Database, raw []byte) state.ExtensionSnapshot
 	NewExtensionWithBuilder(builder merkle.Builder, raw []byte) state.ExtensionSnapshot
-	OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, logger log.Logger)
+	OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, bi module.BlockInfo, logger log.Logger)
 	ToRevision(value int) module.Revision
 	NewBaseTransaction(wc state.WorldContext) (module.Transaction, error)
 	OnExecutionBegin(wc state.WorldContext, logger log.Logger) error
@@ -45,6 +53,14 @@ type Platform interface {
 	Term()
 }
 
+// HistoryReader is implemented by the platform keeping the history of the
+// chain in the node-local database. The history isn't a part of the state,
+// so it's served only by the nodes configured to keep it.
+type HistoryReader interface {
+	GetRewardHistory(dbase db.Database, addr module.Address, start int64, limit int) (interface{}, error)
+	GetValidationHistory(dbase db.Database, ess state.ExtensionSnapshot, owner module.Address, start, end int64, limit int) (interface{}, error)
+}
+
 type ExecutionResult interface {
 	PatchReceipts() module.ReceiptList
 	NormalReceipts() module.ReceiptList
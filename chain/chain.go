@@ -13,6 +13,7 @@ import (
 	"github.com/icon-project/goloop/block"
 	"github.com/icon-project/goloop/chain/base"
 	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/chain/observer"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -22,8 +23,10 @@ import (
 	"github.com/icon-project/goloop/network"
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/server/sink"
 	"github.com/icon-project/goloop/service"
 	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/state"
 )
 
 type State int
@@ -84,6 +87,7 @@ type singleChain struct {
 	bm       module.BlockManager
 	cs       module.Consensus
 	srv      *server.Manager
+	sinks    *sink.Manager
 	nt       module.NetworkTransport
 	nm       module.NetworkManager
 	plt      base.Platform
@@ -271,6 +275,9 @@ func (c *singleChain) lastBlockHeight() int64 {
 	if c.database == nil {
 		return 0
 	}
+	if c.cfg.Observer {
+		return observer.GetLastHeightOf(c.database)
+	}
 	return block.GetLastHeightOf(c.database)
 }
 
@@ -393,6 +400,18 @@ func (c *singleChain) prepareDatabase(chainDir string) error {
 	}
 	cacheDir := path.Join(chainDir, DefaultCacheDir)
 	c.database = cache.AttachManager(cdb, cacheDir, mLevel, fLevel, stores)
+	if c.cfg.FlatState {
+		if c.database, err = state.AttachFlatStateManager(c.database); err != nil {
+			_ = cdb.Close()
+			return errors.Wrap(err, "FailToAttachFlatState")
+		}
+	}
+	if c.cfg.RewardHistory || c.cfg.ValidationHistory {
+		c.database = db.WithFlags(c.database, db.Flags{
+			base.FlagRewardHistory:     c.cfg.RewardHistory,
+			base.FlagValidationHistory: c.cfg.ValidationHistory,
+		})
+	}
 	return nil
 }
 
@@ -463,7 +482,60 @@ func (c *singleChain) prepareManagers() error {
 	return nil
 }
 
+// prepareObserver prepares the managers for observer mode. It has no
+// service manager, so it only follows headers of blocks.
+func (c *singleChain) prepareObserver() error {
+	pr := network.PeerRoleFlag(c.cfg.Role)
+	c.nm = network.NewManager(c, c.nt, c.cfg.SeedAddr, pr.ToRoles()...)
+
+	var err error
+	c.bm, c.cs, err = observer.New(c, c.observerRoot)
+	return err
+}
+
+// observerRoot returns the last block finalized by the block manager, which
+// is used as the root of trust for the observer.
+func (c *singleChain) observerRoot() (module.Block, error) {
+	ContractDir := path.Join(c.cfg.AbsBaseDir(), DefaultContractDir)
+	sm, err := service.NewManager(c, c.nm, c.pm, c.plt, ContractDir)
+	if err != nil {
+		return nil, err
+	}
+	c.sm = sm
+	defer func() {
+		sm.Term()
+		c.sm = nil
+	}()
+	bm, err := block.NewManager(c, nil, c.plt.NewBlockHandlers(c))
+	if err != nil {
+		return nil, err
+	}
+	defer bm.Term()
+	return bm.GetLastBlock()
+}
+
+func (c *singleChain) startSinks() error {
+	if len(c.cfg.EventSinks) == 0 {
+		return nil
+	}
+	sm, err := sink.NewManager(c, c.cfg.EventSinks, c.cfg.AbsBaseDir())
+	if err != nil {
+		return err
+	}
+	sm.Start()
+	c.sinks = sm
+	return nil
+}
+
+func (c *singleChain) stopSinks() {
+	if c.sinks != nil {
+		c.sinks.Stop()
+		c.sinks = nil
+	}
+}
+
 func (c *singleChain) releaseManagers() {
+	c.stopSinks()
 	if c.cs != nil {
 		c.cs.Term()
 		c.cs = nil
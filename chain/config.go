@@ -10,6 +10,7 @@ import (
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/sink"
 )
 
 const (
@@ -36,17 +37,23 @@ type Config struct {
 	Platform string `json:"platform,omitempty"`
 
 	// static
-	SeedAddr         string `json:"seed_addr"`
-	Role             uint   `json:"role"`
-	ConcurrencyLevel int    `json:"concurrency_level,omitempty"`
-	NormalTxPoolSize int    `json:"normal_tx_pool,omitempty"`
-	PatchTxPoolSize  int    `json:"patch_tx_pool,omitempty"`
-	MaxBlockTxBytes  int    `json:"max_block_tx_bytes,omitempty"`
-	NodeCache        string `json:"node_cache,omitempty"`
-	AutoStart        bool   `json:"auto_start,omitempty"`
-	ChildrenLimit    *int   `json:"children_limit,omitempty"`
-	NephewsLimit     *int   `json:"nephews_limit,omitempty"`
-	ValidateTxOnSend bool   `json:"validate_tx_on_send,omitempty"`
+	SeedAddr          string `json:"seed_addr"`
+	Role              uint   `json:"role"`
+	ConcurrencyLevel  int    `json:"concurrency_level,omitempty"`
+	NormalTxPoolSize  int    `json:"normal_tx_pool,omitempty"`
+	PatchTxPoolSize   int    `json:"patch_tx_pool,omitempty"`
+	MaxBlockTxBytes   int    `json:"max_block_tx_bytes,omitempty"`
+	NodeCache         string `json:"node_cache,omitempty"`
+	FlatState         bool   `json:"flat_state,omitempty"`
+	RewardHistory     bool   `json:"reward_history,omitempty"`
+	ValidationHistory bool   `json:"validation_history,omitempty"`
+	AutoStart         bool   `json:"auto_start,omitempty"`
+	ChildrenLimit     *int   `json:"children_limit,omitempty"`
+	NephewsLimit      *int   `json:"nephews_limit,omitempty"`
+	ValidateTxOnSend  bool   `json:"validate_tx_on_send,omitempty"`
+	Observer          bool   `json:"observer,omitempty"`
+
+	EventSinks []*sink.Config `json:"event_sinks,omitempty"`
 
 	// runtime
 	Channel        string `json:"channel"`
@@ -0,0 +1,363 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package observer
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/consensus/fastsync"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	keyLastHeight = "observer.lastHeight"
+	keyLastVotes  = "observer.lastVotes"
+)
+
+// blockManager keeps headers of finalized blocks with the votes in them and
+// their next validators. Transactions and results of blocks are not kept,
+// so only the methods reading headers are supported.
+type blockManager struct {
+	chain module.Chain
+	log   log.Logger
+
+	mtx       sync.Mutex
+	last      base.Block
+	lastVotes module.CommitVoteSet
+	waiters   map[int64][]chan module.Block
+}
+
+// GetLastHeightOf returns the height of the last block observed in the
+// database. It returns 0 if there is no block observed.
+func GetLastHeightOf(dbase db.Database) int64 {
+	prop, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return 0
+	}
+	var height int64
+	if err := prop.Get(db.Raw(keyLastHeight), &height); err != nil {
+		return 0
+	}
+	return height
+}
+
+func unsupported(method string) error {
+	return errors.UnsupportedError.Errorf("UnsupportedInObserver(method=%s)", method)
+}
+
+func newBlockManager(
+	c module.Chain, root func() (module.Block, error),
+) (*blockManager, error) {
+	m := &blockManager{
+		chain:   c,
+		log:     c.Logger().WithFields(log.Fields{log.FieldKeyModule: "OBS"}),
+		waiters: make(map[int64][]chan module.Block),
+	}
+	if err := m.load(); err == nil {
+		return m, nil
+	} else if !errors.NotFoundError.Equals(err) {
+		return nil, err
+	}
+
+	blk, err := root()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := blk.MarshalHeader(buf); err != nil {
+		return nil, err
+	}
+	var nvs []byte
+	if blk.NextValidators() != nil {
+		nvs = blk.NextValidators().Bytes()
+	}
+	rb, err := block.NewHeaderBlock(c.Database(), c.CommitVoteSetDecoder(),
+		buf.Bytes(), blk.Votes().Bytes(), nvs)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.store(rb, nil); err != nil {
+		return nil, err
+	}
+	m.log.Infof("Observe from the block height=%d id=%#x", rb.Height(), rb.ID())
+	return m, nil
+}
+
+func (m *blockManager) load() error {
+	dbase := m.chain.Database()
+	prop, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return err
+	}
+	var height int64
+	if err := prop.Get(db.Raw(keyLastHeight), &height); err != nil {
+		return err
+	}
+	blk, err := m.getBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	m.last = blk
+	hash, err := prop.GetBytes(db.Raw(keyLastVotes))
+	if err != nil && !errors.NotFoundError.Equals(err) {
+		return err
+	}
+	if len(hash) > 0 {
+		bs, err := db.DoGetWithBucketID(dbase, db.BytesByHash, hash)
+		if err != nil {
+			return err
+		}
+		m.lastVotes = m.chain.CommitVoteSetDecoder()(bs)
+	}
+	return nil
+}
+
+func (m *blockManager) store(blk base.Block, votes module.CommitVoteSet) error {
+	dbase := m.chain.Database()
+	if err := blk.FinalizeHeader(dbase); err != nil {
+		return err
+	}
+	if err := blk.NextValidators().Flush(); err != nil {
+		return err
+	}
+	prop, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return err
+	}
+	var votesHash []byte
+	if votes != nil {
+		hb, err := db.NewCodedBucket(dbase, db.BytesByHash, nil)
+		if err != nil {
+			return err
+		}
+		votesHash = votes.Hash()
+		if err := hb.Set(db.Raw(votesHash), db.Raw(votes.Bytes())); err != nil {
+			return err
+		}
+	}
+	if err := prop.Set(db.Raw(keyLastVotes), db.Raw(votesHash)); err != nil {
+		return err
+	}
+	if err := prop.Set(db.Raw(keyLastHeight), blk.Height()); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.last = blk
+	m.lastVotes = votes
+	for _, ch := range m.waiters[blk.Height()] {
+		ch <- blk
+	}
+	delete(m.waiters, blk.Height())
+	return nil
+}
+
+// finalize verifies the block with the votes for it, and stores the block
+// as the last one. The block shall follow the last block.
+func (m *blockManager) finalize(blk base.Block, votes module.CommitVoteSet) error {
+	last, _ := m.lastBlock()
+	if blk.Height() != last.Height()+1 || !bytes.Equal(blk.PrevID(), last.ID()) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidPrevBlock(height=%d,prev=%#x,last=%d,lastID=%#x)",
+			blk.Height(), blk.PrevID(), last.Height(), last.ID())
+	}
+	voters := last.NextValidators()
+	if voters == nil || voters.Len() == 0 {
+		return errors.InvalidStateError.Errorf(
+			"NoVoters(height=%d)", blk.Height())
+	}
+	if votes == nil {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidVotes(height=%d)", blk.Height())
+	}
+	if _, err := votes.VerifyBlock(blk, voters); err != nil {
+		return errors.IllegalArgumentError.Wrapf(err,
+			"InvalidVotes(height=%d)", blk.Height())
+	}
+	if err := blk.VerifyTimestamp(last, voters); err != nil {
+		return errors.IllegalArgumentError.Wrapf(err,
+			"InvalidTimestamp(height=%d)", blk.Height())
+	}
+	return m.store(blk, votes)
+}
+
+func (m *blockManager) lastBlock() (base.Block, module.CommitVoteSet) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.last, m.lastVotes
+}
+
+func (m *blockManager) getBlockByHeight(height int64) (base.Block, error) {
+	dbase := m.chain.Database()
+	hh, err := db.NewCodedBucket(dbase, db.BlockHeaderHashByHeight, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := hh.GetBytes(height)
+	if err != nil {
+		return nil, err
+	}
+	return block.GetHeaderBlock(dbase, m.chain.CommitVoteSetDecoder(), id)
+}
+
+// getVotes returns the votes for the block at the height. It's the votes in
+// the next block or the votes received with the last block.
+func (m *blockManager) getVotes(height int64) (module.CommitVoteSet, error) {
+	last, lastVotes := m.lastBlock()
+	if height > last.Height() || height < 0 {
+		return nil, errors.NotFoundError.Errorf("NoVotes(height=%d)", height)
+	}
+	if height == last.Height() {
+		if lastVotes == nil {
+			return nil, errors.NotFoundError.Errorf("NoVotes(height=%d)", height)
+		}
+		return lastVotes, nil
+	}
+	next, err := m.getBlockByHeight(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	return next.Votes(), nil
+}
+
+func (m *blockManager) GetBlockByHeight(height int64) (module.Block, error) {
+	last, _ := m.lastBlock()
+	if height > last.Height() {
+		return nil, errors.NotFoundError.Errorf("NoBlock(height=%d)", height)
+	} else if height == last.Height() {
+		return last, nil
+	}
+	return m.getBlockByHeight(height)
+}
+
+func (m *blockManager) GetLastBlock() (module.Block, error) {
+	last, _ := m.lastBlock()
+	return last, nil
+}
+
+func (m *blockManager) GetBlock(id []byte) (module.Block, error) {
+	return block.GetHeaderBlock(m.chain.Database(),
+		m.chain.CommitVoteSetDecoder(), id)
+}
+
+func (m *blockManager) WaitForBlock(height int64) (<-chan module.Block, error) {
+	bch := make(chan module.Block, 1)
+	m.mtx.Lock()
+	if height > m.last.Height() {
+		m.waiters[height] = append(m.waiters[height], bch)
+		m.mtx.Unlock()
+		return bch, nil
+	}
+	m.mtx.Unlock()
+
+	blk, err := m.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	bch <- blk
+	return bch, nil
+}
+
+// NewBlockDataFromReader reads fastsync.BlockHeaderData instead of the
+// whole block.
+func (m *blockManager) NewBlockDataFromReader(r io.Reader) (module.BlockData, error) {
+	var data fastsync.BlockHeaderData
+	if err := codec.BC.Unmarshal(r, &data); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidHeaderData")
+	}
+	return block.NewHeaderBlock(m.chain.Database(),
+		m.chain.CommitVoteSetDecoder(),
+		data.Header, data.Votes, data.NextValidators)
+}
+
+func (m *blockManager) Propose(parentID []byte, votes module.CommitVoteSet, cb func(module.BlockCandidate, error)) (module.Canceler, error) {
+	return nil, unsupported("Propose")
+}
+
+func (m *blockManager) Import(r io.Reader, flags int, cb func(module.BlockCandidate, error)) (module.Canceler, error) {
+	return nil, unsupported("Import")
+}
+
+func (m *blockManager) ImportBlock(blk module.BlockData, flags int, cb func(module.BlockCandidate, error)) (module.Canceler, error) {
+	return nil, unsupported("ImportBlock")
+}
+
+func (m *blockManager) Commit(module.BlockCandidate) error {
+	return unsupported("Commit")
+}
+
+func (m *blockManager) Finalize(module.BlockCandidate) error {
+	return unsupported("Finalize")
+}
+
+func (m *blockManager) GetTransactionInfo(id []byte) (module.TransactionInfo, error) {
+	return nil, unsupported("GetTransactionInfo")
+}
+
+func (m *blockManager) Term() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.waiters = make(map[int64][]chan module.Block)
+}
+
+func (m *blockManager) WaitForTransaction(parentID []byte, cb func()) bool {
+	return false
+}
+
+func (m *blockManager) SendTransactionAndWait(result []byte, height int64, txi interface{}) ([]byte, <-chan interface{}, error) {
+	return nil, nil, unsupported("SendTransactionAndWait")
+}
+
+func (m *blockManager) WaitTransactionResult(id []byte) (<-chan interface{}, error) {
+	return nil, unsupported("WaitTransactionResult")
+}
+
+func (m *blockManager) ExportBlocks(from, to int64, dst db.Database, on func(height int64) error) error {
+	return unsupported("ExportBlocks")
+}
+
+func (m *blockManager) ExportGenesis(blk module.Block, writer module.GenesisStorageWriter) error {
+	return unsupported("ExportGenesis")
+}
+
+func (m *blockManager) GetGenesisData() (module.Block, module.CommitVoteSet, error) {
+	return nil, nil, unsupported("GetGenesisData")
+}
+
+func (m *blockManager) NewConsensusInfo(blk module.Block) (module.ConsensusInfo, error) {
+	return nil, unsupported("NewConsensusInfo")
+}
+
+func (m *blockManager) GetBlockAccumulator(height int64) (*module.BlockAccumulator, error) {
+	return nil, unsupported("GetBlockAccumulator")
+}
+
+func (m *blockManager) GetBlockWitness(height, at int64) ([][]byte, error) {
+	return nil, unsupported("GetBlockWitness")
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package observer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/test"
+)
+
+func toHeaderBlock(t *testing.T, c module.Chain, blk module.Block) base.Block {
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, blk.MarshalHeader(buf))
+	hb, err := block.NewHeaderBlock(c.Database(), c.CommitVoteSetDecoder(),
+		buf.Bytes(), blk.Votes().Bytes(), blk.NextValidators().Bytes())
+	assert.NoError(t, err)
+	return hb
+}
+
+func TestBlockManager_Finalize(t *testing.T) {
+	f := test.NewFixture(t, test.AddDefaultNode(false), test.AddValidatorNodes(1))
+	defer f.Close()
+	nd := f.Node
+
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	nd.ProposeFinalizeBlock(nd.NewVoteListForLastBlock())
+	nd.ProposeFinalizeBlock(nd.NewVoteListForLastBlock())
+	lastVotes := nd.NewVoteListForLastBlock()
+	blks := make([]module.Block, 4)
+	for i := range blks {
+		blk, err := nd.BM.GetBlockByHeight(int64(i))
+		assert.NoError(t, err)
+		blks[i] = blk
+	}
+
+	c, err := test.NewChain(t, nd.Chain.Wallet(), db.NewMapDB(), log.New(),
+		consensus.NewCommitVoteSetFromBytes, string(nd.Chain.Genesis()))
+	assert.NoError(t, err)
+	bm, err := newBlockManager(c, func() (module.Block, error) {
+		return blks[0], nil
+	})
+	assert.NoError(t, err)
+	_, err = bm.getVotes(0)
+	assert.Error(t, err)
+
+	// votes for other block
+	err = bm.finalize(toHeaderBlock(t, c, blks[1]), blks[3].Votes())
+	assert.Error(t, err)
+	// block not following the last
+	err = bm.finalize(toHeaderBlock(t, c, blks[2]), blks[3].Votes())
+	assert.Error(t, err)
+
+	for i := 1; i < len(blks); i++ {
+		votes := lastVotes
+		if i+1 < len(blks) {
+			votes = blks[i+1].Votes()
+		}
+		err = bm.finalize(toHeaderBlock(t, c, blks[i]), votes)
+		assert.NoError(t, err)
+	}
+
+	for i := range blks {
+		blk, err := bm.GetBlockByHeight(int64(i))
+		assert.NoError(t, err)
+		assert.Equal(t, blks[i].ID(), blk.ID())
+	}
+	votes, err := bm.getVotes(1)
+	assert.NoError(t, err)
+	assert.Equal(t, blks[2].Votes().Hash(), votes.Hash())
+	votes, err = bm.getVotes(3)
+	assert.NoError(t, err)
+	assert.Equal(t, lastVotes.Hash(), votes.Hash())
+
+	bm2, err := newBlockManager(c, nil)
+	assert.NoError(t, err)
+	blk, err := bm2.GetLastBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, blks[3].ID(), blk.ID())
+	votes, err = bm2.getVotes(3)
+	assert.NoError(t, err)
+	assert.Equal(t, lastVotes.Hash(), votes.Hash())
+	assert.EqualValues(t, 3, GetLastHeightOf(c.Database()))
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package observer follows a chain with headers of blocks and the votes for
+// them without executing transactions. Blocks are verified with the votes
+// signed by the next validators of the previous block, starting from a
+// root block trusted by the node.
+package observer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/consensus/fastsync"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	configRetryInterval = time.Second
+)
+
+type observer struct {
+	chain module.Chain
+	bm    *blockManager
+	log   log.Logger
+
+	mtx      sync.Mutex
+	fsm      fastsync.Manager
+	canceler func() bool
+	timer    *time.Timer
+}
+
+// New returns the block manager and the consensus of the observer.
+// If there is no block observed, root is called for the block to start
+// with, which shall be version 2 or later.
+func New(
+	c module.Chain, root func() (module.Block, error),
+) (module.BlockManager, module.Consensus, error) {
+	bm, err := newBlockManager(c, root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bm, &observer{
+		chain: c,
+		bm:    bm,
+		log:   bm.log,
+	}, nil
+}
+
+func (o *observer) Start() error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.fsm != nil {
+		return errors.InvalidStateError.New("AlreadyStarted")
+	}
+	fsm, err := fastsync.NewManager(o.chain.NetworkManager(), o.bm, o, o.log)
+	if err != nil {
+		return err
+	}
+	fsm.StartServer()
+	o.fsm = fsm
+	o._fetch()
+	return nil
+}
+
+func (o *observer) _fetch() {
+	last, _ := o.bm.lastBlock()
+	canceler, err := o.fsm.FetchHeaders(last.Height()+1, -1, o)
+	if err != nil {
+		o.log.Warnf("Fail to fetch headers height=%d err=%+v",
+			last.Height()+1, err)
+		o._retry()
+		return
+	}
+	o.canceler = canceler
+}
+
+func (o *observer) _retry() {
+	o.timer = time.AfterFunc(configRetryInterval, func() {
+		o.mtx.Lock()
+		defer o.mtx.Unlock()
+
+		if o.fsm == nil || o.timer == nil {
+			return
+		}
+		o.timer = nil
+		o._fetch()
+	})
+}
+
+func (o *observer) OnBlock(br fastsync.BlockResult) {
+	blk, ok := br.Block().(base.Block)
+	if !ok {
+		br.Reject()
+		return
+	}
+	votes := o.chain.CommitVoteSetDecoder()(br.Votes())
+	if err := o.bm.finalize(blk, votes); err != nil {
+		o.log.Warnf("Fail to finalize height=%d err=%+v", blk.Height(), err)
+		br.Reject()
+		return
+	}
+	o.log.Debugf("Finalize height=%d id=%#x", blk.Height(), blk.ID())
+	br.Consume()
+}
+
+func (o *observer) OnEnd(err error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.fsm == nil {
+		return
+	}
+	o.log.Debugf("End of fetching headers err=%v", err)
+	o.canceler = nil
+	o._retry()
+}
+
+func (o *observer) GetBlockProof(height int64, opt int32) ([]byte, error) {
+	votes, err := o.bm.getVotes(height)
+	if err != nil {
+		return nil, err
+	}
+	return votes.Bytes(), nil
+}
+
+func (o *observer) Term() {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.fsm == nil {
+		return
+	}
+	if o.canceler != nil {
+		o.canceler()
+		o.canceler = nil
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	o.fsm.StopServer()
+	o.fsm.Term()
+	o.fsm = nil
+}
+
+func (o *observer) GetStatus() *module.ConsensusStatus {
+	last, _ := o.bm.lastBlock()
+	return &module.ConsensusStatus{
+		Height: last.Height() + 1,
+	}
+}
+
+func (o *observer) GetVotesByHeight(height int64) (module.CommitVoteSet, error) {
+	return o.bm.getVotes(height)
+}
@@ -33,6 +33,9 @@ var consensusStates = map[State]string{
 }
 
 func (t *taskConsensus) String() string {
+	if t.chain.cfg.Observer {
+		return "Observer"
+	}
 	return "Consensus"
 }
 
@@ -45,7 +48,11 @@ func (t *taskConsensus) DetailOf(s State) string {
 }
 
 func (t *taskConsensus) Start() error {
-	if err := t.chain.prepareManagers(); err != nil {
+	prepare := t.chain.prepareManagers
+	if t.chain.cfg.Observer {
+		prepare = t.chain.prepareObserver
+	}
+	if err := prepare(); err != nil {
 		t.result.SetValue(err)
 		return err
 	}
@@ -58,7 +65,9 @@ func (t *taskConsensus) Start() error {
 }
 
 func (t *taskConsensus) _start(c *singleChain) error {
-	c.sm.Start()
+	if c.sm != nil {
+		c.sm.Start()
+	}
 	if err := c.cs.Start(); err != nil {
 		return err
 	}
@@ -66,6 +75,12 @@ func (t *taskConsensus) _start(c *singleChain) error {
 	if err := c.nm.Start(); err != nil {
 		return err
 	}
+	if c.sm == nil {
+		return nil
+	}
+	if err := c.startSinks(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/iiss"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+)
+
+const (
+	ExportStakeTask = "export_stake"
+	ExportStakeName = "ExportStake"
+)
+
+var exportStakeStates = map[State]string{
+	Starting: "export_stake starting",
+	Stopping: "export_stake stopping",
+	Failed:   "export_stake failed",
+}
+
+// exportStakeParams are parameters of export_stake task. It exports
+// the state at Height, or at each term start in [From, To].
+type exportStakeParams struct {
+	Height   int64             `json:"height,omitempty"`
+	From     int64             `json:"from,omitempty"`
+	To       int64             `json:"to,omitempty"`
+	Format   string            `json:"format,omitempty"`
+	Output   string            `json:"output"`
+	Accounts []*common.Address `json:"accounts,omitempty"`
+}
+
+type taskExportStake struct {
+	chain  *singleChain
+	params *exportStakeParams
+	file   string
+	writer iiss.StakeWriter
+
+	height   int64
+	accounts int64
+	missing  int64
+	stop     int32
+	result   resultStore
+}
+
+func (t *taskExportStake) String() string {
+	return fmt.Sprintf("%s(file=%s)", ExportStakeName, path.Base(t.file))
+}
+
+func (t *taskExportStake) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("%s height=%d accounts=%d missing=%d", ExportStakeTask,
+			atomic.LoadInt64(&t.height), atomic.LoadInt64(&t.accounts),
+			atomic.LoadInt64(&t.missing))
+	case Finished:
+		// accounts having only stake can't be found from the state, so
+		// it's complete only if all of them are given in accounts.
+		accounts, missing := atomic.LoadInt64(&t.accounts), atomic.LoadInt64(&t.missing)
+		if missing > 0 {
+			return fmt.Sprintf("%s incomplete accounts=%d missing=%d", ExportStakeTask,
+				accounts, missing)
+		}
+		return fmt.Sprintf("%s done accounts=%d missing=0", ExportStakeTask, accounts)
+	default:
+		if st, ok := exportStakeStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskExportStake) Start() (ret error) {
+	p := t.params
+	if p.Height > 0 && (p.From > 0 || p.To > 0) {
+		return errors.IllegalArgumentError.New("HeightWithRange")
+	}
+	if p.Height <= 0 && (p.From <= 0 || p.To < p.From) {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidRange(height=%d,from=%d,to=%d)", p.Height, p.From, p.To)
+	}
+	if p.Output == "" {
+		return errors.IllegalArgumentError.New("NoOutput")
+	}
+	if filepath.IsAbs(p.Output) {
+		t.file = p.Output
+	} else {
+		t.file = path.Join(t.chain.cfg.AbsBaseDir(), p.Output)
+	}
+
+	tmp, err := ioutil.TempFile(path.Dir(t.file), path.Base(t.file))
+	if err != nil {
+		return errors.Wrap(err, "FailToMakeTemporalFile")
+	}
+	defer func() {
+		if ret != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	bw := bufio.NewWriter(tmp)
+	if t.writer, err = iiss.NewStakeWriter(p.Format, bw); err != nil {
+		return err
+	}
+
+	if err := t.chain.prepareManagers(); err != nil {
+		return err
+	}
+	last, err := t.chain.bm.GetLastBlock()
+	if err != nil {
+		t.chain.releaseManagers()
+		return err
+	}
+	end := p.Height
+	if end == 0 {
+		end = p.To
+	}
+	if end > last.Height() {
+		t.chain.releaseManagers()
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d,last=%d)", end, last.Height())
+	}
+
+	go func() {
+		err := t._export()
+		if err == nil {
+			err = t.writer.Flush()
+		}
+		if err == nil {
+			err = bw.Flush()
+		}
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), t.file)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+		t.result.SetValue(err)
+	}()
+	return nil
+}
+
+func (t *taskExportStake) _interrupted() bool {
+	return atomic.LoadInt32(&t.stop) != 0
+}
+
+// _snapshotAt returns the extension snapshot of the state at the height,
+// which is the result of the block at the height.
+func (t *taskExportStake) _snapshotAt(height int64) (*iiss.ExtensionSnapshotImpl, *icstate.TermSnapshot, error) {
+	blk, err := t.chain.bm.GetBlockByHeight(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	wss, err := service.NewWorldSnapshot(t.chain.Database(), t.chain.plt, blk.Result(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	ess, ok := wss.GetExtensionSnapshot().(*iiss.ExtensionSnapshotImpl)
+	if !ok || ess == nil {
+		return nil, nil, errors.NotFoundError.Errorf("NoIISSState(height=%d)", height)
+	}
+	term := ess.State().NewState(true).GetTermSnapshot()
+	if term == nil {
+		return nil, nil, errors.NotFoundError.Errorf("NoTerm(height=%d)", height)
+	}
+	return ess, term, nil
+}
+
+func (t *taskExportStake) _exportAt(ess *iiss.ExtensionSnapshotImpl, term *icstate.TermSnapshot, height int64) error {
+	atomic.StoreInt64(&t.height, height)
+	extra := make([]module.Address, len(t.params.Accounts))
+	for i, addr := range t.params.Accounts {
+		extra[i] = addr
+	}
+	missing, err := ess.ForEachStaker(extra, func(addr module.Address, account *icstate.AccountSnapshot) error {
+		if t._interrupted() {
+			return errors.ErrInterrupted
+		}
+		atomic.AddInt64(&t.accounts, 1)
+		return t.writer.Write(height, term, addr, account)
+	})
+	if err != nil {
+		return err
+	}
+	if missing > 0 {
+		t.chain.logger.Warnf("ExportStake misses accounts height=%d missing=%d", height, missing)
+	}
+	atomic.AddInt64(&t.missing, int64(missing))
+	return nil
+}
+
+func (t *taskExportStake) _export() error {
+	defer t.chain.releaseManagers()
+
+	p := t.params
+	if p.Height > 0 {
+		ess, term, err := t._snapshotAt(p.Height)
+		if err != nil {
+			return err
+		}
+		return t._exportAt(ess, term, p.Height)
+	}
+
+	// find the first term starting from the height or later
+	_, term, err := t._snapshotAt(p.From)
+	if err != nil {
+		return err
+	}
+	height := term.StartHeight()
+	if height < p.From {
+		height = term.GetEndHeight() + 1
+	}
+	for height <= p.To {
+		ess, term, err := t._snapshotAt(height)
+		if err != nil {
+			return err
+		}
+		if term.StartHeight() != height {
+			return errors.InvalidStateError.Errorf(
+				"InvalidTermStart(height=%d,term=%d)", height, term.StartHeight())
+		}
+		if err = t._exportAt(ess, term, height); err != nil {
+			return err
+		}
+		height = term.GetEndHeight() + 1
+	}
+	return nil
+}
+
+func (t *taskExportStake) Stop() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+func (t *taskExportStake) Wait() error {
+	return t.result.Wait()
+}
+
+func taskExportStakeFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(exportStakeParams)
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+	return &taskExportStake{
+		chain:  c,
+		params: p,
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(ExportStakeTask, taskExportStakeFactory)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	VerifyFlatStateTask = "verify_flat_state"
+	VerifyFlatStateName = "VerifyFlatState"
+)
+
+var verifyFlatStateStates = map[State]string{
+	Starting: "verify_flat_state starting",
+	Stopping: "verify_flat_state stopping",
+	Failed:   "verify_flat_state failed",
+	Finished: "verify_flat_state done",
+}
+
+// taskVerifyFlatState compares the flat state with the world state of the
+// last block.
+type taskVerifyFlatState struct {
+	chain *singleChain
+
+	height   int64
+	accounts int64
+	stop     int32
+	result   resultStore
+}
+
+func (t *taskVerifyFlatState) String() string {
+	return VerifyFlatStateName
+}
+
+func (t *taskVerifyFlatState) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("%s height=%d accounts=%d", VerifyFlatStateTask,
+			atomic.LoadInt64(&t.height), atomic.LoadInt64(&t.accounts))
+	default:
+		if st, ok := verifyFlatStateStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskVerifyFlatState) Start() error {
+	if !t.chain.cfg.FlatState {
+		return errors.InvalidStateError.New("FlatStateDisabled")
+	}
+	if err := t.chain.prepareManagers(); err != nil {
+		return err
+	}
+	last, err := t.chain.bm.GetLastBlock()
+	if err != nil {
+		t.chain.releaseManagers()
+		return err
+	}
+	atomic.StoreInt64(&t.height, last.Height())
+
+	go func() {
+		defer t.chain.releaseManagers()
+		wss, err := service.NewWorldSnapshot(t.chain.Database(), t.chain.plt, last.Result(), nil)
+		if err == nil {
+			err = state.VerifyFlatState(wss, func(key []byte) error {
+				if atomic.LoadInt32(&t.stop) != 0 {
+					return errors.ErrInterrupted
+				}
+				atomic.AddInt64(&t.accounts, 1)
+				return nil
+			})
+		}
+		t.result.SetValue(err)
+	}()
+	return nil
+}
+
+func (t *taskVerifyFlatState) Stop() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+func (t *taskVerifyFlatState) Wait() error {
+	return t.result.Wait()
+}
+
+func taskVerifyFlatStateFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	return &taskVerifyFlatState{
+		chain: c,
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(VerifyFlatStateTask, taskVerifyFlatStateFactory)
+}
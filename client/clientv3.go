@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/icon-project/goloop/block"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
@@ -224,27 +226,42 @@ func (c *ClientV3) GetTransactionByHash(param *v3.TransactionHashParam) (*Transa
 
 var txSerializeExcludes = map[string]bool{"signature": true}
 
-func (c *ClientV3) SendTransaction(w module.Wallet, param *v3.TransactionParam) (*jsonrpc.HexBytes, error) {
-	param.Timestamp = jsonrpc.HexInt(intconv.FormatInt(time.Now().UnixNano() / int64(time.Microsecond)))
+// TransactionHash returns the hash of the transaction to be signed.
+func TransactionHash(param *v3.TransactionParam) ([]byte, error) {
 	js, err := json.Marshal(param)
 	if err != nil {
 		return nil, err
 	}
+	return transaction.HashOfTransactionJSON(js, transaction.Version3)
+}
 
-	bs, err := transaction.SerializeJSON(js, nil, txSerializeExcludes)
+// SignTransaction signs the transaction with the wallet, and sets the
+// signature of it. Other fields including the timestamp are kept.
+func SignTransaction(w module.Wallet, param *v3.TransactionParam) error {
+	hash, err := TransactionHash(param)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	bs = append([]byte("icx_sendTransaction."), bs...)
-	sig, err := w.Sign(crypto.SHA3Sum256(bs))
+	sig, err := w.Sign(hash)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
 	param.Signature = base64.StdEncoding.EncodeToString(sig)
+	return nil
+}
 
+func (c *ClientV3) SendTransaction(w module.Wallet, param *v3.TransactionParam) (*jsonrpc.HexBytes, error) {
+	param.Timestamp = jsonrpc.HexInt(intconv.FormatInt(time.Now().UnixNano() / int64(time.Microsecond)))
+	if err := SignTransaction(w, param); err != nil {
+		return nil, err
+	}
+	return c.SendSignedTransaction(param)
+}
+
+// SendSignedTransaction sends the transaction signed already.
+func (c *ClientV3) SendSignedTransaction(param *v3.TransactionParam) (*jsonrpc.HexBytes, error) {
 	var result jsonrpc.HexBytes
-	if _, err = c.Do("icx_sendTransaction", param, &result); err != nil {
+	if _, err := c.Do("icx_sendTransaction", param, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -317,6 +334,75 @@ func (c *ClientV3) GetProofForEvents(param *v3.ProofEventsParam) ([][][]byte, er
 	return result, nil
 }
 
+//refer server/v3/api_v3.go getBlockAccumulator
+type BlockAccumulator struct {
+	Height common.HexInt64   `json:"height"`
+	Base   common.HexInt64   `json:"base"`
+	Roots  []common.HexBytes `json:"roots"`
+	Root   common.HexBytes   `json:"root"`
+}
+
+func (a *BlockAccumulator) accumulator() *module.BlockAccumulator {
+	roots := make([][]byte, len(a.Roots))
+	for i, root := range a.Roots {
+		roots[i] = root
+	}
+	return &module.BlockAccumulator{
+		Height: a.Height.Value,
+		Base:   a.Base.Value,
+		Roots:  roots,
+	}
+}
+
+// Verify checks whether the root is the hash of the roots.
+func (a *BlockAccumulator) Verify() error {
+	if !bytes.Equal(a.Root, block.AccumulatorRootHash(a.accumulator())) {
+		return errors.IllegalArgumentError.Errorf("InvalidRoot(root=%s)", a.Root)
+	}
+	return nil
+}
+
+// VerifyBlock checks whether the block with the hash at the height is in
+// the accumulator with the witness. The accumulator is expected to be
+// trusted, or verified with Verify against the trusted root.
+func (a *BlockAccumulator) VerifyBlock(height int64, hash []byte, w *BlockWitness) error {
+	if w.At.Value != a.Height.Value || w.Height.Value != height {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidWitness(height=%d,at=%d,exp_height=%d,exp_at=%d)",
+			w.Height.Value, w.At.Value, height, a.Height.Value)
+	}
+	witness := make([][]byte, len(w.Witness))
+	for i, hv := range w.Witness {
+		witness[i] = hv
+	}
+	return block.VerifyBlockWitness(a.accumulator(), height, hash, witness)
+}
+
+//refer server/v3/api_v3.go getBlockWitness
+type BlockWitness struct {
+	Height  common.HexInt64   `json:"height"`
+	At      common.HexInt64   `json:"at"`
+	Witness []common.HexBytes `json:"witness"`
+}
+
+func (c *ClientV3) GetBlockAccumulator(param *v3.BlockHeightParam) (*BlockAccumulator, error) {
+	result := &BlockAccumulator{}
+	_, err := c.Do("icx_getBlockAccumulator", param, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *ClientV3) GetBlockWitness(param *v3.BlockWitnessParam) (*BlockWitness, error) {
+	result := &BlockWitness{}
+	_, err := c.Do("icx_getBlockWitness", param, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *ClientV3) MonitorBlock(param *server.BlockRequest, cb func(v *server.BlockNotification), cancelCh <-chan bool) error {
 	resp := &server.BlockNotification{}
 	return c.Monitor("/block", param, resp, func(v interface{}) {
@@ -361,11 +447,11 @@ func (c *ClientV3) Monitor(reqUrl string, reqPtr, respPtr interface{},
 			defer func() {
 				close(ch)
 			}()
-			c.wsReadJSONLoop(conn, respPtr, cb)
+			c.wsReadLoop(conn, respPtr, cb)
 		}()
 	} else {
 		defer c.wsClose(conn)
-		c.wsReadJSONLoop(conn, respPtr, cb)
+		c.wsReadLoop(conn, respPtr, cb)
 	}
 	return nil
 }
@@ -401,9 +487,9 @@ func (c *ClientV3) wsConnect(reqUrl string, reqHeader http.Header, reqPtr interf
 		return nil, nil, fmt.Errorf("fail to WriteJSON err:%+v", err)
 	}
 
-	if err = conn.ReadJSON(wsResp); err != nil {
+	if err = readWSResponse(conn, wsResp); err != nil {
 		conn.Close()
-		return nil, nil, fmt.Errorf("fail to ReadJSON err:%+v", err)
+		return nil, nil, fmt.Errorf("fail to read WSResponse err:%+v", err)
 	}
 
 	if wsResp.Code != 0 {
@@ -425,12 +511,12 @@ func (c *ClientV3) wsClose(conn *websocket.Conn) {
 	conn.Close()
 }
 
-func (c *ClientV3) wsReadJSONLoop(conn *websocket.Conn, respPtr interface{}, cb func(v interface{})) {
+func (c *ClientV3) wsReadLoop(conn *websocket.Conn, respPtr interface{}, cb func(v interface{})) {
 	elem := reflect.ValueOf(respPtr).Elem()
 	for {
 		v := reflect.New(elem.Type())
 		ptr := v.Interface()
-		if err := conn.ReadJSON(ptr); err != nil {
+		if err := readWSMessage(conn, ptr); err != nil {
 			cb(err)
 			return
 		}
@@ -450,3 +536,22 @@ func (c *ClientV3) EstimateStep(param *v3.TransactionParamForEstimate) (*common.
 	}
 	return &result, nil
 }
+
+func (c *ClientV3) Discover() (*jsonrpc.OpenRPCDocument, error) {
+	doc := &jsonrpc.OpenRPCDocument{}
+	if _, err := c.Do(jsonrpc.DiscoverMethod, nil, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (c *ClientV3) DiscoverDebug() (*jsonrpc.OpenRPCDocument, error) {
+	if len(c.DebugEndPoint) == 0 {
+		return nil, errors.InvalidStateError.New("UnavailableDebugEndPoint")
+	}
+	doc := &jsonrpc.OpenRPCDocument{}
+	if _, err := c.DoURL(c.DebugEndPoint, jsonrpc.DiscoverMethod, nil, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/server"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+)
+
+// CompactInt is an integer in msgpack result. It's encoded as msgpack
+// integer or two's complement big-endian bytes if it doesn't fit in int64.
+type CompactInt struct {
+	big.Int
+}
+
+func (i *CompactInt) DecodeMsgpack(d *msgpack.Decoder) error {
+	v, err := d.DecodeInterfaceLoose()
+	if err != nil {
+		return err
+	}
+	switch n := v.(type) {
+	case int64:
+		i.SetInt64(n)
+	case uint64:
+		i.SetUint64(n)
+	case []byte:
+		intconv.BigIntSetBytes(&i.Int, n)
+	case nil:
+		i.SetInt64(0)
+	default:
+		return fmt.Errorf("invalid type %T for CompactInt", v)
+	}
+	return nil
+}
+
+// CompactAddress is an address in msgpack result.
+type CompactAddress []byte
+
+func (a CompactAddress) Address() *common.Address {
+	if len(a) == 0 {
+		return nil
+	}
+	addr, err := common.NewAddress(a)
+	if err != nil {
+		return nil
+	}
+	return addr
+}
+
+// refer doc/jsonrpc_v3_msgpack.md
+type CompactBlock struct {
+	BlockHash              []byte               `msgpack:"block_hash"`
+	Version                string               `msgpack:"version"`
+	Height                 int64                `msgpack:"height"`
+	Timestamp              int64                `msgpack:"time_stamp"`
+	Proposer               CompactAddress       `msgpack:"peer_id"`
+	PrevID                 []byte               `msgpack:"prev_block_hash"`
+	NormalTransactionsHash []byte               `msgpack:"merkle_tree_root_hash"`
+	NormalTransactions     []CompactTransaction `msgpack:"confirmed_transaction_list"`
+}
+
+type CompactTransaction struct {
+	TxHash    []byte         `msgpack:"txHash"`
+	Version   CompactInt     `msgpack:"version"`
+	From      CompactAddress `msgpack:"from"`
+	To        CompactAddress `msgpack:"to"`
+	Value     *CompactInt    `msgpack:"value,omitempty"`
+	StepLimit CompactInt     `msgpack:"stepLimit"`
+	TimeStamp CompactInt     `msgpack:"timestamp"`
+	NID       *CompactInt    `msgpack:"nid,omitempty"`
+	Nonce     *CompactInt    `msgpack:"nonce,omitempty"`
+	Signature string         `msgpack:"signature"`
+	DataType  string         `msgpack:"dataType,omitempty"`
+	Data      interface{}    `msgpack:"data,omitempty"`
+}
+
+type CompactTransactionResult struct {
+	To                 CompactAddress    `msgpack:"to"`
+	CumulativeStepUsed CompactInt        `msgpack:"cumulativeStepUsed"`
+	StepUsed           CompactInt        `msgpack:"stepUsed"`
+	StepPrice          CompactInt        `msgpack:"stepPrice"`
+	EventLogs          []CompactEventLog `msgpack:"eventLogs"`
+	LogsBloom          []byte            `msgpack:"logsBloom"`
+	Status             int64             `msgpack:"status"`
+	Failure            *struct {
+		CodeValue CompactInt `msgpack:"code"`
+		Message   string     `msgpack:"message"`
+	} `msgpack:"failure,omitempty"`
+	SCOREAddress CompactAddress `msgpack:"scoreAddress,omitempty"`
+	BlockHash    []byte         `msgpack:"blockHash"`
+	BlockHeight  int64          `msgpack:"blockHeight"`
+	TxIndex      int64          `msgpack:"txIndex"`
+	TxHash       []byte         `msgpack:"txHash"`
+}
+
+// CompactEventLog is an event log in msgpack result. The first item of
+// Indexed is the signature string, and others are raw bytes of the values.
+type CompactEventLog struct {
+	Addr    CompactAddress `msgpack:"scoreAddress"`
+	Indexed []interface{}  `msgpack:"indexed"`
+	Data    []interface{}  `msgpack:"data"`
+}
+
+type CompactBlockNotification struct {
+	Hash    []byte      `msgpack:"hash"`
+	Height  int64       `msgpack:"height"`
+	Indexes [][]int64   `msgpack:"indexes,omitempty"`
+	Events  [][][]int64 `msgpack:"events,omitempty"`
+}
+
+type CompactEventNotification struct {
+	Hash   []byte            `msgpack:"hash"`
+	Height int64             `msgpack:"height"`
+	Index  int64             `msgpack:"index"`
+	Events []int64           `msgpack:"events"`
+	Logs   []CompactEventLog `msgpack:"logs,omitempty"`
+}
+
+func decodeMsgpackResult(result interface{}, respPtr interface{}) error {
+	bs, err := msgpack.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return msgpack.Unmarshal(bs, respPtr)
+}
+
+// DoMsgpack sends JSON request and receives compact msgpack response.
+// The result is decoded into respPtr with msgpack tags.
+func (c *JsonRpcClient) DoMsgpack(method string, reqPtr, respPtr interface{}) (*jsonrpc.MsgpackResponse, error) {
+	return c.DoURLMsgpack(c.Endpoint, method, reqPtr, respPtr)
+}
+
+func (c *JsonRpcClient) DoURLMsgpack(url string, method string, reqPtr, respPtr interface{}) (*jsonrpc.MsgpackResponse, error) {
+	jrReq := &jsonrpc.Request{
+		ID:      time.Now().UnixNano() / int64(time.Millisecond),
+		Version: jsonrpc.Version,
+		Method:  &method,
+	}
+	if reqPtr != nil {
+		b, err := json.Marshal(reqPtr)
+		if err != nil {
+			return nil, err
+		}
+		jrReq.Params = json.RawMessage(b)
+	}
+	reqB, err := json.Marshal(jrReq)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(reqB))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(headerContentType, typeApplicationJSON)
+	req.Header.Set(headerAccept, jsonrpc.MIMEApplicationMsgpack)
+	for k, v := range c.CustomHeader {
+		req.Header.Set(k, v)
+	}
+
+	if c.Pre != nil {
+		if err = c.Pre(req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if !jsonrpc.IsMsgpack(resp.Header.Get(headerContentType)) {
+		return nil, NewHttpError(resp)
+	}
+	jrResp := new(jsonrpc.MsgpackResponse)
+	if err = msgpack.NewDecoder(resp.Body).Decode(jrResp); err != nil {
+		return nil, fmt.Errorf("fail to decode response body err:%+v", err)
+	}
+	if jrResp.Error != nil {
+		return jrResp, jrResp.Error.ToError()
+	}
+	if respPtr != nil {
+		if err = decodeMsgpackResult(jrResp.Result, respPtr); err != nil {
+			return jrResp, err
+		}
+	}
+	return jrResp, nil
+}
+
+func (c *ClientV3) GetBlockByHeightMsgpack(param *v3.BlockHeightParam) (*CompactBlock, error) {
+	result := &CompactBlock{}
+	if _, err := c.DoMsgpack("icx_getBlockByHeight", param, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *ClientV3) GetTransactionResultMsgpack(param *v3.TransactionHashParam) (*CompactTransactionResult, error) {
+	result := &CompactTransactionResult{}
+	if _, err := c.DoMsgpack("icx_getTransactionResult", param, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *ClientV3) MonitorBlockMsgpack(param *server.BlockRequest, cb func(v *CompactBlockNotification), cancelCh <-chan bool) error {
+	resp := &CompactBlockNotification{}
+	return c.MonitorMsgpack("/block", param, resp, func(v interface{}) {
+		if bn, ok := v.(*CompactBlockNotification); ok {
+			cb(bn)
+		}
+	}, cancelCh)
+}
+
+func (c *ClientV3) MonitorEventMsgpack(param *server.EventRequest, cb func(v *CompactEventNotification), cancelCh <-chan bool) error {
+	resp := &CompactEventNotification{}
+	return c.MonitorMsgpack("/event", param, resp, func(v interface{}) {
+		if en, ok := v.(*CompactEventNotification); ok {
+			cb(en)
+		}
+	}, cancelCh)
+}
+
+// MonitorMsgpack is same as Monitor except that notifications are
+// received as compact msgpack and decoded into respPtr with msgpack tags.
+func (c *ClientV3) MonitorMsgpack(reqUrl string, reqPtr, respPtr interface{},
+	cb func(v interface{}), cancelCh <-chan bool) error {
+	if cb == nil {
+		return fmt.Errorf("callback function cannot be nil")
+	}
+	header := http.Header{}
+	header.Set(headerAccept, jsonrpc.MIMEApplicationMsgpack)
+	conn, _, err := c.wsConnect(reqUrl, header, reqPtr)
+	if err != nil {
+		return err
+	}
+	if cancelCh != nil {
+		ch := make(chan interface{})
+		go func() {
+			defer c.wsClose(conn)
+			for {
+				select {
+				case <-cancelCh:
+					return
+				case <-ch:
+					return
+				}
+			}
+		}()
+		go func() {
+			defer func() {
+				close(ch)
+			}()
+			c.wsReadLoop(conn, respPtr, cb)
+		}()
+	} else {
+		defer c.wsClose(conn)
+		c.wsReadLoop(conn, respPtr, cb)
+	}
+	return nil
+}
+
+// readWSMessage reads a JSON text message or a msgpack binary message.
+func readWSMessage(conn *websocket.Conn, ptr interface{}) error {
+	mt, bs, err := conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if mt == websocket.BinaryMessage {
+		return msgpack.Unmarshal(bs, ptr)
+	}
+	return json.Unmarshal(bs, ptr)
+}
+
+func readWSResponse(conn *websocket.Conn, resp *server.WSResponse) error {
+	mt, bs, err := conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if mt == websocket.BinaryMessage {
+		var v struct {
+			Code    int    `msgpack:"code"`
+			Message string `msgpack:"message"`
+		}
+		if err := msgpack.Unmarshal(bs, &v); err != nil {
+			return err
+		}
+		resp.Code = v.Code
+		resp.Message = v.Message
+		return nil
+	}
+	return json.Unmarshal(bs, resp)
+}
@@ -129,6 +129,10 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 			param.PatchTxPoolSize, _ = fs.GetInt("patch_tx_pool")
 			param.MaxBlockTxBytes, _ = fs.GetInt("max_block_tx_bytes")
 			param.NodeCache, _ = fs.GetString("node_cache")
+			param.FlatState, _ = fs.GetBool("flat_state")
+			param.RewardHistory, _ = fs.GetBool("reward_history")
+			param.ValidationHistory, _ = fs.GetBool("validation_history")
+			param.Observer, _ = fs.GetBool("observer")
 			param.Channel, _ = fs.GetString("channel")
 			param.SecureSuites, _ = fs.GetString("secure_suites")
 			param.SecureAeads, _ = fs.GetString("secure_aeads")
@@ -190,6 +194,10 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	joinFlags.Int("patch_tx_pool", 0, "Size of patch transaction pool")
 	joinFlags.Int("max_block_tx_bytes", 0, "Max size of transactions in a block")
 	joinFlags.String("node_cache", chain.NodeCacheDefault, "Node cache (none,small,large)")
+	joinFlags.Bool("flat_state", false, "Read world state from flat key-value snapshot")
+	joinFlags.Bool("reward_history", false, "Keep history of rewards for each term")
+	joinFlags.Bool("validation_history", false, "Keep history of block validation of validators")
+	joinFlags.Bool("observer", false, "Follow the chain with headers and votes of blocks only")
 	joinFlags.String("channel", "", "Channel")
 	joinFlags.String("secure_suites", "none,tls,ecdhe",
 		"Supported Secure suites with order (none,tls,ecdhe) - Comma separated string")
@@ -372,6 +380,65 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	backupFlags := backupCmd.Flags()
 	backupFlags.Bool("manual", false, "Manual backup mode (just release database)")
 
+	exportStakeCmd := &cobra.Command{
+		Use:   "exportstake CID",
+		Short: "Start to export stakes, delegations and bonds of accounts",
+		Long: "Start to export stakes, delegations and bonds of accounts.\n" +
+			"Accounts are found from P-Reps, bonders, voters and claimers, so\n" +
+			"accounts which have only staked are exported only if they're given\n" +
+			"with --accounts.",
+		Args: ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			param := make(map[string]interface{})
+			for _, name := range []string{"height", "from", "to"} {
+				if v, _ := fs.GetInt64(name); v != 0 {
+					param[name] = v
+				}
+			}
+			param["format"], _ = fs.GetString("format")
+			param["output"], _ = fs.GetString("output")
+			if accounts, _ := fs.GetStringSlice("accounts"); len(accounts) > 0 {
+				param["accounts"] = accounts
+			}
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/" + chain.ExportStakeTask
+			_, err := adminClient.PostWithJson(reqUrl, param, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(exportStakeCmd)
+	exportStakeFlags := exportStakeCmd.Flags()
+	exportStakeFlags.Int64("height", 0, "Block height of the state to export")
+	exportStakeFlags.Int64("from", 0, "Export at each term start from the height")
+	exportStakeFlags.Int64("to", 0, "Export at each term start until the height")
+	exportStakeFlags.String("format", "csv", "Output format (csv, ndjson)")
+	exportStakeFlags.String("output", "", "Output file (relative to the chain directory)")
+	exportStakeFlags.StringSlice("accounts", nil, "Accounts to export additionally, comma-separated")
+	MarkAnnotationRequired(exportStakeFlags, "output")
+
+	verifyFlatStateCmd := &cobra.Command{
+		Use:   "verifyflatstate CID",
+		Short: "Start to verify flat state with the world state of the last block",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/" + chain.VerifyFlatStateTask
+			_, err := adminClient.PostWithJson(reqUrl, struct{}{}, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(verifyFlatStateCmd)
+
 	genesisCmd := &cobra.Command{
 		Use:   "genesis CID FILE",
 		Short: "Download chain genesis file",
@@ -17,13 +17,20 @@
 package cli
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	v3 "github.com/icon-project/goloop/server/v3"
 )
@@ -68,5 +75,199 @@ func NewDebugCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	}
 	rootCmd.AddCommand(traceCmd)
 
+	profileCmd := &cobra.Command{
+		Use:   "profile HASH",
+		Short: "Show used steps of the transaction by step types and frames",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.TransactionHashParam{
+				Hash: jsonrpc.HexBytes(args[0]),
+			}
+			var trace struct {
+				Steps *stepProfile `json:"steps"`
+			}
+			if _, err := debugClient.Do("debug_getTrace", param, &trace); err != nil {
+				return err
+			}
+			if trace.Steps == nil {
+				return fmt.Errorf("no step profile for the transaction")
+			}
+			return trace.Steps.Print(os.Stdout)
+		},
+	}
+	rootCmd.AddCommand(profileCmd)
+
+	storageCmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Show contracts using the largest storage",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.StorageUsagesParam{}
+			if height, _ := cmd.Flags().GetInt64("height"); height >= 0 {
+				param.Height = jsonrpc.HexInt(intconv.FormatInt(height))
+			}
+			limit, _ := cmd.Flags().GetInt("limit")
+			param.Limit = jsonrpc.HexInt(intconv.FormatInt(int64(limit)))
+			res, err := debugClient.Do("debug_getStorageUsages", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, res.Result)
+		},
+	}
+	storageCmd.Flags().Int64("height", -1, "Height of the block (default: last block)")
+	storageCmd.Flags().Int("limit", 20, "Maximum number of contracts")
+	rootCmd.AddCommand(storageCmd)
+
+	rootCmd.AddCommand(newDebugPoolCmd(&debugClient))
+	rootCmd.AddCommand(newDebugWALCmd())
+
 	return rootCmd, vc
 }
+
+func newDebugPoolCmd(debugClient *client.JsonRpcClient) *cobra.Command {
+	poolCmd := &cobra.Command{
+		Use:   "pool",
+		Short: "Inspect transaction pool",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List pending transactions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.PendingTransactionsParam{}
+			param.Group, _ = cmd.Flags().GetString("group")
+			if from, _ := cmd.Flags().GetString("from"); from != "" {
+				param.FromAddress = jsonrpc.Address(from)
+			}
+			offset, _ := cmd.Flags().GetInt("offset")
+			param.Offset = jsonrpc.HexInt(intconv.FormatInt(int64(offset)))
+			limit, _ := cmd.Flags().GetInt("limit")
+			param.Limit = jsonrpc.HexInt(intconv.FormatInt(int64(limit)))
+			res, err := debugClient.Do("debug_getPendingTransactions", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, res.Result)
+		},
+	}
+	flags := listCmd.Flags()
+	flags.String("group", "normal", "Transaction group (normal, patch)")
+	flags.String("from", "", "Sender of transactions")
+	flags.Int("offset", 0, "Number of transactions to skip")
+	flags.Int("limit", 100, "Maximum number of transactions")
+	poolCmd.AddCommand(listCmd)
+
+	poolCmd.AddCommand(&cobra.Command{
+		Use:   "status HASH",
+		Short: "Show status of the transaction in the pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.TransactionHashParam{
+				Hash: jsonrpc.HexBytes(args[0]),
+			}
+			res, err := debugClient.Do("debug_getPendingTransaction", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, res.Result)
+		},
+	})
+
+	poolCmd.AddCommand(&cobra.Command{
+		Use:   "usage",
+		Short: "Show usage of transaction pools",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := debugClient.Do("debug_getTransactionPoolUsage", nil, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, res.Result)
+		},
+	})
+	return poolCmd
+}
+
+type stepOperation struct {
+	Count common.HexInt32 `json:"count"`
+	Bytes common.HexInt32 `json:"bytes"`
+}
+
+type frameProfile struct {
+	ID         common.HexInt32           `json:"id"`
+	Parent     *common.HexInt32          `json:"parent"`
+	To         string                    `json:"to"`
+	Method     string                    `json:"method"`
+	StepUsed   common.HexInt             `json:"stepUsed"`
+	Steps      map[string]common.HexInt  `json:"steps"`
+	Operations map[string]*stepOperation `json:"operations"`
+}
+
+type stepProfile struct {
+	StepUsed common.HexInt            `json:"stepUsed"`
+	Steps    map[string]common.HexInt `json:"steps"`
+	Frames   []*frameProfile          `json:"frames"`
+}
+
+func sortedStepTypes(m interface{}) []string {
+	var keys []string
+	switch obj := m.(type) {
+	case map[string]common.HexInt:
+		for k := range obj {
+			keys = append(keys, k)
+		}
+	case map[string]*stepOperation:
+		for k := range obj {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (p *stepProfile) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP TYPE\tSTEPS\t")
+	for _, t := range sortedStepTypes(p.Steps) {
+		v := p.Steps[t]
+		fmt.Fprintf(tw, "%s\t%s\t\n", t, v.Int.String())
+	}
+	fmt.Fprintf(tw, "TOTAL\t%s\t\n", p.StepUsed.Int.String())
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FRAME\tPARENT\tTO\tMETHOD\tSTEP USED\tSTEPS\tOPERATIONS\t")
+	for _, f := range p.Frames {
+		parent := ""
+		if f.Parent != nil {
+			parent = f.Parent.String()
+		}
+		var steps []string
+		for _, t := range sortedStepTypes(f.Steps) {
+			v := f.Steps[t]
+			steps = append(steps, fmt.Sprintf("%s=%s", t, v.Int.String()))
+		}
+		var ops []string
+		for _, t := range sortedStepTypes(f.Operations) {
+			op := f.Operations[t]
+			ops = append(ops, fmt.Sprintf("%s=%d(%dB)", t, op.Count.Value, op.Bytes.Value))
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			f.ID.Value, orDash(parent), orDash(f.To), orDash(f.Method),
+			f.StepUsed.Int.String(),
+			orDash(strings.Join(steps, " ")), orDash(strings.Join(ops, " ")))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/consensus"
+)
+
+func newDebugWALCmd() *cobra.Command {
+	walCmd := &cobra.Command{
+		Use:   "wal",
+		Short: "Inspect and repair consensus WAL files",
+		// WAL commands work on local files of a stopped node, so they
+		// don't need the URI of DEBUG API.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	dumpCmd := &cobra.Command{
+		Use:   "dump WAL",
+		Short: "Print records of the WAL",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, _ := cmd.Flags().GetInt64("height")
+			raw, _ := cmd.Flags().GetBool("raw")
+			return dumpWAL(os.Stdout, args[0], height, raw)
+		},
+	}
+	dumpCmd.Flags().Int64("height", 0, "Print only the records for the height")
+	dumpCmd.Flags().Bool("raw", false, "Print payload of records in hex")
+	walCmd.AddCommand(dumpCmd)
+
+	walCmd.AddCommand(&cobra.Command{
+		Use:   "verify WAL",
+		Short: "Verify checksums of records and show where the corruption starts",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifyWAL(os.Stdout, args[0])
+		},
+	})
+
+	walCmd.AddCommand(&cobra.Command{
+		Use:   "truncate WAL INDEX",
+		Short: "Truncate the WAL to keep records before INDEX",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := strconv.Atoi(args[1])
+			if err != nil || index < 0 {
+				return fmt.Errorf("invalid index %s", args[1])
+			}
+			if err := consensus.TruncateWAL(args[0], index); err != nil {
+				return err
+			}
+			fmt.Printf("Truncated %s to %d records\n", args[0], index)
+			return nil
+		},
+	})
+
+	rewriteCmd := &cobra.Command{
+		Use:   "rewrite WAL INDEX",
+		Short: "Rewrite records before INDEX to new WAL files",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := strconv.Atoi(args[1])
+			if err != nil || index < 0 {
+				return fmt.Errorf("invalid index %s", args[1])
+			}
+			output, _ := cmd.Flags().GetString("output")
+			if output == "" {
+				output = args[0]
+			}
+			if err := consensus.RewriteWAL(args[0], output, index); err != nil {
+				return err
+			}
+			fmt.Printf("Rewrote %d records of %s to %s\n", index, args[0], output)
+			return nil
+		},
+	}
+	rewriteCmd.Flags().String("output", "", "WAL for the records (default: replace the WAL)")
+	walCmd.AddCommand(rewriteCmd)
+
+	resetCmd := &cobra.Command{
+		Use:   "reset DIR",
+		Short: "Reset WAL directory with the commit votes of the block",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			dbPath, _ := fs.GetString("db_path")
+			dbType, _ := fs.GetString("db_type")
+			codecType, _ := fs.GetString("codec")
+			height, _ := fs.GetInt64("height")
+			return resetWAL(args[0], dbPath, dbType, codecType, height)
+		},
+	}
+	resetFlags := resetCmd.Flags()
+	resetFlags.String("db_path", "", "DB path for the commit votes. If it's not specified, the WAL directory is just removed")
+	resetFlags.String("db_type", "goleveldb", "Name of database system("+strings.Join(db.RegisteredBackendTypes(), ", ")+")")
+	resetFlags.String("codec", "rlp", "Name of data codec (rlp, mp)")
+	resetFlags.Int64("height", -1, "Height of the block for the commit votes (default: last block)")
+	walCmd.AddCommand(resetCmd)
+
+	return walCmd
+}
+
+func dumpWAL(w io.Writer, id string, height int64, raw bool) error {
+	s, err := consensus.OpenWALScanner(id)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tFILE\tOFFSET\tSIZE\tTYPE\tHEIGHT\tROUND\tSTEP\tMESSAGE")
+	for {
+		rec, err := s.Next()
+		if consensus.IsEOF(err) {
+			break
+		} else if err != nil {
+			tw.Flush()
+			file, offset := s.Position()
+			fmt.Fprintf(w, "Corrupted at record %d (%s:%d): %v\n", s.Index(), file, offset, err)
+			return nil
+		}
+		msg, err := consensus.DecodeWALRecord(rec.Payload)
+		if height > 0 && (msg == nil || msg.Height != height) {
+			continue
+		}
+		if msg == nil {
+			msg = &consensus.WALMessage{Type: "-"}
+		}
+		desc := msg.Message
+		if err != nil {
+			desc = fmt.Sprintf("error: %v", err)
+		}
+		if raw {
+			desc += " " + hex.EncodeToString(rec.Payload)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%d\t%d\t%s\t%s\n",
+			rec.Index, filepath.Base(rec.File), rec.Offset, rec.Size,
+			msg.Type, msg.Height, msg.Round, orDash(msg.Step), desc)
+	}
+	return tw.Flush()
+}
+
+func verifyWAL(w io.Writer, id string) error {
+	s, err := consensus.OpenWALScanner(id)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	var valid int64
+	for {
+		rec, err := s.Next()
+		if consensus.IsEOF(err) {
+			break
+		} else if err != nil {
+			file, offset := s.Position()
+			fmt.Fprintf(w, "Records    : %d\n", s.Index())
+			fmt.Fprintf(w, "Valid size : %d / %d\n", valid, s.Size())
+			return fmt.Errorf("corrupted at record %d (%s:%d): %v", s.Index(), file, offset, err)
+		}
+		valid += rec.Size
+	}
+	fmt.Fprintf(w, "Records    : %d\n", s.Index())
+	fmt.Fprintf(w, "Valid size : %d / %d\n", valid, s.Size())
+	if valid != s.Size() {
+		return fmt.Errorf("unknown bytes after the last record")
+	}
+	return nil
+}
+
+func resetWAL(dir, dbPath, dbType, codecType string, height int64) error {
+	if dbPath == "" {
+		return consensus.ResetWAL(height, dir, nil)
+	}
+	d, err := db.Open(dbPath, dbType, "")
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	cod := codec.RLP
+	if codecType == "mp" {
+		cod = codec.MP
+	}
+	if height < 0 {
+		if height, err = block.GetLastHeightWithCodec(d, cod); err != nil {
+			return err
+		}
+	}
+	bid, err := block.GetBlockHeaderHashByHeight(d, cod, height)
+	if err != nil {
+		return err
+	}
+	cvlBytes, err := block.GetCommitVoteListBytesByHeight(d, cod, height)
+	if err != nil {
+		return err
+	}
+	rec, err := consensus.WALRecordBytesFromCommitVoteListBytes(
+		cvlBytes, height, bid, cod,
+	)
+	if err != nil {
+		return err
+	}
+	if err = consensus.ResetWAL(height, dir, rec); err != nil {
+		return err
+	}
+	fmt.Printf("Reset %s with commit votes of height %d\n", dir, height)
+	return nil
+}
@@ -1,13 +1,16 @@
 package cli
 
 import (
-	"encoding/hex"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -17,7 +20,6 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/intconv"
-	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/jsonrpc"
@@ -300,6 +302,84 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 	}
 	rootCmd.AddCommand(rawCmd)
 
+	discoverCmd := &cobra.Command{
+		Use:   "discover",
+		Short: "Get OpenRPC document of the API",
+		Args:  ArgsWithDefaultErrorFunc(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var doc *jsonrpc.OpenRPCDocument
+			var err error
+			if debugAPI, _ := cmd.Flags().GetBool("debug_api"); debugAPI {
+				doc, err = rpcClient.DiscoverDebug()
+			} else {
+				doc, err = rpcClient.Discover()
+			}
+			if err != nil {
+				return err
+			}
+			if output, _ := cmd.Flags().GetString("output"); output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				return JsonPrettyPrintln(f, doc)
+			}
+			return JsonPrettyPrintln(os.Stdout, doc)
+		},
+	}
+	rootCmd.AddCommand(discoverCmd)
+	discoverFlags := discoverCmd.Flags()
+	discoverFlags.Bool("debug_api", false, "Get the document of JSON-RPC Debug API")
+	discoverFlags.String("output", "", "File for the document")
+
+	iscoreHistoryCmd := &cobra.Command{
+		Use:   "iscorehistory ADDRESS",
+		Short: "Export IScore history of the address for each term in CSV",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start, err := intconv.ParseInt(cmd.Flag("start").Value.String(), 64)
+			if err != nil {
+				return err
+			}
+			w := os.Stdout
+			if output, _ := cmd.Flags().GetString("output"); output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return exportIScoreHistory(&rpcClient, args[0], start, w)
+		},
+	}
+	rootCmd.AddCommand(iscoreHistoryCmd)
+	iscoreHistoryFlags := iscoreHistoryCmd.Flags()
+	iscoreHistoryFlags.Int("start", 0, "Start height of the first term to export")
+	iscoreHistoryFlags.String("output", "", "File for CSV (default: stdout)")
+
+	validationHistoryCmd := &cobra.Command{
+		Use:   "validationhistory ADDRESS",
+		Short: "Print uptime of the P-Rep for each term",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start, err := intconv.ParseInt(cmd.Flag("start").Value.String(), 64)
+			if err != nil {
+				return err
+			}
+			end, err := intconv.ParseInt(cmd.Flag("end").Value.String(), 64)
+			if err != nil {
+				return err
+			}
+			return printValidationHistory(&rpcClient, args[0], start, end, os.Stdout)
+		},
+	}
+	rootCmd.AddCommand(validationHistoryCmd)
+	validationHistoryFlags := validationHistoryCmd.Flags()
+	validationHistoryFlags.Int("start", 0, "Start height of the first term to print")
+	validationHistoryFlags.Int("end", 0, "Start height of the last term to print (default: the current term)")
+
 	rootCmd.AddCommand(
 		&cobra.Command{
 			Use:   "databyhash HASH",
@@ -397,6 +477,47 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 				}
 				return JsonPrettyPrintln(os.Stdout, raw)
 			},
+		},
+		&cobra.Command{
+			Use:   "blockaccumulator HEIGHT",
+			Short: "GetBlockAccumulator",
+			Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				height, err := intconv.ParseInt(args[0], 64)
+				if err != nil {
+					return err
+				}
+				param := &v3.BlockHeightParam{Height: jsonrpc.HexInt(intconv.FormatInt(height))}
+				raw, err := rpcClient.GetBlockAccumulator(param)
+				if err != nil {
+					return err
+				}
+				return JsonPrettyPrintln(os.Stdout, raw)
+			},
+		},
+		&cobra.Command{
+			Use:   "blockwitness HEIGHT [AT]",
+			Short: "GetBlockWitness",
+			Args:  ArgsWithDefaultErrorFunc(cobra.RangeArgs(1, 2)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				height, err := intconv.ParseInt(args[0], 64)
+				if err != nil {
+					return err
+				}
+				param := &v3.BlockWitnessParam{Height: jsonrpc.HexInt(intconv.FormatInt(height))}
+				if len(args) > 1 {
+					at, err := intconv.ParseInt(args[1], 64)
+					if err != nil {
+						return err
+					}
+					param.At = jsonrpc.HexInt(intconv.FormatInt(at))
+				}
+				raw, err := rpcClient.GetBlockWitness(param)
+				if err != nil {
+					return err
+				}
+				return JsonPrettyPrintln(os.Stdout, raw)
+			},
 		})
 	return rootCmd, vc
 }
@@ -451,29 +572,9 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 				return err
 			}
 		}
-		var kb, pb []byte
 		var err error
-		ksf := vc.GetString("key_store")
-		if kb, err = ioutil.ReadFile(ksf); err != nil {
-			return fmt.Errorf("fail to open KeyStore file=%s err=%+v", ksf, err)
-		}
-		//key_secret -> key_password
-		ksec := vc.GetString("key_secret")
-		kpass := vc.GetString("key_password")
-		if ksec != "" {
-			if pb, err = ioutil.ReadFile(ksec); err != nil {
-				return fmt.Errorf("fail to open KeySecret file=%s err=%+v", ksec, err)
-			}
-		} else if kpass != "" {
-			pb = []byte(kpass)
-		} else {
-			return fmt.Errorf("there is no password information for the KeyStore, use --key_secret or --key_password")
-		}
-		rpcWallet, err = wallet.NewFromKeyStore(kb, pb)
-		if err != nil {
-			return fmt.Errorf("fail to create wallet err=%+v", err)
-		}
-		return nil
+		rpcWallet, err = walletFromKeyStore(vc)
+		return err
 	}
 	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
 		txHash, ok := vc.Get("txHash").(*jsonrpc.HexBytes)
@@ -675,32 +776,14 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 		Short: "Coin Transfer Transaction",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var value common.HexInt
-			if _, ok := value.SetString(cmd.Flag("value").Value.String(), 0); !ok {
-				return fmt.Errorf("fail to parsing value %s", cmd.Flag("value").Value.String())
-			}
-			stepLimit := vc.GetInt64("step_limit")
-			nid, err := intconv.ParseInt(vc.GetString("nid"), 64)
+			param, err := newTxParam(rpcWallet.Address().String(),
+				vc.GetInt64("step_limit"), vc.GetString("nid"))
 			if err != nil {
 				return err
 			}
-			param := &v3.TransactionParam{
-				Version:     v3.VersionValue,
-				FromAddress: jsonrpc.Address(rpcWallet.Address().String()),
-				ToAddress:   jsonrpc.Address(cmd.Flag("to").Value.String()),
-				Value:       jsonrpc.HexInt(value.String()),
-				StepLimit:   jsonrpc.HexInt(intconv.FormatInt(stepLimit)),
-				NetworkID:   jsonrpc.HexInt(intconv.FormatInt(nid)),
-				//Nonce:       "",
-			}
-			msg, err := cmd.Flags().GetString("message")
-			if err != nil {
+			if err := buildTransferTx(cmd, param); err != nil {
 				return err
 			}
-			if msg != "" {
-				param.DataType = "message"
-				param.Data = jsonrpc.HexBytes("0x" + hex.EncodeToString([]byte(msg)))
-			}
 
 			txHash, err := rpcClientSendTx(rpcWallet, param)
 			if err != nil {
@@ -711,63 +794,20 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 		},
 	}
 	rootCmd.AddCommand(transferCmd)
-	transferFlags := transferCmd.Flags()
-	transferFlags.String("to", "", "ToAddress")
-	transferFlags.String("value", "", "Value")
-	transferFlags.String("message", "", "Message")
-	MarkAnnotationRequired(transferFlags, "to", "value")
+	addTransferTxFlags(transferCmd)
 
 	callCmd := &cobra.Command{
 		Use:   "call",
 		Short: "SmartContract Call Transaction",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			stepLimit := vc.GetInt64("step_limit")
-			nid, err := intconv.ParseInt(vc.GetString("nid"), 64)
+			param, err := newTxParam(rpcWallet.Address().String(),
+				vc.GetInt64("step_limit"), vc.GetString("nid"))
 			if err != nil {
 				return err
 			}
-
-			param := &v3.TransactionParam{
-				Version:     v3.VersionValue,
-				FromAddress: jsonrpc.Address(rpcWallet.Address().String()),
-				ToAddress:   jsonrpc.Address(cmd.Flag("to").Value.String()),
-				StepLimit:   jsonrpc.HexInt(intconv.FormatInt(stepLimit)),
-				NetworkID:   jsonrpc.HexInt(intconv.FormatInt(nid)),
-				//Nonce:       "",
-				DataType: "call",
-			}
-			dataM := make(map[string]interface{})
-			if dataJson := cmd.Flag("raw").Value.String(); dataJson != "" {
-				var dataBytes []byte
-				if strings.HasPrefix(strings.TrimSpace(dataJson), "{") {
-					dataBytes = []byte(dataJson)
-				} else {
-					var err error
-					if dataBytes, err = readFile(dataJson); err != nil {
-						return err
-					}
-				}
-				if err := json.Unmarshal(dataBytes, &dataM); err != nil {
-					return err
-				}
-			}
-
-			if dataMethod := cmd.Flag("method").Value.String(); dataMethod != "" {
-				dataM["method"] = dataMethod
-			}
-			if dataParams, err := cmd.Flags().GetStringToString("param"); err == nil && len(dataParams) > 0 {
-				dataM["params"] = dataParams
-			}
-			if len(dataM) > 0 {
-				param.Data = dataM
-			}
-			if cmd.Flag("value").Value.String() != "" {
-				var value common.HexInt
-				if _, ok := value.SetString(cmd.Flag("value").Value.String(), 0); !ok {
-					return fmt.Errorf("fail to parsing value %s", cmd.Flag("value").Value.String())
-				}
-				param.Value = jsonrpc.HexInt(value.String())
+			if err := buildCallTx(cmd, param); err != nil {
+				return err
 			}
 
 			txHash, err := rpcClientSendTx(rpcWallet, param)
@@ -779,61 +819,21 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 		},
 	}
 	rootCmd.AddCommand(callCmd)
-	callFlags := callCmd.Flags()
-	callFlags.String("to", "", "ToAddress")
-	callFlags.String("method", "",
-		"Name of the function to invoke in SCORE, if '--raw' used, will overwrite")
-	callFlags.StringToString("param", nil,
-		"key=value, Function parameters, if '--raw' used, will overwrite")
-	callFlags.String("value", "", "Value of transfer")
-	callFlags.String("raw", "", "call with 'data' using raw json file or json-string")
-	MarkAnnotationRequired(callFlags, "to", "method")
+	addCallTxFlags(callCmd)
 
 	deployCmd := &cobra.Command{
 		Use:   "deploy SCORE_ZIP_FILE",
 		Short: "Deploy Transaction",
 		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			stepLimit := vc.GetInt64("step_limit")
-			nid, err := intconv.ParseInt(vc.GetString("nid"), 64)
+			param, err := newTxParam(rpcWallet.Address().String(),
+				vc.GetInt64("step_limit"), vc.GetString("nid"))
 			if err != nil {
 				return err
 			}
-			param := &v3.TransactionParam{
-				Version:     v3.VersionValue,
-				FromAddress: jsonrpc.Address(rpcWallet.Address().String()),
-				ToAddress:   jsonrpc.Address(cmd.Flag("to").Value.String()),
-				StepLimit:   jsonrpc.HexInt(intconv.FormatInt(stepLimit)),
-				NetworkID:   jsonrpc.HexInt(intconv.FormatInt(nid)),
-				//Nonce:       "",
-				DataType: "deploy",
-			}
-			if to := cmd.Flag("to").Value.String(); to != "" {
-				param.ToAddress = jsonrpc.Address(to)
-			}
-			dataM := make(map[string]interface{})
-			dataM["contentType"] = cmd.Flag("content_type").Value.String()
-			isDir, err := IsDirectory(args[0])
-			if err != nil {
+			if err := buildDeployTx(cmd, args[0], param); err != nil {
 				return err
 			}
-			var b []byte
-			if isDir {
-				if b, err = ZipDirectory(args[0], "__pycache__"); err != nil {
-					return fmt.Errorf("fail to zip with directory %s err:%+v", args[0], err)
-				}
-			} else {
-				if b, err = readFile(args[0]); err != nil {
-					return fmt.Errorf("fail to read %s err:%+v", args[0], err)
-				}
-			}
-			dataM["content"] = "0x" + hex.EncodeToString(b)
-			if dataParams, err := cmd.Flags().GetStringToString("param"); err == nil && len(dataParams) > 0 {
-				dataM["params"] = dataParams
-			}
-			if len(dataM) > 0 {
-				param.Data = dataM
-			}
 			txHash, err := rpcClientSendTx(rpcWallet, param)
 			if err != nil {
 				return err
@@ -843,13 +843,7 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 		},
 	}
 	rootCmd.AddCommand(deployCmd)
-	deployFlags := deployCmd.Flags()
-	deployFlags.String("to", "cx0000000000000000000000000000000000000000", "ToAddress")
-	deployFlags.String("content_type", "application/zip",
-		"Mime-type of the content")
-	deployFlags.StringToString("param", nil,
-		"key=value, Function parameters will be delivered to on_install() or on_update()")
-	MarkAnnotationHidden(deployFlags, "content-type")
+	addDeployTxFlags(deployCmd)
 	return rootCmd
 }
 
@@ -978,3 +972,151 @@ func NewMonitorCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comma
 	monitorEventFlags.String("raw", "", "EventFilter raw json file or json-string")
 	return rootCmd
 }
+
+var iscoreHistoryColumns = []string{
+	"startHeight", "endHeight", "blockProduce", "voted", "voting", "iscore",
+}
+
+// exportIScoreHistory writes IScore history of the address in CSV, following
+// the pages of the history until the last term.
+func exportIScoreHistory(rpcClient *client.ClientV3, address string, start int64, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(iscoreHistoryColumns); err != nil {
+		return err
+	}
+	for {
+		param := &v3.IScoreHistoryParam{
+			Address:     jsonrpc.Address(address),
+			StartHeight: jsonrpc.HexInt(intconv.FormatInt(start)),
+		}
+		var res interface{}
+		_, err := rpcClient.Do("icx_getIScoreHistory", param, &res)
+		if err != nil {
+			return err
+		}
+		jso, ok := res.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("InvalidResponse(%v)", res)
+		}
+		history, _ := jso["history"].([]interface{})
+		for _, item := range history {
+			term, ok := item.(map[string]interface{})
+			if !ok {
+				return errors.Errorf("InvalidHistory(%v)", item)
+			}
+			record := make([]string, len(iscoreHistoryColumns))
+			for i, column := range iscoreHistoryColumns {
+				value, _ := term[column].(string)
+				v := new(big.Int)
+				if err := intconv.ParseBigInt(v, value); err != nil {
+					return errors.Wrapf(err, "InvalidValue(%s=%s)", column, value)
+				}
+				record[i] = v.String()
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		next, ok := jso["next"].(string)
+		if !ok {
+			break
+		}
+		if start, err = intconv.ParseInt(next, 64); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatMissedRanges(ranges []interface{}) (string, error) {
+	items := make([]string, 0, len(ranges))
+	for _, item := range ranges {
+		r, _ := item.(map[string]interface{})
+		s, _ := r["startHeight"].(string)
+		e, _ := r["endHeight"].(string)
+		start, err := intconv.ParseInt(s, 64)
+		if err != nil {
+			return "", errors.Wrapf(err, "InvalidMissedRange(%v)", item)
+		}
+		end, err := intconv.ParseInt(e, 64)
+		if err != nil {
+			return "", errors.Wrapf(err, "InvalidMissedRange(%v)", item)
+		}
+		if start == end {
+			items = append(items, fmt.Sprint(start))
+		} else {
+			items = append(items, fmt.Sprintf("%d-%d", start, end))
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
+var validationHistoryColumns = []string{
+	"startHeight", "lastHeight", "blocks", "missed", "maxMissed", "uptime",
+}
+
+// printValidationHistory prints uptime of the P-Rep for each term with
+// the ranges of missed blocks, following the pages of the history.
+func printValidationHistory(rpcClient *client.ClientV3, address string, start, end int64, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "START\tLAST\tBLOCKS\tMISSED\tMAX MISSED\tUPTIME\tMISSED BLOCKS\t")
+	var condition string
+	for {
+		param := &v3.ValidationHistoryParam{
+			Address:     jsonrpc.Address(address),
+			StartHeight: jsonrpc.HexInt(intconv.FormatInt(start)),
+		}
+		if end > 0 {
+			param.EndHeight = jsonrpc.HexInt(intconv.FormatInt(end))
+		}
+		var res interface{}
+		_, err := rpcClient.Do("icx_getValidationHistory", param, &res)
+		if err != nil {
+			return err
+		}
+		jso, ok := res.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("InvalidResponse(%v)", res)
+		}
+		condition, _ = jso["validationPenaltyCondition"].(string)
+		history, _ := jso["history"].([]interface{})
+		for _, item := range history {
+			term, ok := item.(map[string]interface{})
+			if !ok {
+				return errors.Errorf("InvalidHistory(%v)", item)
+			}
+			values := make([]int64, len(validationHistoryColumns))
+			for i, column := range validationHistoryColumns {
+				value, _ := term[column].(string)
+				if values[i], err = intconv.ParseInt(value, 64); err != nil {
+					return errors.Wrapf(err, "InvalidValue(%s=%s)", column, value)
+				}
+			}
+			ranges, _ := term["missedRanges"].([]interface{})
+			missed, err := formatMissedRanges(ranges)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d.%02d%%\t%s\t\n",
+				values[0], values[1], values[2], values[3], values[4],
+				values[5]/100, values[5]%100, orDash(missed))
+		}
+		next, ok := jso["next"].(string)
+		if !ok {
+			break
+		}
+		if start, err = intconv.ParseInt(next, 64); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if condition != "" {
+		if v, err := intconv.ParseInt(condition, 64); err == nil {
+			fmt.Fprintf(w, "\nValidation penalty condition: %d blocks missed in a row\n", v)
+		}
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/tracing"
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/node"
@@ -32,8 +33,11 @@ type ServerConfig struct {
 
 	LogLevel     string               `json:"log_level"`
 	ConsoleLevel string               `json:"console_level"`
+	LogFormat    string               `json:"log_format,omitempty"`
 	LogForwarder *log.ForwarderConfig `json:"log_forwarder,omitempty"`
 	LogWriter    *log.WriterConfig    `json:"log_writer,omitempty"`
+
+	Tracing *tracing.Config `json:"tracing,omitempty"`
 }
 
 func (cfg *ServerConfig) GetAddress() module.Address {
@@ -144,6 +148,7 @@ func NewServerCmd(parentCmd *cobra.Command, parentVc *viper.Viper, version, buil
 	rootPFlags.String("key_password", "", "Password for the KeyStore file")
 	rootPFlags.String("log_level", "debug", "Global log level (trace,debug,info,warn,error,fatal,panic)")
 	rootPFlags.String("console_level", "trace", "Console log level (trace,debug,info,warn,error,fatal,panic)")
+	rootPFlags.String("log_format", "text", "Console log format (text,json)")
 	rootPFlags.String("node_dir", "",
 		"Node data directory (default: [configuration file path]/.chain/[ADDRESS])")
 	rootPFlags.StringP("node_sock", "s", "",
@@ -170,6 +175,11 @@ func NewServerCmd(parentCmd *cobra.Command, parentVc *viper.Viper, version, buil
 	rootPFlags.Int("log_writer_maxbackups", 0, "Maximum number of backups")
 	rootPFlags.Bool("log_writer_localtime", false, "Use localtime on rotated log file instead of UTC")
 	rootPFlags.Bool("log_writer_compress", false, "Use gzip on rotated log file")
+	rootPFlags.String("log_writer_format", "text", "Log file format (text,json)")
+
+	rootPFlags.String("tracing_exporter", "", "Tracing exporter (stdout,file), tracing is disabled if it's empty")
+	rootPFlags.String("tracing_target", "", "Tracing target file for file exporter")
+	rootPFlags.Float64("tracing_sample", 1, "Tracing sampling probability (0,1]")
 
 	BindPFlags(vc, rootCmd.PersistentFlags())
 
@@ -222,6 +232,12 @@ func NewServerCmd(parentCmd *cobra.Command, parentVc *viper.Viper, version, buil
 				if err != nil {
 					log.Panicf("Fail to set file logger err=%+v", err)
 				}
+				if err = logger.SetFileFormat(lwCfg.Format); err != nil {
+					log.Panicf("Invalid log_writer_format=%s", lwCfg.Format)
+				}
+			}
+			if err := logger.SetFormat(cfg.LogFormat); err != nil {
+				log.Panicf("Invalid log_format=%s", cfg.LogFormat)
 			}
 
 			if lv, err := log.ParseLevel(cfg.LogLevel); err != nil {
@@ -249,6 +265,16 @@ func NewServerCmd(parentCmd *cobra.Command, parentVc *viper.Viper, version, buil
 					log.Fatalf("Invalid log_forwarder err:%+v", err)
 				}
 			}
+			if cfg.Tracing != nil {
+				var tCfg tracing.Config
+				tCfg = *cfg.Tracing
+				if tCfg.Target != "" {
+					tCfg.Target = cfg.ResolveAbsolute(tCfg.Target)
+				}
+				if err := tracing.Start(&tCfg); err != nil {
+					log.Fatalf("Invalid tracing err:%+v", err)
+				}
+			}
 			if cpuProfile := vc.GetString("cpuprofile"); cpuProfile != "" {
 				if err := StartCPUProfile(cpuProfile); err != nil {
 					log.Fatalf(err.Error())
@@ -298,6 +324,7 @@ func MergeWithViper(vc *viper.Viper, cfg *ServerConfig) error {
 	eeSocket := vc.GetString("ee_socket")
 	backupDir := vc.GetString("backup_dir")
 	lwFilename := vc.GetString("log_writer_filename")
+	tTarget := vc.GetString("tracing_target")
 
 	if cfgFilePath != "" {
 		cfg.SetFilePath(cfgFilePath)
@@ -328,6 +355,15 @@ func MergeWithViper(vc *viper.Viper, cfg *ServerConfig) error {
 				return errors.Errorf("fail to merge config file=%s err=%+v", cfg.FilePath, err)
 			}
 		}
+		if tVc := vc.Sub("tracing"); tVc != nil {
+			m := make(map[string]interface{})
+			for _, k := range tVc.AllKeys() {
+				m["tracing_"+k] = tVc.Get(k)
+			}
+			if err := vc.MergeConfigMap(m); err != nil {
+				return errors.Errorf("fail to merge config file=%s err=%+v", cfg.FilePath, err)
+			}
+		}
 	}
 
 	if err := vc.Unmarshal(cfg, ViperDecodeOptJson); err != nil {
@@ -372,6 +408,7 @@ func MergeWithViper(vc *viper.Viper, cfg *ServerConfig) error {
 		MaxBackups: vc.GetInt("log_writer_maxbackups"),
 		LocalTime:  vc.GetBool("log_writer_localtime"),
 		Compress:   vc.GetBool("log_writer_compress"),
+		Format:     vc.GetString("log_writer_format"),
 	}
 	if len(lwFilename) > 0 {
 		lwCfg.Filename = cfg.ResolveRelative(lwFilename)
@@ -380,6 +417,20 @@ func MergeWithViper(vc *viper.Viper, cfg *ServerConfig) error {
 		cfg.LogWriter = lwCfg
 	}
 
+	tCfg := &tracing.Config{
+		Exporter: vc.GetString("tracing_exporter"),
+		Target:   vc.GetString("tracing_target"),
+		Sample:   vc.GetFloat64("tracing_sample"),
+	}
+	if len(tTarget) > 0 {
+		tCfg.Target = cfg.ResolveRelative(tTarget)
+	}
+	if len(tCfg.Exporter) > 0 {
+		cfg.Tracing = tCfg
+	} else {
+		cfg.Tracing = nil
+	}
+
 	if nodeDir != "" {
 		cfg.BaseDir = cfg.ResolveRelative(nodeDir)
 	}
@@ -0,0 +1,453 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+	"github.com/icon-project/goloop/service/transaction"
+)
+
+func walletFromKeyStore(vc *viper.Viper) (module.Wallet, error) {
+	var kb, pb []byte
+	var err error
+	ksf := vc.GetString("key_store")
+	if kb, err = ioutil.ReadFile(ksf); err != nil {
+		return nil, fmt.Errorf("fail to open KeyStore file=%s err=%+v", ksf, err)
+	}
+	//key_secret -> key_password
+	ksec := vc.GetString("key_secret")
+	kpass := vc.GetString("key_password")
+	if ksec != "" {
+		if pb, err = ioutil.ReadFile(ksec); err != nil {
+			return nil, fmt.Errorf("fail to open KeySecret file=%s err=%+v", ksec, err)
+		}
+	} else if kpass != "" {
+		pb = []byte(kpass)
+	} else {
+		return nil, fmt.Errorf("there is no password information for the KeyStore, use --key_secret or --key_password")
+	}
+	w, err := wallet.NewFromKeyStore(kb, pb)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create wallet err=%+v", err)
+	}
+	return w, nil
+}
+
+func newTxParam(from string, stepLimit int64, nid string) (*v3.TransactionParam, error) {
+	nidValue, err := intconv.ParseInt(nid, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v3.TransactionParam{
+		Version:     v3.VersionValue,
+		FromAddress: jsonrpc.Address(from),
+		StepLimit:   jsonrpc.HexInt(intconv.FormatInt(stepLimit)),
+		NetworkID:   jsonrpc.HexInt(intconv.FormatInt(nidValue)),
+	}, nil
+}
+
+func addTransferTxFlags(c *cobra.Command) {
+	flags := c.Flags()
+	flags.String("to", "", "ToAddress")
+	flags.String("value", "", "Value")
+	flags.String("message", "", "Message")
+	MarkAnnotationRequired(flags, "to", "value")
+}
+
+func buildTransferTx(cmd *cobra.Command, param *v3.TransactionParam) error {
+	var value common.HexInt
+	if _, ok := value.SetString(cmd.Flag("value").Value.String(), 0); !ok {
+		return fmt.Errorf("fail to parsing value %s", cmd.Flag("value").Value.String())
+	}
+	param.ToAddress = jsonrpc.Address(cmd.Flag("to").Value.String())
+	param.Value = jsonrpc.HexInt(value.String())
+	msg, err := cmd.Flags().GetString("message")
+	if err != nil {
+		return err
+	}
+	if msg != "" {
+		param.DataType = "message"
+		param.Data = jsonrpc.HexBytes("0x" + hex.EncodeToString([]byte(msg)))
+	}
+	return nil
+}
+
+func addCallTxFlags(c *cobra.Command) {
+	flags := c.Flags()
+	flags.String("to", "", "ToAddress")
+	flags.String("method", "",
+		"Name of the function to invoke in SCORE, if '--raw' used, will overwrite")
+	flags.StringToString("param", nil,
+		"key=value, Function parameters, if '--raw' used, will overwrite")
+	flags.String("value", "", "Value of transfer")
+	flags.String("raw", "", "call with 'data' using raw json file or json-string")
+	MarkAnnotationRequired(flags, "to", "method")
+}
+
+func buildCallTx(cmd *cobra.Command, param *v3.TransactionParam) error {
+	param.ToAddress = jsonrpc.Address(cmd.Flag("to").Value.String())
+	param.DataType = "call"
+	dataM := make(map[string]interface{})
+	if dataJson := cmd.Flag("raw").Value.String(); dataJson != "" {
+		var dataBytes []byte
+		if strings.HasPrefix(strings.TrimSpace(dataJson), "{") {
+			dataBytes = []byte(dataJson)
+		} else {
+			var err error
+			if dataBytes, err = readFile(dataJson); err != nil {
+				return err
+			}
+		}
+		if err := json.Unmarshal(dataBytes, &dataM); err != nil {
+			return err
+		}
+	}
+
+	if dataMethod := cmd.Flag("method").Value.String(); dataMethod != "" {
+		dataM["method"] = dataMethod
+	}
+	if dataParams, err := cmd.Flags().GetStringToString("param"); err == nil && len(dataParams) > 0 {
+		dataM["params"] = dataParams
+	}
+	if len(dataM) > 0 {
+		param.Data = dataM
+	}
+	if cmd.Flag("value").Value.String() != "" {
+		var value common.HexInt
+		if _, ok := value.SetString(cmd.Flag("value").Value.String(), 0); !ok {
+			return fmt.Errorf("fail to parsing value %s", cmd.Flag("value").Value.String())
+		}
+		param.Value = jsonrpc.HexInt(value.String())
+	}
+	return nil
+}
+
+func addDeployTxFlags(c *cobra.Command) {
+	flags := c.Flags()
+	flags.String("to", "cx0000000000000000000000000000000000000000", "ToAddress")
+	flags.String("content_type", "application/zip",
+		"Mime-type of the content")
+	flags.StringToString("param", nil,
+		"key=value, Function parameters will be delivered to on_install() or on_update()")
+	MarkAnnotationHidden(flags, "content-type")
+}
+
+func buildDeployTx(cmd *cobra.Command, content string, param *v3.TransactionParam) error {
+	param.ToAddress = jsonrpc.Address(cmd.Flag("to").Value.String())
+	param.DataType = "deploy"
+	dataM := make(map[string]interface{})
+	dataM["contentType"] = cmd.Flag("content_type").Value.String()
+	isDir, err := IsDirectory(content)
+	if err != nil {
+		return err
+	}
+	var b []byte
+	if isDir {
+		if b, err = ZipDirectory(content, "__pycache__"); err != nil {
+			return fmt.Errorf("fail to zip with directory %s err:%+v", content, err)
+		}
+	} else {
+		if b, err = readFile(content); err != nil {
+			return fmt.Errorf("fail to read %s err:%+v", content, err)
+		}
+	}
+	dataM["content"] = "0x" + hex.EncodeToString(b)
+	if dataParams, err := cmd.Flags().GetStringToString("param"); err == nil && len(dataParams) > 0 {
+		dataM["params"] = dataParams
+	}
+	param.Data = dataM
+	return nil
+}
+
+func readTxParam(file string) (*v3.TransactionParam, error) {
+	b, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	param := &v3.TransactionParam{}
+	if err := json.Unmarshal(b, param); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal transaction file=%s err=%+v", file, err)
+	}
+	return param, nil
+}
+
+func writeTxParam(output string, param *v3.TransactionParam) error {
+	if output == "" {
+		return JsonPrettyPrintln(os.Stdout, param)
+	}
+	return JsonPrettySaveFile(output, 0644, param)
+}
+
+// TxInspection is the result of inspecting a transaction.
+type TxInspection struct {
+	TxHash    jsonrpc.HexBytes `json:"txHash"`
+	From      jsonrpc.Address  `json:"from"`
+	To        jsonrpc.Address  `json:"to"`
+	NetworkID jsonrpc.HexInt   `json:"nid"`
+	Timestamp string           `json:"timestamp"`
+	Signed    bool             `json:"signed"`
+	Signer    jsonrpc.Address  `json:"signer,omitempty"`
+	Errors    []string         `json:"errors,omitempty"`
+}
+
+func inspectTx(bs []byte, nid string) (*TxInspection, error) {
+	param := &v3.TransactionParam{}
+	if err := json.Unmarshal(bs, param); err != nil {
+		return nil, err
+	}
+	hash, err := client.TransactionHash(param)
+	if err != nil {
+		return nil, err
+	}
+	r := &TxInspection{
+		TxHash:    jsonrpc.HexBytes("0x" + hex.EncodeToString(hash)),
+		From:      param.FromAddress,
+		To:        param.ToAddress,
+		NetworkID: param.NetworkID,
+		Signed:    param.Signature != "",
+	}
+	if ts, err := param.Timestamp.Int64(); err == nil {
+		r.Timestamp = time.Unix(0, ts*int64(time.Microsecond)).UTC().Format(time.RFC3339Nano)
+	}
+
+	if nid != "" {
+		if expected, err := intconv.ParseInt(nid, 64); err != nil {
+			return nil, err
+		} else if v, err := param.NetworkID.Int64(); err != nil || v != expected {
+			r.Errors = append(r.Errors, fmt.Sprintf("nid mismatch (expected=%#x)", expected))
+		}
+	}
+	if !r.Signed {
+		return r, nil
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(param.Signature)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("invalid signature encoding: %v", err))
+		return r, nil
+	}
+	sig, err := crypto.ParseSignature(sigBytes)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("invalid signature: %v", err))
+		return r, nil
+	}
+	pk, err := sig.RecoverPublicKey(hash)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("fail to recover public key: %v", err))
+		return r, nil
+	}
+	signer := common.NewAccountAddressFromPublicKey(pk)
+	r.Signer = jsonrpc.Address(signer.String())
+	if string(r.Signer) != string(param.FromAddress) {
+		r.Errors = append(r.Errors, "signature isn't made by from")
+	}
+
+	// check it as the node does
+	tx, err := transaction.NewTransactionFromJSON(bs)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("invalid transaction: %v", err))
+	} else if err := tx.Verify(); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("invalid transaction: %v", err))
+	} else if !bytes.Equal(tx.ID(), hash) {
+		r.Errors = append(r.Errors, "hash mismatch")
+	}
+	return r, nil
+}
+
+// currentTimestamp returns the current time in microseconds for the
+// timestamp of transactions.
+func currentTimestamp() int64 {
+	return time.Now().UnixNano() / int64(time.Microsecond)
+}
+
+func NewTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command, *viper.Viper) {
+	rootCmd, vc := NewCommand(parentCmd, parentVc, "tx", "Build, sign and send transactions separately")
+
+	buildCmd, buildVc := NewCommand(rootCmd, vc, "build", "Build an unsigned transaction")
+	buildFlags := buildCmd.PersistentFlags()
+	buildFlags.String("from", "", "FromAddress")
+	buildFlags.String("nid", "", "Network ID")
+	buildFlags.Int64("step_limit", 0, "StepLimit")
+	buildFlags.Int64("timestamp", 0, "Timestamp in microseconds (default: current time)")
+	buildFlags.String("output", "", "File for the transaction (default: stdout)")
+	MarkAnnotationCustom(buildFlags, "from", "nid", "step_limit")
+	BindPFlags(buildVc, buildFlags)
+	buildCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return ValidateFlagsWithViper(buildVc, cmd.Flags())
+	}
+	buildTx := func(cmd *cobra.Command, build func(param *v3.TransactionParam) error) error {
+		param, err := newTxParam(buildVc.GetString("from"),
+			buildVc.GetInt64("step_limit"), buildVc.GetString("nid"))
+		if err != nil {
+			return err
+		}
+		if err := build(param); err != nil {
+			return err
+		}
+		ts := buildVc.GetInt64("timestamp")
+		if ts == 0 {
+			ts = currentTimestamp()
+		}
+		param.Timestamp = jsonrpc.HexInt(intconv.FormatInt(ts))
+		return writeTxParam(buildVc.GetString("output"), param)
+	}
+
+	transferCmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Coin Transfer Transaction",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return buildTx(cmd, func(param *v3.TransactionParam) error {
+				return buildTransferTx(cmd, param)
+			})
+		},
+	}
+	buildCmd.AddCommand(transferCmd)
+	addTransferTxFlags(transferCmd)
+
+	callCmd := &cobra.Command{
+		Use:   "call",
+		Short: "SmartContract Call Transaction",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return buildTx(cmd, func(param *v3.TransactionParam) error {
+				return buildCallTx(cmd, param)
+			})
+		},
+	}
+	buildCmd.AddCommand(callCmd)
+	addCallTxFlags(callCmd)
+
+	deployCmd := &cobra.Command{
+		Use:   "deploy SCORE_ZIP_FILE",
+		Short: "Deploy Transaction",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return buildTx(cmd, func(param *v3.TransactionParam) error {
+				return buildDeployTx(cmd, args[0], param)
+			})
+		},
+	}
+	buildCmd.AddCommand(deployCmd)
+	addDeployTxFlags(deployCmd)
+
+	signCmd, signVc := NewCommand(rootCmd, vc, "sign FILE", "Sign the transaction with the KeyStore")
+	signCmd.Args = ArgsWithDefaultErrorFunc(cobra.ExactArgs(1))
+	signFlags := signCmd.Flags()
+	signFlags.String("key_store", "", "KeyStore file for wallet")
+	signFlags.String("key_secret", "", "Secret(password) file for KeyStore")
+	signFlags.String("key_password", "", "Password for the KeyStore file")
+	signFlags.Bool("refresh_timestamp", false, "Set the timestamp to the current time before signing")
+	signFlags.String("output", "", "File for the signed transaction (default: stdout)")
+	MarkAnnotationCustom(signFlags, "key_store")
+	BindPFlags(signVc, signFlags)
+	signCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := ValidateFlagsWithViper(signVc, cmd.Flags()); err != nil {
+			return err
+		}
+		param, err := readTxParam(args[0])
+		if err != nil {
+			return err
+		}
+		w, err := walletFromKeyStore(signVc)
+		if err != nil {
+			return err
+		}
+		if addr := w.Address().String(); param.FromAddress == "" {
+			param.FromAddress = jsonrpc.Address(addr)
+		} else if string(param.FromAddress) != addr {
+			return fmt.Errorf("from=%s isn't the address of the KeyStore(%s)", param.FromAddress, addr)
+		}
+		// transactions built long before signing are expired.
+		if signVc.GetBool("refresh_timestamp") {
+			param.Timestamp = jsonrpc.HexInt(intconv.FormatInt(currentTimestamp()))
+		}
+		if err := client.SignTransaction(w, param); err != nil {
+			return err
+		}
+		return writeTxParam(signVc.GetString("output"), param)
+	}
+
+	var rpcClient client.ClientV3
+	sendCmd, sendVc := NewCommand(rootCmd, vc, "send FILE", "Send the signed transaction")
+	sendCmd.Args = ArgsWithDefaultErrorFunc(cobra.ExactArgs(1))
+	AddRpcRequiredFlags(sendCmd)
+	BindPFlags(sendVc, sendCmd.PersistentFlags())
+	sendCmd.PersistentPreRunE = RpcPersistentPreRunE(sendVc, &rpcClient)
+	sendCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		param, err := readTxParam(args[0])
+		if err != nil {
+			return err
+		}
+		if param.Signature == "" {
+			return fmt.Errorf("transaction isn't signed")
+		}
+		txHash, err := rpcClient.SendSignedTransaction(param)
+		if err != nil {
+			return err
+		}
+		return JsonPrettyPrintln(os.Stdout, txHash)
+	}
+
+	inspectCmd := &cobra.Command{
+		Use:   "inspect FILE",
+		Short: "Decode the transaction and check its hash, signature and network ID",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bs, err := readFile(args[0])
+			if err != nil {
+				return err
+			}
+			nid, _ := cmd.Flags().GetString("nid")
+			r, err := inspectTx(bs, nid)
+			if err != nil {
+				return err
+			}
+			if err := JsonPrettyPrintln(os.Stdout, r); err != nil {
+				return err
+			}
+			if len(r.Errors) > 0 {
+				return fmt.Errorf("invalid transaction")
+			}
+			return nil
+		},
+	}
+	rootCmd.AddCommand(inspectCmd)
+	inspectCmd.Flags().String("nid", "", "Expected network ID")
+
+	return rootCmd, vc
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/tracing"
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/network"
 	"github.com/icon-project/goloop/server"
@@ -50,9 +51,12 @@ type GoChainConfig struct {
 
 	LogLevel     string               `json:"log_level"`
 	ConsoleLevel string               `json:"console_level"`
+	LogFormat    string               `json:"log_format,omitempty"`
 	LogForwarder *log.ForwarderConfig `json:"log_forwarder,omitempty"`
 
 	LogWriter *log.WriterConfig `json:"log_writer,omitempty"`
+
+	Tracing *tracing.Config `json:"tracing,omitempty"`
 }
 
 func (config *GoChainConfig) String() string {
@@ -72,6 +76,9 @@ func (config *GoChainConfig) Set(name string) error {
 		if config.LogWriter != nil {
 			lwCfg = *config.LogWriter
 		}
+		if config.Tracing != nil {
+			tCfg = *config.Tracing
+		}
 	}
 	return nil
 }
@@ -93,6 +100,7 @@ var eeSocket string
 var modLevels map[string]string
 var lfCfg log.ForwarderConfig
 var lwCfg log.WriterConfig
+var tCfg tracing.Config
 var importMode bool
 var importMaxHeight int64
 var importDataSource string
@@ -114,7 +122,7 @@ func main() {
 	flag.StringVar(&cfg.RPCAddr, "rpc", ":9080", "Listen ip-port of JSON-RPC")
 	flag.BoolVar(&cfg.RPCDump, "rpc_dump", false, "JSON-RPC Request, Response Dump flag")
 	flag.BoolVar(&cfg.RPCDebug, "rpc_debug", false, "JSON-RPC Debug enable")
-	flag.IntVar(&cfg.RPCBatchLimit, "rpc_batch_limit", 10, "JSON-RPC batch limit")
+	flag.IntVar(&cfg.RPCBatchLimit, "rpc_batch_limit", 100, "JSON-RPC batch limit")
 	flag.StringVar(&cfg.SeedAddr, "seed", "", "Ip-port of Seed")
 	flag.StringVar(&genesisStorage, "genesis_storage", "", "Genesis storage path")
 	flag.StringVar(&genesisPath, "genesis", "", "Genesis template directory or file")
@@ -134,11 +142,16 @@ func main() {
 	flag.IntVar(&cfg.PatchTxPoolSize, "patch_tx_pool", 0, "Patch transaction pool size")
 	flag.IntVar(&cfg.MaxBlockTxBytes, "max_block_tx_bytes", 0, "Maximum size of transactions in a block")
 	flag.StringVar(&cfg.NodeCache, "node_cache", chain.NodeCacheDefault, "Node cache (none,small,large)")
+	flag.BoolVar(&cfg.FlatState, "flat_state", false, "Read world state from flat key-value snapshot")
+	flag.BoolVar(&cfg.RewardHistory, "reward_history", false, "Keep history of rewards for each term")
+	flag.BoolVar(&cfg.ValidationHistory, "validation_history", false, "Keep history of block validation of validators")
+	flag.BoolVar(&cfg.Observer, "observer", false, "Follow the chain with headers and votes of blocks only")
 	flag.BoolVar(&cfg.ValidateTxOnSend, "validate_tx_on_send", false, "Validate transaction on send")
 	cfg.ChildrenLimit = flag.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	cfg.NephewsLimit = flag.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	flag.StringVar(&cfg.LogLevel, "log_level", "debug", "Main log level")
 	flag.StringVar(&cfg.ConsoleLevel, "console_level", "trace", "Console log level")
+	flag.StringVar(&cfg.LogFormat, "log_format", "text", "Console log format (text,json)")
 	flag.StringToStringVar(&modLevels, "mod_level", nil, "Console log level for specific module (<mod>=<level>,...)")
 	flag.StringVar(&lfCfg.Vendor, "log_forwarder_vendor", "", "LogForwarder vendor (fluentd,logstash)")
 	flag.StringVar(&lfCfg.Address, "log_forwarder_address", "", "LogForwarder address")
@@ -155,6 +168,10 @@ func main() {
 	flag.IntVar(&lwCfg.MaxBackups, "log_writer_maxbackups", 0, "Log file max backups")
 	flag.BoolVar(&lwCfg.LocalTime, "log_writer_localtime", false, "Uses localtime for rotated filename")
 	flag.BoolVar(&lwCfg.Compress, "log_writer_compress", false, "Uses gzip for rotated file")
+	flag.StringVar(&lwCfg.Format, "log_writer_format", "text", "Log file format (text,json)")
+	flag.StringVar(&tCfg.Exporter, "tracing_exporter", "", "Tracing exporter (stdout,file)")
+	flag.StringVar(&tCfg.Target, "tracing_target", "", "Tracing target file for file exporter")
+	flag.Float64Var(&tCfg.Sample, "tracing_sample", 1, "Tracing sampling probability (0,1]")
 	flag.BoolVar(&importMode, "import", false, "Run in import mode")
 	flag.Int64Var(&importMaxHeight, "import_max_height", 0, "Import max height")
 	flag.StringVar(&importDataSource, "import_data_source", "datasource/", "Import data source")
@@ -333,6 +350,12 @@ func Execute(cmd *cobra.Command, args []string) {
 		cfg.LogWriter = nil
 	}
 
+	if tCfg.Exporter != "" {
+		cfg.Tracing = &tCfg
+	} else {
+		cfg.Tracing = nil
+	}
+
 	if *cfg.ChildrenLimit < 0 {
 		cfg.ChildrenLimit = nil
 	}
@@ -373,6 +396,22 @@ func Execute(cmd *cobra.Command, args []string) {
 		if err != nil {
 			log.Panicf("Fail to set log writer err=%+v", err)
 		}
+		if err = logger.SetFileFormat(lwCfg.Format); err != nil {
+			log.Panicf("Fail to set log writer format=%s", lwCfg.Format)
+		}
+	}
+	if err := logger.SetFormat(cfg.LogFormat); err != nil {
+		log.Panicf("Fail to set log format=%s", cfg.LogFormat)
+	}
+
+	if cfg.Tracing != nil {
+		tCfg = *cfg.Tracing
+		if tCfg.Target != "" {
+			tCfg.Target = cfg.ResolveAbsolute(tCfg.Target)
+		}
+		if err := tracing.Start(&tCfg); err != nil {
+			log.Panicf("Fail to start tracing err=%+v", err)
+		}
 	}
 
 	if lv, err := log.ParseLevel(cfg.LogLevel); err != nil {
@@ -473,6 +512,7 @@ func Execute(cmd *cobra.Command, args []string) {
 
 	// TODO : server-chain setting
 	srv := server.NewManager(cfg.RPCAddr, cfg.RPCDump, cfg.RPCDebug, "", cfg.RPCBatchLimit, wallet, logger)
+	srv.SetEEManager(pm)
 	hex.EncodeToString(wallet.Address().ID())
 	c := chain.NewChain(wallet, nt, srv, pm, logger, &cfg.Config)
 	err = c.Init()
@@ -45,6 +45,7 @@ func main() {
 	cli.NewStatsCmd(rootCmd, rootVc)
 	cli.NewRpcCmd(rootCmd, nil)
 	cli.NewDebugCmd(rootCmd, nil)
+	cli.NewTxCmd(rootCmd, nil)
 	rootCmd.AddCommand(
 		cli.NewGStorageCmd("gs"),
 		cli.NewGenesisCmd("gn"),
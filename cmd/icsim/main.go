@@ -0,0 +1,73 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/common/log"
+)
+
+// execute runs the command with the arguments, and returns the exit code.
+func execute(args []string, stdout, stderr io.Writer) int {
+	var metrics string
+	var verbose bool
+	cmd := &cobra.Command{
+		Use:   os.Args[0] + " <scenario file>",
+		Short: "Run IISS scenario with the simulator",
+		Long: "Run IISS scenario written in JSON or YAML with the simulator.\n" +
+			"It reports metrics of each term in CSV format.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !verbose {
+				log.GlobalLogger().SetLevel(log.WarnLevel)
+			}
+			s, err := LoadScenario(args[0])
+			if err != nil {
+				return err
+			}
+			w := stdout
+			if len(metrics) > 0 {
+				f, err := os.Create(metrics)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return NewRunner(s, w, stderr).Run()
+		},
+	}
+	cmd.SetArgs(args)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	flags := cmd.Flags()
+	flags.StringVarP(&metrics, "metrics", "m", "", "File to write metrics of terms in CSV (default: stdout)")
+	flags.BoolVarP(&verbose, "verbose", "v", false, "Print logs of the simulator")
+	if err := cmd.Execute(); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(execute(os.Args[1:], os.Stdout, os.Stderr))
+}
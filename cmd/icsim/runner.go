@@ -0,0 +1,660 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/icsim"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+var governanceAddress = common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+
+var metricsHeader = []string{
+	"term", "startHeight", "endHeight", "revision",
+	"totalSupply", "totalStake", "totalBonded", "totalDelegated",
+	"preps", "iglobal", "iscore",
+}
+
+type Runner struct {
+	scenario *Scenario
+	sim      icsim.Simulator
+	accounts map[string]module.Address
+	names    []string
+	metrics  *csv.Writer
+	log      io.Writer
+	failures int
+}
+
+// addressOf returns the address of the account with the name. If there is
+// no account with the name, then the name is parsed as an address.
+func (r *Runner) addressOf(name string) (module.Address, error) {
+	if addr, ok := r.accounts[name]; ok {
+		return addr, nil
+	}
+	addr, err := common.NewAddressFromString(name)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Errorf("UnknownAccount(%q)", name)
+	}
+	return addr, nil
+}
+
+func (r *Runner) addressPtrOf(name string) (*common.Address, error) {
+	addr, err := r.addressOf(name)
+	if err != nil {
+		return nil, err
+	}
+	return common.AddressToPtr(addr), nil
+}
+
+func applyRewardFund(rf *icstate.RewardFund, o *RewardFund) {
+	if o == nil {
+		return
+	}
+	if o.Iglobal != nil {
+		rf.Iglobal = o.Iglobal.Value()
+	}
+	if o.Iprep != nil {
+		rf.Iprep = big.NewInt(*o.Iprep)
+	}
+	if o.Icps != nil {
+		rf.Icps = big.NewInt(*o.Icps)
+	}
+	if o.Irelay != nil {
+		rf.Irelay = big.NewInt(*o.Irelay)
+	}
+	if o.Ivoter != nil {
+		rf.Ivoter = big.NewInt(*o.Ivoter)
+	}
+}
+
+func setInt64(p *int64, v *int64) {
+	if v != nil {
+		*p = *v
+	}
+}
+
+func setInt(p *int, v *int) {
+	if v != nil {
+		*p = *v
+	}
+}
+
+func (r *Runner) init() error {
+	s := r.scenario
+	r.accounts = make(map[string]module.Address)
+	balances := make(map[string]*big.Int)
+	for _, a := range s.Accounts {
+		if len(a.Name) == 0 {
+			return errors.IllegalArgumentError.New("NoAccountName")
+		}
+		if _, ok := r.accounts[a.Name]; ok {
+			return errors.IllegalArgumentError.Errorf("DuplicateAccount(%q)", a.Name)
+		}
+		var addr module.Address
+		if len(a.Address) > 0 {
+			var err error
+			if addr, err = common.NewAddressFromString(a.Address); err != nil {
+				return errors.IllegalArgumentError.Wrapf(err, "InvalidAddress(%q)", a.Address)
+			}
+		} else {
+			addr = common.NewAccountAddress(crypto.SHA3Sum256([]byte(a.Name))[:common.AddressIDBytes])
+		}
+		r.accounts[a.Name] = addr
+		r.names = append(r.names, a.Name)
+		balances[icutils.ToKey(addr)] = new(big.Int).Set(a.Balance.Value())
+	}
+
+	if len(s.Validators) == 0 {
+		return errors.IllegalArgumentError.New("NoValidators")
+	}
+	validators := make([]module.Validator, len(s.Validators))
+	for i, name := range s.Validators {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return err
+		}
+		if validators[i], err = state.ValidatorFromAddress(addr); err != nil {
+			return err
+		}
+	}
+
+	revision := icmodule.LatestRevision
+	if s.Revision > 0 {
+		revision = s.Revision
+	}
+	c := icsim.NewConfig()
+	sc := &s.Config
+	setInt64(&c.TermPeriod, sc.TermPeriod)
+	setInt64(&c.MainPRepCount, sc.MainPRepCount)
+	setInt64(&c.SubPRepCount, sc.SubPRepCount)
+	setInt64(&c.Irep, sc.Irep)
+	setInt64(&c.Rrep, sc.Rrep)
+	setInt64(&c.BondRequirement, sc.BondRequirement)
+	setInt64(&c.UnbondingPeriodMultiplier, sc.UnbondingPeriodMultiplier)
+	setInt64(&c.UnstakeSlotMax, sc.UnstakeSlotMax)
+	setInt64(&c.LockMinMultiplier, sc.LockMinMultiplier)
+	setInt64(&c.LockMaxMultiplier, sc.LockMaxMultiplier)
+	setInt64(&c.UnbondingMax, sc.UnbondingMax)
+	setInt(&c.ValidationPenaltyCondition, sc.ValidationPenaltyCondition)
+	setInt64(&c.ConsistentValidationPenaltyCondition, sc.ConsistentValidationPenaltyCondition)
+	setInt64(&c.ConsistentValidationPenaltyMask, sc.ConsistentValidationPenaltyMask)
+	setInt(&c.ConsistentValidationPenaltySlashRatio, sc.ConsistentValidationPenaltySlashRatio)
+	setInt64(&c.DelegationSlotMax, sc.DelegationSlotMax)
+	if rf := sc.RewardFund; rf != nil {
+		if rf.Iglobal != nil {
+			if !rf.Iglobal.IsInt64() {
+				return errors.IllegalArgumentError.Errorf("InvalidIglobal(%s)", rf.Iglobal.String())
+			}
+			c.Iglobal = rf.Iglobal.Int64()
+		}
+		setInt64(&c.Iprep, rf.Iprep)
+		setInt64(&c.Icps, rf.Icps)
+		setInt64(&c.Irelay, rf.Irelay)
+		setInt64(&c.Ivoter, rf.Ivoter)
+	}
+	r.sim = icsim.NewSimulator(icmodule.ValueToRevision(revision), validators, balances, c)
+	if r.sim == nil {
+		return errors.InvalidStateError.New("FailToInitializeSimulator")
+	}
+	return r.metrics.Write(metricsHeader)
+}
+
+func (r *Runner) consensusInfo(missed []string) (module.ConsensusInfo, error) {
+	if len(missed) == 0 {
+		return nil, nil
+	}
+	vl := r.sim.ValidatorList()
+	voted := make([]bool, len(vl))
+	for i := range voted {
+		voted[i] = true
+	}
+	for _, name := range missed {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range vl {
+			if v.Address().Equal(addr) {
+				voted[i] = false
+			}
+		}
+	}
+	return icsim.NewConsensusInfo(r.sim.Database(), vl, voted), nil
+}
+
+// advance generates blocks until the height, and it records metrics of the
+// terms ended in the meantime.
+func (r *Runner) advance(height int64, missed []string) error {
+	for r.sim.BlockHeight() < height {
+		next := height
+		tss := r.sim.TermSnapshot()
+		ended := false
+		if tss != nil && tss.GetEndHeight() > r.sim.BlockHeight() && tss.GetEndHeight() <= height {
+			next = tss.GetEndHeight()
+			ended = true
+		}
+		csi, err := r.consensusInfo(missed)
+		if err != nil {
+			return err
+		}
+		if err = r.sim.GoTo(next, csi); err != nil {
+			return err
+		}
+		if ended {
+			if err = r.record(tss); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Runner) record(tss *icstate.TermSnapshot) error {
+	info := r.sim.GetNetworkInfo()
+	iscore := new(big.Int)
+	for _, name := range r.names {
+		if v := r.sim.QueryIScore(r.accounts[name]); v != nil {
+			iscore.Add(iscore, v)
+		}
+	}
+	var iglobal interface{}
+	if rf, ok := info["rewardFund"].(map[string]interface{}); ok {
+		iglobal = rf["Iglobal"]
+	}
+	row := []string{
+		fmt.Sprint(tss.Sequence()),
+		fmt.Sprint(tss.StartHeight()),
+		fmt.Sprint(tss.GetEndHeight()),
+		fmt.Sprint(r.sim.Revision().Value()),
+		r.sim.TotalSupply().String(),
+		r.sim.TotalStake().String(),
+		toString(info["totalBonded"]),
+		toString(info["totalDelegated"]),
+		toString(info["preps"]),
+		toString(iglobal),
+		iscore.String(),
+	}
+	if err := r.metrics.Write(row); err != nil {
+		return err
+	}
+	r.metrics.Flush()
+	return r.metrics.Error()
+}
+
+func toString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func (r *Runner) prepInfo(from module.Address, name, node string) (*icstate.PRepInfo, error) {
+	id := from.String()
+	city := "Seoul"
+	country := "KOR"
+	email := fmt.Sprintf("%s@example.com", id)
+	website := fmt.Sprintf("https://%s.example.com/", id)
+	details := fmt.Sprintf("%sdetails/", website)
+	endpoint := fmt.Sprintf("%s.example.com:7100", id)
+	info := &icstate.PRepInfo{
+		City:        &city,
+		Country:     &country,
+		Name:        &name,
+		Email:       &email,
+		WebSite:     &website,
+		Details:     &details,
+		P2PEndpoint: &endpoint,
+	}
+	if len(node) > 0 {
+		addr, err := r.addressOf(node)
+		if err != nil {
+			return nil, err
+		}
+		info.Node = addr
+	}
+	return info, nil
+}
+
+func (r *Runner) votes(vs []Vote) ([]*common.Address, []*big.Int, error) {
+	addrs := make([]*common.Address, len(vs))
+	amounts := make([]*big.Int, len(vs))
+	for i := range vs {
+		addr, err := r.addressPtrOf(vs[i].Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		addrs[i] = addr
+		amounts[i] = vs[i].Amount.Value()
+	}
+	return addrs, amounts, nil
+}
+
+func (r *Runner) transaction(a *Action) (icsim.Transaction, error) {
+	var from, address module.Address
+	var err error
+	if len(a.From) > 0 {
+		if from, err = r.addressOf(a.From); err != nil {
+			return nil, err
+		}
+	}
+	if len(a.Address) > 0 {
+		if address, err = r.addressOf(a.Address); err != nil {
+			return nil, err
+		}
+	}
+	needs := func(ok bool, name string) error {
+		if !ok {
+			return errors.IllegalArgumentError.Errorf("NoParam(type=%s,param=%s)", a.Type, name)
+		}
+		return nil
+	}
+
+	sim := r.sim
+	switch a.Type {
+	case "setStake":
+		if err = needs(from != nil, "from"); err == nil {
+			err = needs(a.Amount != nil, "amount")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return sim.SetStake(from, a.Amount.Value()), nil
+	case "setDelegation":
+		if err = needs(from != nil, "from"); err != nil {
+			return nil, err
+		}
+		addrs, amounts, err := r.votes(a.Delegations)
+		if err != nil {
+			return nil, err
+		}
+		ds := make(icstate.Delegations, len(addrs))
+		for i := range addrs {
+			ds[i] = icstate.NewDelegation(addrs[i], amounts[i])
+		}
+		return sim.SetDelegation(from, ds), nil
+	case "setBond":
+		if err = needs(from != nil, "from"); err != nil {
+			return nil, err
+		}
+		addrs, amounts, err := r.votes(a.Bonds)
+		if err != nil {
+			return nil, err
+		}
+		bonds := make(icstate.Bonds, len(addrs))
+		for i := range addrs {
+			bonds[i] = icstate.NewBond(addrs[i], amounts[i])
+		}
+		return sim.SetBond(from, bonds), nil
+	case "setBonderList":
+		if err = needs(from != nil, "from"); err != nil {
+			return nil, err
+		}
+		bl := make(icstate.BonderList, len(a.Bonders))
+		for i, name := range a.Bonders {
+			if bl[i], err = r.addressPtrOf(name); err != nil {
+				return nil, err
+			}
+		}
+		return sim.SetBonderList(from, bl), nil
+	case "registerPRep", "setPRep":
+		if err = needs(from != nil, "from"); err != nil {
+			return nil, err
+		}
+		name := a.Name
+		if len(name) == 0 {
+			name = a.From
+		}
+		info, err := r.prepInfo(from, name, a.Node)
+		if err != nil {
+			return nil, err
+		}
+		if a.Type == "registerPRep" {
+			return sim.RegisterPRep(from, info), nil
+		}
+		return sim.SetPRep(from, info), nil
+	case "unregisterPRep":
+		if err = needs(from != nil, "from"); err != nil {
+			return nil, err
+		}
+		return sim.UnregisterPRep(from), nil
+	case "disqualifyPRep":
+		if err = needs(address != nil, "address"); err != nil {
+			return nil, err
+		}
+		if from == nil {
+			from = governanceAddress
+		}
+		return sim.DisqualifyPRep(from, address), nil
+	case "penalizeNonVoters":
+		if err = needs(address != nil, "address"); err != nil {
+			return nil, err
+		}
+		return sim.PenalizeNonVoters(address), nil
+	case "setRevision":
+		return sim.SetRevision(icmodule.ValueToRevision(a.Revision)), nil
+	case "claimIScore":
+		if err = needs(from != nil, "from"); err != nil {
+			return nil, err
+		}
+		return sim.ClaimIScore(from), nil
+	case "setNetworkScore":
+		if err = needs(len(a.Role) > 0, "role"); err != nil {
+			return nil, err
+		}
+		return sim.SetNetworkScore(a.Role, address), nil
+	case "setRewardFund":
+		rf := sim.GetRewardFund().Clone()
+		applyRewardFund(rf, a.RewardFund)
+		return sim.SetRewardFund(rf), nil
+	case "setSlashingRate":
+		var pt icmodule.PenaltyType
+		switch a.Penalty {
+		case "blockValidation":
+			pt = icmodule.PenaltyBlockValidation
+		case "nonVote":
+			pt = icmodule.PenaltyNonVote
+		default:
+			return nil, errors.IllegalArgumentError.Errorf("InvalidPenalty(%q)", a.Penalty)
+		}
+		return sim.SetSlashingRate(pt, a.Rate), nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownActionType(%q)", a.Type)
+	}
+}
+
+func (r *Runner) failf(idx int, step *Step, format string, args ...interface{}) {
+	r.failures++
+	fmt.Fprintf(r.log, "FAIL step[%d] %s: %s\n", idx, step.Name, fmt.Sprintf(format, args...))
+}
+
+func (r *Runner) checkAmount(idx int, step *Step, name string, expected *Amount, actual *big.Int) {
+	if expected == nil {
+		return
+	}
+	if actual == nil {
+		actual = new(big.Int)
+	}
+	if expected.Cmp(actual) != 0 {
+		r.failf(idx, step, "%s expected=%s actual=%s", name, expected.String(), actual.String())
+	}
+}
+
+func (r *Runner) check(idx int, step *Step) error {
+	a := step.Assert
+	sim := r.sim
+	if a.Revision > 0 && a.Revision != sim.Revision().Value() {
+		r.failf(idx, step, "revision expected=%d actual=%d", a.Revision, sim.Revision().Value())
+	}
+	r.checkAmount(idx, step, "totalSupply", a.TotalSupply, sim.TotalSupply())
+	r.checkAmount(idx, step, "totalStake", a.TotalStake, sim.TotalStake())
+	r.checkAmount(idx, step, "totalBond", a.TotalBond, sim.TotalBond())
+	for name, v := range a.Balances {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return err
+		}
+		r.checkAmount(idx, step, "balance of "+name, &v, sim.GetBalance(addr))
+	}
+	for name, v := range a.Stakes {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return err
+		}
+		stake, _ := sim.GetStake(addr)["stake"].(*big.Int)
+		r.checkAmount(idx, step, "stake of "+name, &v, stake)
+	}
+	for name, v := range a.IScores {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return err
+		}
+		r.checkAmount(idx, step, "iscore of "+name, &v, sim.QueryIScore(addr))
+	}
+	for name, v := range a.PReps {
+		addr, err := r.addressOf(name)
+		if err != nil {
+			return err
+		}
+		prep := sim.GetPRep(addr)
+		if prep == nil {
+			r.failf(idx, step, "no prep %s", name)
+			continue
+		}
+		if len(v.Grade) > 0 && v.Grade != prep.Grade().String() {
+			r.failf(idx, step, "grade of %s expected=%s actual=%s", name, v.Grade, prep.Grade())
+		}
+		if len(v.Status) > 0 && v.Status != prep.Status().String() {
+			r.failf(idx, step, "status of %s expected=%s actual=%s", name, v.Status, prep.Status())
+		}
+		r.checkAmount(idx, step, "delegated of "+name, v.Delegated, prep.Delegated())
+		r.checkAmount(idx, step, "bonded of "+name, v.Bonded, prep.Bonded())
+	}
+	if len(a.NetworkScores) > 0 {
+		scores := sim.GetNetworkScores()
+		for role, name := range a.NetworkScores {
+			score := scores[role]
+			if len(name) == 0 {
+				if score != nil {
+					r.failf(idx, step, "network score of %s expected=none actual=%s", role, score)
+				}
+				continue
+			}
+			addr, err := r.addressOf(name)
+			if err != nil {
+				return err
+			}
+			if score == nil || !score.Equal(addr) {
+				r.failf(idx, step, "network score of %s expected=%s actual=%v", role, addr, score)
+			}
+		}
+	}
+	if a.RewardFund != nil {
+		rf := sim.GetRewardFund()
+		expected := rf.Clone()
+		applyRewardFund(expected, a.RewardFund)
+		if !expected.Equal(rf) {
+			r.failf(idx, step, "rewardFund expected=%v actual=%v", expected, rf)
+		}
+	}
+	return nil
+}
+
+// skipTermBoundary generates empty blocks while the next block is the first
+// or one of the last two blocks of the term. The simulator handles terms only
+// with empty blocks, so actions can't be executed in such blocks.
+func (r *Runner) skipTermBoundary(missed []string) error {
+	for {
+		tss := r.sim.TermSnapshot()
+		if tss == nil {
+			return nil
+		}
+		next := r.sim.BlockHeight() + 1
+		if next != tss.StartHeight() && next != tss.GetEndHeight()-1 && next != tss.GetEndHeight() {
+			return nil
+		}
+		if err := r.advance(next, missed); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Runner) runStep(idx int, step *Step) error {
+	sim := r.sim
+	if len(step.Actions) > 0 {
+		planned := sim.BlockHeight() + 1
+		if err := r.skipTermBoundary(step.MissedVoters); err != nil {
+			return err
+		}
+		if height := sim.BlockHeight() + 1; height != planned {
+			fmt.Fprintf(r.log, "step[%d] %s actions moved from height=%d to height=%d for the term boundary\n",
+				idx, step.Name, planned, height)
+		}
+		block := icsim.NewBlock()
+		for i := range step.Actions {
+			tx, err := r.transaction(&step.Actions[i])
+			if err != nil {
+				return errors.Wrapf(err, "InvalidAction(step=%d,action=%d)", idx, i)
+			}
+			block.AddTransaction(tx)
+		}
+		csi, err := r.consensusInfo(step.MissedVoters)
+		if err != nil {
+			return err
+		}
+		receipts, err := sim.GoByBlock(block, csi)
+		if err != nil {
+			return err
+		}
+		for i, rct := range receipts {
+			action := &step.Actions[i]
+			if success := rct.Status() == icsim.Success; success == action.ExpectFailure {
+				r.failf(idx, step, "action[%d] %s expectFailure=%t err=%v",
+					i, action.Type, action.ExpectFailure, rct.Error())
+			}
+		}
+	}
+	if step.Go > 0 {
+		if err := r.advance(sim.BlockHeight()+step.Go, step.MissedVoters); err != nil {
+			return err
+		}
+	}
+	if step.GoTo > 0 {
+		if step.GoTo <= sim.BlockHeight() {
+			return errors.IllegalArgumentError.Errorf(
+				"InvalidGoTo(step=%d,height=%d,goTo=%d)", idx, sim.BlockHeight(), step.GoTo)
+		}
+		if err := r.advance(step.GoTo, step.MissedVoters); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < step.GoToTermEnd; i++ {
+		tss := sim.TermSnapshot()
+		if tss == nil || tss.GetEndHeight() <= sim.BlockHeight() {
+			return errors.InvalidStateError.Errorf("NoTermEnd(step=%d,height=%d)", idx, sim.BlockHeight())
+		}
+		if err := r.advance(tss.GetEndHeight(), step.MissedVoters); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(r.log, "step[%d] %s height=%d revision=%d\n",
+		idx, step.Name, sim.BlockHeight(), sim.Revision().Value())
+	if step.Assert != nil {
+		return r.check(idx, step)
+	}
+	return nil
+}
+
+// Run runs all steps of the scenario, and it returns an error if any of
+// assertions fails.
+func (r *Runner) Run() error {
+	if err := r.init(); err != nil {
+		return err
+	}
+	for i := range r.scenario.Steps {
+		if err := r.runStep(i, &r.scenario.Steps[i]); err != nil {
+			return err
+		}
+	}
+	r.metrics.Flush()
+	if err := r.metrics.Error(); err != nil {
+		return err
+	}
+	if r.failures > 0 {
+		return errors.Errorf("%d assertion(s) failed", r.failures)
+	}
+	return nil
+}
+
+func NewRunner(s *Scenario, metrics io.Writer, log io.Writer) *Runner {
+	return &Runner{
+		scenario: s,
+		metrics:  csv.NewWriter(metrics),
+		log:      log,
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmount_UnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		json  string
+		value string
+	}{
+		{`100`, "100"},
+		{`"100"`, "100"},
+		{`"0x64"`, "100"},
+		{`"2icx"`, "2000000000000000000"},
+		{`"0.5icx"`, "500000000000000000"},
+		{`" 0.5 icx "`, "500000000000000000"},
+	}
+	for _, c := range cases {
+		var a Amount
+		if assert.NoError(t, json.Unmarshal([]byte(c.json), &a), c.json) {
+			assert.Equal(t, c.value, a.String(), c.json)
+		}
+	}
+	for _, js := range []string{`1.5`, `"0x"`, `"abc"`, `"1e-19icx"`, `"icx"`} {
+		var a Amount
+		assert.Error(t, json.Unmarshal([]byte(js), &a), js)
+	}
+	var a *Amount
+	assert.Nil(t, a.Value())
+}
+
+func runScenario(t *testing.T, name string) (*Runner, [][]string, string, error) {
+	s, err := LoadScenario(filepath.Join("testdata", name))
+	assert.NoError(t, err)
+	metrics := bytes.NewBuffer(nil)
+	logs := bytes.NewBuffer(nil)
+	r := NewRunner(s, metrics, logs)
+	err = r.Run()
+	rows, cerr := csv.NewReader(metrics).ReadAll()
+	assert.NoError(t, cerr)
+	return r, rows, logs.String(), err
+}
+
+func TestRunner_Example(t *testing.T) {
+	r, rows, logs, err := runScenario(t, "example.yaml")
+	assert.NoError(t, err)
+	assert.Zero(t, r.failures)
+
+	// a row for each term ended in the scenario
+	assert.Equal(t, 7, len(rows))
+	assert.Equal(t, metricsHeader, rows[0])
+	end := int64(10)
+	for _, row := range rows[1:] {
+		assert.Equal(t, len(metricsHeader), len(row))
+		assert.Equal(t, strconv.FormatInt(end, 10), row[2])
+		end += 10
+	}
+	last := rows[len(rows)-1]
+	assert.Equal(t, "10090000000000000000000", last[5])
+	assert.Equal(t, "90000000000000000000", last[6])
+	assert.Equal(t, "3", last[8])
+	assert.Equal(t, "6000000000000000000000000", last[9])
+	assert.NotEqual(t, "0", last[10])
+
+	// actions of the first step are moved from the start of the term
+	assert.Contains(t, logs, "step[0] register actions moved from height=1 to height=2")
+	assert.NotContains(t, logs, "FAIL")
+}
+
+func TestRunner_Failures(t *testing.T) {
+	r, rows, logs, err := runScenario(t, "failure.yaml")
+	assert.Error(t, err)
+	assert.Equal(t, 2, r.failures)
+	assert.Equal(t, [][]string{metricsHeader}, rows)
+	assert.Equal(t, 2, strings.Count(logs, "FAIL "))
+	assert.Contains(t, logs, "action[1] registerPRep expectFailure=true")
+	assert.Contains(t, logs, "stake of user1 expected=100000000000000000000")
+}
+
+func TestExecute(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	assert.Equal(t, 0, execute([]string{filepath.Join("testdata", "example.yaml")}, out, out))
+	assert.Equal(t, 1, execute([]string{filepath.Join("testdata", "failure.yaml")}, out, out))
+	assert.Equal(t, 1, execute([]string{filepath.Join("testdata", "none.yaml")}, out, out))
+
+	metrics := filepath.Join(t.TempDir(), "metrics.csv")
+	out.Reset()
+	assert.Equal(t, 0, execute([]string{"-m", metrics, filepath.Join("testdata", "example.yaml")}, out, out))
+	assert.NotContains(t, out.String(), metricsHeader[0]+",")
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+)
+
+// Amount is an amount of loop. In a scenario, it's written as a number,
+// a decimal or hexadecimal string, or a decimal string with "icx" suffix.
+type Amount struct {
+	big.Int
+}
+
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	var s string
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(b)
+	}
+	return a.parse(strings.TrimSpace(s))
+}
+
+func (a *Amount) parse(s string) error {
+	if strings.HasSuffix(s, "icx") {
+		v, ok := new(big.Rat).SetString(strings.TrimSpace(strings.TrimSuffix(s, "icx")))
+		if !ok {
+			return errors.Errorf("InvalidAmount(%q)", s)
+		}
+		v.Mul(v, new(big.Rat).SetInt(icutils.BigIntICX))
+		if !v.IsInt() {
+			return errors.Errorf("InvalidAmount(%q)", s)
+		}
+		a.Set(v.Num())
+		return nil
+	}
+	return intconv.ParseBigInt(&a.Int, s)
+}
+
+func (a *Amount) Value() *big.Int {
+	if a == nil {
+		return nil
+	}
+	return &a.Int
+}
+
+type RewardFund struct {
+	Iglobal *Amount `json:"iglobal"`
+	Iprep   *int64  `json:"iprep"`
+	Icps    *int64  `json:"icps"`
+	Irelay  *int64  `json:"irelay"`
+	Ivoter  *int64  `json:"ivoter"`
+}
+
+type Config struct {
+	TermPeriod                            *int64      `json:"termPeriod"`
+	MainPRepCount                         *int64      `json:"mainPRepCount"`
+	SubPRepCount                          *int64      `json:"subPRepCount"`
+	Irep                                  *int64      `json:"irep"`
+	Rrep                                  *int64      `json:"rrep"`
+	BondRequirement                       *int64      `json:"bondRequirement"`
+	UnbondingPeriodMultiplier             *int64      `json:"unbondingPeriodMultiplier"`
+	UnstakeSlotMax                        *int64      `json:"unstakeSlotMax"`
+	LockMinMultiplier                     *int64      `json:"lockMinMultiplier"`
+	LockMaxMultiplier                     *int64      `json:"lockMaxMultiplier"`
+	UnbondingMax                          *int64      `json:"unbondingMax"`
+	ValidationPenaltyCondition            *int        `json:"validationPenaltyCondition"`
+	ConsistentValidationPenaltyCondition  *int64      `json:"consistentValidationPenaltyCondition"`
+	ConsistentValidationPenaltyMask       *int64      `json:"consistentValidationPenaltyMask"`
+	ConsistentValidationPenaltySlashRatio *int        `json:"consistentValidationPenaltySlashRatio"`
+	DelegationSlotMax                     *int64      `json:"delegationSlotMax"`
+	RewardFund                            *RewardFund `json:"rewardFund"`
+}
+
+type Account struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Balance Amount `json:"balance"`
+}
+
+type Vote struct {
+	Address string `json:"address"`
+	Amount  Amount `json:"amount"`
+}
+
+type Action struct {
+	Type          string      `json:"type"`
+	From          string      `json:"from"`
+	Address       string      `json:"address"`
+	Amount        *Amount     `json:"amount"`
+	Delegations   []Vote      `json:"delegations"`
+	Bonds         []Vote      `json:"bonds"`
+	Bonders       []string    `json:"bonders"`
+	Name          string      `json:"name"`
+	Node          string      `json:"node"`
+	Revision      int         `json:"revision"`
+	Role          string      `json:"role"`
+	RewardFund    *RewardFund `json:"rewardFund"`
+	Penalty       string      `json:"penalty"`
+	Rate          int         `json:"rate"`
+	ExpectFailure bool        `json:"expectFailure"`
+}
+
+type PRepAssert struct {
+	Grade     string  `json:"grade"`
+	Status    string  `json:"status"`
+	Delegated *Amount `json:"delegated"`
+	Bonded    *Amount `json:"bonded"`
+}
+
+type Assert struct {
+	Revision      int                   `json:"revision"`
+	TotalSupply   *Amount               `json:"totalSupply"`
+	TotalStake    *Amount               `json:"totalStake"`
+	TotalBond     *Amount               `json:"totalBond"`
+	Balances      map[string]Amount     `json:"balances"`
+	Stakes        map[string]Amount     `json:"stakes"`
+	IScores       map[string]Amount     `json:"iscores"`
+	PReps         map[string]PRepAssert `json:"preps"`
+	NetworkScores map[string]string     `json:"networkScores"`
+	RewardFund    *RewardFund           `json:"rewardFund"`
+}
+
+// Step is a unit of a scenario. Actions are executed in a block first, then
+// blocks are generated by Go, GoTo or GoToTermEnd, and Assert is checked
+// at last.
+type Step struct {
+	Name         string   `json:"name"`
+	Actions      []Action `json:"actions"`
+	Go           int64    `json:"go"`
+	GoTo         int64    `json:"goTo"`
+	GoToTermEnd  int      `json:"goToTermEnd"`
+	MissedVoters []string `json:"missedVoters"`
+	Assert       *Assert  `json:"assert"`
+}
+
+type Scenario struct {
+	Revision   int       `json:"revision"`
+	Config     Config    `json:"config"`
+	Accounts   []Account `json:"accounts"`
+	Validators []string  `json:"validators"`
+	Steps      []Step    `json:"steps"`
+}
+
+// toJSONValue converts the value decoded by yaml to the one which can be
+// encoded by json.
+func toJSONValue(v interface{}) interface{} {
+	switch o := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(o))
+		for k, e := range o {
+			m[fmt.Sprint(k)] = toJSONValue(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(o))
+		for i, e := range o {
+			l[i] = toJSONValue(e)
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+// LoadScenario reads the scenario from the file. The file is regarded as
+// YAML if it has ".yaml" or ".yml" extension, otherwise it's JSON.
+func LoadScenario(name string) (*Scenario, error) {
+	bs, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		var v interface{}
+		if err = yaml.Unmarshal(bs, &v); err != nil {
+			return nil, errors.Wrapf(err, "InvalidYAML(file=%s)", name)
+		}
+		if bs, err = json.Marshal(toJSONValue(v)); err != nil {
+			return nil, err
+		}
+	}
+	s := new(Scenario)
+	if err = json.Unmarshal(bs, s); err != nil {
+		return nil, errors.Wrapf(err, "InvalidScenario(file=%s)", name)
+	}
+	return s, nil
+}
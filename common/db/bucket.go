@@ -28,8 +28,45 @@ const (
 
 	// ChainProperty is general key value map for chain property.
 	ChainProperty BucketID = "C"
+
+	// FlatState maps accounts and storage values of the world state from
+	// their keys. It's used as a cache of the merkle trie.
+	FlatState BucketID = "F"
+
+	// BlockAccumulator maps node hash to node of the merkle accumulator of
+	// block hashes. It also has the state of the accumulator.
+	BlockAccumulator BucketID = "A"
 )
 
+// PrefixDeleter is implemented by buckets able to delete entries by the
+// prefix of their keys.
+type PrefixDeleter interface {
+	DeletePrefix(prefix []byte) error
+}
+
+// DeletePrefix deletes all entries of the bucket whose keys start with
+// the prefix.
+func DeletePrefix(bk Bucket, prefix []byte) error {
+	if pd, ok := bk.(PrefixDeleter); ok {
+		return pd.DeletePrefix(prefix)
+	}
+	return errors.UnsupportedError.Errorf("NoPrefixDeleter(type=%T)", bk)
+}
+
+// prefixEnd returns the smallest key greater than all keys having the
+// prefix. It returns nil if there is no such key.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
 // internalKey returns key prefixed with the bucket's id.
 func internalKey(id BucketID, key []byte) []byte {
 	buf := make([]byte, len(key)+len(id))
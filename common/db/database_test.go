@@ -149,3 +149,48 @@ func TestDatabase_SetReopenGet(t *testing.T) {
 		})
 	}
 }
+
+func testDatabase_DeletePrefix(t *testing.T, backend BackendType) {
+	dir, err := ioutil.TempDir("", string(backend))
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testDB, _ := openDatabase(backend, "test", dir)
+	defer testDB.Close()
+
+	bucket, _ := testDB.GetBucket("h")
+	other, _ := testDB.GetBucket("he")
+	keys := [][]byte{[]byte("e"), []byte("ell"), []byte("ello"), []byte("f")}
+	for _, k := range keys {
+		assert.NoError(t, bucket.Set(k, []byte("v")))
+		assert.NoError(t, other.Set(k, []byte("v")))
+	}
+
+	err = DeletePrefix(bucket, []byte("el"))
+	assert.NoError(t, err)
+	for i, k := range keys {
+		has, err := bucket.Has(k)
+		assert.NoError(t, err)
+		assert.Equal(t, i != 1 && i != 2, has)
+
+		has, err = other.Has(k)
+		assert.NoError(t, err)
+		assert.True(t, has)
+	}
+}
+
+func TestDatabase_DeletePrefix(t *testing.T) {
+	for be, _ := range backends {
+		t.Run(string(be), func(t *testing.T) {
+			testDatabase_DeletePrefix(t, be)
+		})
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte{0x01, 0x03}, prefixEnd([]byte{0x01, 0x02}))
+	assert.Equal(t, []byte{0x02}, prefixEnd([]byte{0x01, 0xff}))
+	assert.Nil(t, prefixEnd([]byte{0xff, 0xff}))
+}
@@ -6,9 +6,18 @@ import (
 
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/icon-project/goloop/common/errors"
 )
 
-const GoLevelDBBackend BackendType = "goleveldb"
+const (
+	GoLevelDBBackend BackendType = "goleveldb"
+
+	// goLevelDeleteBatch is the number of entries deleted at once by
+	// DeletePrefix.
+	goLevelDeleteBatch = 1024
+)
 
 func init() {
 	dbCreator := func(name string, dir string) (Database, error) {
@@ -95,3 +104,27 @@ func (bucket *goLevelBucket) Set(key []byte, value []byte) error {
 func (bucket *goLevelBucket) Delete(key []byte) error {
 	return bucket.db.Delete(internalKey(bucket.id, key), nil)
 }
+
+func (bucket *goLevelBucket) DeletePrefix(prefix []byte) error {
+	if len(bucket.id) == 0 {
+		// keys of other buckets have the same prefix
+		return errors.UnsupportedError.New("DeletePrefixOnEmptyID")
+	}
+	itr := bucket.db.NewIterator(util.BytesPrefix(internalKey(bucket.id, prefix)), nil)
+	defer itr.Release()
+
+	batch := new(leveldb.Batch)
+	for itr.Next() {
+		batch.Delete(itr.Key())
+		if batch.Len() >= goLevelDeleteBatch {
+			if err := bucket.db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+	return bucket.db.Write(batch, nil)
+}
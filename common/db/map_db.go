@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -111,6 +112,20 @@ func (t *mapBucket) Set(k, v []byte) error {
 	return nil
 }
 
+func (t *mapBucket) DeletePrefix(prefix []byte) error {
+	if configLogMapDB {
+		log.Printf("mapBucket[%s].DeletePrefix(%x)", t.id, prefix)
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for k := range t.real {
+		if strings.HasPrefix(k, string(prefix)) {
+			delete(t.real, k)
+		}
+	}
+	return nil
+}
+
 func (t *mapBucket) Delete(k []byte) error {
 	if configLogMapDB {
 		log.Printf("mapBucket[%s].Delete(%x)", t.id, k)
@@ -36,6 +36,13 @@ func (bk *proxyBucket) Delete(key []byte) error {
 	return errors.New("ProxyIsNotRealized")
 }
 
+func (bk *proxyBucket) DeletePrefix(prefix []byte) error {
+	if bk.real != nil {
+		return DeletePrefix(bk.real, prefix)
+	}
+	return errors.New("ProxyIsNotRealized")
+}
+
 type proxyDB struct {
 	real    Database
 	buckets map[string]*proxyBucket
@@ -245,6 +245,20 @@ func (db *RocksDB) deleteValue(cf *C.rocksdb_column_family_handle_t, k []byte) e
 	return nil
 }
 
+func (db *RocksDB) deleteRange(cf *C.rocksdb_column_family_handle_t, start, end []byte) error {
+	var (
+		cErr   *C.char
+		cStart = (*C.char)(unsafe.Pointer(&start[0]))
+		cEnd   = (*C.char)(unsafe.Pointer(&end[0]))
+	)
+	C.rocksdb_delete_range_cf(db.db, db.wo, cf, cStart, C.size_t(len(start)), cEnd, C.size_t(len(end)), &cErr)
+	if cErr != nil {
+		defer C.rocksdb_free(unsafe.Pointer(cErr))
+		return errors.New(C.GoString(cErr))
+	}
+	return nil
+}
+
 type RocksBucket struct {
 	cf *C.rocksdb_column_family_handle_t
 	db *RocksDB
@@ -265,3 +279,11 @@ func (b *RocksBucket) Set(key []byte, value []byte) error {
 func (b *RocksBucket) Delete(key []byte) error {
 	return b.db.deleteValue(b.cf, key)
 }
+
+func (b *RocksBucket) DeletePrefix(prefix []byte) error {
+	end := prefixEnd(prefix)
+	if len(prefix) == 0 || end == nil {
+		return errors.New("InvalidPrefix")
+	}
+	return b.db.deleteRange(b.cf, prefix, end)
+}
@@ -11,16 +11,18 @@ type logFilter struct {
 	defaultLevel Level
 	moduleLevels map[string]Level
 
-	fileWriter  io.Writer
-	filterLevel Level
+	fileWriter    io.Writer
+	fileFormatter logrus.Formatter
+	filterLevel   Level
 }
 
 func newLogFilter(formatter logrus.Formatter) *logFilter {
 	return &logFilter{
-		formatter:    formatter,
-		defaultLevel: TraceLevel,
-		filterLevel:  TraceLevel,
-		moduleLevels: make(map[string]Level, 6),
+		formatter:     formatter,
+		fileFormatter: formatter,
+		defaultLevel:  TraceLevel,
+		filterLevel:   TraceLevel,
+		moduleLevels:  make(map[string]Level, 6),
 	}
 }
 
@@ -45,14 +47,22 @@ func (f *logFilter) Format(e *logrus.Entry) ([]byte, error) {
 	if e.Level > logrus.Level(level) && f.fileWriter == nil {
 		return nil, nil
 	}
-	buf, err := f.formatter.Format(e)
-	if f.fileWriter != nil && len(buf) > 0 {
-		f.fileWriter.Write(buf)
-	}
-	if e.Level > logrus.Level(level) {
-		return nil, nil
+	if f.fileWriter != nil {
+		buf, err := f.fileFormatter.Format(e)
+		if len(buf) > 0 {
+			f.fileWriter.Write(buf)
+		}
+		if e.Level > logrus.Level(level) {
+			return nil, nil
+		}
+		if f.fileFormatter == f.formatter {
+			return buf, err
+		}
+		if e.Buffer != nil {
+			e.Buffer.Reset()
+		}
 	}
-	return buf, err
+	return f.formatter.Format(e)
 }
 
 func (f *logFilter) SetModuleLevel(module string, level Level) {
@@ -75,6 +85,26 @@ func (f *logFilter) GetDefaultLevel() Level {
 	return f.defaultLevel
 }
 
+// SetFormat sets the format of the console output.
+func (f *logFilter) SetFormat(format string) error {
+	formatter, err := newFormatter(format)
+	if err != nil {
+		return err
+	}
+	f.formatter = formatter
+	return nil
+}
+
+// SetFileFormat sets the format of the file writer.
+func (f *logFilter) SetFileFormat(format string) error {
+	formatter, err := newFormatter(format)
+	if err != nil {
+		return err
+	}
+	f.fileFormatter = formatter
+	return nil
+}
+
 // SetFileWriter set file writer
 func (f *logFilter) SetFileWriter(writer io.Writer) error {
 	f.fileWriter = writer
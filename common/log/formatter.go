@@ -1,13 +1,78 @@
 package log
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
+func newFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case "", FormatText:
+		return customFormatter{}, nil
+	case FormatJSON:
+		return jsonFormatter{}, nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownLogFormat(format=%s)", format)
+	}
+}
+
+// textValue returns the value of the field for text format.
+func textValue(v interface{}) interface{} {
+	switch obj := v.(type) {
+	case []byte:
+		return "0x" + hex.EncodeToString(obj)
+	default:
+		return v
+	}
+}
+
+// nativeValue returns the value of the field which can be used as a native
+// value of JSON or forwarders.
+func nativeValue(v interface{}) interface{} {
+	switch obj := v.(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	case []byte:
+		return "0x" + hex.EncodeToString(obj)
+	case time.Duration:
+		return int64(obj / time.Microsecond)
+	case time.Time:
+		return obj.Format(time.RFC3339Nano)
+	case error:
+		return obj.Error()
+	case fmt.Stringer:
+		return obj.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func sortedKeys(data logrus.Fields) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type customFormatter struct{}
 
 var levelNames = []string{"P", "F", "E", "W", "I", "D", "T"}
@@ -48,12 +113,44 @@ func (customFormatter) Format(e *logrus.Entry) ([]byte, error) {
 		fmt.Fprint(buf, path.Base(e.Caller.File), ":", e.Caller.Line, " ")
 	}
 	buf.WriteString(strings.TrimRight(e.Message, "\n"))
-	for k, v := range e.Data {
+	for _, k := range sortedKeys(e.Data) {
 		if _, ok := systemFields[k]; ok {
 			continue
 		}
-		fmt.Fprintf(buf, " %s=%v", k, v)
+		fmt.Fprintf(buf, " %s=%v", k, textValue(e.Data[k]))
 	}
 	buf.WriteString("\n")
 	return buf.Bytes(), nil
 }
+
+// jsonFormatter writes an entry as a JSON object per line. Fields of the
+// entry are written as keys of the object.
+type jsonFormatter struct{}
+
+const (
+	jsonKeyTime    = "time"
+	jsonKeyLevel   = "level"
+	jsonKeyMessage = "msg"
+	jsonKeySource  = "src"
+)
+
+func (jsonFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	obj := make(map[string]interface{}, len(e.Data)+5)
+	for k, v := range e.Data {
+		obj[k] = nativeValue(v)
+	}
+	obj[jsonKeyTime] = e.Time.Format(time.RFC3339Nano)
+	obj[jsonKeyLevel] = e.Level.String()
+	obj[jsonKeyMessage] = strings.TrimRight(e.Message, "\n")
+	if e.HasCaller() {
+		if _, ok := obj[FieldKeyModule]; !ok {
+			obj[FieldKeyModule] = getPackageName(e.Caller.Function)
+		}
+		obj[jsonKeySource] = fmt.Sprintf("%s:%d", path.Base(e.Caller.File), e.Caller.Line)
+	}
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return append(bs, '\n'), nil
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(out *bytes.Buffer) Logger {
+	l := New()
+	l.(*loggerWrapper).Out = out
+	l.SetLevel(TraceLevel)
+	return l
+}
+
+func TestJSONFormat(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := newTestLogger(out)
+	assert.NoError(t, l.SetFormat(FormatJSON))
+
+	l.WithFields(Fields{
+		FieldKeyModule:   "SV",
+		FieldKeyHeight:   int64(10),
+		FieldKeyTxID:     []byte{0x12, 0x34},
+		FieldKeyDuration: 1500 * time.Microsecond,
+		"reason":         errors.New("fail"),
+	}).Debugf("DROP TX\n")
+
+	var obj map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &obj))
+	assert.Equal(t, "debug", obj["level"])
+	assert.Equal(t, "DROP TX", obj["msg"])
+	assert.Equal(t, "SV", obj[FieldKeyModule])
+	assert.EqualValues(t, 10, obj[FieldKeyHeight])
+	assert.Equal(t, "0x1234", obj[FieldKeyTxID])
+	assert.EqualValues(t, 1500, obj[FieldKeyDuration])
+	assert.Equal(t, "fail", obj["reason"])
+	assert.Contains(t, obj["src"], "formatter_test.go:")
+	_, err := time.Parse(time.RFC3339Nano, obj["time"].(string))
+	assert.NoError(t, err)
+
+	assert.Error(t, l.SetFormat("unknown"))
+}
+
+func TestTextFormat_Fields(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := newTestLogger(out)
+
+	l.WithFields(Fields{
+		FieldKeyRound:  int32(1),
+		FieldKeyHeight: int64(10),
+		FieldKeyTxID:   []byte{0x12, 0x34},
+	}).Info("enter round")
+
+	line := strings.TrimSpace(out.String())
+	assert.True(t, strings.HasSuffix(line, "enter round height=10 round=1 txid=0x1234"), line)
+}
+
+func TestFileFormat(t *testing.T) {
+	out := new(bytes.Buffer)
+	file := new(bytes.Buffer)
+	l := newTestLogger(out)
+	assert.NoError(t, l.SetFileWriter(file))
+	assert.NoError(t, l.SetFileFormat(FormatJSON))
+	l.SetConsoleLevel(InfoLevel)
+
+	l.WithFields(Fields{FieldKeyHeight: 1}).Info("first")
+	l.WithFields(Fields{FieldKeyHeight: 2}).Debug("second")
+
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+	assert.Contains(t, out.String(), "first height=1")
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	assert.Len(t, lines, 2)
+	for i, line := range lines {
+		var obj map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &obj))
+		assert.EqualValues(t, i+1, obj[FieldKeyHeight])
+	}
+}
+
+func TestHookWrapper_NativeFields(t *testing.T) {
+	var fired logrus.Fields
+	h := &HookWrapper{
+		h: hookFunc(func(e *logrus.Entry) error {
+			fired = e.Data
+			return nil
+		}),
+	}
+	data := logrus.Fields{
+		FieldKeyTxID:     []byte{0xab},
+		FieldKeyDuration: time.Millisecond,
+	}
+	e := &logrus.Entry{Data: data, Time: time.Now()}
+	assert.NoError(t, h.Fire(e))
+	assert.Equal(t, "0xab", fired[FieldKeyTxID])
+	assert.EqualValues(t, 1000, fired[FieldKeyDuration])
+	assert.Equal(t, []byte{0xab}, e.Data[FieldKeyTxID])
+}
+
+type hookFunc func(e *logrus.Entry) error
+
+func (f hookFunc) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (f hookFunc) Fire(e *logrus.Entry) error {
+	return f(e)
+}
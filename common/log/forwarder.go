@@ -122,7 +122,7 @@ func (h *HookWrapper) Fire(e *logrus.Entry) error {
 	}()
 	e.Data = make(map[string]interface{}, len(d)+2)
 	for k, v := range d {
-		e.Data[k] = v
+		e.Data[k] = nativeValue(v)
 	}
 
 	e.Data["logtime"] = e.Time.UnixNano()
@@ -76,6 +76,16 @@ const (
 	FieldKeyEID    = "eid"
 )
 
+// Keys of typed fields for common data. They are written as native keys
+// by JSON format and forwarders.
+const (
+	FieldKeyHeight   = "height"
+	FieldKeyRound    = "round"
+	FieldKeyTxID     = "txid"
+	FieldKeyPeer     = "peer"
+	FieldKeyDuration = "duration"
+)
+
 var systemFields = map[string]bool{
 	FieldKeyWallet: true,
 	FieldKeyModule: true,
@@ -141,6 +151,8 @@ type Logger interface {
 	Writer() *io.PipeWriter
 	WriterLevel(lv Level) *io.PipeWriter
 	SetFileWriter(writer io.Writer) error
+	SetFormat(format string) error
+	SetFileFormat(format string) error
 
 	addHook(hook logrus.Hook)
 }
@@ -217,6 +229,14 @@ func (w entryWrapper) SetFileWriter(writer io.Writer) error {
 	return w.Logger.Formatter.(*logFilter).SetFileWriter(writer)
 }
 
+func (w entryWrapper) SetFormat(format string) error {
+	return w.Logger.Formatter.(*logFilter).SetFormat(format)
+}
+
+func (w entryWrapper) SetFileFormat(format string) error {
+	return w.Logger.Formatter.(*logFilter).SetFileFormat(format)
+}
+
 type loggerWrapper struct {
 	*logrus.Logger
 }
@@ -285,6 +305,14 @@ func (w loggerWrapper) SetFileWriter(writer io.Writer) error {
 	return w.Logger.Formatter.(*logFilter).SetFileWriter(writer)
 }
 
+func (w loggerWrapper) SetFormat(format string) error {
+	return w.Logger.Formatter.(*logFilter).SetFormat(format)
+}
+
+func (w loggerWrapper) SetFileFormat(format string) error {
+	return w.Logger.Formatter.(*logFilter).SetFileFormat(format)
+}
+
 func getPackageName(f string) string {
 	lastSlash := strings.LastIndex(f, "/")
 	if lastSlash >= 0 {
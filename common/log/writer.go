@@ -13,6 +13,7 @@ type WriterConfig struct {
 	MaxBackups int    `json:"maxbackups"`
 	LocalTime  bool   `json:"localtime"`
 	Compress   bool   `json:"compress"`
+	Format     string `json:"format,omitempty"`
 }
 
 func NewWriter(cfg *WriterConfig) (io.Writer, error) {
@@ -0,0 +1,193 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+type Config struct {
+	// Exporter is the type of the exporter (stdout, file).
+	Exporter string `json:"exporter"`
+	// Target is the path of the file for file exporter.
+	Target string `json:"target,omitempty"`
+	// Sample is probability of sampling new traces. Zero or value
+	// greater than one samples all traces.
+	Sample float64 `json:"sample,omitempty"`
+}
+
+func (c *Config) sampler() trace.Sampler {
+	if c.Sample <= 0 || c.Sample >= 1 {
+		return trace.AlwaysSample()
+	}
+	return trace.ProbabilitySampler(c.Sample)
+}
+
+// SpanRecord is the record of a span written by the exporter.
+type SpanRecord struct {
+	TraceID    string                 `json:"traceId"`
+	SpanID     string                 `json:"spanId"`
+	ParentID   string                 `json:"parentId,omitempty"`
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Duration   int64                  `json:"duration"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Status     *SpanStatus            `json:"status,omitempty"`
+}
+
+type SpanStatus struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func newSpanRecord(sd *trace.SpanData) *SpanRecord {
+	r := &SpanRecord{
+		TraceID:    hex.EncodeToString(sd.TraceID[:]),
+		SpanID:     hex.EncodeToString(sd.SpanID[:]),
+		Name:       sd.Name,
+		Start:      sd.StartTime,
+		End:        sd.EndTime,
+		Duration:   int64(sd.EndTime.Sub(sd.StartTime) / time.Microsecond),
+		Attributes: sd.Attributes,
+	}
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		r.ParentID = hex.EncodeToString(sd.ParentSpanID[:])
+	}
+	if sd.Code != trace.StatusCodeOK {
+		r.Status = &SpanStatus{
+			Code:    sd.Code,
+			Message: sd.Message,
+		}
+	}
+	return r
+}
+
+// jsonExporter writes finished spans as JSON lines.
+type jsonExporter struct {
+	lock   sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+func (e *jsonExporter) ExportSpan(sd *trace.SpanData) {
+	bs, err := json.Marshal(newSpanRecord(sd))
+	if err != nil {
+		return
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	_, _ = e.w.Write(append(bs, '\n'))
+}
+
+func (e *jsonExporter) Close() error {
+	if e.closer != nil {
+		return e.closer.Close()
+	}
+	return nil
+}
+
+func newExporter(cfg *Config) (*jsonExporter, error) {
+	switch cfg.Exporter {
+	case ExporterStdout:
+		return &jsonExporter{w: os.Stdout}, nil
+	case ExporterFile:
+		if cfg.Target == "" {
+			return nil, errors.IllegalArgumentError.New("NoTargetForFileExporter")
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.Target), 0700); err != nil {
+			return nil, errors.WithCode(err, errors.CriticalIOError)
+		}
+		f, err := os.OpenFile(cfg.Target, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, errors.WithCode(err, errors.CriticalIOError)
+		}
+		return &jsonExporter{w: f, closer: f}, nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownExporter(type=%s)", cfg.Exporter)
+	}
+}
+
+var (
+	exporterLock sync.Mutex
+	exporter     *jsonExporter
+)
+
+func init() {
+	// Tracing is disabled until an exporter is configured.
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+}
+
+// Start registers the exporter in the configuration, and starts sampling.
+// It replaces the exporter registered before.
+func Start(cfg *Config) error {
+	e, err := newExporter(cfg)
+	if err != nil {
+		return err
+	}
+
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+
+	stopInLock()
+	trace.RegisterExporter(e)
+	trace.ApplyConfig(trace.Config{DefaultSampler: cfg.sampler()})
+	exporter = e
+	return nil
+}
+
+// Stop stops sampling and unregisters the exporter.
+func Stop() {
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+
+	stopInLock()
+}
+
+func stopInLock() {
+	if exporter != nil {
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+		trace.UnregisterExporter(exporter)
+		_ = exporter.Close()
+		exporter = nil
+	}
+}
+
+// EndSpan sets status of the span for the error, and ends it.
+func EndSpan(span *trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	span.End()
+}
+
+// TraceParent returns the context of the span in W3C trace context format
+// (version-traceid-spanid-flags). It returns empty string if the span is not
+// sampled, so that the receiver doesn't need to record it.
+func TraceParent(span *trace.Span) string {
+	if span == nil {
+		return ""
+	}
+	sc := span.SpanContext()
+	if !sc.IsSampled() {
+		return ""
+	}
+	return fmt.Sprintf("00-%x-%x-%02x", sc.TraceID[:], sc.SpanID[:], byte(sc.TraceOptions))
+}
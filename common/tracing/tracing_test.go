@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+)
+
+func TestFileExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloop-tracing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "sub", "trace.json")
+	assert.NoError(t, Start(&Config{Exporter: ExporterFile, Target: target}))
+
+	ctx, parent := trace.StartSpan(context.Background(), "parent")
+	_, child := trace.StartSpan(ctx, "child")
+	child.AddAttributes(trace.Int64Attribute("index", 1))
+	EndSpan(child, errors.New("fail"))
+	EndSpan(parent, nil)
+	Stop()
+
+	// not exported after stop
+	_, span := trace.StartSpan(context.Background(), "stopped")
+	span.End()
+
+	f, err := os.Open(target)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var records []*SpanRecord
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		r := new(SpanRecord)
+		assert.NoError(t, json.Unmarshal(sc.Bytes(), r))
+		records = append(records, r)
+	}
+	assert.Len(t, records, 2)
+	assert.Equal(t, "child", records[0].Name)
+	assert.Equal(t, "parent", records[1].Name)
+	assert.Equal(t, records[1].SpanID, records[0].ParentID)
+	assert.Equal(t, records[1].TraceID, records[0].TraceID)
+	assert.EqualValues(t, 1, records[0].Attributes["index"])
+	assert.NotNil(t, records[0].Status)
+	assert.Equal(t, "fail", records[0].Status.Message)
+	assert.Nil(t, records[1].Status)
+}
+
+func TestStart_Invalid(t *testing.T) {
+	assert.Error(t, Start(&Config{Exporter: "unknown"}))
+	assert.Error(t, Start(&Config{Exporter: ExporterFile}))
+}
+
+func TestTraceParent(t *testing.T) {
+	assert.Equal(t, "", TraceParent(nil))
+
+	_, span := trace.StartSpan(context.Background(), "test",
+		trace.WithSampler(trace.NeverSample()))
+	assert.Equal(t, "", TraceParent(span))
+	span.End()
+
+	_, span = trace.StartSpan(context.Background(), "test",
+		trace.WithSampler(trace.AlwaysSample()))
+	tp := TraceParent(span)
+	span.End()
+	parts := strings.Split(tp, "-")
+	assert.Len(t, parts, 4)
+	assert.Equal(t, "00", parts[0])
+	assert.Len(t, parts[1], 32)
+	assert.Len(t, parts[2], 16)
+	assert.Equal(t, "01", parts[3])
+}
@@ -2,6 +2,7 @@ package mta
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 
@@ -259,6 +260,9 @@ type serializedMTAccumulator struct {
 func (a *Accumulator) Flush() error {
 	roots := make([][]byte, len(a.roots))
 	for i, r := range a.roots {
+		if r == nil {
+			continue
+		}
 		if err := r.Flush(); err != nil {
 			return err
 		}
@@ -329,27 +333,133 @@ func (a *Accumulator) AddData(d []byte) []Witness {
 }
 
 func (a *Accumulator) WitnessFor(idx int64) ([]Witness, error) {
-	if idx >= a.length {
+	return a.WitnessAt(idx, a.length)
+}
+
+// locate returns the height of the root having the item at idx when the
+// accumulator has length items, and the index of the item under the root.
+func locate(idx, length int64) (int, int64, bool) {
+	if idx < 0 || idx >= length {
+		return 0, 0, false
+	}
+	for h := 63; h >= 0; h-- {
+		size := int64(1) << uint(h)
+		if length&size == 0 {
+			continue
+		}
+		if idx < size {
+			return h, idx, true
+		}
+		idx -= size
+	}
+	return 0, 0, false
+}
+
+// nodeAt returns the node at the height, which has the item at idx.
+func (a *Accumulator) nodeAt(height int, idx int64) (Node, error) {
+	h, idx, ok := locate(idx, a.length)
+	if !ok || h >= len(a.roots) || h < height {
 		return nil, errors.ErrNotFound
 	}
-	offset := len(a.roots)
-	for offset > 0 {
-		inbound := int64(1) << uint(offset-1)
-		if idx < inbound {
-			witness := make([]Witness, 0, offset-1)
-			root, w, err := a.roots[offset-1].WitnessFor(offset-1, idx, witness)
-			a.roots[offset-1] = root
-			return w, err
+	node := a.roots[h]
+	for ; h > height; h-- {
+		if hn, ok := node.(*hashNode); ok {
+			n, err := hn.resolve()
+			if err != nil {
+				return nil, err
+			}
+			node = n
+		}
+		bn, ok := node.(*branchNode)
+		if !ok {
+			return nil, errors.InvalidStateError.Errorf("InvalidNode(%s)", node)
+		}
+		if bound := int64(1) << uint(h-1); idx < bound {
+			node = bn.left
+		} else {
+			node = bn.right
+			idx -= bound
 		}
-		idx -= inbound
-		offset -= 1
 	}
-	return nil, errors.ErrNotFound
+	return node, nil
 }
 
-func (a *Accumulator) Verify(ws []Witness, h []byte) error {
+// RootsAt returns hashes of roots when the accumulator had length items.
+// Hash of the root at height h is at index h, and it's nil if there is no
+// root at the height.
+func (a *Accumulator) RootsAt(length int64) ([][]byte, error) {
+	if length < 0 || length > a.length {
+		return nil, errors.ErrNotFound
+	}
+	var roots [][]byte
+	var offset int64
+	for h := 63; h >= 0; h-- {
+		size := int64(1) << uint(h)
+		if length&size == 0 {
+			continue
+		}
+		node, err := a.nodeAt(h, offset)
+		if err != nil {
+			return nil, err
+		}
+		if roots == nil {
+			roots = make([][]byte, h+1)
+		}
+		roots[h] = node.Hash()
+		offset += size
+	}
+	return roots, nil
+}
+
+// WitnessAt returns the witness of the item at idx for the roots when the
+// accumulator had length items.
+func (a *Accumulator) WitnessAt(idx, length int64) ([]Witness, error) {
+	if length > a.length {
+		return nil, errors.ErrNotFound
+	}
+	h, _, ok := locate(idx, length)
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	offset := idx &^ (int64(1)<<uint(h) - 1)
+	node, err := a.nodeAt(h, offset)
+	if err != nil {
+		return nil, err
+	}
+	return witnessOf(node, h, idx-offset)
+}
+
+// witnessOf returns the witness of the item at idx under the node at the
+// depth. Nodes resolved on the way are not kept, so it doesn't change the
+// node, and it may be used for the accumulator being shared.
+func witnessOf(node Node, depth int, idx int64) ([]Witness, error) {
+	w := make([]Witness, depth)
+	for d := depth; d > 0; d-- {
+		if hn, ok := node.(*hashNode); ok {
+			n, err := hn.resolve()
+			if err != nil {
+				return nil, err
+			}
+			node = n
+		}
+		bn, ok := node.(*branchNode)
+		if !ok {
+			return nil, errors.InvalidStateError.Errorf("InvalidNode(%s)", node)
+		}
+		if bound := int64(1) << uint(d-1); idx < bound {
+			w[d-1] = Witness{Right, bn.right.Hash()}
+			node = bn.left
+		} else {
+			w[d-1] = Witness{Left, bn.left.Hash()}
+			node = bn.right
+			idx -= bound
+		}
+	}
+	return w, nil
+}
+
+func hashWithWitness(ws []Witness, h []byte) []byte {
 	buf := make([]byte, HashSize*2)
-	height := 0
 	for _, w := range ws {
 		if w.Direction == Left {
 			copy(buf, w.HashValue)
@@ -359,8 +469,13 @@ func (a *Accumulator) Verify(ws []Witness, h []byte) error {
 			copy(buf[HashSize:], w.HashValue)
 		}
 		h = crypto.SHA3Sum256(buf)
-		height += 1
 	}
+	return h
+}
+
+func (a *Accumulator) Verify(ws []Witness, h []byte) error {
+	h = hashWithWitness(ws, h)
+	height := len(ws)
 	if height >= len(a.roots) {
 		return errors.IllegalArgumentError.New("GivenWitnessIsNewer")
 	}
@@ -395,3 +510,31 @@ func HashesToWitness(hvs [][]byte, idx int64) []Witness {
 	}
 	return w
 }
+
+// RootHash returns the hash representing the accumulator with length items
+// and the roots.
+func RootHash(length int64, roots [][]byte) []byte {
+	buf := make([]byte, 8, 8+len(roots)*HashSize)
+	binary.BigEndian.PutUint64(buf, uint64(length))
+	for h := len(roots) - 1; h >= 0; h-- {
+		buf = append(buf, roots[h]...)
+	}
+	return crypto.SHA3Sum256(buf)
+}
+
+// VerifyHashes verifies hashes of the witness for the item at idx with the
+// roots when the accumulator had length items.
+func VerifyHashes(length int64, roots [][]byte, idx int64, hvs [][]byte, h []byte) error {
+	height, idx, ok := locate(idx, length)
+	if !ok {
+		return errors.IllegalArgumentError.Errorf("InvalidIndex(idx=%d,len=%d)", idx, length)
+	}
+	if len(hvs) != height || height >= len(roots) || roots[height] == nil {
+		return errors.IllegalArgumentError.New("InvalidWitness")
+	}
+	h = hashWithWitness(HashesToWitness(hvs, idx), h)
+	if !bytes.Equal(roots[height], h) {
+		return errors.IllegalArgumentError.New("InvalidWitness")
+	}
+	return nil
+}
@@ -62,3 +62,63 @@ func TestMTAccumulator_Basic(t *testing.T) {
 
 	t.Logf("%s", a)
 }
+
+func TestMTAccumulator_RootsAt(t *testing.T) {
+	mdb := db.NewMapDB()
+	bk, _ := mdb.GetBucket("")
+
+	a := &Accumulator{
+		KeyForState: []byte("a"),
+		Bucket:      bk,
+	}
+	var hashes [][]byte
+	var rootsAt [][][]byte
+	for i := 0; i < 13; i++ {
+		roots, err := a.RootsAt(a.Len())
+		assert.NoError(t, err)
+		rootsAt = append(rootsAt, roots)
+		h := crypto.SHA3Sum256([]byte{byte(i)})
+		hashes = append(hashes, h)
+		a.AddHash(h)
+	}
+	assert.NoError(t, a.Flush())
+
+	a = &Accumulator{
+		KeyForState: []byte("a"),
+		Bucket:      bk,
+	}
+	assert.NoError(t, a.Recover())
+
+	for length := int64(1); length < int64(len(rootsAt)); length++ {
+		roots, err := a.RootsAt(length)
+		assert.NoError(t, err)
+		assert.Equal(t, rootsAt[length], roots)
+		for idx := int64(0); idx < length; idx++ {
+			w, err := a.WitnessAt(idx, length)
+			assert.NoError(t, err)
+			hvs := WitnessesToHashes(w)
+			assert.NoError(t, VerifyHashes(length, roots, idx, hvs, hashes[idx]))
+			assert.Error(t, VerifyHashes(length, roots, idx, hvs, crypto.SHA3Sum256([]byte("x"))))
+		}
+		_, err = a.WitnessAt(length, length)
+		assert.Error(t, err)
+	}
+	// nodes resolved for witnesses aren't kept in the accumulator
+	for idx := int64(0); idx < a.Len(); idx++ {
+		_, err := a.WitnessAt(idx, a.Len())
+		assert.NoError(t, err)
+	}
+	for _, r := range a.roots {
+		if r != nil {
+			assert.IsType(t, &hashNode{}, r)
+		}
+	}
+	for idx, h := range hashes {
+		w, err := a.WitnessFor(int64(idx))
+		assert.NoError(t, err)
+		assert.NoError(t, a.Verify(w, h))
+	}
+	_, err := a.RootsAt(a.Len() + 1)
+	assert.Error(t, err)
+	assert.NotEqual(t, RootHash(4, rootsAt[4]), RootHash(5, rootsAt[5]))
+}
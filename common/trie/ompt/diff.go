@@ -0,0 +1,205 @@
+package ompt
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common/trie"
+)
+
+// diffSide is a cursor over the nodes of a trie in the order of keys.
+// It keeps the value to be compared and the subtrees to be visited.
+type diffSide struct {
+	m     *mpt
+	stack []iteratorItem
+	key   string
+	value trie.Object
+}
+
+func newDiffSide(m *mpt) *diffSide {
+	s := &diffSide{m: m}
+	m.mutex.Lock()
+	root := m.root
+	m.mutex.Unlock()
+	if root != nil {
+		s.stack = append(s.stack, iteratorItem{k: "", n: root})
+	}
+	return s
+}
+
+func (s *diffSide) hasValue() bool {
+	return s.value != nil
+}
+
+func (s *diffSide) hasNode() bool {
+	return len(s.stack) > 0
+}
+
+func (s *diffSide) top() *iteratorItem {
+	return &s.stack[len(s.stack)-1]
+}
+
+func (s *diffSide) pop() iteratorItem {
+	l := len(s.stack)
+	ii := s.stack[l-1]
+	s.stack = s.stack[0 : l-1]
+	return ii
+}
+
+func (s *diffSide) push(k string, n node) (node, error) {
+	s.stack = append(s.stack, iteratorItem{k: k, n: n})
+	return n, nil
+}
+
+// expand visits the node on the top, and pushes its children. If the node
+// has a value, then it becomes the current value.
+func (s *diffSide) expand() error {
+	ii := s.pop()
+	key, value, err := ii.n.traverse(s.m, ii.k, s.push)
+	if err != nil {
+		return err
+	}
+	if value != nil {
+		s.key, s.value = key, value
+	}
+	return nil
+}
+
+func (s *diffSide) consume() (string, trie.Object) {
+	key, value := s.key, s.value
+	s.key, s.value = "", nil
+	return key, value
+}
+
+func isSameNode(n1, n2 node) bool {
+	if n1 == n2 {
+		return true
+	}
+	return bytes.Equal(n1.getLink(true), n2.getLink(true))
+}
+
+type objectDifferenceHandler func(op int, key []byte, exp, real trie.Object)
+
+// compare calls handler for the keys having different values in the order
+// of keys. Subtrees with same hash at same position are skipped, so the cost
+// is proportional to the amount of changes instead of the size of tries.
+// op is -1 for the key only in exp, 1 for the key only in real, and 0 for
+// the key having different values.
+func compare(exp, real *mpt, handler objectDifferenceHandler) error {
+	e, r := newDiffSide(exp), newDiffSide(real)
+	for {
+		switch {
+		case e.hasValue() && r.hasValue():
+			switch {
+			case e.key < r.key:
+				k, v := e.consume()
+				handler(-1, keysToBytes(k), v, nil)
+			case e.key > r.key:
+				k, v := r.consume()
+				handler(1, keysToBytes(k), nil, v)
+			default:
+				k, ve := e.consume()
+				_, vr := r.consume()
+				if !bytes.Equal(ve.Bytes(), vr.Bytes()) {
+					handler(0, keysToBytes(k), ve, vr)
+				}
+			}
+		case e.hasValue():
+			if !r.hasNode() || e.key < r.top().k {
+				k, v := e.consume()
+				handler(-1, keysToBytes(k), v, nil)
+			} else if err := r.expand(); err != nil {
+				return err
+			}
+		case r.hasValue():
+			if !e.hasNode() || r.key < e.top().k {
+				k, v := r.consume()
+				handler(1, keysToBytes(k), nil, v)
+			} else if err := e.expand(); err != nil {
+				return err
+			}
+		case e.hasNode() && r.hasNode():
+			te, tr := e.top(), r.top()
+			switch {
+			case te.k == tr.k && isSameNode(te.n, tr.n):
+				e.pop()
+				r.pop()
+			case te.k <= tr.k:
+				if err := e.expand(); err != nil {
+					return err
+				}
+			default:
+				if err := r.expand(); err != nil {
+					return err
+				}
+			}
+		case e.hasNode():
+			if err := e.expand(); err != nil {
+				return err
+			}
+		case r.hasNode():
+			if err := r.expand(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func mptOf(o interface{}) *mpt {
+	switch m := o.(type) {
+	case *mpt:
+		return m
+	case *mptForBytes:
+		return m.mpt
+	default:
+		return nil
+	}
+}
+
+// CompareImmutableForObject calls handler for the keys having different
+// values. It returns false if the tries are not made by this package.
+func CompareImmutableForObject(exp, real trie.ImmutableForObject, handler func(op int, key []byte, exp, real trie.Object)) (bool, error) {
+	m1, m2 := mptOf(exp), mptOf(real)
+	if m1 == nil || m2 == nil {
+		return false, nil
+	}
+	return true, compare(m1, m2, handler)
+}
+
+// CompareImmutable calls handler for the keys having different values.
+// It returns false if the tries are not made by this package.
+func CompareImmutable(exp, real trie.Immutable, handler func(op int, key, exp, real []byte)) (bool, error) {
+	m1, m2 := mptOf(exp), mptOf(real)
+	if m1 == nil || m2 == nil {
+		return false, nil
+	}
+	return true, compare(m1, m2, func(op int, key []byte, exp, real trie.Object) {
+		handler(op, key, bytesOf(exp), bytesOf(real))
+	})
+}
+
+// SeekImmutableForObject returns an iterator for the keys not less than
+// start. It returns nil if the trie is not made by this package.
+func SeekImmutableForObject(t trie.ImmutableForObject, start []byte) trie.IteratorForObject {
+	if m, ok := t.(*mpt); ok {
+		return m.Seek(start)
+	}
+	return nil
+}
+
+// SeekImmutable returns an iterator for the keys not less than start.
+// It returns nil if the trie is not made by this package.
+func SeekImmutable(t trie.Immutable, start []byte) trie.Iterator {
+	if m, ok := t.(*mptForBytes); ok {
+		return m.Seek(start)
+	}
+	return nil
+}
+
+func bytesOf(o trie.Object) []byte {
+	if o == nil {
+		return nil
+	}
+	return o.Bytes()
+}
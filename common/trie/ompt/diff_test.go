@@ -0,0 +1,163 @@
+package ompt
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/trie"
+)
+
+type testDiff struct {
+	op   int
+	key  string
+	exp  string
+	real string
+}
+
+func (d testDiff) String() string {
+	return fmt.Sprintf("{%d %x %x %x}", d.op, d.key, d.exp, d.real)
+}
+
+func expectedDiffs(exp, real map[string]string) []testDiff {
+	var diffs []testDiff
+	for k, ve := range exp {
+		if vr, ok := real[k]; !ok {
+			diffs = append(diffs, testDiff{-1, k, ve, ""})
+		} else if ve != vr {
+			diffs = append(diffs, testDiff{0, k, ve, vr})
+		}
+	}
+	for k, vr := range real {
+		if _, ok := exp[k]; !ok {
+			diffs = append(diffs, testDiff{1, k, "", vr})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].key < diffs[j].key
+	})
+	return diffs
+}
+
+func TestCompareImmutable(t *testing.T) {
+	database := db.NewMapDB()
+	r := rand.New(rand.NewSource(1))
+	randomKey := func() string {
+		// short keys to make common prefixes and values in branches
+		k := make([]byte, 1+r.Intn(3))
+		r.Read(k)
+		return string(k)
+	}
+
+	values := make(map[string]string)
+	t1 := NewMutable(database, nil)
+	for i := 0; i < 500; i++ {
+		k := randomKey()
+		v := fmt.Sprintf("value%d", i)
+		values[k] = v
+		t1.Set([]byte(k), []byte(v))
+	}
+	s1 := t1.GetSnapshot()
+	s1.Flush()
+
+	for _, changes := range []int{0, 1, 10, 100, 1000} {
+		t.Run(fmt.Sprint(changes), func(t *testing.T) {
+			values2 := make(map[string]string)
+			for k, v := range values {
+				values2[k] = v
+			}
+			t2 := NewMutableFromImmutable(s1)
+			for i := 0; i < changes; i++ {
+				k := randomKey()
+				if r.Intn(3) == 0 {
+					delete(values2, k)
+					t2.Delete([]byte(k))
+				} else {
+					v := fmt.Sprintf("changed%d", i)
+					values2[k] = v
+					t2.Set([]byte(k), []byte(v))
+				}
+			}
+			s2 := t2.GetSnapshot()
+
+			var diffs []testDiff
+			ok, err := CompareImmutable(s1, s2, func(op int, key, exp, real []byte) {
+				diffs = append(diffs, testDiff{op, string(key), string(exp), string(real)})
+			})
+			if !ok || err != nil {
+				t.Fatalf("Fail to compare ok=%v err=%+v", ok, err)
+			}
+			expected := expectedDiffs(values, values2)
+			if fmt.Sprint(diffs) != fmt.Sprint(expected) {
+				t.Errorf("Different diffs\nexp=%v\nreal=%v", expected, diffs)
+			}
+
+			// reverse direction with the trie from the database
+			s3 := NewImmutable(database, s1.Hash())
+			diffs = diffs[:0]
+			CompareImmutable(s2, s3, func(op int, key, exp, real []byte) {
+				diffs = append(diffs, testDiff{op, string(key), string(exp), string(real)})
+			})
+			expected = expectedDiffs(values2, values)
+			if fmt.Sprint(diffs) != fmt.Sprint(expected) {
+				t.Errorf("Different reverse diffs\nexp=%v\nreal=%v", expected, diffs)
+			}
+		})
+	}
+}
+
+func TestCompareImmutable_Empty(t *testing.T) {
+	database := db.NewMapDB()
+	empty := NewImmutableForObject(database, nil, reflect.TypeOf(bytesObject(nil)))
+	t1 := NewMutableForObject(database, nil, reflect.TypeOf(bytesObject(nil)))
+	t1.Set([]byte("a"), bytesObject("1"))
+	t1.Set([]byte("ab"), bytesObject("2"))
+
+	var keys [][]byte
+	handler := func(op int, key []byte, exp, real trie.Object) {
+		keys = append(keys, key)
+	}
+	CompareImmutableForObject(empty, t1.GetSnapshot(), handler)
+	CompareImmutableForObject(t1.GetSnapshot(), empty, handler)
+	CompareImmutableForObject(empty, empty, handler)
+	if len(keys) != 4 || !bytes.Equal(keys[0], []byte("a")) || !bytes.Equal(keys[1], []byte("ab")) {
+		t.Errorf("Unexpected keys=%q", keys)
+	}
+}
+
+func TestSeekImmutable(t *testing.T) {
+	database := db.NewMapDB()
+	r := rand.New(rand.NewSource(2))
+	t1 := NewMutable(database, nil)
+	var keys []string
+	for i := 0; i < 300; i++ {
+		k := make([]byte, 1+r.Intn(3))
+		r.Read(k)
+		if old, _ := t1.Set(k, k); old == nil {
+			keys = append(keys, string(k))
+		}
+	}
+	sort.Strings(keys)
+	s1 := t1.GetSnapshot()
+
+	for _, start := range []string{"", "\x00", "\x80", "\x80\x01\x02\x03", "\xff\xff\xff\xff", keys[10], keys[100]} {
+		idx := sort.SearchStrings(keys, start)
+		for itr := SeekImmutable(s1, []byte(start)); itr.Has(); itr.Next() {
+			_, k, err := itr.Get()
+			if err != nil {
+				t.Fatalf("Fail to iterate err=%+v", err)
+			}
+			if idx >= len(keys) || string(k) != keys[idx] {
+				t.Fatalf("Unexpected key start=%x idx=%d key=%x", start, idx, k)
+			}
+			idx++
+		}
+		if idx != len(keys) {
+			t.Errorf("Missing keys start=%x idx=%d", start, idx)
+		}
+	}
+}
@@ -312,6 +312,7 @@ type iterator struct {
 	value  trie.Object
 	error  error
 	prefix string
+	start  string
 }
 
 func (i *iterator) Get() (trie.Object, []byte, error) {
@@ -342,8 +343,22 @@ func (i *iterator) filterItem(k string, n node) (node, error) {
 	}
 }
 
+// seekItem schedules the node only if it may have keys not less than start.
+func (i *iterator) seekItem(k string, n node) (node, error) {
+	if k >= i.start || strings.HasPrefix(i.start, k) {
+		return i.appendItem(k, n)
+	}
+	return n, nil
+}
+
 func (i *iterator) traverse(ii iteratorItem) (string, trie.Object, error) {
-	if len(i.prefix) > 0 {
+	if len(i.start) > 0 {
+		key, value, err := ii.n.traverse(i.m, ii.k, i.seekItem)
+		if err != nil || key < i.start {
+			return "", nil, err
+		}
+		return key, value, err
+	} else if len(i.prefix) > 0 {
 		if i.checkPrefix(ii.k, false) {
 			return ii.n.traverse(i.m, ii.k, i.appendItem)
 		} else {
@@ -422,6 +437,33 @@ func (m *mpt) Filter(prefix []byte) trie.IteratorForObject {
 	return i
 }
 
+// Seek returns an iterator for the keys not less than start.
+func (m *mpt) Seek(start []byte) trie.IteratorForObject {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	root := m.root
+	if root != nil {
+		if n, err := root.realize(m); err == nil {
+			root = n
+			m.root = n
+		}
+	}
+	if root == nil {
+		return &iterator{
+			m:     m,
+			stack: []iteratorItem{},
+		}
+	}
+	i := &iterator{
+		m:     m,
+		stack: []iteratorItem{{k: "", n: root}},
+		start: string(bytesToNibs(start)),
+	}
+	i.Next()
+	return i
+}
+
 func (m *mpt) GetProof(k []byte) [][]byte {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
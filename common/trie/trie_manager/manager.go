@@ -6,6 +6,7 @@ import (
 
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/ompt"
 )
 
 type trieManager struct {
@@ -35,6 +36,9 @@ func New(database db.Database) trie.Manager {
 type BytesDifferenceHandler func(diff int, key, expect, real []byte)
 
 func CompareImmutable(exp, real trie.Immutable, handler BytesDifferenceHandler) error {
+	if ok, err := ompt.CompareImmutable(exp, real, handler); ok {
+		return err
+	}
 	for ie, ir := exp.Iterator(), real.Iterator(); ie.Has() || ir.Has(); {
 		ve, ke, err := ie.Get()
 		if err != nil {
@@ -73,6 +77,9 @@ func CompareImmutable(exp, real trie.Immutable, handler BytesDifferenceHandler)
 type ObjectDifferenceHandler func(op int, key []byte, expect, real trie.Object)
 
 func CompareImmutableForObject(exp, real trie.ImmutableForObject, handler ObjectDifferenceHandler) error {
+	if ok, err := ompt.CompareImmutableForObject(exp, real, handler); ok {
+		return err
+	}
 	for ie, ir := exp.Iterator(), real.Iterator(); ie.Has() || ir.Has(); {
 		ve, ke, err := ie.Get()
 		if err != nil {
@@ -272,7 +272,10 @@ func (cs *consensus) _resetForNewRound(round int32) {
 	cs.currentBlockParts.Zerofy()
 	cs.round = round
 	cs.hvs.removeLowerRoundExcept(cs.round-1, cs.lockedRound)
-	cs.log.Infof("enter round Height:%d Round:%d\n", cs.height, cs.round)
+	cs.log.WithFields(log.Fields{
+		log.FieldKeyHeight: cs.height,
+		log.FieldKeyRound:  cs.round,
+	}).Info("enter round")
 	cs.metric.OnRound(cs.round)
 	if cs.cancelBlockRequest != nil {
 		cs.cancelBlockRequest.Cancel()
@@ -367,11 +370,15 @@ func (cs *consensus) OnFailure(err error, pi module.ProtocolInfo, b []byte) {
 }
 
 func (cs *consensus) OnJoin(id module.PeerID) {
-	cs.log.Debugf("OnJoin(peer:%v)\n", common.HexPre(id.Bytes()))
+	cs.log.WithFields(log.Fields{
+		log.FieldKeyPeer: common.HexPre(id.Bytes()),
+	}).Debug("OnJoin")
 }
 
 func (cs *consensus) OnLeave(id module.PeerID) {
-	cs.log.Debugf("OnLeave(peer:%v)\n", common.HexPre(id.Bytes()))
+	cs.log.WithFields(log.Fields{
+		log.FieldKeyPeer: common.HexPre(id.Bytes()),
+	}).Debug("OnLeave")
 }
 
 func (cs *consensus) ReceiveProposalMessage(msg *ProposalMessage, unicast bool) error {
@@ -1743,6 +1750,9 @@ func (cs *consensus) GetStatus() *module.ConsensusStatus {
 	if cs.validators != nil {
 		res.Proposer = cs.isProposer()
 	}
+	if cs.syncer != nil {
+		res.FastSync = cs.syncer.FastSyncStatus()
+	}
 	return res
 }
 
@@ -1879,7 +1889,9 @@ func (cs *consensus) Step() step {
 
 func (cs *consensus) ReceiveBlock(br fastsync.BlockResult) {
 	blk := br.Block()
-	cs.log.Debugf("ReceiveBlock Height:%d\n", blk.Height())
+	cs.log.WithFields(log.Fields{
+		log.FieldKeyHeight: blk.Height(),
+	}).Debug("ReceiveBlock")
 
 	if cs.height < blk.Height() {
 		cs.prefetchItems = append(cs.prefetchItems, br)
@@ -1901,7 +1913,9 @@ func (cs *consensus) processBlock(br fastsync.BlockResult) {
 	}
 
 	blk := br.Block()
-	cs.log.Debugf("processBlock Height:%d\n", blk.Height())
+	cs.log.WithFields(log.Fields{
+		log.FieldKeyHeight: blk.Height(),
+	}).Debug("processBlock")
 
 	cvl := NewCommitVoteSetFromBytes(br.Votes())
 	if cvl == nil {
@@ -17,7 +17,11 @@
 package consensus_test
 
 import (
+	"bytes"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -25,6 +29,7 @@ import (
 	"github.com/icon-project/goloop/consensus/fastsync"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/test"
+	"github.com/icon-project/goloop/test/clock"
 )
 
 func TestConsensus_FastSyncServer(t *testing.T) {
@@ -226,3 +231,123 @@ func TestConsensus_BasicConsensus2(t *testing.T) {
 	assert.EqualValues(t, 3, blk.Height())
 	assert.EqualValues(t, 4, f.CS.GetStatus().Height)
 }
+
+// waitTimeout is only for failing the test instead of blocking it forever.
+const waitTimeout = 30 * time.Second
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	select {
+	case <-ch:
+	case <-time.After(waitTimeout):
+		assert.FailNow(t, "timeout", "waiting for %s", what)
+	}
+}
+
+func waitForBlock(t *testing.T, n *test.Node, height int64) module.Block {
+	chn, err := n.BM.WaitForBlock(height)
+	assert.NoError(t, err)
+	select {
+	case blk := <-chn:
+		return blk
+	case <-time.After(waitTimeout):
+		assert.FailNow(t, "timeout", "height=%d", height)
+		return nil
+	}
+}
+
+func TestConsensus_PartitionAndByzantine(t *testing.T) {
+	f := test.NewFixture(t,
+		test.AddDefaultNode(false),
+		test.AddValidatorNodes(4),
+	)
+	defer f.Close()
+
+	// the last validator is replaced with a byzantine node, which sends
+	// conflicting votes for the block voted by the others.
+	honest := f.Nodes[:3]
+	byz := test.NewByzantineNode(t, f.Nodes[3].Chain.Wallet())
+	var mtx sync.Mutex
+	voted := make(map[string]bool)
+	byz.SetScript(func(b *test.ByzantineNode, pk *test.Packet) {
+		vm, ok := test.DecodeVote(pk)
+		if !ok || vm.BlockID == nil {
+			return
+		}
+		key := fmt.Sprint(vm.Height, vm.Round, vm.Type)
+		mtx.Lock()
+		if voted[key] {
+			mtx.Unlock()
+			return
+		}
+		voted[key] = true
+		mtx.Unlock()
+		peers := b.Peers()
+		half := len(peers) / 2
+		b.SendConflictingVotes(vm.Type, vm.Height, vm.Round, vm.Timestamp,
+			vm.BlockID, vm.BlockPartSetID, peers[:half],
+			nil, nil, peers[half:],
+		)
+	})
+
+	cl := &clock.Clock{}
+	fn := test.NewFaultNetwork(cl, 1)
+	fn.Interconnect(honest)
+	for _, n := range honest {
+		fn.Connect(n.NM, byz)
+	}
+
+	// votes of height 1 sent by the honest validators in their groups show
+	// that they are running consensus for the height in the partition.
+	voters := make(map[string]bool)
+	allVoted := make(chan struct{})
+	for _, n := range honest {
+		voters[string(n.NM.ID().Bytes())] = true
+	}
+	fn.AddFilter(func(from, to module.PeerID, pk *test.Packet) bool {
+		if vm, ok := test.DecodeVote(pk); ok && vm.Height == 1 {
+			mtx.Lock()
+			defer mtx.Unlock()
+			if voters[string(from.Bytes())] {
+				delete(voters, string(from.Bytes()))
+				if len(voters) == 0 {
+					close(allVoted)
+				}
+			}
+		}
+		return false
+	})
+
+	// no group has enough honest validators for consensus, and the
+	// partition is healed when the clock of the network passes.
+	const partitionTime = time.Minute
+	fn.Partition(
+		[]module.PeerID{honest[0].NM.ID(), honest[1].NM.ID()},
+		[]module.PeerID{honest[2].NM.ID(), byz.ID()},
+	)
+	fn.At(partitionTime, fn.Heal)
+
+	chs := make([]<-chan module.Block, len(honest))
+	for i, n := range honest {
+		ch, err := n.BM.WaitForBlock(1)
+		assert.NoError(t, err)
+		chs[i] = ch
+		assert.NoError(t, n.CS.Start())
+	}
+	waitFor(t, allVoted, "votes of honest validators")
+	for i, n := range honest {
+		select {
+		case blk := <-chs[i]:
+			assert.Failf(t, "finalized in partition", "height=%d", blk.Height())
+		default:
+		}
+		assert.EqualValues(t, 1, n.CS.GetStatus().Height)
+	}
+
+	cl.PassTime(partitionTime)
+	const target = 3
+	blk := waitForBlock(t, honest[0], target)
+	for _, n := range honest[1:] {
+		b := waitForBlock(t, n, target)
+		assert.True(t, bytes.Equal(blk.ID(), b.ID()))
+	}
+}
@@ -123,10 +123,11 @@ type peer struct {
 }
 
 type fetchRequest struct {
-	cl        *client
-	heightSet *heightSet
-	cb        FetchCallback
-	maxActive int
+	cl         *client
+	heightSet  *heightSet
+	dataOption int32
+	cb         FetchCallback
+	maxActive  int
 
 	validPeers     []*peer
 	nActivePeers   int
@@ -147,6 +148,7 @@ func newClient(nm module.NetworkManager, ph module.ProtocolHandler,
 func (cl *client) fetchBlocks(
 	begin int64,
 	end int64,
+	dataOption int32,
 	cb FetchCallback,
 ) (*fetchRequest, error) {
 	cl.Lock()
@@ -163,6 +165,7 @@ func (cl *client) fetchBlocks(
 	fr := &fetchRequest{}
 	fr.cl = cl
 	fr.heightSet = newHeightSet(begin, end)
+	fr.dataOption = dataOption
 	fr.cb = cb
 	fr.maxActive = configMaxActive
 
@@ -424,6 +427,7 @@ func (f *fetcher) _doSend() {
 	var msg BlockRequest
 	msg.RequestID = f.requestID
 	msg.Height = f.height
+	msg.DataOption = f.fr.dataOption
 	bs := codec.MustMarshalToBytes(&msg)
 	fidPre := common.HexPre(f.id.Bytes())
 	f.cl.log.Debugf("Request RequestID:%d Height:%d peer:%s\n", f.requestID, f.height, fidPre)
@@ -36,6 +36,15 @@ type Manager interface {
 		end int64,
 		cb FetchCallback,
 	) (canceler func() bool, err error)
+
+	// FetchHeaders fetches blocks like FetchBlocks, but requests
+	// BlockHeaderData for the blocks. NewBlockDataFromReader of the block
+	// manager shall read BlockHeaderData.
+	FetchHeaders(
+		begin int64,
+		end int64,
+		cb FetchCallback,
+	) (canceler func() bool, err error)
 	Term()
 }
 
@@ -81,11 +90,28 @@ func (m *manager) FetchBlocks(
 	begin int64,
 	end int64,
 	cb FetchCallback,
+) (canceler func() bool, err error) {
+	return m.fetch(begin, end, 0, cb)
+}
+
+func (m *manager) FetchHeaders(
+	begin int64,
+	end int64,
+	cb FetchCallback,
+) (canceler func() bool, err error) {
+	return m.fetch(begin, end, DataOptionHeader, cb)
+}
+
+func (m *manager) fetch(
+	begin int64,
+	end int64,
+	dataOption int32,
+	cb FetchCallback,
 ) (canceler func() bool, err error) {
 	if end < 0 {
 		end = math.MaxInt64
 	}
-	fr, err := m.client.fetchBlocks(begin, end, cb)
+	fr, err := m.client.fetchBlocks(begin, end, dataOption, cb)
 	if err != nil {
 		return nil, err
 	}
@@ -33,9 +33,7 @@ type BlockRequestV2 struct {
 	ProofOption int32
 }
 
-type BlockRequest = BlockRequestV2
-
-func (m *BlockRequest) RLPEncodeSelf(e codec.Encoder) error {
+func (m *BlockRequestV2) RLPEncodeSelf(e codec.Encoder) error {
 	var err error
 	if m.ProofOption == 0 {
 		err = e.EncodeListOf(m.RequestID, m.Height)
@@ -45,7 +43,7 @@ func (m *BlockRequest) RLPEncodeSelf(e codec.Encoder) error {
 	return err
 }
 
-func (m *BlockRequest) RLPDecodeSelf(d codec.Decoder) error {
+func (m *BlockRequestV2) RLPDecodeSelf(d codec.Decoder) error {
 	d2, err := d.DecodeList()
 	if err != nil {
 		return err
@@ -61,6 +59,51 @@ func (m *BlockRequest) RLPDecodeSelf(d codec.Decoder) error {
 	return nil
 }
 
+// DataOptionHeader requests BlockHeaderData instead of the whole block.
+const DataOptionHeader int32 = 1
+
+type BlockRequestV3 struct {
+	RequestID   uint32
+	Height      int64
+	ProofOption int32
+	DataOption  int32
+}
+
+type BlockRequest = BlockRequestV3
+
+func (m *BlockRequest) RLPEncodeSelf(e codec.Encoder) error {
+	var err error
+	if m.DataOption != 0 {
+		err = e.EncodeListOf(m.RequestID, m.Height, m.ProofOption, m.DataOption)
+	} else if m.ProofOption != 0 {
+		err = e.EncodeListOf(m.RequestID, m.Height, m.ProofOption)
+	} else {
+		err = e.EncodeListOf(m.RequestID, m.Height)
+	}
+	return err
+}
+
+func (m *BlockRequest) RLPDecodeSelf(d codec.Decoder) error {
+	d2, err := d.DecodeList()
+	if err != nil {
+		return err
+	}
+	cnt, err := d2.DecodeMulti(
+		&m.RequestID, &m.Height, &m.ProofOption, &m.DataOption,
+	)
+	if cnt >= 2 && err == io.EOF {
+		if cnt < 3 {
+			m.ProofOption = 0
+		}
+		m.DataOption = 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 type BlockMetadata struct {
 	RequestID   uint32
 	BlockLength int32 // -1 if fails
@@ -74,3 +117,12 @@ type BlockData struct {
 
 type CancelAllBlockRequests struct {
 }
+
+// BlockHeaderData is sent as the data of the block for the request with
+// DataOptionHeader. It has the votes in the header and the next validators
+// of the block to verify following blocks without transactions.
+type BlockHeaderData struct {
+	Header         []byte
+	Votes          []byte
+	NextValidators []byte
+}
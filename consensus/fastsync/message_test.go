@@ -94,3 +94,55 @@ func TestBlockRequest_SendV2ReceiveV2(t *testing.T) {
 		msgV2Another,
 	)
 }
+
+func TestBlockRequest_SendV3ReceiveV2(t *testing.T) {
+	msgV3 := BlockRequestV3{
+		RequestID:   1,
+		Height:      1,
+		ProofOption: 1,
+		DataOption:  DataOptionHeader,
+	}
+	bsV3 := codec.MustMarshalToBytes(&msgV3)
+	var msgV2 BlockRequestV2
+	codec.MustUnmarshalFromBytes(bsV3, &msgV2)
+	assert.Equal(t,
+		BlockRequestV2{
+			RequestID:   1,
+			Height:      1,
+			ProofOption: 1,
+		},
+		msgV2,
+	)
+}
+
+func TestBlockRequest_SendV2ReceiveV3(t *testing.T) {
+	msgV2 := BlockRequestV2{
+		RequestID:   1,
+		Height:      1,
+		ProofOption: 1,
+	}
+	bsV2 := codec.MustMarshalToBytes(&msgV2)
+	var msgV3 BlockRequestV3
+	codec.MustUnmarshalFromBytes(bsV2, &msgV3)
+	assert.Equal(t,
+		BlockRequestV3{
+			RequestID:   1,
+			Height:      1,
+			ProofOption: 1,
+		},
+		msgV3,
+	)
+	assert.Equal(t, bsV2, codec.MustMarshalToBytes(&msgV3))
+}
+
+func TestBlockRequest_SendV3ReceiveV3(t *testing.T) {
+	msgV3 := BlockRequestV3{
+		RequestID:  1,
+		Height:     1,
+		DataOption: DataOptionHeader,
+	}
+	bsV3 := codec.MustMarshalToBytes(&msgV3)
+	var msgV3Another BlockRequestV3
+	codec.MustUnmarshalFromBytes(bsV3, &msgV3Another)
+	assert.Equal(t, msgV3, msgV3Another)
+}
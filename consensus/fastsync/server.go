@@ -197,6 +197,39 @@ func (h *sconHandler) cancelAllRequests() {
 	h.nextItems = nil
 }
 
+func (h *sconHandler) setNoBlock(requestID uint32) {
+	h.nextMsgPI = ProtoBlockMetadata
+	h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
+		RequestID:   requestID,
+		BlockLength: -1,
+		Proof:       nil,
+	})
+	h.buf = nil
+}
+
+func marshalBlockData(w io.Writer, blk module.Block, opt int32) error {
+	if opt&DataOptionHeader == 0 {
+		if err := blk.MarshalHeader(w); err != nil {
+			return err
+		}
+		return blk.MarshalBody(w)
+	}
+	header := bytes.NewBuffer(nil)
+	if err := blk.MarshalHeader(header); err != nil {
+		return err
+	}
+	data := &BlockHeaderData{
+		Header: header.Bytes(),
+	}
+	if votes := blk.Votes(); votes != nil {
+		data.Votes = votes.Bytes()
+	}
+	if nvs := blk.NextValidators(); nvs != nil {
+		data.NextValidators = nvs.Bytes()
+	}
+	return codec.Marshal(w, data)
+}
+
 func (h *sconHandler) updateCurrentTask() {
 	if len(h.nextItems) == 0 {
 		return
@@ -207,29 +240,21 @@ func (h *sconHandler) updateCurrentTask() {
 	h.requestID = ni.RequestID
 	blk, err := h.bm.GetBlockByHeight(ni.Height)
 	if err != nil {
-		h.nextMsgPI = ProtoBlockMetadata
-		h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
-			RequestID:   ni.RequestID,
-			BlockLength: -1,
-			Proof:       nil,
-		})
-		h.buf = nil
+		h.setNoBlock(ni.RequestID)
 		return
 	}
 	proof, err := h.bpp.GetBlockProof(ni.Height, ni.ProofOption)
 	if err != nil {
-		h.nextMsgPI = ProtoBlockMetadata
-		h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
-			RequestID:   ni.RequestID,
-			BlockLength: -1,
-			Proof:       nil,
-		})
-		h.buf = nil
+		h.setNoBlock(ni.RequestID)
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := marshalBlockData(buf, blk, ni.DataOption); err != nil {
+		h.log.Debugf("Fail to marshal block height=%d err=%+v", ni.Height, err)
+		h.setNoBlock(ni.RequestID)
 		return
 	}
-	h.buf = bytes.NewBuffer(nil)
-	h.log.Must(blk.MarshalHeader(h.buf))
-	h.log.Must(blk.MarshalBody(h.buf))
+	h.buf = buf
 	h.nextMsgPI = ProtoBlockMetadata
 	h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
 		RequestID:   ni.RequestID,
@@ -1,6 +1,7 @@
 package fastsync
 
 import (
+	"bytes"
 	"crypto/rand"
 	"testing"
 
@@ -83,6 +84,37 @@ func TestServer_Success(t *testing.T) {
 	assert.Equal(t, data, s.rawBlocks[0])
 }
 
+func TestServer_Header(t *testing.T) {
+	s := newServerTestSetUp(t)
+	bs := codec.MustMarshalToBytes(&BlockRequest{
+		RequestID:  1,
+		Height:     1,
+		DataOption: DataOptionHeader,
+	})
+	err := s.ph2.Unicast(ProtoBlockRequest, bs, s.nm.ID)
+	assert.Nil(t, err)
+
+	header := bytes.NewBuffer(nil)
+	assert.NoError(t, s.blks[1].MarshalHeader(header))
+	expected := codec.MustMarshalToBytes(&BlockHeaderData{
+		Header: header.Bytes(),
+		Votes:  s.blks[0].ID(),
+	})
+
+	ev := <-s.r2.ch
+	md := &BlockMetadata{1, int32(len(expected)), s.votes[2]}
+	s.assertEqualReceiveEvent(ProtoBlockMetadata, md, s.nm.ID, ev)
+	ev = <-s.r2.ch
+	var msg BlockData
+	codec.MustUnmarshalFromBytes(ev.(tReceiveEvent).b, &msg)
+	assert.Equal(t, expected, msg.Data)
+
+	var data BlockHeaderData
+	codec.MustUnmarshalFromBytes(msg.Data, &data)
+	assert.Equal(t, header.Bytes(), data.Header)
+	assert.Nil(t, data.NextValidators)
+}
+
 func TestServer_Fail(t *testing.T) {
 }
 
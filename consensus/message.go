@@ -100,6 +100,21 @@ func NewProposalMessage() *ProposalMessage {
 	return msg
 }
 
+// NewSignedProposalMessage returns a proposal message for the part set
+// signed by the wallet.
+func NewSignedProposalMessage(
+	w module.Wallet,
+	height int64, round int32, partSetID *PartSetID, polRound int32,
+) *ProposalMessage {
+	msg := NewProposalMessage()
+	msg.Height = height
+	msg.Round = round
+	msg.BlockPartSetID = partSetID
+	msg.POLRound = polRound
+	_ = msg.sign(w)
+	return msg
+}
+
 func (msg *ProposalMessage) Verify() error {
 	if err := msg._HR.verify(); err != nil {
 		return err
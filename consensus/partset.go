@@ -216,6 +216,14 @@ func newPartSetBuffer(sz int) PartSetBuffer {
 	return &partSetBuffer{ps: new(partSet), size: sz}
 }
 
+// NewPartSetFromBytes returns a complete part set for the block data
+// (header and body) split in the same way as the proposer does.
+func NewPartSetFromBytes(bs []byte) PartSet {
+	psb := newPartSetBuffer(configBlockPartSize)
+	_, _ = psb.Write(bs)
+	return psb.PartSet()
+}
+
 func NewPartSetFromID(h *PartSetID) PartSet {
 	return &partSet{
 		parts: make([]*part, h.Count),
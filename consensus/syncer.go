@@ -41,6 +41,10 @@ type Syncer interface {
 	Start() error
 	Stop()
 	OnEngineStepChange()
+
+	// FastSyncStatus returns status of block fetching. It returns nil if
+	// it's not fetching blocks.
+	FastSyncStatus() *module.FastSyncStatus
 }
 
 var SyncerProtocols = []module.ProtocolInfo{
@@ -138,6 +142,10 @@ func (p *peer) doSync() (module.ProtocolInfo, Message) {
 		p.log.Tracef("higher peer height %v > %v\n", p.Height, e.Height())
 		if p.Height > e.Height()+configFastSyncThreshold && p.syncer.fetchCanceler == nil {
 			p.syncer.fetchCanceler, _ = p.syncer.fsm.FetchBlocks(e.Height(), -1, p.syncer)
+			p.syncer.fetchStart = e.Height()
+			p.syncer.fetchTarget = p.Height
+		} else if p.syncer.fetchCanceler != nil && p.Height > p.syncer.fetchTarget {
+			p.syncer.fetchTarget = p.Height
 		}
 		return 0, nil
 	}
@@ -261,6 +269,8 @@ type syncer struct {
 	lastSendTime  time.Time
 	running       bool
 	fetchCanceler func() bool
+	fetchStart    int64
+	fetchTarget   int64
 }
 
 func newSyncer(e Engine, logger log.Logger, nm module.NetworkManager, bm module.BlockManager, mutex *common.Mutex, addr module.Address) (Syncer, error) {
@@ -520,3 +530,13 @@ func (s *syncer) OnEnd(err error) {
 	s.log.Debugf("syncer.OnEnd %+v\n", err)
 	s.fetchCanceler = nil
 }
+
+func (s *syncer) FastSyncStatus() *module.FastSyncStatus {
+	if s.fetchCanceler == nil {
+		return nil
+	}
+	return &module.FastSyncStatus{
+		Start:  s.fetchStart,
+		Target: s.fetchTarget,
+	}
+}
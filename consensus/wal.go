@@ -196,6 +196,16 @@ func (w *walWriter) WriteBytes(payload []byte) (int, error) {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
+	frame := walFrame(payload)
+	n, err := w.buf.Write(frame)
+	if err == nil && w.eldestUnsyncData == nil {
+		now := time.Now()
+		w.eldestUnsyncData = &now
+	}
+	return n, err
+}
+
+func walFrame(payload []byte) []byte {
 	crc := crc32.Checksum(payload, crc32c)
 	payloadLen := len(payload)
 	frameLen := headerLen + payloadLen
@@ -205,12 +215,7 @@ func (w *walWriter) WriteBytes(payload []byte) (int, error) {
 	binary.BigEndian.PutUint32(frame[4:headerLen], uint32(payloadLen))
 	copy(frame[headerLen:], payload)
 	//log.Printf("wal write crc=%x payloadLen:%v payload:%x\n", crc, payloadLen, payload)
-	n, err := w.buf.Write(frame)
-	if err == nil && w.eldestUnsyncData == nil {
-		now := time.Now()
-		w.eldestUnsyncData = &now
-	}
-	return n, err
+	return frame
 }
 
 func WALWriteObject(w WALWriter, v interface{}) error {
@@ -442,27 +447,7 @@ func (w *walReader) CloseAndRepair() error {
 		return err
 	}
 
-	left := w.validOffset
-	idx := w.wi.headIdx
-	for _, s := range w.wi.fileSizes {
-		if left <= s {
-			if left < s {
-				err := os.Truncate(fileFor(w.id, idx), left)
-				if err != nil {
-					return errors.WithStack(err)
-				}
-			}
-			for i := idx + 1; i <= w.wi.tailIdx; i++ {
-				if err := os.Remove(fileFor(w.id, idx)); err != nil {
-					return errors.WithStack(err)
-				}
-			}
-			return nil
-		}
-		left -= s
-		idx++
-	}
-	return nil
+	return w.wi.truncateAt(w.id, w.validOffset)
 }
 
 func IsCorruptedWAL(err error) bool {
@@ -514,12 +499,12 @@ func ResetWAL(height int64, dir string, voteListBytes []byte) error {
 		FileLimit:  configCommitWALDataSize,
 		TotalLimit: configCommitWALDataSize * 3,
 	})
-	defer func() {
-		log.Must(ww.Close())
-	}()
 	if err != nil {
 		return err
 	}
+	defer func() {
+		log.Must(ww.Close())
+	}()
 	if _, err = ww.WriteBytes(voteListBytes); err != nil {
 		return err
 	}
@@ -0,0 +1,311 @@
+package consensus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// WALRecord is a record of WAL files read by WALScanner.
+type WALRecord struct {
+	// Index is the sequence number of the record from the first record.
+	Index int
+
+	// File is the path of the file including the record, and Offset is
+	// the offset of the record in the file.
+	File   string
+	Offset int64
+
+	// Size is the size of the record including the header.
+	Size int64
+
+	Payload []byte
+}
+
+// WALScanner reads records of WAL files with their positions. Unlike
+// WALReader, it doesn't repair anything, so it can be used for inspecting
+// WAL files of a stopped node.
+type WALScanner struct {
+	id     string
+	wi     *walInfo
+	files  []*os.File
+	reader io.Reader
+	index  int
+	offset int64
+}
+
+func OpenWALScanner(id string) (*WALScanner, error) {
+	wi, err := readWALInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	if wi.headIdx > wi.tailIdx {
+		return nil, errors.Wrapf(os.ErrNotExist, "no file for wal %v", id)
+	}
+	s := &WALScanner{id: id, wi: wi}
+	readers := make([]io.Reader, 0, len(wi.fileSizes))
+	for i := range wi.fileSizes {
+		f, err := os.Open(fileFor(id, wi.headIdx+uint64(i)))
+		if err != nil {
+			s.Close()
+			return nil, errors.WithStack(err)
+		}
+		s.files = append(s.files, f)
+		readers = append(readers, f)
+	}
+	s.reader = bufio.NewReaderSize(io.MultiReader(readers...), configWALBufSize)
+	return s, nil
+}
+
+// Next returns the next record. It returns io.EOF if there is no more
+// record, io.ErrUnexpectedEOF if the last record is incomplete, and
+// an error for which IsCorruptedWAL returns true on bad checksum.
+func (s *WALScanner) Next() (*WALRecord, error) {
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(s.reader, header); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	crc := binary.BigEndian.Uint32(header[0:4])
+	payloadLen := binary.BigEndian.Uint32(header[4:headerLen])
+	payload := make([]byte, payloadLen)
+	if _, err := io.ReadFull(s.reader, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, errors.WithStack(err)
+	}
+	if actualCRC := crc32.Checksum(payload, crc32c); actualCRC != crc {
+		return nil, errors.Wrapf(errCorruptedWAL, "bad crc: read:%x actual:%x payloadLen:%v", crc, actualCRC, payloadLen)
+	}
+
+	file, offset := s.Position()
+	rec := &WALRecord{
+		Index:   s.index,
+		File:    file,
+		Offset:  offset,
+		Size:    int64(headerLen) + int64(payloadLen),
+		Payload: payload,
+	}
+	s.index++
+	s.offset += rec.Size
+	return rec, nil
+}
+
+// Index returns the index of the next record.
+func (s *WALScanner) Index() int {
+	return s.index
+}
+
+// Position returns the file and the offset of the next record. After a
+// failure of Next, it's where the corruption starts.
+func (s *WALScanner) Position() (string, int64) {
+	idx, offset := s.wi.positionOf(s.offset)
+	return fileFor(s.id, idx), offset
+}
+
+// Size returns the total size of the files.
+func (s *WALScanner) Size() int64 {
+	return s.wi.totalSize
+}
+
+func (s *WALScanner) Close() error {
+	for _, f := range s.files {
+		if err := f.Close(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	s.files = nil
+	return nil
+}
+
+// positionOf returns the file index and the offset in the file for the
+// offset in the concatenated files.
+func (wi *walInfo) positionOf(offset int64) (uint64, int64) {
+	idx := wi.headIdx
+	for i, sz := range wi.fileSizes {
+		if offset < sz || i == len(wi.fileSizes)-1 {
+			break
+		}
+		offset -= sz
+		idx++
+	}
+	return idx, offset
+}
+
+// truncateAt truncates the files at the offset in the concatenated files,
+// and removes the following files.
+func (wi *walInfo) truncateAt(id string, offset int64) error {
+	idx, left := wi.positionOf(offset)
+	if left < wi.fileSizes[idx-wi.headIdx] {
+		if err := os.Truncate(fileFor(id, idx), left); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for i := idx + 1; i <= wi.tailIdx; i++ {
+		if err := os.Remove(fileFor(id, i)); err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (wi *walInfo) remove(id string) error {
+	for i := wi.headIdx; i <= wi.tailIdx; i++ {
+		if err := os.Remove(fileFor(id, i)); err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func scanWAL(id string, n int, cb func(rec *WALRecord) error) (*WALScanner, error) {
+	s, err := OpenWALScanner(id)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	for s.Index() < n {
+		rec, err := s.Next()
+		if err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err,
+				"InvalidRecordIndex(index=%d,valid=%d)", n, s.Index())
+		}
+		if cb != nil {
+			if err := cb(rec); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// TruncateWAL truncates the WAL files to keep only the first n records.
+func TruncateWAL(id string, n int) error {
+	s, err := scanWAL(id, n, nil)
+	if err != nil {
+		return err
+	}
+	return s.wi.truncateAt(id, s.offset)
+}
+
+// RewriteWAL writes the first n records of the WAL to new WAL files of
+// the other ID. If the ID is same, the WAL files are replaced by the new
+// ones.
+func RewriteWAL(id string, to string, n int) error {
+	if err := os.MkdirAll(filepath.Dir(to), walDirPermission); err != nil {
+		return errors.WithStack(err)
+	}
+	tmp := to + ".rewrite"
+	f, err := os.OpenFile(fileFor(tmp, 0), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, walPermission)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(fileFor(tmp, 0))
+
+	w := bufio.NewWriterSize(f, configWALBufSize)
+	_, err = scanWAL(id, n, func(rec *WALRecord) error {
+		_, err := w.Write(walFrame(rec.Payload))
+		return err
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if wi, err := readWALInfo(to); err == nil {
+		if err := wi.remove(to); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(fileFor(tmp, 0), fileFor(to, 0)); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// WALMessage is the summary of a consensus message in a WAL record.
+type WALMessage struct {
+	Type   string
+	Height int64
+	Round  int32
+	Step   string
+
+	// Message is the description of the message.
+	Message string
+}
+
+func stepOfVoteType(vt VoteType) step {
+	if vt == VoteTypePrevote {
+		return stepPrevote
+	}
+	return stepPrecommit
+}
+
+// DecodeWALRecord decodes the consensus message in the payload of a record.
+func DecodeWALRecord(payload []byte) (*WALMessage, error) {
+	if len(payload) < 2 {
+		return nil, errors.Errorf("too short wal message len=%v", len(payload))
+	}
+	sp := binary.BigEndian.Uint16(payload[0:2])
+	msg, err := UnmarshalMessage(sp, payload[2:])
+	if err != nil {
+		return nil, err
+	}
+	res := &WALMessage{}
+	switch m := msg.(type) {
+	case *ProposalMessage:
+		res.Type = "proposal"
+		res.Height = m.Height
+		res.Round = m.Round
+		res.Step = stepPropose.String()
+	case *BlockPartMessage:
+		res.Type = "blockPart"
+		res.Height = m.Height
+		res.Round = m.Nonce
+	case *voteMessage:
+		res.Type = "vote"
+		res.Height = m.Height
+		res.Round = m.Round
+		res.Step = stepOfVoteType(m.Type).String()
+	case *voteListMessage:
+		res.Type = "voteList"
+		if m.VoteList != nil && m.VoteList.Len() > 0 {
+			vm := m.VoteList.Get(0)
+			res.Height = vm.Height
+			res.Round = vm.Round
+			res.Step = stepOfVoteType(vm.Type).String()
+		}
+	case *RoundStateMessage:
+		res.Type = "roundState"
+		res.Height = m.Height
+		res.Round = m.Round
+	default:
+		res.Type = fmt.Sprintf("%#04x", sp)
+	}
+	if err := msg.Verify(); err != nil {
+		return res, err
+	}
+	if m, ok := msg.(*voteListMessage); ok {
+		for i := 0; i < m.VoteList.Len(); i++ {
+			if err := m.VoteList.Get(i).Verify(); err != nil {
+				return res, errors.Wrapf(err, "bad vote index=%d", i)
+			}
+		}
+	}
+	res.Message = fmt.Sprint(msg)
+	return res, nil
+}
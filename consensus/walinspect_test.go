@@ -0,0 +1,139 @@
+package consensus_test
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+)
+
+func writeVotes(t *testing.T, id string, n int) {
+	ww, err := consensus.OpenWALForWrite(id, &consensus.WALConfig{})
+	assert.NoError(t, err)
+	w := wallet.New()
+	for i := 0; i < n; i++ {
+		msg := consensus.NewVoteMessage(w, consensus.VoteTypePrevote, 10, int32(i), []byte{0x01}, nil, 1000)
+		bs := make([]byte, 2)
+		binary.BigEndian.PutUint16(bs, uint16(consensus.ProtoVote))
+		bs = append(bs, codec.BC.MustMarshalToBytes(msg)...)
+		_, err = ww.WriteBytes(bs)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, ww.Close())
+}
+
+func scanAll(t *testing.T, id string) ([]*consensus.WALRecord, error) {
+	s, err := consensus.OpenWALScanner(id)
+	assert.NoError(t, err)
+	defer s.Close()
+	var recs []*consensus.WALRecord
+	for {
+		rec, err := s.Next()
+		if err != nil {
+			if consensus.IsEOF(err) {
+				err = nil
+			}
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func TestWALScanner(t *testing.T) {
+	base, err := ioutil.TempDir("", "goloop-waltest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(base)
+	id := path.Join(base, "round")
+
+	writeVotes(t, id, 3)
+	recs, err := scanAll(t, id)
+	assert.NoError(t, err)
+	assert.Len(t, recs, 3)
+	for i, rec := range recs {
+		assert.Equal(t, i, rec.Index)
+		assert.Equal(t, id+"_0", rec.File)
+		if i > 0 {
+			assert.Equal(t, recs[i-1].Offset+recs[i-1].Size, rec.Offset)
+		}
+		msg, err := consensus.DecodeWALRecord(rec.Payload)
+		assert.NoError(t, err)
+		assert.Equal(t, "vote", msg.Type)
+		assert.EqualValues(t, 10, msg.Height)
+		assert.EqualValues(t, i, msg.Round)
+		assert.Equal(t, "stepPrevote", msg.Step)
+	}
+
+	// corrupt the last record
+	f, err := os.OpenFile(id+"_0", os.O_WRONLY, 0600)
+	assert.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xff}, recs[2].Offset+recs[2].Size-1)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	s, err := consensus.OpenWALScanner(id)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = s.Next()
+		assert.NoError(t, err)
+	}
+	_, err = s.Next()
+	assert.True(t, consensus.IsCorruptedWAL(err))
+	file, offset := s.Position()
+	assert.Equal(t, id+"_0", file)
+	assert.Equal(t, recs[2].Offset, offset)
+	assert.NoError(t, s.Close())
+
+	// rewrite to other WAL keeping the original
+	other := path.Join(base, "other", "round")
+	assert.NoError(t, consensus.RewriteWAL(id, other, 2))
+	recs2, err := scanAll(t, other)
+	assert.NoError(t, err)
+	assert.Len(t, recs2, 2)
+	assert.Equal(t, recs[1].Payload, recs2[1].Payload)
+
+	assert.Error(t, consensus.TruncateWAL(id, 3))
+	assert.NoError(t, consensus.TruncateWAL(id, 1))
+	recs, err = scanAll(t, id)
+	assert.NoError(t, err)
+	assert.Len(t, recs, 1)
+}
+
+func TestWALReader_CloseAndRepair(t *testing.T) {
+	base, err := ioutil.TempDir("", "goloop-waltest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(base)
+	id := path.Join(base, "round")
+
+	writeVotes(t, id, 2)
+	bs, err := ioutil.ReadFile(id + "_0")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(id+"_1", bs, 0600))
+	f, err := os.OpenFile(id+"_0", os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte{0x01, 0x02})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	wr, err := consensus.OpenWALForRead(id)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = wr.ReadBytes()
+		assert.NoError(t, err)
+	}
+	_, err = wr.ReadBytes()
+	assert.Error(t, err)
+	assert.NoError(t, wr.CloseAndRepair())
+
+	recs, err := scanAll(t, id)
+	assert.NoError(t, err)
+	assert.Len(t, recs, 2)
+	_, err = os.Stat(id + "_1")
+	assert.True(t, os.IsNotExist(err))
+}
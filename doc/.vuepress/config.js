@@ -26,6 +26,7 @@ module.exports = {
                 sidebarDepth: 2,    // optional, defaults to 1
                 children: [
                     '/jsonrpc_v3',
+                    '/jsonrpc_v3_msgpack',
                     '/btp_extension',
                 ]
             },
@@ -35,6 +36,9 @@ module.exports = {
                     '/goloop_admin_api',
                     ['/goloop_cli', "Goloop CLI"],
                     ['/metric', "Metric"],
+                    ['/health', "Health"],
+                    ['/tracing', "Tracing"],
+                    ['/logging', "Logging"],
                 ]
             },
             //EndOfSidebar
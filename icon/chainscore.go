@@ -644,6 +644,36 @@ var chainMethods = []*chainMethod{
 		},
 		nil,
 	}, icmodule.RevisionICON2R3, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "scheduleCallback",
+		scoreapi.FlagExternal, 3,
+		[]scoreapi.Parameter{
+			{"height", scoreapi.Integer, nil, nil},
+			{"method", scoreapi.String, nil, nil},
+			{"stepLimit", scoreapi.Integer, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Integer,
+		},
+	}, icmodule.RevisionScheduledCallback, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "cancelCallback",
+		scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"id", scoreapi.Integer, nil, nil},
+		},
+		nil,
+	}, icmodule.RevisionScheduledCallback, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getCallback",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"id", scoreapi.Integer, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionScheduledCallback, 0},
 }
 
 func applyStepLimits(fee *FeeConfig, as state.AccountState) error {
@@ -693,6 +693,10 @@ func (s *chainScore) Ex_getScoreStatus(address module.Address) (map[string]inter
 		scoreStatus["depositInfo"] = di
 	}
 
+	if usage := as.StorageUsage(); usage != nil {
+		scoreStatus["storageUsage"] = usage.ToJSON()
+	}
+
 	// blocked
 	if as.IsBlocked() == true {
 		scoreStatus["blocked"] = "0x1"
@@ -750,3 +754,28 @@ func (s *chainScore) Ex_getFeeSharingConfig() (map[string]interface{}, error) {
 	fsConfig["depositIssueRate"] = s.cc.DepositIssueRate()
 	return fsConfig, nil
 }
+
+func (s *chainScore) Ex_scheduleCallback(height *common.HexInt, method string, stepLimit *common.HexInt) (int64, error) {
+	if err := s.tryChargeCall(false); err != nil {
+		return 0, err
+	}
+	return contract.ScheduleCallback(s.cc, s.from, height.Int64(), method, stepLimit.Value())
+}
+
+func (s *chainScore) Ex_cancelCallback(id *common.HexInt) error {
+	if err := s.tryChargeCall(false); err != nil {
+		return err
+	}
+	return contract.CancelCallback(s.cc, s.from, id.Int64())
+}
+
+func (s *chainScore) Ex_getCallback(id *common.HexInt) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(false); err != nil {
+		return nil, err
+	}
+	cb := contract.GetScheduledCallback(s.cc.GetAccountState(state.SystemID), id.Int64())
+	if cb == nil {
+		return nil, scoreresult.New(StatusNotFound, "CallbackNotFound")
+	}
+	return cb.ToJSON(), nil
+}
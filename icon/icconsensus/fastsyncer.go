@@ -35,6 +35,7 @@ const sleepInterval = 3 * time.Second
 type fastSyncer struct {
 	mu            sync.Mutex
 	height        int64
+	start         int64
 	to            int64
 	c             base.Chain
 	parent        *wrapper
@@ -60,6 +61,7 @@ func newFastSyncer(
 	})
 	f := &fastSyncer{
 		height: height,
+		start:  height,
 		to:     to,
 		c:      c,
 		parent: parent,
@@ -130,6 +132,10 @@ func (f *fastSyncer) GetStatus() *module.ConsensusStatus {
 		Height:   f.height,
 		Round:    0,
 		Proposer: false,
+		FastSync: &module.FastSyncStatus{
+			Start:  f.start,
+			Target: f.to,
+		},
 	}
 }
 
@@ -26,4 +26,18 @@ const (
 	// BlockMerkle basically maps node hash to block merkle node for v1 block.
 	// In addition, it also has merkleTreeData.
 	BlockMerkle db.BucketID = "H"
+
+	// RewardHistory maps address and start height of the term to the rewards
+	// calculated for the term. It's written only if the chain keeps the
+	// history of rewards.
+	RewardHistory db.BucketID = "R"
+
+	// ValidationHistory maps address and start height of the term to the
+	// record of block validation for the term. It's written only if the
+	// chain keeps the history of block validation.
+	ValidationHistory db.BucketID = "V"
+
+	// CalculatorCheckpoint has the partial result of the reward calculation
+	// in progress, so the calculation can be resumed after restart.
+	CalculatorCheckpoint db.BucketID = "K"
 )
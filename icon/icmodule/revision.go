@@ -37,13 +37,15 @@ const (
 	Revision16
 	Revision17
 	Revision18
+	Revision19
+	Revision20
 	RevisionReserved
 )
 
 const (
 	DefaultRevision = Revision1
 	MaxRevision     = RevisionReserved - 1
-	LatestRevision  = Revision18
+	LatestRevision  = Revision20
 )
 
 const (
@@ -94,6 +96,10 @@ const (
 	RevisionFixVotingReward     = Revision17
 
 	RevisionFixTransferRewardFund = Revision18
+
+	RevisionTrackStorageUsage = Revision19
+
+	RevisionScheduledCallback = Revision20
 )
 
 var revisionFlags = []module.Revision{
@@ -135,6 +141,10 @@ var revisionFlags = []module.Revision{
 	0,
 	// Revision18
 	module.FixLostFeeByDeposit,
+	// Revision19
+	module.TrackStorageUsage,
+	// Revision20
+	module.ScheduledCallback,
 }
 
 func init() {
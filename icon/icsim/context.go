@@ -32,7 +32,8 @@ import (
 )
 
 var (
-	treasury = common.MustNewAddressFromString("hx1000000000000000000000000000000000000000")
+	treasury   = common.MustNewAddressFromString("hx1000000000000000000000000000000000000000")
+	governance = common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
 )
 
 type WorldContext interface {
@@ -272,7 +273,7 @@ func (ctx *callContext) CallOnTimer(to module.Address, params []byte) error {
 }
 
 func (ctx *callContext) Governance() module.Address {
-	return ctx.Governance()
+	return governance
 }
 
 func (ctx *callContext) FrameLogger() *trace.Logger {
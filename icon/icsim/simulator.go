@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/icon/icmodule"
 	"github.com/icon-project/goloop/icon/iiss/icstate"
 	"github.com/icon-project/goloop/module"
 )
@@ -36,6 +37,10 @@ const (
 	TypeSetPRep
 	TypeSetRevision
 	TypeClaimIScore
+	TypeSetNetworkScore
+	TypeSetRewardFund
+	TypeSetSlashingRate
+	TypePenalizeNonVoters
 )
 
 type Transaction interface {
@@ -113,4 +118,12 @@ type Simulator interface {
 	RegisterPRep(from module.Address, info *icstate.PRepInfo) Transaction
 	UnregisterPRep(from module.Address) Transaction
 	DisqualifyPRep(from module.Address, address module.Address) Transaction
+	PenalizeNonVoters(address module.Address) Transaction
+
+	GetNetworkScores() map[string]module.Address
+	SetNetworkScore(role string, address module.Address) Transaction
+
+	GetRewardFund() *icstate.RewardFund
+	SetRewardFund(rf *icstate.RewardFund) Transaction
+	SetSlashingRate(penaltyType icmodule.PenaltyType, rate int) Transaction
 }
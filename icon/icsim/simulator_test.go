@@ -23,24 +23,12 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/icon-project/goloop/common"
-	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/icon/icmodule"
 	"github.com/icon-project/goloop/icon/iiss/icstate"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/state"
 )
 
-func newConsensusInfo(dbase db.Database, vl []module.Validator, voted []bool) module.ConsensusInfo {
-	vss, err := state.ValidatorSnapshotFromSlice(dbase, vl)
-	if err != nil {
-		return nil
-	}
-	v, _ := vss.Get(vss.Len() - 1)
-	copiedVoted := make([]bool, vss.Len())
-	copy(copiedVoted, voted)
-	return common.NewConsensusInfo(v.Address(), vss, copiedVoted)
-}
-
 func initEnv(t *testing.T, c *config, revision module.Revision) *Env {
 	var env *Env
 	var err error
@@ -721,3 +709,50 @@ func TestSimulator_ReplaceBondedDelegationWithPower(t *testing.T) {
 	_, ok = jso["totalPower"].(*big.Int)
 	assert.True(t, ok)
 }
+
+func TestSimulator_GovernanceActions(t *testing.T) {
+	c := NewConfig()
+	c.MainPRepCount = 22
+	c.TermPeriod = 100
+	c.BondedPRepCount = 1
+
+	env := initEnv(t, c, icmodule.Revision13)
+	sim := env.sim
+
+	// network score
+	score := common.MustNewAddressFromString("cx1")
+	receipts, err := sim.GoByTransaction(sim.SetNetworkScore(icstate.CPSKey, score), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+	assert.True(t, score.Equal(sim.GetNetworkScores()[icstate.CPSKey]))
+
+	receipts, err = sim.GoByTransaction(sim.SetNetworkScore(icstate.GovernanceKey, score), nil)
+	assert.NoError(t, err)
+	assert.False(t, checkReceipts(receipts))
+
+	// reward fund
+	rf := sim.GetRewardFund()
+	rf.Iglobal = new(big.Int).Mul(rf.Iglobal, big.NewInt(2))
+	receipts, err = sim.GoByTransaction(sim.SetRewardFund(rf), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+	assert.Zero(t, rf.Iglobal.Cmp(sim.GetRewardFund().Iglobal))
+
+	// slashing rate and penalty
+	prep := env.preps[0]
+	bonded := sim.GetPRep(prep).Bonded()
+	assert.True(t, bonded.Sign() > 0)
+
+	block := NewBlock()
+	block.AddTransaction(sim.SetSlashingRate(icmodule.PenaltyNonVote, 10))
+	block.AddTransaction(sim.SetSlashingRate(icmodule.PenaltyLowProductivity, 10))
+	receipts, err = sim.GoByBlock(block, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Success, receipts[0].Status())
+	assert.NotEqual(t, Success, receipts[1].Status())
+
+	receipts, err = sim.GoByTransaction(sim.PenalizeNonVoters(prep), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+	assert.True(t, sim.GetPRep(prep).Bonded().Cmp(bonded) < 0)
+}
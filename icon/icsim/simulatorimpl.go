@@ -221,7 +221,14 @@ func (sim *simulatorImpl) setStake(es *iiss.ExtensionStateImpl, tx Transaction)
 	args := tx.Args()
 	from := args[0].(module.Address)
 	amount := args[1].(*big.Int)
-	return es.State.GetAccountState(from).SetStake(amount)
+	as := es.State.GetAccountState(from)
+	// Unlike the chain score, it doesn't transfer the balance, but keeps
+	// the total stake so that it can be slashed.
+	delta := new(big.Int).Sub(amount, as.Stake())
+	if err := as.SetStake(amount); err != nil {
+		return err
+	}
+	return es.State.SetTotalStake(new(big.Int).Add(es.State.GetTotalStake(), delta))
 }
 
 // Go generates as many blocks as the number passed as a parameter "blocks"
@@ -365,6 +372,14 @@ func (sim *simulatorImpl) executeTx(csi module.ConsensusInfo, ws state.WorldStat
 		err = sim.setRevision(wc, tx)
 	case TypeClaimIScore:
 		err = sim.claimIScore(es, wc, tx)
+	case TypeSetNetworkScore:
+		err = sim.setNetworkScore(es, tx)
+	case TypeSetRewardFund:
+		err = sim.setRewardFund(es, tx)
+	case TypeSetSlashingRate:
+		err = sim.setSlashingRate(es, tx)
+	case TypePenalizeNonVoters:
+		err = sim.penalizeNonVoters(es, wc, tx)
 	default:
 		return errors.Errorf("Unexpected transaction: %v", tx.Type())
 	}
@@ -409,6 +424,17 @@ func (sim *simulatorImpl) disqualifyPRep(es *iiss.ExtensionStateImpl, wc WorldCo
 	return es.DisqualifyPRep(cc, address)
 }
 
+func (sim *simulatorImpl) PenalizeNonVoters(address module.Address) Transaction {
+	return NewTransaction(TypePenalizeNonVoters, []interface{}{address})
+}
+
+func (sim *simulatorImpl) penalizeNonVoters(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	address := args[0].(module.Address)
+	cc := NewCallContext(wc, governance)
+	return es.PenalizeNonVoters(cc, address)
+}
+
 func (sim *simulatorImpl) SetPRep(from module.Address, info *icstate.PRepInfo) Transaction {
 	return NewTransaction(TypeSetPRep, []interface{}{from, info})
 }
@@ -490,6 +516,59 @@ func (sim *simulatorImpl) QueryIScore(address module.Address) *big.Int {
 	return iscore
 }
 
+func (sim *simulatorImpl) GetNetworkScores() map[string]module.Address {
+	ws := state.NewReadOnlyWorldState(sim.wss)
+	wc := NewWorldContext(ws, sim.blockHeight, sim.revision, nil, sim.stepPrice)
+	es := wc.GetExtensionState().(*iiss.ExtensionStateImpl)
+	return es.State.GetNetworkScores(NewCallContext(wc, nil))
+}
+
+// SetNetworkScore designates the network score for the role. Unlike the
+// chain score, it doesn't check the owner of the score.
+func (sim *simulatorImpl) SetNetworkScore(role string, address module.Address) Transaction {
+	return NewTransaction(TypeSetNetworkScore, []interface{}{role, address})
+}
+
+func (sim *simulatorImpl) setNetworkScore(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	role := args[0].(string)
+	address, _ := args[1].(module.Address)
+	return es.State.SetNetworkScore(role, address)
+}
+
+func (sim *simulatorImpl) GetRewardFund() *icstate.RewardFund {
+	es := sim.getExtensionState(true)
+	return es.State.GetRewardFund()
+}
+
+func (sim *simulatorImpl) SetRewardFund(rf *icstate.RewardFund) Transaction {
+	return NewTransaction(TypeSetRewardFund, []interface{}{rf})
+}
+
+func (sim *simulatorImpl) setRewardFund(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	rf := args[0].(*icstate.RewardFund)
+	return es.State.SetRewardFund(rf)
+}
+
+func (sim *simulatorImpl) SetSlashingRate(penaltyType icmodule.PenaltyType, rate int) Transaction {
+	return NewTransaction(TypeSetSlashingRate, []interface{}{penaltyType, rate})
+}
+
+func (sim *simulatorImpl) setSlashingRate(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	penaltyType := args[0].(icmodule.PenaltyType)
+	rate := args[1].(int)
+	switch penaltyType {
+	case icmodule.PenaltyBlockValidation:
+		return es.State.SetConsistentValidationPenaltySlashRatio(rate)
+	case icmodule.PenaltyNonVote:
+		return es.State.SetNonVotePenaltySlashRatio(rate)
+	default:
+		return errors.Errorf("Invalid penaltyType: %d", penaltyType)
+	}
+}
+
 func (sim *simulatorImpl) GetPRepTerm() map[string]interface{} {
 	es := sim.getExtensionState(true)
 	jso, _ := es.GetPRepTermInJSON(sim.BlockHeight())
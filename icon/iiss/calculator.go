@@ -19,8 +19,10 @@ package iiss
 import (
 	"bytes"
 	"math/big"
+	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/icon-project/goloop/common"
@@ -65,6 +67,10 @@ var (
 	BigIntMinDelegation = big.NewInt(int64(MinDelegation))
 )
 
+// calculatorBatchSize is the number of accounts calculated together by
+// workers in the voting phase. Checkpoints are made between batches.
+var calculatorBatchSize = 4096
+
 type Calculator struct {
 	log log.Logger
 
@@ -75,6 +81,11 @@ type Calculator struct {
 	global      icstage.Global
 	temp        *icreward.State
 	stats       *statistics
+	history     *rewardRecorder
+
+	workers      int
+	progress     calcProgress
+	checkpointTS time.Time
 
 	lock    sync.Mutex
 	waiters []*sync.Cond
@@ -162,6 +173,12 @@ func (c *Calculator) Stop() {
 	}
 }
 
+func (c *Calculator) isStopped() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err != nil
+}
+
 func UpdateCalculator(c *Calculator, ess state.ExtensionSnapshot, logger log.Logger) *Calculator {
 	essi := ess.(*ExtensionSnapshotImpl)
 	back := essi.Back2()
@@ -185,25 +202,26 @@ func (c *Calculator) run() (err error) {
 	}()
 
 	startTS := time.Now()
-	if err = c.prepare(); err != nil {
+	c.checkpointTS = startTS
+	if err = c.runPhase(phasePrepare, c.prepare); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to prepare calculator")
 		return
 	}
 	prepareTS := time.Now()
 
-	if err = c.calculateBlockProduce(); err != nil {
+	if err = c.runPhase(phaseBlockProduce, c.calculateBlockProduce); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to calculate block produce reward")
 		return
 	}
 	bpTS := time.Now()
 
-	if err = c.calculateVotedReward(); err != nil {
+	if err = c.runPhase(phaseVoted, c.calculateVotedReward); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to calculate P-Rep voted reward")
 		return
 	}
 	votedTS := time.Now()
 
-	if err = c.calculateVotingReward(); err != nil {
+	if err = c.runPhase(phaseVoting, c.calculateVotingReward); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to calculate ICONist voting reward")
 		return
 	}
@@ -213,6 +231,14 @@ func (c *Calculator) run() (err error) {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to do post work of calculator")
 		return
 	}
+	if c.history != nil {
+		if err := c.history.flush(c.database); err != nil {
+			c.log.Warnf("Failed to write reward history. %+v", err)
+		}
+	}
+	if err := c.clearCheckpoint(); err != nil {
+		c.log.Warnf("Failed to clear checkpoint. %+v", err)
+	}
 	finalTS := time.Now()
 
 	c.log.Infof("Calculation time: total=%s prepare=%s blockProduce=%s voted=%s voting=%s postwork=%s",
@@ -226,6 +252,31 @@ func (c *Calculator) run() (err error) {
 	return nil
 }
 
+// runPhase runs the phase unless it's done before the checkpoint resumed
+// with, then makes a checkpoint for the next phase.
+func (c *Calculator) runPhase(phase int, f func() error) error {
+	if c.progress.Phase > phase {
+		return nil
+	}
+	if err := f(); err != nil {
+		return err
+	}
+	c.progress = calcProgress{Phase: phase + 1}
+	return c.tryCheckpoint()
+}
+
+// tryCheckpoint makes a checkpoint unless the calculation is stopped.
+// Failure of a checkpoint doesn't fail the calculation.
+func (c *Calculator) tryCheckpoint() error {
+	if c.isStopped() {
+		return errors.ErrInterrupted
+	}
+	if err := c.checkpoint(); err != nil {
+		c.log.Warnf("Failed to make checkpoint. %+v", err)
+	}
+	return nil
+}
+
 func (c *Calculator) prepare() error {
 	var err error
 	c.log.Infof("Start calculation %d", c.startHeight)
@@ -317,6 +368,11 @@ func (c *Calculator) updateIScore(addr module.Address, reward *big.Int, t Reward
 		return err
 	}
 	c.log.Tracef("Update IScore %s by %d: %+v + %s = %+v", addr, t, iScore, reward, nIScore)
+	if c.history != nil {
+		if err = c.history.add(addr, reward, t); err != nil {
+			return err
+		}
+	}
 
 	switch t {
 	case TypeBlockProduce:
@@ -748,8 +804,11 @@ func (c *Calculator) calculateVotingReward() error {
 	}
 
 	// calculate voting reward
-	for _, i := range inputs {
+	// each input has two steps, accounts without events and with events.
+	for idx, i := range inputs {
+		step := idx * 2
 		if err = c.processVoting(
+			step,
 			i._type,
 			multiplier,
 			divider,
@@ -759,6 +818,7 @@ func (c *Calculator) calculateVotingReward() error {
 			return err
 		}
 		if err = c.processVotingEvent(
+			step+1,
 			i._type,
 			multiplier,
 			divider,
@@ -817,8 +877,77 @@ func (c *Calculator) addDataForBugDisabledPRep(prepInfo map[string]*pRepEnable,
 	return nil
 }
 
+// votingJob is the voting reward calculation of an account done by
+// a worker. Events are applied to voting if the account has events.
+type votingJob struct {
+	addr   *common.Address
+	voting icreward.Voting
+	events map[int]icstage.VoteList
+	reward *big.Int
+	err    error
+}
+
+// calculatedInStep returns the number of accounts already calculated in the
+// step of the voting phase, or -1 if the step is done.
+func (c *Calculator) calculatedInStep(step int) int {
+	p := c.progress
+	switch {
+	case p.Phase > phaseVoting, p.Phase == phaseVoting && p.Step > step:
+		return -1
+	case p.Phase == phaseVoting && p.Step == step:
+		return p.Position
+	default:
+		return 0
+	}
+}
+
+// runVotingJobs calculates the jobs with workers, then writes the results
+// in the order of the jobs, so the result doesn't depend on the number of
+// workers.
+func (c *Calculator) runVotingJobs(step, position int, jobs []*votingJob, calculate func(j *votingJob)) error {
+	if c.workers <= 1 || len(jobs) <= 1 {
+		for _, j := range jobs {
+			calculate(j)
+		}
+	} else {
+		var wg sync.WaitGroup
+		next := int64(-1)
+		for i := 0; i < c.workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					idx := int(atomic.AddInt64(&next, 1))
+					if idx >= len(jobs) {
+						return
+					}
+					calculate(jobs[idx])
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	for _, j := range jobs {
+		if j.err != nil {
+			return j.err
+		}
+		if j.events != nil {
+			if err := c.writeVoting(j.addr, j.voting); err != nil {
+				return err
+			}
+		}
+		if err := c.updateIScore(j.addr, j.reward, TypeVoting); err != nil {
+			return err
+		}
+	}
+	c.progress = calcProgress{Phase: phaseVoting, Step: step, Position: position + len(jobs)}
+	return c.tryCheckpoint()
+}
+
 // processVoting calculator voting reward with delegating and bonding data.
 func (c *Calculator) processVoting(
+	step int,
 	_type int,
 	multiplier *big.Int,
 	divider *big.Int,
@@ -828,16 +957,25 @@ func (c *Calculator) processVoting(
 	if multiplier.Sign() == 0 {
 		return nil
 	}
+	skip := c.calculatedInStep(step)
+	if skip < 0 {
+		return nil
+	}
 
 	// voting took place in the previous period
 	from := -1
 	to := c.global.GetOffsetLimit()
+	calculate := func(j *votingJob) {
+		j.reward = c.votingReward(multiplier, divider, from, to, prepInfo, j.voting.Iterator())
+	}
 	var prefix []byte
 	if _type == icreward.TypeDelegating {
 		prefix = icreward.DelegatingKey.Build()
 	} else {
 		prefix = icreward.BondingKey.Build()
 	}
+	position := 0
+	jobs := make([]*votingJob, 0, calculatorBatchSize)
 	for iter := c.base.Filter(prefix); iter.Has(); iter.Next() {
 		o, key, err := iter.Get()
 		if err != nil {
@@ -853,23 +991,28 @@ func (c *Calculator) processVoting(
 		if err != nil {
 			return err
 		}
-		var reward *big.Int
 		if _, ok := eventMap[string(addr.Bytes())]; ok {
 			continue
-		} else {
-			voting := toVoting(_type, o)
-			if voting == nil {
-				c.log.Errorf("Failed to convert data to voting instance")
-				continue
-			}
-			reward = c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
 		}
-		if err = c.updateIScore(addr, reward, TypeVoting); err != nil {
-			return err
+		voting := toVoting(_type, o)
+		if voting == nil {
+			c.log.Errorf("Failed to convert data to voting instance")
+			continue
+		}
+		if position < skip {
+			position += 1
+			continue
+		}
+		jobs = append(jobs, &votingJob{addr: addr, voting: voting})
+		if len(jobs) == calculatorBatchSize {
+			if err = c.runVotingJobs(step, position, jobs, calculate); err != nil {
+				return err
+			}
+			position += len(jobs)
+			jobs = jobs[:0]
 		}
 	}
-
-	return nil
+	return c.runVotingJobs(step, position, jobs, calculate)
 }
 
 // votingReward calculate voting reward with a single voting data
@@ -926,73 +1069,101 @@ func (c *Calculator) votingReward(
 
 // processVotingEvent calculate reward for account who got DELEGATE event
 func (c *Calculator) processVotingEvent(
+	step int,
 	_type int,
 	multiplier *big.Int,
 	divider *big.Int,
 	prepInfo map[string]*pRepEnable,
 	eventMap map[string]map[int]icstage.VoteList,
 ) error {
-	for key, events := range eventMap { // each account
-		addr, _ := common.NewAddress([]byte(key))
-		reward := new(big.Int)
-		offsets := make([]int, 0, len(events))
-		for offset, _ := range events {
-			offsets = append(offsets, offset)
-		}
-		// sort with offset
-		sort.Ints(offsets)
+	skip := c.calculatedInStep(step)
+	if skip < 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(eventMap))
+	for key := range eventMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-		voting, err := c.getVoting(_type, addr)
-		if err != nil {
-			return err
+	calculate := func(j *votingJob) {
+		j.reward, j.err = c.votingEventReward(multiplier, divider, prepInfo, j)
+	}
+	for position := skip; position < len(keys); position += calculatorBatchSize {
+		end := position + calculatorBatchSize
+		if end > len(keys) {
+			end = len(keys)
 		}
-
-		// initial voting took place in the previous period
-		// New configuration works from the next block
-		from := -1
-		offsetLimit := c.global.GetOffsetLimit()
-		iissVersion := c.global.GetIISSVersion()
-		for i := 0; i < len(events); i += 1 {
-			to := offsets[i]
-			switch iissVersion {
-			case icstate.IISSVersion2:
-				ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
-				reward.Add(reward, ret)
-				c.log.Tracef("VotingEvent %s %d add: %d-%d %s", addr, i, from, offsetLimit, ret)
-				ret = c.votingReward(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator())
-				reward.Sub(reward, ret)
-				c.log.Tracef("VotingEvent %s %d sub: %d-%d %s", addr, i, to, offsetLimit, ret)
-			case icstate.IISSVersion3:
-				to = offsets[i]
-				ret := c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
-				reward.Add(reward, ret)
-				c.log.Tracef("VotingEvent %s %d: %d-%d %s", addr, i, from, to, ret)
-			}
-
-			// update Bonding or Delegating
-			votes := events[to]
-			if err = voting.ApplyVotes(votes); err != nil {
-				errors.Wrapf(err, "Failed to apply vote of %s, offset=%d, votes=%+v", addr, to, votes)
+		jobs := make([]*votingJob, 0, end-position)
+		for _, key := range keys[position:end] { // each account
+			addr, _ := common.NewAddress([]byte(key))
+			voting, err := c.getVoting(_type, addr)
+			if err != nil {
 				return err
 			}
-
-			from = to
-		}
-		// calculate reward for last event
-		ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
-		reward.Add(reward, ret)
-		c.log.Tracef("VotingEvent %s last: %d, %d: %s", addr, from, offsetLimit, ret)
-
-		if err = c.writeVoting(addr, voting); err != nil {
-			return nil
+			jobs = append(jobs, &votingJob{addr: addr, voting: voting, events: eventMap[key]})
 		}
-		if err = c.updateIScore(addr, reward, TypeVoting); err != nil {
+		if err := c.runVotingJobs(step, position, jobs, calculate); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// votingEventReward calculates reward of the account applying its events
+// to the voting of the job.
+func (c *Calculator) votingEventReward(
+	multiplier *big.Int,
+	divider *big.Int,
+	prepInfo map[string]*pRepEnable,
+	j *votingJob,
+) (*big.Int, error) {
+	addr, voting, events := j.addr, j.voting, j.events
+	reward := new(big.Int)
+	offsets := make([]int, 0, len(events))
+	for offset, _ := range events {
+		offsets = append(offsets, offset)
+	}
+	// sort with offset
+	sort.Ints(offsets)
+
+	// initial voting took place in the previous period
+	// New configuration works from the next block
+	from := -1
+	offsetLimit := c.global.GetOffsetLimit()
+	iissVersion := c.global.GetIISSVersion()
+	for i := 0; i < len(events); i += 1 {
+		to := offsets[i]
+		switch iissVersion {
+		case icstate.IISSVersion2:
+			ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+			reward.Add(reward, ret)
+			c.log.Tracef("VotingEvent %s %d add: %d-%d %s", addr, i, from, offsetLimit, ret)
+			ret = c.votingReward(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator())
+			reward.Sub(reward, ret)
+			c.log.Tracef("VotingEvent %s %d sub: %d-%d %s", addr, i, to, offsetLimit, ret)
+		case icstate.IISSVersion3:
+			to = offsets[i]
+			ret := c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
+			reward.Add(reward, ret)
+			c.log.Tracef("VotingEvent %s %d: %d-%d %s", addr, i, from, to, ret)
+		}
+
+		// update Bonding or Delegating
+		votes := events[to]
+		if err := voting.ApplyVotes(votes); err != nil {
+			return nil, errors.Wrapf(err, "Failed to apply vote of %s, offset=%d, votes=%+v", addr, to, votes)
+		}
+
+		from = to
+	}
+	// calculate reward for last event
+	ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+	reward.Add(reward, ret)
+	c.log.Tracef("VotingEvent %s last: %d, %d: %s", addr, from, offsetLimit, ret)
+	return reward, nil
+}
+
 func toVoting(_type int, o trie.Object) icreward.Voting {
 	switch _type {
 	case icreward.TypeDelegating:
@@ -1067,6 +1238,16 @@ func (c *Calculator) postWork() (err error) {
 const InitBlockHeight = -1
 
 func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot, logger log.Logger) *Calculator {
+	c := newCalculator(database, back, reward, logger)
+	if c != nil && c.startHeight != InitBlockHeight {
+		go c.run()
+	}
+	return c
+}
+
+// newCalculator returns the calculator resumed from the checkpoint if it
+// exists, but it doesn't start the calculation.
+func newCalculator(database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot, logger log.Logger) *Calculator {
 	var err error
 	var global icstage.Global
 	var startHeight int64
@@ -1091,9 +1272,15 @@ func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icrewar
 		global:      global,
 		startHeight: startHeight,
 		stats:       newStatistics(),
+		workers:     runtime.GOMAXPROCS(0),
+	}
+	if startHeight != InitBlockHeight && IsRewardHistoryEnabled(database) {
+		c.history = newRewardRecorder(startHeight, global.GetOffsetLimit())
 	}
 	if startHeight != InitBlockHeight {
-		go c.run()
+		if err = c.resume(); err != nil {
+			logger.Warnf("Failed to resume calculation. %+v", err)
+		}
 	}
 	return c
 }
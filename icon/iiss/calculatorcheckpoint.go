@@ -0,0 +1,176 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icdb"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+)
+
+// Phases of the calculation
+const (
+	phasePrepare = iota
+	phaseBlockProduce
+	phaseVoted
+	phaseVoting
+	phaseDone
+)
+
+var (
+	// calculatorCheckpointInterval is the minimum interval between
+	// checkpoints. Calculations shorter than it are never checkpointed.
+	calculatorCheckpointInterval = time.Minute
+
+	calculatorCheckpointKey = []byte("checkpoint")
+)
+
+// calcProgress is the position of the calculation. Phase is the phase to
+// be calculated next. In the voting phase, Step is the index of the step
+// and Position is the number of accounts already calculated in the step.
+type calcProgress struct {
+	Phase    int
+	Step     int
+	Position int
+}
+
+// calcCheckpoint is a partial result of the calculation written to the
+// database, so the calculation can be resumed after restart.
+type calcCheckpoint struct {
+	StartHeight  int64
+	Back         []byte
+	Base         []byte
+	Temp         []byte
+	History      []byte
+	Progress     calcProgress
+	BlockProduce *big.Int
+	Voted        *big.Int
+	Voting       *big.Int
+}
+
+func (cp *calcCheckpoint) isFor(c *Calculator) bool {
+	return cp.StartHeight == c.startHeight &&
+		bytes.Equal(cp.Back, c.back.Bytes()) &&
+		bytes.Equal(cp.Base, c.base.Bytes())
+}
+
+func getCalcCheckpoint(bk db.Bucket) (*calcCheckpoint, error) {
+	bs, err := bk.Get(calculatorCheckpointKey)
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	cp := new(calcCheckpoint)
+	if _, err = codec.BC.UnmarshalFromBytes(bs, cp); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidCalculatorCheckpoint")
+	}
+	return cp, nil
+}
+
+// checkpoint writes the partial result with the current progress if the
+// last one is old enough. Tries of the partial result are flushed before
+// the checkpoint refers them.
+func (c *Calculator) checkpoint() error {
+	if time.Since(c.checkpointTS) < calculatorCheckpointInterval {
+		return nil
+	}
+	temp := c.temp.GetSnapshot()
+	if err := temp.Flush(); err != nil {
+		return err
+	}
+	cp := &calcCheckpoint{
+		StartHeight:  c.startHeight,
+		Back:         c.back.Bytes(),
+		Base:         c.base.Bytes(),
+		Temp:         temp.Bytes(),
+		Progress:     c.progress,
+		BlockProduce: c.stats.BlockProduce(),
+		Voted:        c.stats.Voted(),
+		Voting:       c.stats.Voting(),
+	}
+	if c.history != nil {
+		hash, err := c.history.checkpoint(c.database)
+		if err != nil {
+			return err
+		}
+		cp.History = hash
+	}
+	bs, err := codec.BC.MarshalToBytes(cp)
+	if err != nil {
+		return err
+	}
+	bk, err := c.database.GetBucket(icdb.CalculatorCheckpoint)
+	if err != nil {
+		return err
+	}
+	if err = bk.Set(calculatorCheckpointKey, bs); err != nil {
+		return err
+	}
+	c.checkpointTS = time.Now()
+	c.log.Debugf("Checkpoint calculation %d at %+v", c.startHeight, c.progress)
+	return nil
+}
+
+// resume restores the partial result from the checkpoint of the same
+// calculation if it exists.
+func (c *Calculator) resume() error {
+	bk, err := c.database.GetBucket(icdb.CalculatorCheckpoint)
+	if err != nil {
+		return err
+	}
+	cp, err := getCalcCheckpoint(bk)
+	if err != nil || cp == nil || !cp.isFor(c) {
+		return err
+	}
+	if c.history != nil {
+		if cp.History == nil {
+			c.log.Warnf("Reward history of the term %d is dropped as it's resumed without history",
+				c.startHeight)
+			c.history = nil
+		} else {
+			c.history.resume(c.database, cp.History)
+		}
+	}
+	c.temp = icreward.NewSnapshot(c.database, cp.Temp).NewState()
+	c.stats = &statistics{
+		blockProduce: cp.BlockProduce,
+		voted:        cp.Voted,
+		voting:       cp.Voting,
+	}
+	c.progress = cp.Progress
+	c.log.Infof("Resume calculation %d from %+v", c.startHeight, c.progress)
+	return nil
+}
+
+// clearCheckpoint removes the checkpoint of the calculation. Checkpoints
+// of other calculations are kept.
+func (c *Calculator) clearCheckpoint() error {
+	bk, err := c.database.GetBucket(icdb.CalculatorCheckpoint)
+	if err != nil {
+		return err
+	}
+	cp, err := getCalcCheckpoint(bk)
+	if err != nil || cp == nil || !cp.isFor(c) {
+		return err
+	}
+	return bk.Delete(calculatorCheckpointKey)
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icdb"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+)
+
+// checkpointDB keeps every checkpoint written to the database.
+type checkpointDB struct {
+	db.Database
+	checkpoints [][]byte
+}
+
+type checkpointBucket struct {
+	db.Bucket
+	database *checkpointDB
+}
+
+func (b *checkpointBucket) Set(key, value []byte) error {
+	b.database.checkpoints = append(b.database.checkpoints, value)
+	return b.Bucket.Set(key, value)
+}
+
+func (d *checkpointDB) GetBucket(id db.BucketID) (db.Bucket, error) {
+	bk, err := d.Database.GetBucket(id)
+	if err != nil || id != icdb.CalculatorCheckpoint {
+		return bk, err
+	}
+	return &checkpointBucket{bk, d}, nil
+}
+
+const testStakers = 200
+
+// newCalculatorForTest makes the calculator on a new database with stakers
+// delegating to P-Reps. Some of them change their delegations in the term.
+func newCalculatorForTest(t *testing.T, workers int) (*Calculator, *checkpointDB) {
+	database := &checkpointDB{Database: db.NewMapDB()}
+	flagged := db.WithFlags(database, db.Flags{base.FlagRewardHistory: true})
+
+	preps := []*common.Address{
+		common.MustNewAddressFromString("hx1001"),
+		common.MustNewAddressFromString("hx1002"),
+		common.MustNewAddressFromString("hx1003"),
+	}
+	reward := icreward.NewState(flagged, nil)
+	for _, prep := range preps {
+		voted := icreward.NewVoted()
+		voted.SetEnable(true)
+		voted.SetDelegated(big.NewInt(testStakers * MinDelegation))
+		assert.NoError(t, reward.SetVoted(prep, voted))
+	}
+	stage := icstage.NewState(flagged)
+	assert.NoError(t, stage.AddGlobalV2(icmodule.RevisionEnableIISS3, 100, 99,
+		new(big.Int).Mul(big.NewInt(1_000_000), icmodule.BigIntICX),
+		big.NewInt(50), big.NewInt(50), big.NewInt(0), big.NewInt(0), len(preps), 0))
+	for i := 0; i < testStakers; i++ {
+		addr := common.MustNewAddressFromString(fmt.Sprintf("hx%d", i+1))
+		amount := big.NewInt(int64(MinDelegation + i))
+		if i%4 != 0 {
+			delegating := icreward.NewDelegating()
+			delegating.Delegations = icstate.Delegations{
+				icstate.NewDelegation(preps[i%len(preps)], amount),
+			}
+			assert.NoError(t, reward.SetDelegating(addr, delegating))
+		}
+		if i%3 == 0 {
+			_, _, err := stage.AddEventDelegation(i%99, addr, icstage.VoteList{
+				icstage.NewVote(preps[(i+1)%len(preps)], amount),
+			})
+			assert.NoError(t, err)
+		}
+	}
+	rss := reward.GetSnapshot()
+	assert.NoError(t, rss.Flush())
+	back := stage.GetSnapshot()
+	assert.NoError(t, back.Flush())
+
+	c := newCalculator(flagged, back, rss, log.New())
+	c.workers = workers
+	return c, database
+}
+
+func TestCalculator_Parallel(t *testing.T) {
+	defer func(size int) {
+		calculatorBatchSize = size
+	}(calculatorBatchSize)
+	calculatorBatchSize = 16
+
+	serial, _ := newCalculatorForTest(t, 1)
+	assert.NoError(t, serial.run())
+	assert.True(t, serial.TotalReward().Sign() > 0)
+
+	parallel, _ := newCalculatorForTest(t, 4)
+	assert.NoError(t, parallel.run())
+	assert.Equal(t, serial.Result().Bytes(), parallel.Result().Bytes())
+	assert.Equal(t, serial.TotalReward(), parallel.TotalReward())
+}
+
+func TestCalculator_Resume(t *testing.T) {
+	defer func(size int, interval time.Duration) {
+		calculatorBatchSize = size
+		calculatorCheckpointInterval = interval
+	}(calculatorBatchSize, calculatorCheckpointInterval)
+	calculatorBatchSize = 16
+	calculatorCheckpointInterval = 0
+
+	expected, _ := newCalculatorForTest(t, 1)
+	assert.NoError(t, expected.run())
+
+	c, database := newCalculatorForTest(t, 4)
+	assert.NoError(t, c.run())
+	assert.Equal(t, expected.Result().Bytes(), c.Result().Bytes())
+
+	// the checkpoint is cleared after the calculation
+	bk, err := c.database.GetBucket(icdb.CalculatorCheckpoint)
+	assert.NoError(t, err)
+	bs, err := bk.Get(calculatorCheckpointKey)
+	assert.NoError(t, err)
+	assert.Nil(t, bs)
+
+	checkpoints := database.checkpoints
+	var resumeAt []int
+	for i, bs := range checkpoints {
+		cp := new(calcCheckpoint)
+		codec.BC.MustUnmarshalFromBytes(bs, cp)
+		if i == 0 || i == len(checkpoints)-1 ||
+			(cp.Progress.Phase == phaseVoting && cp.Progress.Position > 0 && len(resumeAt) < 3) {
+			resumeAt = append(resumeAt, i)
+		}
+	}
+	assert.Equal(t, 4, len(resumeAt))
+
+	for _, i := range resumeAt {
+		cp := new(calcCheckpoint)
+		codec.BC.MustUnmarshalFromBytes(checkpoints[i], cp)
+		assert.NoError(t, bk.Set(calculatorCheckpointKey, checkpoints[i]))
+
+		resumed := newCalculator(c.database, c.back, c.base, log.New())
+		resumed.workers = 2
+		assert.Equal(t, cp.Progress, resumed.progress)
+		assert.NoError(t, resumed.run())
+		assert.Equal(t, expected.Result().Bytes(), resumed.Result().Bytes())
+		assert.Equal(t, expected.TotalReward(), resumed.TotalReward())
+	}
+
+	// rewards collected before checkpoints are kept in the history
+	for i := 0; i < testStakers; i++ {
+		addr := common.MustNewAddressFromString(fmt.Sprintf("hx%d", i+1))
+		exp, _, err := GetRewardHistory(expected.database, addr, 0, 1)
+		assert.NoError(t, err)
+		history, _, err := GetRewardHistory(c.database, addr, 0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, exp, history)
+	}
+}
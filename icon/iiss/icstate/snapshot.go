@@ -23,6 +23,7 @@ import (
 	"github.com/icon-project/goloop/common/trie/trie_manager"
 	"github.com/icon-project/goloop/icon/iiss/icobject"
 	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
 )
 
 type Snapshot struct {
@@ -66,6 +67,29 @@ func (ss *Snapshot) GetRewardCalcInfo() (*RewardCalcInfo, error) {
 	return rc, nil
 }
 
+// ForEachAccount calls f for all accounts in the state with their keys.
+// Accounts are keyed by hash of their addresses, so addresses of them aren't
+// known, and it iterates all objects in the state.
+func (ss *Snapshot) ForEachAccount(f func(key []byte, account *AccountSnapshot) error) error {
+	for iter := ss.store.Filter(nil); iter.Has(); iter.Next() {
+		o, key, err := iter.Get()
+		if err != nil {
+			return err
+		}
+		if obj, ok := o.(*icobject.Object); ok && obj.Tag().Type() == TypeAccount {
+			if err := f(key, ToAccount(obj)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AccountKeyOf returns the key of the account of the address in the state.
+func AccountKeyOf(addr module.Address) []byte {
+	return AccountDictPrefix.Append(addr).Build()
+}
+
 func (ss *Snapshot) NewState(readonly bool) *State {
 	return NewStateFromSnapshot(ss, readonly, icutils.NewIconLogger(nil))
 }
@@ -456,6 +456,17 @@ func (s *State) GetPRepStatuses() ([]*PRepStatusState, error) {
 	return pss, nil
 }
 
+// GetPRepOwners returns owners of all P-Reps ever registered, including
+// unregistered and disqualified ones.
+func (s *State) GetPRepOwners() []module.Address {
+	size := s.allPRepCache.Size()
+	owners := make([]module.Address, size)
+	for i := 0; i < size; i++ {
+		owners[i] = s.allPRepCache.Get(i)
+	}
+	return owners
+}
+
 func sortPRepStatuses(owners []module.Address, pss []*PRepStatusState, br int64) {
 	sort.Slice(pss, func(i, j int) bool {
 		ret := pss[i].GetBondedDelegation(br).Cmp(pss[j].GetBondedDelegation(br))
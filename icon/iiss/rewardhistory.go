@@ -0,0 +1,342 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
+	"github.com/icon-project/goloop/icon/icdb"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	historyIndexPrefix  = 'i'
+	historyRecordPrefix = 'r'
+)
+
+// RewardHistory is the rewards of an account for a term.
+// It's kept in the node locally, so it's not a part of the state.
+type RewardHistory struct {
+	StartHeight  int64
+	OffsetLimit  int
+	BlockProduce *big.Int
+	Voted        *big.Int
+	Voting       *big.Int
+}
+
+func newRewardHistory(startHeight int64, offsetLimit int) *RewardHistory {
+	return &RewardHistory{
+		StartHeight:  startHeight,
+		OffsetLimit:  offsetLimit,
+		BlockProduce: new(big.Int),
+		Voted:        new(big.Int),
+		Voting:       new(big.Int),
+	}
+}
+
+func (h *RewardHistory) add(reward *big.Int, t RewardType) {
+	switch t {
+	case TypeBlockProduce:
+		h.BlockProduce.Add(h.BlockProduce, reward)
+	case TypeVoted:
+		h.Voted.Add(h.Voted, reward)
+	case TypeVoting:
+		h.Voting.Add(h.Voting, reward)
+	}
+}
+
+func (h *RewardHistory) IScore() *big.Int {
+	sum := new(big.Int).Add(h.BlockProduce, h.Voted)
+	return sum.Add(sum, h.Voting)
+}
+
+func (h *RewardHistory) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"startHeight":  intconv.FormatInt(h.StartHeight),
+		"endHeight":    intconv.FormatInt(h.StartHeight + int64(h.OffsetLimit)),
+		"blockProduce": intconv.FormatBigInt(h.BlockProduce),
+		"voted":        intconv.FormatBigInt(h.Voted),
+		"voting":       intconv.FormatBigInt(h.Voting),
+		"iscore":       intconv.FormatBigInt(h.IScore()),
+	}
+}
+
+func (h *RewardHistory) RLPEncodeSelf(e codec.Encoder) error {
+	return e.EncodeListOf(h.OffsetLimit, h.BlockProduce, h.Voted, h.Voting)
+}
+
+func (h *RewardHistory) RLPDecodeSelf(d codec.Decoder) error {
+	return d.DecodeListOf(&h.OffsetLimit, &h.BlockProduce, &h.Voted, &h.Voting)
+}
+
+func historyIndexKey(addr module.Address) []byte {
+	return append([]byte{historyIndexPrefix}, addr.Bytes()...)
+}
+
+func historyRecordKey(addr module.Address, startHeight int64) []byte {
+	key := append([]byte{historyRecordPrefix}, addr.Bytes()...)
+	var height [8]byte
+	binary.BigEndian.PutUint64(height[:], uint64(startHeight))
+	return append(key, height[:]...)
+}
+
+// IsRewardHistoryEnabled returns whether the history of rewards is kept
+// in the database.
+func IsRewardHistoryEnabled(database db.Database) bool {
+	enabled, _ := db.GetFlag(database, base.FlagRewardHistory).(bool)
+	return enabled
+}
+
+// rewardRecorder collects rewards of accounts while the calculator
+// calculates rewards for a term. Rewards collected before the last checkpoint
+// of the calculator are kept in stored.
+type rewardRecorder struct {
+	startHeight int64
+	offsetLimit int
+	rewards     map[string]*RewardHistory
+	stored      trie.Mutable
+}
+
+func newRewardRecorder(startHeight int64, offsetLimit int) *rewardRecorder {
+	return &rewardRecorder{
+		startHeight: startHeight,
+		offsetLimit: offsetLimit,
+		rewards:     make(map[string]*RewardHistory),
+	}
+}
+
+func (r *rewardRecorder) get(key string) (*RewardHistory, error) {
+	if h, ok := r.rewards[key]; ok {
+		return h, nil
+	}
+	h := newRewardHistory(r.startHeight, r.offsetLimit)
+	if r.stored != nil {
+		bs, err := r.stored.Get([]byte(key))
+		if err != nil {
+			return nil, err
+		}
+		if bs != nil {
+			if _, err = codec.BC.UnmarshalFromBytes(bs, h); err != nil {
+				return nil, errors.CriticalFormatError.Wrap(err, "InvalidRewardHistory")
+			}
+		}
+	}
+	r.rewards[key] = h
+	return h, nil
+}
+
+func (r *rewardRecorder) add(addr module.Address, reward *big.Int, t RewardType) error {
+	if reward.Sign() == 0 {
+		return nil
+	}
+	h, err := r.get(icutils.ToKey(addr))
+	if err != nil {
+		return err
+	}
+	h.add(reward, t)
+	return nil
+}
+
+// store moves collected rewards to stored.
+func (r *rewardRecorder) store(database db.Database) error {
+	if r.stored == nil {
+		r.stored = trie_manager.NewMutable(database, nil)
+	}
+	for key, h := range r.rewards {
+		bs, err := codec.BC.MarshalToBytes(h)
+		if err != nil {
+			return err
+		}
+		if _, err = r.stored.Set([]byte(key), bs); err != nil {
+			return err
+		}
+	}
+	r.rewards = make(map[string]*RewardHistory)
+	return nil
+}
+
+// checkpoint flushes collected rewards to the database, and returns
+// the hash to resume with.
+func (r *rewardRecorder) checkpoint(database db.Database) ([]byte, error) {
+	if err := r.store(database); err != nil {
+		return nil, err
+	}
+	ss := r.stored.GetSnapshot()
+	if err := ss.Flush(); err != nil {
+		return nil, err
+	}
+	return ss.Hash(), nil
+}
+
+func (r *rewardRecorder) resume(database db.Database, hash []byte) {
+	r.stored = trie_manager.NewMutable(database, hash)
+	r.rewards = make(map[string]*RewardHistory)
+}
+
+func writeRewardHistory(bk db.Bucket, key []byte, h *RewardHistory) error {
+	addr, err := common.NewAddress(key)
+	if err != nil {
+		return err
+	}
+	bs, err := codec.BC.MarshalToBytes(h)
+	if err != nil {
+		return err
+	}
+	if err = bk.Set(historyRecordKey(addr, h.StartHeight), bs); err != nil {
+		return err
+	}
+	return addHistoryIndex(bk, addr, h.StartHeight)
+}
+
+// flush writes collected rewards to the database. Records are written before
+// the index of the account, so it can be written again with the same result
+// after it's interrupted.
+func (r *rewardRecorder) flush(database db.Database) error {
+	bk, err := database.GetBucket(icdb.RewardHistory)
+	if err != nil {
+		return err
+	}
+	if r.stored == nil {
+		for key, h := range r.rewards {
+			if err = writeRewardHistory(bk, []byte(key), h); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err = r.store(database); err != nil {
+		return err
+	}
+	for iter := r.stored.GetSnapshot().Iterator(); iter.Has(); iter.Next() {
+		bs, key, err := iter.Get()
+		if err != nil {
+			return err
+		}
+		h := newRewardHistory(r.startHeight, r.offsetLimit)
+		if _, err = codec.BC.UnmarshalFromBytes(bs, h); err != nil {
+			return errors.CriticalFormatError.Wrap(err, "InvalidRewardHistory")
+		}
+		if err = writeRewardHistory(bk, key, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getHistoryIndex(bk db.Bucket, addr module.Address) ([]int64, error) {
+	bs, err := bk.Get(historyIndexKey(addr))
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	var heights []int64
+	if _, err = codec.BC.UnmarshalFromBytes(bs, &heights); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidHistoryIndex")
+	}
+	return heights, nil
+}
+
+// addHistoryIndex adds start height of the term to the index of the account
+// if it's not in the index.
+func addHistoryIndex(bk db.Bucket, addr module.Address, startHeight int64) error {
+	heights, err := getHistoryIndex(bk, addr)
+	if err != nil {
+		return err
+	}
+	idx := sort.Search(len(heights), func(i int) bool {
+		return heights[i] >= startHeight
+	})
+	if idx < len(heights) && heights[idx] == startHeight {
+		return nil
+	}
+	heights = append(heights, 0)
+	copy(heights[idx+1:], heights[idx:])
+	heights[idx] = startHeight
+	bs, err := codec.BC.MarshalToBytes(heights)
+	if err != nil {
+		return err
+	}
+	return bk.Set(historyIndexKey(addr), bs)
+}
+
+// findHistoryIndex returns start heights in the index of the account which
+// are in [startHeight, endHeight], or later than startHeight if endHeight is
+// zero. It returns at most limit heights, and the next height if there are
+// more.
+func findHistoryIndex(bk db.Bucket, addr module.Address, startHeight, endHeight int64, limit int) ([]int64, int64, error) {
+	heights, err := getHistoryIndex(bk, addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	idx := sort.Search(len(heights), func(i int) bool {
+		return heights[i] >= startHeight
+	})
+	heights = heights[idx:]
+	if endHeight > 0 {
+		idx = sort.Search(len(heights), func(i int) bool {
+			return heights[i] > endHeight
+		})
+		heights = heights[:idx]
+	}
+	var next int64
+	if len(heights) > limit {
+		next = heights[limit]
+		heights = heights[:limit]
+	}
+	return heights, next, nil
+}
+
+// GetRewardHistory returns rewards of the account for the terms starting
+// from startHeight or later, in the order of the terms. It returns at most
+// limit records, and start height of the next term if there are more.
+func GetRewardHistory(database db.Database, addr module.Address, startHeight int64, limit int) ([]*RewardHistory, int64, error) {
+	bk, err := database.GetBucket(icdb.RewardHistory)
+	if err != nil {
+		return nil, 0, err
+	}
+	heights, next, err := findHistoryIndex(bk, addr, startHeight, 0, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	history := make([]*RewardHistory, 0, len(heights))
+	for _, height := range heights {
+		bs, err := bk.Get(historyRecordKey(addr, height))
+		if err != nil {
+			return nil, 0, err
+		}
+		if bs == nil {
+			return nil, 0, errors.NotFoundError.Errorf(
+				"NoRewardHistory(addr=%s,height=%d)", addr, height)
+		}
+		h := &RewardHistory{StartHeight: height}
+		if _, err = codec.BC.UnmarshalFromBytes(bs, h); err != nil {
+			return nil, 0, errors.CriticalFormatError.Wrap(err, "InvalidRewardHistory")
+		}
+		history = append(history, h)
+	}
+	return history, next, nil
+}
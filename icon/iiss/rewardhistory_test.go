@@ -0,0 +1,84 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+)
+
+func TestRewardHistory(t *testing.T) {
+	database := db.NewMapDB()
+	assert.False(t, IsRewardHistoryEnabled(database))
+	database = db.WithFlags(database, db.Flags{base.FlagRewardHistory: true})
+	assert.True(t, IsRewardHistoryEnabled(database))
+
+	addr1 := common.MustNewAddressFromString("hx1")
+	addr2 := common.MustNewAddressFromString("hx2")
+
+	// terms are calculated out of order, and the last one is calculated again
+	for _, start := range []int64{200, 100, 300, 300} {
+		c := MakeCalculator(database, nil)
+		c.stats = newStatistics()
+		c.history = newRewardRecorder(start, 99)
+		assert.NoError(t, c.updateIScore(addr1, big.NewInt(start), TypeBlockProduce))
+		assert.NoError(t, c.updateIScore(addr1, big.NewInt(10), TypeVoted))
+		assert.NoError(t, c.updateIScore(addr1, big.NewInt(1), TypeVoting))
+		assert.NoError(t, c.updateIScore(addr1, big.NewInt(2), TypeVoting))
+		assert.NoError(t, c.updateIScore(addr2, big.NewInt(0), TypeVoting))
+		assert.NoError(t, c.history.flush(database))
+	}
+
+	history, next, err := GetRewardHistory(database, addr1, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(300), next)
+	assert.Equal(t, 2, len(history))
+	for i, h := range history {
+		start := int64(100 * (i + 1))
+		assert.Equal(t, start, h.StartHeight)
+		assert.Equal(t, 99, h.OffsetLimit)
+		assert.Equal(t, start, h.BlockProduce.Int64())
+		assert.Equal(t, int64(10), h.Voted.Int64())
+		assert.Equal(t, int64(3), h.Voting.Int64())
+		assert.Equal(t, start+13, h.IScore().Int64())
+	}
+
+	history, next, err = GetRewardHistory(database, addr1, next, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, int64(300), history[0].StartHeight)
+	jso := history[0].ToJSON()
+	assert.Equal(t, "0x12c", jso["startHeight"])
+	assert.Equal(t, "0x18f", jso["endHeight"])
+	assert.Equal(t, "0x139", jso["iscore"])
+
+	history, next, err = GetRewardHistory(database, addr1, 301, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(history))
+
+	// zero rewards are not recorded
+	history, _, err = GetRewardHistory(database, addr2, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(history))
+}
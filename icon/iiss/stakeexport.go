@@ -0,0 +1,305 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/icon/iiss/icobject"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+)
+
+func (s *ExtensionSnapshotImpl) State() *icstate.Snapshot {
+	return s.state
+}
+
+// addressSet collects addresses without duplication.
+type addressSet map[string]module.Address
+
+func (as addressSet) add(addr module.Address) {
+	if addr != nil {
+		as[icutils.ToKey(addr)] = addr
+	}
+}
+
+func (as addressSet) addKeysOf(iter trie.IteratorForObject) error {
+	for ; iter.Has(); iter.Next() {
+		_, key, err := iter.Get()
+		if err != nil {
+			return err
+		}
+		keys, err := containerdb.SplitKeys(key)
+		if err != nil || len(keys) < 2 {
+			return errors.CriticalFormatError.Wrapf(err, "InvalidKey(key=%x)", key)
+		}
+		addr, err := common.NewAddress(keys[1])
+		if err != nil {
+			return errors.CriticalFormatError.Wrapf(err, "InvalidAddressInKey(key=%x)", key)
+		}
+		as.add(addr)
+	}
+	return nil
+}
+
+func (as addressSet) addVotersOf(stage *icstage.Snapshot) error {
+	if stage == nil {
+		return nil
+	}
+	if err := as.addKeysOf(stage.Filter(icstage.IScoreClaimKey.Build())); err != nil {
+		return err
+	}
+	for iter := stage.Filter(icstage.EventKey.Build()); iter.Has(); iter.Next() {
+		o, _, err := iter.Get()
+		if err != nil {
+			return err
+		}
+		obj := o.(*icobject.Object)
+		switch obj.Tag().Type() {
+		case icstage.TypeEventDelegation, icstage.TypeEventBond, icstage.TypeEventDelegated:
+			as.add(icstage.ToEventVote(obj).From())
+		case icstage.TypeEventDelegationV2:
+			as.add(icstage.ToEventDelegationV2(obj).From())
+		}
+	}
+	return nil
+}
+
+func (as addressSet) sorted() []module.Address {
+	addrs := make([]module.Address, 0, len(as))
+	for _, addr := range as {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+	return addrs
+}
+
+// ForEachStaker calls f for accounts having stake, unstakes, delegations,
+// bonds or unbonds in the order of addresses, and returns the number of
+// such accounts not found.
+//
+// Accounts are keyed by hash of addresses in the state, so they are found
+// from P-Reps and their bonders, voters known to the reward calculation,
+// and events and claims of the terms not calculated yet. Accounts which have
+// never voted or claimed, like ones having only stake, are found only if
+// they are in extra. They are counted by iterating all accounts in the state,
+// so the caller can tell whether it has all of them.
+func (s *ExtensionSnapshotImpl) ForEachStaker(extra []module.Address, f func(addr module.Address, account *icstate.AccountSnapshot) error) (int, error) {
+	as := make(addressSet)
+	for _, addr := range extra {
+		as.add(addr)
+	}
+	st := s.state.NewState(true)
+	for _, owner := range st.GetPRepOwners() {
+		as.add(owner)
+		if pb := st.GetPRepBaseByOwner(owner, false); pb != nil {
+			for _, bonder := range pb.BonderList() {
+				as.add(bonder)
+			}
+		}
+	}
+	if s.reward != nil {
+		if err := as.addKeysOf(s.reward.Filter(icreward.DelegatingKey.Build())); err != nil {
+			return 0, err
+		}
+		if err := as.addKeysOf(s.reward.Filter(icreward.BondingKey.Build())); err != nil {
+			return 0, err
+		}
+	}
+	for _, stage := range []*icstage.Snapshot{s.front, s.back1, s.back2} {
+		if err := as.addVotersOf(stage); err != nil {
+			return 0, err
+		}
+	}
+
+	found := make(map[string]bool)
+	for _, addr := range as.sorted() {
+		account := st.GetAccountSnapshot(addr)
+		if account == nil || !hasStake(account) {
+			continue
+		}
+		if err := f(addr, account); err != nil {
+			return 0, err
+		}
+		found[string(icstate.AccountKeyOf(addr))] = true
+	}
+
+	missing := 0
+	err := s.state.ForEachAccount(func(key []byte, account *icstate.AccountSnapshot) error {
+		if hasStake(account) && !found[string(key)] {
+			missing++
+		}
+		return nil
+	})
+	return missing, err
+}
+
+func hasStake(a *icstate.AccountSnapshot) bool {
+	return !a.IsEmpty() || a.Delegations().Has() || len(a.Bonds()) > 0 || len(a.Unbonds()) > 0
+}
+
+// StakeWriter writes accounts found by ForEachStaker at the height.
+type StakeWriter interface {
+	Write(height int64, term *icstate.TermSnapshot, addr module.Address, account *icstate.AccountSnapshot) error
+	Flush() error
+}
+
+// NewStakeWriter returns StakeWriter for the format, "csv" or "ndjson".
+func NewStakeWriter(format string, w io.Writer) (StakeWriter, error) {
+	switch strings.ToLower(format) {
+	case "", "csv":
+		return newStakeCSVWriter(w), nil
+	case "ndjson":
+		return &stakeJSONWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownFormat(format=%s)", format)
+	}
+}
+
+// StakeCSVColumns are columns of CSV written by StakeWriter. Each row has
+// an item of the account, and the type of it is one of stake, unstake,
+// delegation, bond and unbond. Target is the P-Rep of delegation, bond
+// or unbond, and expireHeight is set for unstake and unbond.
+var StakeCSVColumns = []string{
+	"height", "termSequence", "termStartHeight", "address",
+	"type", "target", "amount", "expireHeight",
+}
+
+type stakeCSVWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func newStakeCSVWriter(w io.Writer) *stakeCSVWriter {
+	return &stakeCSVWriter{writer: csv.NewWriter(w)}
+}
+
+func (w *stakeCSVWriter) Write(height int64, term *icstate.TermSnapshot, addr module.Address, account *icstate.AccountSnapshot) error {
+	if !w.header {
+		if err := w.writer.Write(StakeCSVColumns); err != nil {
+			return err
+		}
+		w.header = true
+	}
+	prefix := []string{
+		fmt.Sprint(height),
+		fmt.Sprint(term.Sequence()),
+		fmt.Sprint(term.StartHeight()),
+		addr.String(),
+	}
+	write := func(t string, target module.Address, amount *big.Int, expire int64) error {
+		var targetStr, expireStr string
+		if target != nil {
+			targetStr = target.String()
+		}
+		if expire != 0 {
+			expireStr = fmt.Sprint(expire)
+		}
+		return w.writer.Write(append(prefix, t, targetStr, amount.String(), expireStr))
+	}
+	if err := write("stake", nil, account.Stake(), 0); err != nil {
+		return err
+	}
+	for _, u := range account.UnStakes() {
+		if err := write("unstake", nil, u.Value, u.Expire); err != nil {
+			return err
+		}
+	}
+	for _, d := range account.Delegations() {
+		if err := write("delegation", d.Address, d.Value.Value(), 0); err != nil {
+			return err
+		}
+	}
+	for _, b := range account.Bonds() {
+		if err := write("bond", b.Address, b.Value.Value(), 0); err != nil {
+			return err
+		}
+	}
+	for _, ub := range account.Unbonds() {
+		if err := write("unbond", ub.Address(), ub.Value(), ub.Expire()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *stakeCSVWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type stakeJSONWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *stakeJSONWriter) Write(height int64, term *icstate.TermSnapshot, addr module.Address, account *icstate.AccountSnapshot) error {
+	unstakes := make([]interface{}, len(account.UnStakes()))
+	for i, u := range account.UnStakes() {
+		unstakes[i] = map[string]interface{}{
+			"unstake":            intconv.FormatBigInt(u.Value),
+			"unstakeBlockHeight": intconv.FormatInt(u.Expire),
+		}
+	}
+	unbonds := make([]interface{}, len(account.Unbonds()))
+	for i, ub := range account.Unbonds() {
+		unbonds[i] = map[string]interface{}{
+			"address":           ub.Address(),
+			"value":             intconv.FormatBigInt(ub.Value()),
+			"expireBlockHeight": intconv.FormatInt(ub.Expire()),
+		}
+	}
+	delegations := account.Delegations()
+	if delegations == nil {
+		delegations = icstate.Delegations{}
+	}
+	bonds := account.Bonds()
+	if bonds == nil {
+		bonds = icstate.Bonds{}
+	}
+	return w.encoder.Encode(map[string]interface{}{
+		"height":          intconv.FormatInt(height),
+		"termSequence":    intconv.FormatInt(int64(term.Sequence())),
+		"termStartHeight": intconv.FormatInt(term.StartHeight()),
+		"address":         addr,
+		"stake":           intconv.FormatBigInt(account.Stake()),
+		"unstakes":        unstakes,
+		"delegations":     delegations,
+		"bonds":           bonds,
+		"unbonds":         unbonds,
+	})
+}
+
+func (w *stakeJSONWriter) Flush() error {
+	return nil
+}
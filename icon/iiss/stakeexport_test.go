@@ -0,0 +1,101 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestExtensionSnapshotImpl_ForEachStaker(t *testing.T) {
+	database := db.NewMapDB()
+	es := NewExtensionSnapshot(database, nil).NewState(false).(*ExtensionStateImpl)
+
+	prep := common.MustNewAddressFromString("hx1")
+	voter := common.MustNewAddressFromString("hx2")
+	staker := common.MustNewAddressFromString("hx3")
+	unknown := common.MustNewAddressFromString("hx4")
+
+	for _, addr := range []*common.Address{voter, staker, unknown} {
+		assert.NoError(t, es.State.GetAccountState(addr).SetStake(big.NewInt(100)))
+	}
+	es.State.GetAccountState(voter).SetDelegation(icstate.Delegations{
+		icstate.NewDelegation(prep, big.NewInt(30)),
+	})
+	_, _, err := es.Front.AddEventDelegation(0, voter, icstage.VoteList{
+		icstage.NewVote(prep, big.NewInt(30)),
+	})
+	assert.NoError(t, err)
+	term := icstate.GenesisTerm(es.State, 100, icmodule.RevisionIISS).GetSnapshot()
+
+	ess := es.GetSnapshot().(*ExtensionSnapshotImpl)
+	var found []module.Address
+	missing, err := ess.ForEachStaker([]module.Address{staker}, func(addr module.Address, account *icstate.AccountSnapshot) error {
+		found = append(found, addr)
+		return nil
+	})
+	assert.NoError(t, err)
+	// the account having only stake is found only if it's given
+	assert.Equal(t, []module.Address{voter, staker}, found)
+	assert.Equal(t, 1, missing)
+	missing, err = ess.ForEachStaker([]module.Address{staker, unknown}, func(addr module.Address, account *icstate.AccountSnapshot) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, missing)
+
+	buf := bytes.NewBuffer(nil)
+	w, err := NewStakeWriter("csv", buf)
+	assert.NoError(t, err)
+	missing, err = ess.ForEachStaker(nil, func(addr module.Address, account *icstate.AccountSnapshot) error {
+		return w.Write(100, term, addr, account)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, missing)
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, strings.Join(StakeCSVColumns, ",")+"\n"+
+		"100,0,100,hx0000000000000000000000000000000000000002,stake,,100,\n"+
+		"100,0,100,hx0000000000000000000000000000000000000002,delegation,hx0000000000000000000000000000000000000001,30,\n",
+		buf.String())
+
+	buf.Reset()
+	w, err = NewStakeWriter("ndjson", buf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(100, term, voter, ess.State().NewState(true).GetAccountSnapshot(voter)))
+	var jso map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &jso))
+	assert.Equal(t, "0x64", jso["stake"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"address": prep.String(), "value": "0x1e"},
+	}, jso["delegations"])
+	assert.Equal(t, []interface{}{}, jso["bonds"])
+
+	_, err = NewStakeWriter("xml", buf)
+	assert.Error(t, err)
+}
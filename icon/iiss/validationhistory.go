@@ -0,0 +1,382 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icdb"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// validationProgressKey is the key for the records of the current term.
+// It's not prefixed by historyIndexPrefix nor historyRecordPrefix.
+var validationProgressKey = []byte("progress")
+
+// maxMissedRanges is the maximum number of missed ranges kept for a term.
+// Missed blocks out of the ranges are still counted.
+const maxMissedRanges = 100
+
+// MissedRange is the range of consecutive blocks missed by a validator.
+type MissedRange struct {
+	Start int64
+	End   int64
+}
+
+func (r *MissedRange) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"startHeight": intconv.FormatInt(r.Start),
+		"endHeight":   intconv.FormatInt(r.End),
+	}
+}
+
+// ValidationHistory is the record of block validation of a P-Rep for a term.
+// It's kept in the node locally, so it's not a part of the state.
+type ValidationHistory struct {
+	StartHeight  int64
+	EndHeight    int64
+	LastHeight   int64
+	Blocks       int64
+	Missed       int64
+	MaxMissed    int64
+	MissedRanges []MissedRange
+
+	// consecutive is the number of missed blocks in a row until LastHeight
+	consecutive int64
+}
+
+func newValidationHistory(startHeight, endHeight int64) *ValidationHistory {
+	return &ValidationHistory{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+}
+
+func (h *ValidationHistory) add(height int64, missed bool) {
+	h.Blocks++
+	if !missed {
+		h.consecutive = 0
+		h.LastHeight = height
+		return
+	}
+	h.Missed++
+	if h.consecutive > 0 && h.LastHeight == height-1 {
+		h.consecutive++
+	} else {
+		h.consecutive = 1
+	}
+	if h.consecutive > h.MaxMissed {
+		h.MaxMissed = h.consecutive
+	}
+	if n := len(h.MissedRanges); n > 0 && h.MissedRanges[n-1].End == height-1 {
+		h.MissedRanges[n-1].End = height
+	} else if n < maxMissedRanges {
+		h.MissedRanges = append(h.MissedRanges, MissedRange{height, height})
+	}
+	h.LastHeight = height
+}
+
+// Uptime returns the rate of validated blocks in basis points.
+func (h *ValidationHistory) Uptime() int64 {
+	if h.Blocks == 0 {
+		return 0
+	}
+	return (h.Blocks - h.Missed) * 10000 / h.Blocks
+}
+
+func (h *ValidationHistory) ToJSON() map[string]interface{} {
+	ranges := make([]interface{}, len(h.MissedRanges))
+	for i := range h.MissedRanges {
+		ranges[i] = h.MissedRanges[i].ToJSON()
+	}
+	return map[string]interface{}{
+		"startHeight":  intconv.FormatInt(h.StartHeight),
+		"endHeight":    intconv.FormatInt(h.EndHeight),
+		"lastHeight":   intconv.FormatInt(h.LastHeight),
+		"blocks":       intconv.FormatInt(h.Blocks),
+		"missed":       intconv.FormatInt(h.Missed),
+		"maxMissed":    intconv.FormatInt(h.MaxMissed),
+		"uptime":       intconv.FormatInt(h.Uptime()),
+		"missedRanges": ranges,
+	}
+}
+
+func (h *ValidationHistory) RLPEncodeSelf(e codec.Encoder) error {
+	return e.EncodeListOf(
+		h.EndHeight, h.LastHeight, h.Blocks, h.Missed, h.MaxMissed,
+		h.MissedRanges, h.consecutive)
+}
+
+func (h *ValidationHistory) RLPDecodeSelf(d codec.Decoder) error {
+	return d.DecodeListOf(
+		&h.EndHeight, &h.LastHeight, &h.Blocks, &h.Missed, &h.MaxMissed,
+		&h.MissedRanges, &h.consecutive)
+}
+
+// IsValidationHistoryEnabled returns whether the history of block validation
+// is kept in the database.
+func IsValidationHistoryEnabled(database db.Database) bool {
+	enabled, _ := db.GetFlag(database, base.FlagValidationHistory).(bool)
+	return enabled
+}
+
+// validationProgress is the records of the current term. It's written to
+// the database on every block, so that recording can be continued after
+// restart.
+type validationProgress struct {
+	startHeight     int64
+	endHeight       int64
+	voteStartHeight int64
+	lastHeight      int64
+	owners          []*common.Address
+	records         map[string]*ValidationHistory
+}
+
+func newValidationProgress(term *icstate.TermSnapshot) *validationProgress {
+	return &validationProgress{
+		startHeight:     term.StartHeight(),
+		endHeight:       term.GetEndHeight(),
+		voteStartHeight: term.GetVoteStartHeight(),
+		records:         make(map[string]*ValidationHistory),
+	}
+}
+
+func (p *validationProgress) add(owner module.Address, height int64, missed bool) {
+	key := icutils.ToKey(owner)
+	h, ok := p.records[key]
+	if !ok {
+		h = newValidationHistory(p.startHeight, p.endHeight)
+		p.records[key] = h
+		p.owners = append(p.owners, common.AddressToPtr(owner))
+	}
+	h.add(height, missed)
+}
+
+func (p *validationProgress) RLPEncodeSelf(e codec.Encoder) error {
+	records := make([]*ValidationHistory, len(p.owners))
+	for i, owner := range p.owners {
+		records[i] = p.records[icutils.ToKey(owner)]
+	}
+	return e.EncodeListOf(
+		p.startHeight, p.endHeight, p.voteStartHeight, p.lastHeight,
+		p.owners, records)
+}
+
+func (p *validationProgress) RLPDecodeSelf(d codec.Decoder) error {
+	var records []*ValidationHistory
+	if err := d.DecodeListOf(
+		&p.startHeight, &p.endHeight, &p.voteStartHeight, &p.lastHeight,
+		&p.owners, &records); err != nil {
+		return err
+	}
+	if len(records) != len(p.owners) {
+		return errors.CriticalFormatError.Errorf(
+			"InvalidValidationProgress(owners=%d,records=%d)",
+			len(p.owners), len(records))
+	}
+	p.records = make(map[string]*ValidationHistory, len(records))
+	for i, owner := range p.owners {
+		records[i].StartHeight = p.startHeight
+		p.records[icutils.ToKey(owner)] = records[i]
+	}
+	return nil
+}
+
+func (p *validationProgress) save(bk db.Bucket) error {
+	bs, err := codec.BC.MarshalToBytes(p)
+	if err != nil {
+		return err
+	}
+	return bk.Set(validationProgressKey, bs)
+}
+
+// flush writes records of the term to the database, then removes the
+// progress. Like rewardRecorder.flush, it can be written again with the same
+// result after it's interrupted.
+func (p *validationProgress) flush(bk db.Bucket) error {
+	for _, owner := range p.owners {
+		bs, err := codec.BC.MarshalToBytes(p.records[icutils.ToKey(owner)])
+		if err != nil {
+			return err
+		}
+		if err = bk.Set(historyRecordKey(owner, p.startHeight), bs); err != nil {
+			return err
+		}
+		if err = addHistoryIndex(bk, owner, p.startHeight); err != nil {
+			return err
+		}
+	}
+	return bk.Delete(validationProgressKey)
+}
+
+func loadValidationProgress(bk db.Bucket) (*validationProgress, error) {
+	bs, err := bk.Get(validationProgressKey)
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	p := new(validationProgress)
+	if _, err = codec.BC.UnmarshalFromBytes(bs, p); err != nil {
+		return nil, errors.CriticalFormatError.Wrap(err, "InvalidValidationProgress")
+	}
+	return p, nil
+}
+
+// ValidationRecorder records block validation of validators with the
+// voters of each finalized block.
+type ValidationRecorder struct {
+	lock     sync.Mutex
+	loaded   bool
+	progress *validationProgress
+}
+
+// OnFinalize records block validation of the block at the height with
+// the extension snapshot finalized for the block.
+func (r *ValidationRecorder) OnFinalize(ess state.ExtensionSnapshot, height int64, logger log.Logger) {
+	snapshot, ok := ess.(*ExtensionSnapshotImpl)
+	if !ok || snapshot == nil || !IsValidationHistoryEnabled(snapshot.database) {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s := icstate.NewStateFromSnapshot(snapshot.state, true, logger)
+	if err := r.record(snapshot.database, s, height); err != nil {
+		logger.Warnf("Failed to record validation history. %+v", err)
+	}
+}
+
+func (r *ValidationRecorder) record(database db.Database, s *icstate.State, height int64) error {
+	bk, err := database.GetBucket(icdb.ValidationHistory)
+	if err != nil {
+		return err
+	}
+	if !r.loaded {
+		if r.progress, err = loadValidationProgress(bk); err != nil {
+			return err
+		}
+		r.loaded = true
+	}
+
+	p := r.progress
+	if p != nil && height <= p.lastHeight {
+		// already recorded
+		return nil
+	}
+	if p == nil || height > p.endHeight {
+		if p != nil {
+			// the last block of the term wasn't recorded
+			if err = p.flush(bk); err != nil {
+				return err
+			}
+			r.progress = nil
+		}
+		// At the last block of a term, the term in the state is already
+		// the next one, so the block is ignored here.
+		term := s.GetTermSnapshot()
+		if term == nil || !term.IsDecentralized() ||
+			height < term.StartHeight() || height > term.GetEndHeight() {
+			return nil
+		}
+		p = newValidationProgress(term)
+		r.progress = p
+	}
+
+	if height >= p.voteStartHeight {
+		if voters := s.GetLastBlockVotersSnapshot(); voters != nil {
+			for i := 0; i < voters.Len(); i++ {
+				owner := voters.Get(i)
+				ps := s.GetPRepStatusByOwner(owner, false)
+				if ps == nil {
+					continue
+				}
+				p.add(owner, height, ps.LastState() == icstate.Failure)
+			}
+		}
+	}
+	p.lastHeight = height
+
+	if height == p.endHeight {
+		r.progress = nil
+		return p.flush(bk)
+	}
+	return p.save(bk)
+}
+
+// GetValidationHistory returns records of block validation of the P-Rep for
+// the terms starting in [startHeight, endHeight], or from startHeight if
+// endHeight is zero. The record of the current term is included if it's in
+// the range. It returns at most limit records, and start height of the next
+// term if there are more.
+func GetValidationHistory(database db.Database, owner module.Address, startHeight, endHeight int64, limit int) ([]*ValidationHistory, int64, error) {
+	bk, err := database.GetBucket(icdb.ValidationHistory)
+	if err != nil {
+		return nil, 0, err
+	}
+	heights, next, err := findHistoryIndex(bk, owner, startHeight, endHeight, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	history := make([]*ValidationHistory, 0, len(heights)+1)
+	for _, height := range heights {
+		bs, err := bk.Get(historyRecordKey(owner, height))
+		if err != nil {
+			return nil, 0, err
+		}
+		if bs == nil {
+			return nil, 0, errors.NotFoundError.Errorf(
+				"NoValidationHistory(addr=%s,height=%d)", owner, height)
+		}
+		h := &ValidationHistory{StartHeight: height}
+		if _, err = codec.BC.UnmarshalFromBytes(bs, h); err != nil {
+			return nil, 0, errors.CriticalFormatError.Wrap(err, "InvalidValidationHistory")
+		}
+		history = append(history, h)
+	}
+	if next != 0 {
+		return history, next, nil
+	}
+
+	p, err := loadValidationProgress(bk)
+	if err != nil || p == nil {
+		return history, 0, err
+	}
+	if p.startHeight < startHeight || (endHeight > 0 && p.startHeight > endHeight) {
+		return history, 0, nil
+	}
+	if n := len(history); n > 0 && history[n-1].StartHeight >= p.startHeight {
+		return history, 0, nil
+	}
+	h, ok := p.records[icutils.ToKey(owner)]
+	if !ok {
+		return history, 0, nil
+	}
+	if len(history) == limit {
+		return history, p.startHeight, nil
+	}
+	return append(history, h), 0, nil
+}
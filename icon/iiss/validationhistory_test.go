@@ -0,0 +1,132 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/icon/icdb"
+)
+
+func TestValidationHistory_add(t *testing.T) {
+	h := newValidationHistory(100, 199)
+	for height := int64(100); height < 110; height++ {
+		// missed 102~104 and 107
+		missed := (height >= 102 && height <= 104) || height == 107
+		h.add(height, missed)
+	}
+	assert.Equal(t, int64(10), h.Blocks)
+	assert.Equal(t, int64(4), h.Missed)
+	assert.Equal(t, int64(3), h.MaxMissed)
+	assert.Equal(t, int64(109), h.LastHeight)
+	assert.Equal(t, []MissedRange{{102, 104}, {107, 107}}, h.MissedRanges)
+	assert.Equal(t, int64(6000), h.Uptime())
+
+	// a missed block after a gap starts a new range
+	h.add(111, true)
+	h.add(113, true)
+	assert.Equal(t, int64(1), h.consecutive)
+	assert.Equal(t, 4, len(h.MissedRanges))
+
+	// ranges are limited, but missed blocks are still counted
+	h = newValidationHistory(0, 1000)
+	for height := int64(0); height < 2*maxMissedRanges; height++ {
+		h.add(height*2, true)
+	}
+	assert.Equal(t, maxMissedRanges, len(h.MissedRanges))
+	assert.Equal(t, int64(2*maxMissedRanges), h.Missed)
+	assert.Equal(t, int64(1), h.MaxMissed)
+}
+
+func TestGetValidationHistory(t *testing.T) {
+	database := db.NewMapDB()
+	assert.False(t, IsValidationHistoryEnabled(database))
+	database = db.WithFlags(database, db.Flags{base.FlagValidationHistory: true})
+	assert.True(t, IsValidationHistoryEnabled(database))
+
+	bk, err := database.GetBucket(icdb.ValidationHistory)
+	assert.NoError(t, err)
+
+	addr1 := common.MustNewAddressFromString("hx1")
+	addr2 := common.MustNewAddressFromString("hx2")
+
+	for _, start := range []int64{100, 200, 300} {
+		p := &validationProgress{
+			startHeight: start,
+			endHeight:   start + 99,
+			records:     make(map[string]*ValidationHistory),
+		}
+		for height := start; height < start+100; height++ {
+			p.add(addr1, height, height == start+10)
+			if start != 200 {
+				p.add(addr2, height, false)
+			}
+			p.lastHeight = height
+		}
+		if start == 300 {
+			// the current term is kept as progress
+			assert.NoError(t, p.save(bk))
+		} else {
+			assert.NoError(t, p.flush(bk))
+		}
+	}
+
+	p, err := loadValidationProgress(bk)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(300), p.startHeight)
+	assert.Equal(t, int64(399), p.lastHeight)
+	assert.Equal(t, 2, len(p.owners))
+
+	history, next, err := GetValidationHistory(database, addr1, 0, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(300), next)
+	assert.Equal(t, 2, len(history))
+	for i, h := range history {
+		start := int64(100 * (i + 1))
+		assert.Equal(t, start, h.StartHeight)
+		assert.Equal(t, start+99, h.EndHeight)
+		assert.Equal(t, int64(100), h.Blocks)
+		assert.Equal(t, int64(1), h.Missed)
+		assert.Equal(t, []MissedRange{{start + 10, start + 10}}, h.MissedRanges)
+	}
+
+	history, next, err = GetValidationHistory(database, addr1, next, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, 1, len(history))
+	jso := history[0].ToJSON()
+	assert.Equal(t, "0x12c", jso["startHeight"])
+	assert.Equal(t, "0x18f", jso["lastHeight"])
+	assert.Equal(t, "0x26ac", jso["uptime"])
+
+	history, next, err = GetValidationHistory(database, addr1, 150, 250, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, int64(200), history[0].StartHeight)
+
+	history, _, err = GetValidationHistory(database, addr2, 0, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, int64(100), history[0].StartHeight)
+	assert.Equal(t, int64(300), history[1].StartHeight)
+}
@@ -54,6 +54,7 @@ type BlockConverter struct {
 	blkByHash   db.Bucket
 	chainBucket db.Bucket
 	svc         Service
+	status      *Status
 
 	stopCh chan<- struct{}
 	resCh  <-chan interface{}
@@ -115,14 +116,22 @@ func NewBlockConverterWithService(
 		blkByHash:   blkByHash,
 		chainBucket: chainBucket,
 		svc:         svc,
+		status:      newStatus(chain.MetricContext()),
 	}
 	ex.trace = logger.WithFields(log.Fields{
 		log.FieldKeyModule: "TRACE",
 	})
 	ex.database = database
+	if err := ex.loadCheckpoint(); err != nil {
+		return nil, err
+	}
 	return ex, nil
 }
 
+func (e *BlockConverter) Status() *Status {
+	return e.status
+}
+
 func (e *BlockConverter) Start(from, to int64) (<-chan interface{}, error) {
 	return e.execute(from, to, nil)
 }
@@ -224,7 +233,7 @@ func (e *BlockConverter) originalReceipts(txs []module.Transaction) ([]txresult.
 		}
 		rct, err := e.cs.GetReceipt(tx.ID())
 		if err != nil {
-			return nil, errors.Wrapf(err, "FailureInGetReceipts(txid=%#x)", tx.ID())
+			return nil, toStoreFailure(errors.Wrapf(err, "FailureInGetReceipts(txid=%#x)", tx.ID()))
 		}
 		rcts[idx] = rct.(txresult.Receipt)
 	}
@@ -268,7 +277,7 @@ func (e *BlockConverter) proposeTransition(last *Transition) (*Transition, error
 	prevV0 := last.block
 	blkv0, err := e.cs.GetBlockByHeight(int(height))
 	if err != nil {
-		return nil, err
+		return nil, toStoreFailure(errors.Wrapf(err, "FailureInGetBlock(height=%d)", height))
 	}
 	if err := blkv0.Verify(last.block); err != nil {
 		return nil, err
@@ -451,6 +460,10 @@ func (e *BlockConverter) execute(from, to int64, firstNForcedResults []*BlockTra
 			from = last + 1
 		}
 		if last > 0 && len(firstNForcedResults) == 0 {
+			if err := e.verifyCheckpoint(last); err != nil {
+				resCh <- err
+				return
+			}
 			if last < to {
 				last = to
 			}
@@ -552,6 +565,18 @@ func (e *BlockConverter) doExecute(
 			tps,
 		)
 		tr, err := e.proposeTransition(prevTR)
+		delay := StoreRetryDelay
+		for retry := 0; retry < StoreRetryLimit && isStoreFailure(err); retry++ {
+			e.status.onStoreFailure(height)
+			e.log.Warnf("Store failure on Block[ %9d ], retry after %s err=%+v", height, delay, err)
+			select {
+			case <-stopCh:
+				return errors.InterruptedError.Errorf("Execution interrupted")
+			case <-time.After(delay):
+			}
+			delay = nextStoreRetryDelay(delay)
+			tr, err = e.proposeTransition(prevTR)
+		}
 		if err != nil {
 			return errors.Wrapf(err, "FailureInPropose(height=%d)", height)
 		}
@@ -572,6 +597,9 @@ func (e *BlockConverter) doExecute(
 		}
 
 		if err := e.checkResult(tr); err != nil {
+			if werr := e.writeCheckpoint(e.status.onMismatch(height)); werr != nil {
+				e.log.Warnf("Fail to write checkpoint err=%+v", werr)
+			}
 			return err
 		}
 
@@ -600,6 +628,14 @@ func (e *BlockConverter) doExecute(
 		if err != nil {
 			return err
 		}
+		var receipts int
+		if prevTR.block != nil {
+			receipts = len(prevTR.block.NormalTransactions())
+		}
+		cp := e.status.onBlock(blk.Height(), blk.Hash(), blk.Result(), receipts)
+		if err = e.writeCheckpoint(cp); err != nil {
+			return err
+		}
 		if err = bk.Set(db.Raw(KeyLastBlockHeight), blk.Height()); err != nil {
 			return err
 		}
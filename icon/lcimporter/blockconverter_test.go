@@ -17,14 +17,21 @@
 package lcimporter_test
 
 import (
+	"context"
 	"encoding/hex"
+	"fmt"
 	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/client"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
@@ -104,6 +111,10 @@ func (c *testChain) NetworkManager() module.NetworkManager {
 	return nil
 }
 
+func (c *testChain) MetricContext() context.Context {
+	return context.Background()
+}
+
 func newTestChain(database db.Database, logger log.Logger) (*testChain, error) {
 	w := wallet.New()
 	return &testChain{
@@ -479,6 +490,128 @@ func TestBlockConverter_Continue3(t_ *testing.T) {
 	})
 }
 
+type flakyStore struct {
+	lcimporter.Store
+	failures int
+	err      error
+}
+
+func (s *flakyStore) GetBlockByHeight(height int) (blockv0.Block, error) {
+	if s.failures > 0 {
+		s.failures -= 1
+		return nil, s.err
+	}
+	return s.Store.GetBlockByHeight(height)
+}
+
+func newFlakyBlockConverterTest(t *testing.T, failures int, err error) *blockConverterTest {
+	dbase := db.NewMapDB()
+	s, serr := newTestStore(dbase)
+	assert.NoError(t, serr)
+	store := &flakyStore{Store: s, failures: failures, err: err}
+	return newBlockConverterTest2(t, dbase, store, ictest.NewPlatform())
+}
+
+func setStoreRetryDelay(t *testing.T, delay time.Duration) {
+	old := lcimporter.StoreRetryDelay
+	lcimporter.StoreRetryDelay = delay
+	t.Cleanup(func() {
+		lcimporter.StoreRetryDelay = old
+	})
+}
+
+var errConnRefused = &url.Error{
+	Op:  "Post",
+	URL: "http://localhost:9000/api/v3",
+	Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+}
+
+func TestBlockConverter_Checkpoint(t_ *testing.T) {
+	t := newBlockConverterTest(t_)
+	ch, err := t.Start(0, 1)
+	assert.NoError(t, err)
+	res := <-ch
+	res = <-ch
+	var blockHash, result []byte
+	assertBlockTransaction(t, res, 1, 1, func(r *BTX) {
+		blockHash = r.BlockHash
+		result = r.Result
+	})
+	cp := t.Status().Checkpoint()
+	assert.EqualValues(t, 1, cp.Height)
+	assert.Equal(t, blockHash, cp.BlockHash)
+	assert.Equal(t, result, cp.Result)
+	assert.EqualValues(t, 0, cp.Mismatches)
+
+	// the checkpoint is restored with the database
+	t = newBlockConverterTestWithDB(t_, t.chain.Database())
+	assert.Equal(t, cp, t.Status().Checkpoint())
+}
+
+func TestBlockConverter_CheckpointAfterCrash(t_ *testing.T) {
+	t := newBlockConverterTest(t_)
+	ch, err := t.Start(0, 1)
+	assert.NoError(t, err)
+	<-ch
+	<-ch
+	cp := t.Status().Checkpoint()
+	assert.EqualValues(t, 1, cp.Height)
+	assert.EqualValues(t, 1, cp.Receipts)
+
+	// crash after writing the checkpoint, before writing the last height
+	bk, err := db.NewCodedBucket(t.chain.Database(), db.ChainProperty, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, bk.Set(db.Raw(lcimporter.KeyLastBlockHeight), int64(0)))
+
+	t = newBlockConverterTestWithDB(t_, t.chain.Database())
+	ch, err = t.Start(1, 1)
+	assert.NoError(t, err)
+	res := <-ch
+	assertBlockTransaction(t, res, 1, 1, nil)
+	assert.Equal(t, cp, t.Status().Checkpoint())
+}
+
+func TestBlockConverter_RetryOnStoreFailure(t_ *testing.T) {
+	setStoreRetryDelay(t_, time.Millisecond)
+	for name, err := range map[string]error{
+		"Transport":    errConnRefused,
+		"HTTP":         client.NewHttpError(&http.Response{Status: "503 Service Unavailable", Body: http.NoBody}),
+		"Availability": errors.ErrNotFound,
+	} {
+		t_.Run(name, func(t_ *testing.T) {
+			t := newFlakyBlockConverterTest(t_, 2, err)
+			ch, err := t.Start(0, 0)
+			assert.NoError(t, err)
+			res := <-ch
+			assertBlockTransaction(t, res, 0, 1, func(r *BTX) {
+				assert.Equal(t, t.emptyResult, r.Result)
+			})
+			assert.Contains(t, t.Status().String(), "storeFailures=2")
+		})
+	}
+}
+
+func TestBlockConverter_NoRetryOnInvalidData(t_ *testing.T) {
+	setStoreRetryDelay(t_, time.Millisecond)
+	t := newFlakyBlockConverterTest(t_, 1, errors.IllegalArgumentError.New("InvalidBlock"))
+	ch, err := t.Start(0, 0)
+	assert.NoError(t, err)
+	res := <-ch
+	assert.True(t, errors.IllegalArgumentError.Equals(res.(error)))
+	assert.Contains(t, t.Status().String(), "storeFailures=0")
+}
+
+func TestBlockConverter_RetryLimitOnStoreFailure(t_ *testing.T) {
+	setStoreRetryDelay(t_, time.Millisecond)
+	t := newFlakyBlockConverterTest(t_, lcimporter.StoreRetryLimit+1, errConnRefused)
+	ch, err := t.Start(0, 0)
+	assert.NoError(t, err)
+	res := <-ch
+	assert.Error(t, res.(error))
+	assert.Contains(t, t.Status().String(),
+		fmt.Sprintf("storeFailures=%d", lcimporter.StoreRetryLimit))
+}
+
 func TestBlockConverter_Term(t_ *testing.T) {
 	t := newBlockConverterTest(t_)
 	ch, err := t.Start(0, 1)
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2021 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcimporter
+
+import (
+	"bytes"
+	"net"
+	"time"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const KeyCheckpoint = "block.checkpoint"
+
+var (
+	StoreRetryDelay    = time.Second
+	StoreRetryMaxDelay = time.Minute
+	StoreRetryLimit    = 10
+)
+
+// Checkpoint is the position of the import. Result is the result of the
+// block at Height, which is verified with receipts of ICON1. Receipts is
+// the number of receipts verified, and Mismatches is the number of blocks
+// failed on verification.
+type Checkpoint struct {
+	Height       int64
+	BlockHash    []byte
+	Result       []byte
+	Receipts     int64
+	Mismatches   int64
+	LastMismatch int64
+}
+
+func (e *BlockConverter) loadCheckpoint() error {
+	bs, err := e.chainBucket.Get([]byte(KeyCheckpoint))
+	if err != nil || len(bs) == 0 {
+		return err
+	}
+	cp := new(Checkpoint)
+	if _, err = codec.BC.UnmarshalFromBytes(bs, cp); err != nil {
+		return errors.CriticalFormatError.Wrap(err, "InvalidCheckpoint")
+	}
+	e.status.setCheckpoint(cp)
+	return nil
+}
+
+func (e *BlockConverter) writeCheckpoint(cp *Checkpoint) error {
+	bs, err := codec.BC.MarshalToBytes(cp)
+	if err != nil {
+		return err
+	}
+	return e.chainBucket.Set([]byte(KeyCheckpoint), bs)
+}
+
+// verifyCheckpoint checks whether the block stored at the last height is
+// the one in the checkpoint before resuming the import.
+func (e *BlockConverter) verifyCheckpoint(last int64) error {
+	cp := e.status.Checkpoint()
+	if cp.Height != last || last < 0 {
+		return nil
+	}
+	blk, err := e.GetBlockByHeight(last)
+	if err != nil {
+		return err
+	}
+	if blk == nil {
+		return errors.NotFoundError.Errorf("NoBlockForCheckpoint(height=%d)", last)
+	}
+	if !bytes.Equal(blk.Hash(), cp.BlockHash) || !bytes.Equal(blk.Result(), cp.Result) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidCheckpoint(height=%d,hash=%#x,result=%#x,exp_hash=%#x,exp_result=%#x)",
+			last, blk.Hash(), blk.Result(), cp.BlockHash, cp.Result)
+	}
+	return nil
+}
+
+// storeFailure is the failure in getting data from the store, which may be
+// recovered by retrying later.
+type storeFailure struct {
+	error
+}
+
+// toStoreFailure returns storeFailure for the error caused by the transport
+// or the availability of the data in the store. Other errors, like invalid
+// data, are returned as they are.
+func toStoreFailure(err error) error {
+	cause := errors.FindCause(err, func(err error) bool {
+		if errors.NotFoundError.Equals(err) {
+			return true
+		}
+		switch err.(type) {
+		case net.Error, *client.HttpError:
+			return true
+		}
+		return false
+	})
+	if cause != nil {
+		return &storeFailure{err}
+	}
+	return err
+}
+
+func (e *storeFailure) Unwrap() error {
+	return e.error
+}
+
+func isStoreFailure(err error) bool {
+	return errors.FindCause(err, func(err error) bool {
+		_, ok := err.(*storeFailure)
+		return ok
+	}) != nil
+}
+
+func nextStoreRetryDelay(delay time.Duration) time.Duration {
+	if delay *= 2; delay > StoreRetryMaxDelay {
+		return StoreRetryMaxDelay
+	}
+	return delay
+}
@@ -69,10 +69,19 @@ type Executor struct {
 	consumer consumeID
 	pending  *sync.Cond
 	bc       IBlockConverter
+	status   *Status
 
 	acc hexary.Accumulator
 }
 
+// GetStatus returns the status of the block conversion if it's available.
+func (e *Executor) GetStatus() string {
+	if e.status == nil {
+		return ""
+	}
+	return e.status.String()
+}
+
 func (e *Executor) candidateInLock(from int64) ([]*BlockTransaction, error) {
 	if e.start > from {
 		if err := e.rebaseInLock(from, -1, nil); err != nil {
@@ -538,5 +547,10 @@ func NewExecutor(chain module.Chain, dbase db.Database, cfg *Config) (*Executor,
 		return nil, err
 	}
 
-	return NewExecutorWithBC(rdb, idb, logger, bc)
+	ex, err := NewExecutorWithBC(rdb, idb, logger, bc)
+	if err != nil {
+		return nil, err
+	}
+	ex.status = bc.Status()
+	return ex, nil
 }
@@ -226,6 +226,18 @@ func (sm *ServiceManager) GetTotalSupply(result []byte) (*big.Int, error) {
 	return nil, errors.ErrInvalidState
 }
 
+func (sm *ServiceManager) GetStorageUsages(result []byte, limit int) ([]*module.ContractStorageUsage, error) {
+	return nil, errors.ErrInvalidState
+}
+
+func (sm *ServiceManager) GetRewardHistory(addr module.Address, start int64, limit int) (interface{}, error) {
+	return nil, errors.ErrInvalidState
+}
+
+func (sm *ServiceManager) GetValidationHistory(result []byte, owner module.Address, start, end int64, limit int) (interface{}, error) {
+	return nil, errors.ErrInvalidState
+}
+
 func (sm *ServiceManager) GetNetworkID(result []byte) (int64, error) {
 	// It doesn't store NID and CID, so return configuration value.
 	return int64(sm.ch.NID()), nil
@@ -260,6 +272,18 @@ func (sm *ServiceManager) HasTransaction(id []byte) bool {
 	return false
 }
 
+func (sm *ServiceManager) GetPendingTransactions(g module.TransactionGroup, from module.Address, offset, limit int) ([]*module.PendingTransaction, int) {
+	return []*module.PendingTransaction{}, 0
+}
+
+func (sm *ServiceManager) GetPendingTransaction(id []byte) (*module.PendingTransaction, error) {
+	return nil, errors.NotFoundError.Errorf("NotInPool(id=%#x)", id)
+}
+
+func (sm *ServiceManager) GetTransactionPoolUsage() []module.TransactionPoolUsage {
+	return []module.TransactionPoolUsage{}
+}
+
 func (sm *ServiceManager) SendTransactionAndWait(result []byte, height int64, tx interface{}) ([]byte, <-chan interface{}, error) {
 	return nil, nil, errors.ErrInvalidState
 }
@@ -276,7 +300,11 @@ func (sm *ServiceManager) ImportResult(result []byte, vh []byte, src db.Database
 	return errors.ErrInvalidState
 }
 
-func (sm *ServiceManager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo) (module.Receipt, error) {
+func (sm *ServiceManager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo, ti *module.TraceInfo) (module.Receipt, error) {
+	return nil, errors.ErrInvalidState
+}
+
+func (sm *ServiceManager) SimulateTransactions(result []byte, vh []byte, txs [][]byte, bi module.BlockInfo) ([]module.Receipt, error) {
 	return nil, errors.ErrInvalidState
 }
 
@@ -337,11 +365,16 @@ func (sm *ServiceManager) GetStatus() string {
 	sm.lock.Lock()
 	defer sm.lock.Unlock()
 
+	var state string
 	if sm.finishedInLock() {
-		return fmt.Sprintf("%d finished", sm.next)
+		state = fmt.Sprintf("%d finished", sm.next)
 	} else {
-		return fmt.Sprintf("%d running", sm.next)
+		state = fmt.Sprintf("%d running", sm.next)
+	}
+	if status := sm.ex.GetStatus(); len(status) > 0 {
+		state += " " + status
 	}
+	return state
 }
 
 func NewServiceManagerWithExecutor(chain module.Chain, ex *Executor, ps BlockV1ProofStorage, vs []*common.Address, cb ImportCallback) (*ServiceManager, error) {
@@ -17,9 +17,12 @@
 package lcimporter
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/server/metric"
 )
 
 const (
@@ -40,3 +43,81 @@ func Statusf(l log.Logger, format string, args ...interface{}) {
 		statusDisplay = true
 	}
 }
+
+// Status is the progress of the import. It's updated by BlockConverter, and
+// reported with ServiceManager.GetStatus and metrics.
+type Status struct {
+	lock          sync.Mutex
+	metric        *metric.LCImportMetric
+	checkpoint    Checkpoint
+	storeFailures int64
+}
+
+// Checkpoint returns the last checkpoint.
+func (s *Status) Checkpoint() Checkpoint {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.checkpoint
+}
+
+func (s *Status) setCheckpoint(cp *Checkpoint) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.checkpoint = *cp
+}
+
+// onBlock updates the checkpoint with the imported block and the number of
+// receipts verified with it, then returns the checkpoint to be written.
+// Receipts of a block are counted only once, even if the block is imported
+// again after a crash before the last height is written, or a rebase.
+func (s *Status) onBlock(height int64, hash, result []byte, receipts int) *Checkpoint {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if height > s.checkpoint.Height {
+		s.checkpoint.Height = height
+		s.checkpoint.Receipts += int64(receipts)
+	} else {
+		receipts = 0
+	}
+	if height == s.checkpoint.Height {
+		s.checkpoint.BlockHash = hash
+		s.checkpoint.Result = result
+	}
+	s.metric.OnBlock(height, receipts)
+	cp := s.checkpoint
+	return &cp
+}
+
+// onMismatch records the mismatch of results at the height, then returns
+// the checkpoint to be written.
+func (s *Status) onMismatch(height int64) *Checkpoint {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.checkpoint.Mismatches += 1
+	s.checkpoint.LastMismatch = height
+	s.metric.OnMismatch(height)
+	cp := s.checkpoint
+	return &cp
+}
+
+func (s *Status) onStoreFailure(height int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.storeFailures += 1
+	s.metric.OnStoreFailure(height)
+}
+
+func (s *Status) String() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return fmt.Sprintf("imported=%d receipts=%d mismatches=%d storeFailures=%d",
+		s.checkpoint.Height, s.checkpoint.Receipts, s.checkpoint.Mismatches,
+		s.storeFailures)
+}
+
+func newStatus(ctx context.Context) *Status {
+	return &Status{
+		metric:     metric.NewLCImportMetric(ctx),
+		checkpoint: Checkpoint{Height: -1},
+	}
+}
@@ -28,6 +28,8 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/common/merkle"
 	"github.com/icon-project/goloop/consensus"
@@ -49,6 +51,7 @@ import (
 
 type platform struct {
 	calculator iiss.CalculatorHolder
+	validation iiss.ValidationRecorder
 	base       string
 }
 
@@ -112,9 +115,12 @@ func (p *platform) NewBaseTransaction(wc state.WorldContext) (module.Transaction
 	return tx, nil
 }
 
-func (p *platform) OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, logger log.Logger) {
+func (p *platform) OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, bi module.BlockInfo, logger log.Logger) {
 	// Start background calculator if it's not started.
 	p.calculator.Start(ess, logger)
+	if bi != nil {
+		p.validation.OnFinalize(ess, bi.Height(), logger)
+	}
 }
 
 func (p *platform) OnExecutionBegin(wc state.WorldContext, logger log.Logger) error {
@@ -213,6 +219,59 @@ func (t *platform) CommitVoteSetDecoder() module.CommitVoteSetDecoder {
 	}
 }
 
+// GetRewardHistory returns rewards of the account for each term, which are
+// kept only in the nodes configured to do so.
+func (p *platform) GetRewardHistory(dbase db.Database, addr module.Address, start int64, limit int) (interface{}, error) {
+	if !iiss.IsRewardHistoryEnabled(dbase) {
+		return nil, errors.UnsupportedError.New("RewardHistoryDisabled")
+	}
+	history, next, err := iiss.GetRewardHistory(dbase, addr, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	terms := make([]interface{}, len(history))
+	for i, h := range history {
+		terms[i] = h.ToJSON()
+	}
+	jso := map[string]interface{}{
+		"address": addr,
+		"history": terms,
+	}
+	if next != 0 {
+		jso["next"] = intconv.FormatInt(next)
+	}
+	return jso, nil
+}
+
+// GetValidationHistory returns block validation of the P-Rep for each term,
+// which is kept only in the nodes configured to do so.
+func (p *platform) GetValidationHistory(dbase db.Database, ess state.ExtensionSnapshot, owner module.Address, start, end int64, limit int) (interface{}, error) {
+	if !iiss.IsValidationHistoryEnabled(dbase) {
+		return nil, errors.UnsupportedError.New("ValidationHistoryDisabled")
+	}
+	history, next, err := iiss.GetValidationHistory(dbase, owner, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	terms := make([]interface{}, len(history))
+	for i, h := range history {
+		terms[i] = h.ToJSON()
+	}
+	jso := map[string]interface{}{
+		"address": owner,
+		"history": terms,
+	}
+	// the condition is available after the extension is initialized
+	if essi, ok := ess.(*iiss.ExtensionSnapshotImpl); ok && essi != nil {
+		condition := essi.State().NewState(true).GetValidationPenaltyCondition()
+		jso["validationPenaltyCondition"] = intconv.FormatInt(condition)
+	}
+	if next != 0 {
+		jso["next"] = intconv.FormatInt(next)
+	}
+	return jso, nil
+}
+
 const (
 	BlockV1ProofFile = "block_v1_proof.bin"
 )
@@ -23,7 +23,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/icon/blockv0"
 	"github.com/icon-project/goloop/icon/lcimporter"
@@ -52,4 +56,52 @@ func TestPlatform_BlockV1Proof(t *testing.T) {
 	assert.Equal(t, votes.Hash(), votes2.Hash())
 	assert.Equal(t, root, mh2.RootHash)
 	assert.Equal(t, height, mh2.Leaves)
-}
\ No newline at end of file
+}
+
+func TestPlatform_RewardHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "platform*")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plt, err := NewPlatform(dir, 1)
+	assert.NoError(t, err)
+	hr := plt.(base.HistoryReader)
+	addr := common.MustNewAddressFromString("hx1")
+
+	database := db.NewMapDB()
+	_, err = hr.GetRewardHistory(database, addr, 0, 10)
+	assert.True(t, errors.UnsupportedError.Equals(err))
+
+	database = db.WithFlags(database, db.Flags{base.FlagRewardHistory: true})
+	res, err := hr.GetRewardHistory(database, addr, 0, 10)
+	assert.NoError(t, err)
+	jso := res.(map[string]interface{})
+	assert.Equal(t, addr, jso["address"])
+	assert.Len(t, jso["history"], 0)
+	assert.NotContains(t, jso, "next")
+}
+
+func TestPlatform_ValidationHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "platform*")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plt, err := NewPlatform(dir, 1)
+	assert.NoError(t, err)
+	hr := plt.(base.HistoryReader)
+	owner := common.MustNewAddressFromString("hx1")
+
+	database := db.NewMapDB()
+	ess := plt.NewExtensionSnapshot(database, nil)
+	_, err = hr.GetValidationHistory(database, ess, owner, 0, 0, 10)
+	assert.True(t, errors.UnsupportedError.Equals(err))
+
+	database = db.WithFlags(database, db.Flags{base.FlagValidationHistory: true})
+	res, err := hr.GetValidationHistory(database, ess, owner, 0, 0, 10)
+	assert.NoError(t, err)
+	jso := res.(map[string]interface{})
+	assert.Equal(t, owner, jso["address"])
+	assert.Len(t, jso["history"], 0)
+	assert.NotContains(t, jso, "validationPenaltyCondition")
+	assert.NotContains(t, jso, "next")
+}
@@ -123,6 +123,23 @@ type BlockManager interface {
 	// NewConsensusInfo returns a ConsensusInfo with blk's proposer and
 	// votes in blk.
 	NewConsensusInfo(blk Block) (ConsensusInfo, error)
+
+	// GetBlockAccumulator returns the accumulator of block hashes after
+	// the block at the height is finalized.
+	GetBlockAccumulator(height int64) (*BlockAccumulator, error)
+
+	// GetBlockWitness returns the witness proving that the block at the
+	// height is in the accumulator after the block at the height at.
+	GetBlockWitness(height, at int64) ([][]byte, error)
+}
+
+// BlockAccumulator is the merkle accumulator of hashes of blocks from Base
+// to Height. Roots[h] is hash of the root at height h, or nil if there is
+// no root at the height.
+type BlockAccumulator struct {
+	Height int64
+	Base   int64
+	Roots  [][]byte
 }
 
 type TransactionInfo interface {
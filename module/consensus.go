@@ -4,6 +4,16 @@ type ConsensusStatus struct {
 	Height   int64
 	Round    int32
 	Proposer bool
+
+	// FastSync is not nil while blocks are fetched from peers.
+	FastSync *FastSyncStatus
+}
+
+type FastSyncStatus struct {
+	// Start is the height where fetching is started.
+	Start int64
+	// Target is the highest height reported by peers.
+	Target int64
 }
 
 type Consensus interface {
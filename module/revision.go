@@ -32,6 +32,8 @@ const (
 	LegacyInputJSON
 	LegacyNoTimeout
 	FixLostFeeByDeposit
+	TrackStorageUsage
+	ScheduledCallback
 	LastRevisionBit
 )
 
@@ -86,6 +88,14 @@ func (r Revision) LegacyBalanceCheck() bool {
 	return (r & LegacyBalanceCheck) != 0
 }
 
+func (r Revision) TrackStorageUsage() bool {
+	return (r & TrackStorageUsage) != 0
+}
+
+func (r Revision) ScheduledCallback() bool {
+	return (r & ScheduledCallback) != 0
+}
+
 func (r Revision) Has(flag Revision) bool {
 	return (r & flag) != 0
 }
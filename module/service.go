@@ -3,6 +3,7 @@ package module
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/icon-project/goloop/common/db"
 )
@@ -331,6 +332,20 @@ type ServiceManager interface {
 	// GetTotalSupply returns total supplied coin
 	GetTotalSupply(result []byte) (*big.Int, error)
 
+	// GetStorageUsages returns storage usages of the contracts using the
+	// largest bytes in descending order. Only contracts tracking the usage
+	// after the revision are included.
+	GetStorageUsages(result []byte, limit int) ([]*ContractStorageUsage, error)
+
+	// GetRewardHistory returns rewards of the account for each term kept
+	// by the node. It returns UnsupportedError if the node doesn't keep it.
+	GetRewardHistory(addr Address, start int64, limit int) (interface{}, error)
+
+	// GetValidationHistory returns block validation of the validator for
+	// each term kept by the node, with the configuration of the state.
+	// It returns UnsupportedError if the node doesn't keep it.
+	GetValidationHistory(result []byte, owner Address, start, end int64, limit int) (interface{}, error)
+
 	// GetNetworkID returns network ID of the state
 	GetNetworkID(result []byte) (int64, error)
 
@@ -355,6 +370,19 @@ type ServiceManager interface {
 	// HasTransaction returns whether it has specified transaction in the pool
 	HasTransaction(id []byte) bool
 
+	// GetPendingTransactions returns transactions in the pool of the group
+	// in order of candidates, skipping offset transactions. If from is not
+	// nil, it returns transactions of the sender only. It also returns the
+	// number of matched transactions in the pool.
+	GetPendingTransactions(g TransactionGroup, from Address, offset, limit int) ([]*PendingTransaction, int)
+
+	// GetPendingTransaction returns the transaction in the pool with the
+	// status. It returns errors.NotFoundError if it's not in the pool.
+	GetPendingTransaction(id []byte) (*PendingTransaction, error)
+
+	// GetTransactionPoolUsage returns usage of the pools for each group.
+	GetTransactionPoolUsage() []TransactionPoolUsage
+
 	// SendTransactionAndWait send transaction and return channel for result
 	SendTransactionAndWait(result []byte, height int64, tx interface{}) ([]byte, <-chan interface{}, error)
 
@@ -371,8 +399,54 @@ type ServiceManager interface {
 
 	// ExecuteTransaction executes the transaction on the specified state.
 	// Then it returns the expected result of the transaction.
-	// It ignores supplied step limit.
-	ExecuteTransaction(result []byte, vh []byte, js []byte, bi BlockInfo) (Receipt, error)
+	// It ignores supplied step limit. If ti is not nil, the execution is
+	// traced with its callback.
+	ExecuteTransaction(result []byte, vh []byte, js []byte, bi BlockInfo, ti *TraceInfo) (Receipt, error)
+
+	// SimulateTransactions executes the transactions in order on the
+	// specified state. Each transaction is executed on the state changed by
+	// the previous ones, and the changes are discarded at the end.
+	// It ignores supplied step limits.
+	SimulateTransactions(result []byte, vh []byte, txs [][]byte, bi BlockInfo) ([]Receipt, error)
+}
+
+// Status of a transaction in the pool
+const (
+	PendingTxQueued         = "queued"
+	PendingTxWaitingBalance = "waitingBalance"
+	PendingTxExpired        = "expired"
+	PendingTxInvalid        = "invalid"
+)
+
+type PendingTransaction struct {
+	Transaction Transaction
+	Group       TransactionGroup
+
+	// Received is the time when it's received from the user. It's zero
+	// if it's received from other nodes.
+	Received time.Time
+
+	// Status is the status of the transaction in the pool.
+	Status string
+
+	// Reason is the last error on validating the transaction for a block.
+	Reason error
+}
+
+type TransactionPoolUsage struct {
+	Group TransactionGroup
+	Size  int
+	Used  int
+}
+
+// ContractStorageUsage is the number of keys and the total bytes of keys
+// and values stored by the contract.
+type ContractStorageUsage struct {
+	// Address is nil if the address of the contract is unknown.
+	Address      Address
+	DeployTxHash []byte
+	Keys         int64
+	Bytes        int64
 }
 
 type TraceInfo struct {
@@ -393,3 +467,15 @@ type TraceCallback interface {
 	OnLog(level TraceLevel, msg string)
 	OnEnd(e error)
 }
+
+// StepProfileCallback may be implemented by TraceCallback to get the profile
+// of used steps of the transaction. The profile is updated until the end of
+// the execution.
+type StepProfileCallback interface {
+	OnStepProfile(p StepProfile)
+}
+
+// StepProfile is breakdown of used steps by step types and call frames.
+type StepProfile interface {
+	ToJSON(version JSONVersion) (interface{}, error)
+}
@@ -472,7 +472,9 @@ func (p2p *PeerToPeer) onPacket(pkt *Packet, p *Peer) {
 
 		isBroadcast := pkt.dest == p2pDestAny && pkt.ttl == 0
 		if isBroadcast && isSourcePeer && !p.HasRole(p2pRoleRoot) {
-			p2p.logger.Infoln("onPacket", "Drop, Not authorized", p.ID(), pkt.protocol, pkt.subProtocol)
+			p2p.logger.WithFields(log.Fields{
+				log.FieldKeyPeer: p.ID(),
+			}).Infoln("onPacket", "Drop, Not authorized", pkt.protocol, pkt.subProtocol)
 			return
 		}
 
@@ -832,7 +834,9 @@ func (p2p *PeerToPeer) sendToPeers(ctx context.Context, peers *PeerSet) {
 		//p2p.packetRw.WriteTo(p.writer)
 		if err := p.send(ctx); err != nil && err != ErrDuplicatedPacket {
 			pkt := ctx.Value(p2pContextKeyPacket).(*Packet)
-			p2p.logger.Infoln("sendToPeers", err, pkt.protocol, pkt.subProtocol, p.ID())
+			p2p.logger.WithFields(log.Fields{
+				log.FieldKeyPeer: p.ID(),
+			}).Infoln("sendToPeers", err, pkt.protocol, pkt.subProtocol)
 		}
 	}
 }
@@ -922,7 +926,9 @@ func (p2p *PeerToPeer) sendToFriends(ctx context.Context) {
 		for _, p := range ps {
 			//p2p.packetRw.WriteTo(p.writer)
 			if err := p.send(ctx); err != nil && err != ErrDuplicatedPacket {
-				p2p.logger.Infoln("sendToFriends", err, pkt.protocol, pkt.subProtocol, p.ID())
+				p2p.logger.WithFields(log.Fields{
+					log.FieldKeyPeer: p.ID(),
+				}).Infoln("sendToFriends", err, pkt.protocol, pkt.subProtocol)
 			}
 		}
 	} else {
@@ -94,7 +94,7 @@ func newPeer(conn net.Conn, cbFunc packetCbFunc, in bool, dial NetAddress, l log
 		attr:        make(map[string]interface{}),
 		dial:        dial,
 	}
-	p.logger = l.WithFields(log.Fields{"peer": p.ID()})
+	p.logger = l.WithFields(log.Fields{log.FieldKeyPeer: p.ID()})
 	p.setPacketCbFunc(cbFunc)
 
 	return p
@@ -6,9 +6,11 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"time"
 
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/jsonrpc"
 )
 
@@ -110,10 +112,25 @@ type RuntimeConfig struct {
 	RPCDefaultChannel string `json:"rpcDefaultChannel"`
 	RPCIncludeDebug   bool   `json:"rpcIncludeDebug"`
 	RPCBatchLimit     int    `json:"rpcBatchLimit"`
+	RPCSimulateLimit  int    `json:"rpcSimulateLimit"`
+	RPCBatchWorkers   int    `json:"rpcBatchWorkers"`
+	RPCBatchWaiters   int    `json:"rpcBatchWaiters"`
+
+	ReadyMaxBlockAge  int   `json:"readyMaxBlockAge"`
+	ReadyMaxHeightLag int64 `json:"readyMaxHeightLag"`
+	ReadyMinPeers     int   `json:"readyMinPeers"`
 
 	FilePath string `json:"-"` // absolute path
 }
 
+func (c *RuntimeConfig) ReadyConfig() server.ReadyConfig {
+	return server.ReadyConfig{
+		MaxBlockAge:  time.Duration(c.ReadyMaxBlockAge) * time.Second,
+		MaxHeightLag: c.ReadyMaxHeightLag,
+		MinPeers:     c.ReadyMinPeers,
+	}
+}
+
 func (c *RuntimeConfig) load() error {
 	log.Println("load ", c.FilePath)
 	if _, err := os.Stat(c.FilePath); err != nil {
@@ -127,6 +144,15 @@ func (c *RuntimeConfig) load() error {
 	if err = json.Unmarshal(b, c); err != nil {
 		return err
 	}
+	// Before costs of methods(with batch workers), rpcBatchLimit was the
+	// number of items in a batch request.
+	var keys map[string]json.RawMessage
+	if err = json.Unmarshal(b, &keys); err != nil {
+		return err
+	}
+	if _, ok := keys["rpcBatchWorkers"]; !ok {
+		c.RPCBatchLimit *= jsonrpc.DefaultMethodCost
+	}
 	return nil
 }
 func (c *RuntimeConfig) save() error {
@@ -143,9 +169,14 @@ func (c *RuntimeConfig) save() error {
 
 func loadRuntimeConfig(baseDir string) (*RuntimeConfig, error) {
 	cfg := &RuntimeConfig{
-		EEInstances: DefaultEEInstances,
-		RPCBatchLimit: jsonrpc.DefaultBatchLimit,
-		FilePath:    path.Join(baseDir, "rconfig.json"),
+		EEInstances:       DefaultEEInstances,
+		RPCBatchLimit:     jsonrpc.DefaultBatchLimit,
+		RPCSimulateLimit:  jsonrpc.DefaultSimulateLimit,
+		RPCBatchWorkers:   jsonrpc.DefaultBatchWorkers,
+		RPCBatchWaiters:   jsonrpc.DefaultBatchWaiters,
+		ReadyMaxBlockAge:  int(server.DefaultReadyMaxBlockAge / time.Second),
+		ReadyMaxHeightLag: server.DefaultReadyMaxHeightLag,
+		FilePath:          path.Join(baseDir, "rconfig.json"),
 	}
 	if err := cfg.load(); err != nil {
 		if os.IsNotExist(err) {
@@ -0,0 +1,31 @@
+package node
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+func TestRuntimeConfig_BatchLimit(t *testing.T) {
+	dir := t.TempDir()
+
+	// new configuration
+	cfg, err := loadRuntimeConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, jsonrpc.DefaultBatchLimit, cfg.RPCBatchLimit)
+	cfg, err = loadRuntimeConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, jsonrpc.DefaultBatchLimit, cfg.RPCBatchLimit)
+
+	// configuration saved before costs of methods
+	err = ioutil.WriteFile(path.Join(dir, "rconfig.json"),
+		[]byte(`{"eeInstances":1,"rpcBatchLimit":10,"rpcSimulateLimit":100}`), 0644)
+	assert.NoError(t, err)
+	cfg, err = loadRuntimeConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*jsonrpc.DefaultMethodCost, cfg.RPCBatchLimit)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/icon-project/goloop/network"
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/server/sink"
 	"github.com/icon-project/goloop/service/eeproxy"
 )
 
@@ -408,29 +409,34 @@ func (n *Node) JoinChain(
 	cfgFile, _ := filepath.Abs(path.Join(chainDir, ChainConfigFileName))
 
 	cfg := &chain.Config{
-		NID:              nid,
-		DBType:           p.DBType,
-		Platform:         p.Platform,
-		Channel:          channel,
-		SecureSuites:     p.SecureSuites,
-		SecureAeads:      p.SecureAeads,
-		SeedAddr:         p.SeedAddr,
-		Role:             p.Role,
-		GenesisStorage:   genesisStorage,
-		ConcurrencyLevel: p.ConcurrencyLevel,
-		NormalTxPoolSize: p.NormalTxPoolSize,
-		PatchTxPoolSize:  p.PatchTxPoolSize,
-		MaxBlockTxBytes:  p.MaxBlockTxBytes,
-		NodeCache:        p.NodeCache,
-		DefWaitTimeout:   p.DefWaitTimeout,
-		MaxWaitTimeout:   p.MaxWaitTimeout,
-		TxTimeout:        p.TxTimeout,
-		AutoStart:        p.AutoStart,
-		FilePath:         cfgFile,
-		NIDForP2P:        n.cfg.NIDForP2P,
-		ChildrenLimit:    p.ChildrenLimit,
-		NephewsLimit:     p.NephewsLimit,
-		ValidateTxOnSend: p.ValidateTxOnSend,
+		NID:               nid,
+		DBType:            p.DBType,
+		Platform:          p.Platform,
+		Channel:           channel,
+		SecureSuites:      p.SecureSuites,
+		SecureAeads:       p.SecureAeads,
+		SeedAddr:          p.SeedAddr,
+		Role:              p.Role,
+		GenesisStorage:    genesisStorage,
+		ConcurrencyLevel:  p.ConcurrencyLevel,
+		NormalTxPoolSize:  p.NormalTxPoolSize,
+		PatchTxPoolSize:   p.PatchTxPoolSize,
+		MaxBlockTxBytes:   p.MaxBlockTxBytes,
+		NodeCache:         p.NodeCache,
+		FlatState:         p.FlatState,
+		RewardHistory:     p.RewardHistory,
+		ValidationHistory: p.ValidationHistory,
+		Observer:          p.Observer,
+		DefWaitTimeout:    p.DefWaitTimeout,
+		MaxWaitTimeout:    p.MaxWaitTimeout,
+		TxTimeout:         p.TxTimeout,
+		AutoStart:         p.AutoStart,
+		FilePath:          cfgFile,
+		NIDForP2P:         n.cfg.NIDForP2P,
+		ChildrenLimit:     p.ChildrenLimit,
+		NephewsLimit:      p.NephewsLimit,
+		ValidateTxOnSend:  p.ValidateTxOnSend,
+		EventSinks:        p.EventSinks,
 	}
 
 	if err := n.saveChainConfig(cfg, cfgFile); err != nil {
@@ -714,6 +720,30 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 				return errors.Errorf("InvalidNodeCacheOption(%s)", value)
 			}
 			c.cfg.NodeCache = value
+		case "flatState":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.FlatState = bc
+			}
+		case "rewardHistory":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.RewardHistory = bc
+			}
+		case "validationHistory":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.ValidationHistory = bc
+			}
+		case "observer":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.Observer = bc
+			}
 		case "defaultWaitTimeout":
 			if intVal, err := strconv.ParseInt(value, 0, 64); err != nil {
 				return errors.Wrapf(err, "invalid value type")
@@ -782,6 +812,12 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.ValidateTxOnSend = bc
 			}
+		case "eventSinks":
+			if sinks, err := sink.ParseConfigs([]byte(value)); err != nil {
+				return err
+			} else {
+				c.cfg.EventSinks = sinks
+			}
 		default:
 			return errors.Errorf("not found key %s", key)
 		}
@@ -894,6 +930,48 @@ func (n *Node) Configure(key string, value string) error {
 			n.rcfg.RPCBatchLimit = intVal
 		}
 		n.srv.SetBatchLimit(n.rcfg.RPCBatchLimit)
+	case "rpcSimulateLimit":
+		if intVal, err := strconv.Atoi(value); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.RPCSimulateLimit = intVal
+		}
+		n.srv.SetSimulateLimit(n.rcfg.RPCSimulateLimit)
+	case "rpcBatchWorkers":
+		if intVal, err := strconv.Atoi(value); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.RPCBatchWorkers = intVal
+		}
+		n.srv.SetBatchWorkers(n.rcfg.RPCBatchWorkers)
+	case "rpcBatchWaiters":
+		if intVal, err := strconv.Atoi(value); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.RPCBatchWaiters = intVal
+		}
+		n.srv.SetBatchWaiters(n.rcfg.RPCBatchWaiters)
+	case "readyMaxBlockAge":
+		if intVal, err := strconv.Atoi(value); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.ReadyMaxBlockAge = intVal
+		}
+		n.srv.SetReadyConfig(n.rcfg.ReadyConfig())
+	case "readyMaxHeightLag":
+		if intVal, err := strconv.ParseInt(value, 0, 64); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.ReadyMaxHeightLag = intVal
+		}
+		n.srv.SetReadyConfig(n.rcfg.ReadyConfig())
+	case "readyMinPeers":
+		if intVal, err := strconv.Atoi(value); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.ReadyMinPeers = intVal
+		}
+		n.srv.SetReadyConfig(n.rcfg.ReadyConfig())
 	default:
 		return errors.Errorf("not found key")
 	}
@@ -940,6 +1018,11 @@ func NewNode(
 	if err := pm.SetInstances(rcfg.EEInstances, rcfg.EEInstances, rcfg.EEInstances); err != nil {
 		log.Panicf("fail to EEManager.SetInstances err=%+v", err)
 	}
+	srv.SetEEManager(pm)
+	srv.SetReadyConfig(rcfg.ReadyConfig())
+	srv.SetSimulateLimit(rcfg.RPCSimulateLimit)
+	srv.SetBatchWorkers(rcfg.RPCBatchWorkers)
+	srv.SetBatchWaiters(rcfg.RPCBatchWaiters)
 	go func() {
 		if err := pm.Loop(); err != nil {
 			log.Panic(err)
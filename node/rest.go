@@ -22,6 +22,7 @@ import (
 	"github.com/icon-project/goloop/network"
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/server/sink"
 	"github.com/icon-project/goloop/service"
 )
 
@@ -78,25 +79,31 @@ type ChainInspectView struct {
 }
 
 type ChainConfig struct {
-	DBType           string `json:"dbType"`
-	Platform         string `json:"platform"`
-	SeedAddr         string `json:"seedAddress"`
-	Role             uint   `json:"role"`
-	ConcurrencyLevel int    `json:"concurrencyLevel,omitempty"`
-	NormalTxPoolSize int    `json:"normalTxPool,omitempty"`
-	PatchTxPoolSize  int    `json:"patchTxPool,omitempty"`
-	MaxBlockTxBytes  int    `json:"maxBlockTxBytes,omitempty"`
-	NodeCache        string `json:"nodeCache,omitempty"`
-	Channel          string `json:"channel"`
-	SecureSuites     string `json:"secureSuites"`
-	SecureAeads      string `json:"secureAeads"`
-	DefWaitTimeout   int64  `json:"defaultWaitTimeout"`
-	MaxWaitTimeout   int64  `json:"maxWaitTimeout"`
-	TxTimeout        int64  `json:"txTimeout"`
-	AutoStart        bool   `json:"autoStart"`
-	ChildrenLimit    *int   `json:"childrenLimit,omitempty"`
-	NephewsLimit     *int   `json:"nephewsLimit,omitempty"`
-	ValidateTxOnSend bool   `json:"validateTxOnSend,omitempty"`
+	DBType            string `json:"dbType"`
+	Platform          string `json:"platform"`
+	SeedAddr          string `json:"seedAddress"`
+	Role              uint   `json:"role"`
+	ConcurrencyLevel  int    `json:"concurrencyLevel,omitempty"`
+	NormalTxPoolSize  int    `json:"normalTxPool,omitempty"`
+	PatchTxPoolSize   int    `json:"patchTxPool,omitempty"`
+	MaxBlockTxBytes   int    `json:"maxBlockTxBytes,omitempty"`
+	NodeCache         string `json:"nodeCache,omitempty"`
+	FlatState         bool   `json:"flatState,omitempty"`
+	RewardHistory     bool   `json:"rewardHistory,omitempty"`
+	ValidationHistory bool   `json:"validationHistory,omitempty"`
+	Observer          bool   `json:"observer,omitempty"`
+	Channel           string `json:"channel"`
+	SecureSuites      string `json:"secureSuites"`
+	SecureAeads       string `json:"secureAeads"`
+	DefWaitTimeout    int64  `json:"defaultWaitTimeout"`
+	MaxWaitTimeout    int64  `json:"maxWaitTimeout"`
+	TxTimeout         int64  `json:"txTimeout"`
+	AutoStart         bool   `json:"autoStart"`
+	ChildrenLimit     *int   `json:"childrenLimit,omitempty"`
+	NephewsLimit      *int   `json:"nephewsLimit,omitempty"`
+	ValidateTxOnSend  bool   `json:"validateTxOnSend,omitempty"`
+
+	EventSinks []*sink.Config `json:"eventSinks,omitempty"`
 }
 
 type ChainImportParam struct {
@@ -155,25 +162,30 @@ func NewChainInspectView(c *Chain) *ChainInspectView {
 
 func NewChainConfig(cfg *chain.Config) *ChainConfig {
 	v := &ChainConfig{
-		DBType:           cfg.DBType,
-		Platform:         cfg.Platform,
-		SeedAddr:         cfg.SeedAddr,
-		Role:             cfg.Role,
-		ConcurrencyLevel: cfg.ConcurrencyLevel,
-		NormalTxPoolSize: cfg.NormalTxPoolSize,
-		PatchTxPoolSize:  cfg.PatchTxPoolSize,
-		MaxBlockTxBytes:  cfg.MaxBlockTxBytes,
-		NodeCache:        cfg.NodeCache,
-		Channel:          cfg.Channel,
-		SecureSuites:     cfg.SecureSuites,
-		SecureAeads:      cfg.SecureAeads,
-		DefWaitTimeout:   cfg.DefWaitTimeout,
-		MaxWaitTimeout:   cfg.MaxWaitTimeout,
-		TxTimeout:        cfg.TxTimeout,
-		AutoStart:        cfg.AutoStart,
-		ChildrenLimit:    cfg.ChildrenLimit,
-		NephewsLimit:     cfg.NephewsLimit,
-		ValidateTxOnSend: cfg.ValidateTxOnSend,
+		DBType:            cfg.DBType,
+		Platform:          cfg.Platform,
+		SeedAddr:          cfg.SeedAddr,
+		Role:              cfg.Role,
+		ConcurrencyLevel:  cfg.ConcurrencyLevel,
+		NormalTxPoolSize:  cfg.NormalTxPoolSize,
+		PatchTxPoolSize:   cfg.PatchTxPoolSize,
+		MaxBlockTxBytes:   cfg.MaxBlockTxBytes,
+		NodeCache:         cfg.NodeCache,
+		FlatState:         cfg.FlatState,
+		RewardHistory:     cfg.RewardHistory,
+		ValidationHistory: cfg.ValidationHistory,
+		Observer:          cfg.Observer,
+		Channel:           cfg.Channel,
+		SecureSuites:      cfg.SecureSuites,
+		SecureAeads:       cfg.SecureAeads,
+		DefWaitTimeout:    cfg.DefWaitTimeout,
+		MaxWaitTimeout:    cfg.MaxWaitTimeout,
+		TxTimeout:         cfg.TxTimeout,
+		AutoStart:         cfg.AutoStart,
+		ChildrenLimit:     cfg.ChildrenLimit,
+		NephewsLimit:      cfg.NephewsLimit,
+		ValidateTxOnSend:  cfg.ValidateTxOnSend,
+		EventSinks:        cfg.EventSinks,
 	}
 	return v
 }
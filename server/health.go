@@ -0,0 +1,282 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/eeproxy"
+)
+
+const (
+	UrlHealth = "/health"
+	UrlReady  = "/ready"
+	UrlStatus = "/status"
+
+	DefaultReadyMaxBlockAge  = 60 * time.Second
+	DefaultReadyMaxHeightLag = 10
+)
+
+// ReadyConfig is thresholds for readiness of channels.
+// Zero value disables the check.
+type ReadyConfig struct {
+	// MaxBlockAge is maximum age of the last block.
+	MaxBlockAge time.Duration
+	// MaxHeightLag is maximum difference between the last block height
+	// and the height reported by peers while it's fetching blocks.
+	MaxHeightLag int64
+	// MinPeers is minimum number of connected peers.
+	MinPeers int
+}
+
+type ConsensusSyncStatus struct {
+	Height   int64 `json:"height"`
+	Round    int32 `json:"round"`
+	Proposer bool  `json:"proposer"`
+}
+
+type FastSyncStatus struct {
+	Start  int64 `json:"start"`
+	Target int64 `json:"target"`
+	Remain int64 `json:"remain"`
+}
+
+type EngineStatus struct {
+	Type   string `json:"type"`
+	Active int    `json:"active"`
+	Ready  int    `json:"ready"`
+	Using  int    `json:"using"`
+}
+
+type SyncStatus struct {
+	Channel   string               `json:"channel"`
+	Ready     bool                 `json:"ready"`
+	Reasons   []string             `json:"reasons,omitempty"`
+	Height    int64                `json:"height"`
+	BlockHash string               `json:"blockHash,omitempty"`
+	BlockTime int64                `json:"blockTime"`
+	BlockAge  int64                `json:"blockAge"`
+	Consensus *ConsensusSyncStatus `json:"consensus,omitempty"`
+	FastSync  *FastSyncStatus      `json:"fastSync,omitempty"`
+	Peers     map[string]int       `json:"peers"`
+	Engines   []EngineStatus       `json:"engines,omitempty"`
+}
+
+type ReadyStatus struct {
+	Ready    bool          `json:"ready"`
+	Channels []*SyncStatus `json:"channels"`
+}
+
+type readyChecker struct {
+	lock sync.Mutex
+	cfg  ReadyConfig
+	eem  eeproxy.Manager
+}
+
+func (rc *readyChecker) config() ReadyConfig {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return rc.cfg
+}
+
+func (rc *readyChecker) engines() []EngineStatus {
+	rc.lock.Lock()
+	eem := rc.eem
+	rc.lock.Unlock()
+
+	if eem == nil {
+		return nil
+	}
+	ess := eem.Status()
+	res := make([]EngineStatus, len(ess))
+	for i, es := range ess {
+		res[i] = EngineStatus(es)
+	}
+	return res
+}
+
+var peerRoles = []module.Role{
+	module.ROLE_VALIDATOR,
+	module.ROLE_SEED,
+	module.ROLE_NORMAL,
+}
+
+// GetSyncStatus returns sync status of the chain and evaluates readiness
+// with the thresholds.
+func GetSyncStatus(chain module.Chain, cfg ReadyConfig, engines []EngineStatus, now time.Time) *SyncStatus {
+	s := &SyncStatus{
+		Channel: chain.Channel(),
+		Peers:   make(map[string]int),
+		Engines: engines,
+	}
+	if !chain.IsStarted() {
+		s.Reasons = append(s.Reasons, "ChainNotStarted")
+		return s
+	}
+	if bm := chain.BlockManager(); bm != nil {
+		if blk, err := bm.GetLastBlock(); err == nil {
+			s.Height = blk.Height()
+			s.BlockHash = fmt.Sprintf("%#x", blk.ID())
+			s.BlockTime = blk.Timestamp()
+			age := now.Sub(time.Unix(0, blk.Timestamp()*int64(time.Microsecond)))
+			if age < 0 {
+				age = 0
+			}
+			s.BlockAge = int64(age / time.Second)
+		}
+	}
+	if cs := chain.Consensus(); cs != nil {
+		if st := cs.GetStatus(); st != nil {
+			s.Consensus = &ConsensusSyncStatus{
+				Height:   st.Height,
+				Round:    st.Round,
+				Proposer: st.Proposer,
+			}
+			if st.FastSync != nil {
+				s.FastSync = &FastSyncStatus{
+					Start:  st.FastSync.Start,
+					Target: st.FastSync.Target,
+				}
+			}
+		}
+	}
+	if s.FastSync != nil && s.FastSync.Target > s.Height {
+		s.FastSync.Remain = s.FastSync.Target - s.Height
+	}
+	if nm := chain.NetworkManager(); nm != nil {
+		for _, role := range peerRoles {
+			s.Peers[string(role)] = len(nm.GetPeersByRole(role))
+		}
+		s.Peers["total"] = len(nm.GetPeers())
+	}
+	s.evaluate(cfg)
+	return s
+}
+
+func (s *SyncStatus) evaluate(cfg ReadyConfig) {
+	if s.BlockTime == 0 {
+		s.Reasons = append(s.Reasons, "NoBlock")
+	} else if cfg.MaxBlockAge > 0 && time.Duration(s.BlockAge)*time.Second > cfg.MaxBlockAge {
+		s.Reasons = append(s.Reasons,
+			fmt.Sprintf("BlockTooOld(age=%ds,max=%s)", s.BlockAge, cfg.MaxBlockAge))
+	}
+	if s.Consensus == nil {
+		s.Reasons = append(s.Reasons, "ConsensusNotRunning")
+	}
+	if s.FastSync != nil && cfg.MaxHeightLag > 0 && s.FastSync.Remain > cfg.MaxHeightLag {
+		s.Reasons = append(s.Reasons,
+			fmt.Sprintf("FarBehind(height=%d,target=%d)", s.Height, s.FastSync.Target))
+	}
+	if cfg.MinPeers > 0 && s.Peers["total"] < cfg.MinPeers {
+		s.Reasons = append(s.Reasons,
+			fmt.Sprintf("NotEnoughPeers(peers=%d,min=%d)", s.Peers["total"], cfg.MinPeers))
+	}
+	for _, e := range s.Engines {
+		if e.Active == 0 {
+			s.Reasons = append(s.Reasons,
+				fmt.Sprintf("EngineNotAvailable(type=%s)", e.Type))
+		}
+	}
+	s.Ready = len(s.Reasons) == 0
+}
+
+func (srv *Manager) SetReadyConfig(cfg ReadyConfig) {
+	srv.rc.lock.Lock()
+	defer srv.rc.lock.Unlock()
+	srv.rc.cfg = cfg
+}
+
+func (srv *Manager) ReadyConfig() ReadyConfig {
+	return srv.rc.config()
+}
+
+// SetEEManager sets the manager used to check availability of
+// execution engines.
+func (srv *Manager) SetEEManager(eem eeproxy.Manager) {
+	srv.rc.lock.Lock()
+	defer srv.rc.lock.Unlock()
+	srv.rc.eem = eem
+}
+
+func (srv *Manager) allChains() []module.Chain {
+	srv.mtx.RLock()
+	defer srv.mtx.RUnlock()
+
+	chains := make([]module.Chain, 0, len(srv.chains))
+	for _, c := range srv.chains {
+		chains = append(chains, c)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].Channel() < chains[j].Channel()
+	})
+	return chains
+}
+
+func (srv *Manager) chainSyncStatus(ctx echo.Context) (*SyncStatus, error) {
+	channel := ctx.Param("channel")
+	chain := srv.Chain(channel)
+	if chain == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound,
+			fmt.Sprintf("channel %s not found", channel))
+	}
+	return GetSyncStatus(chain, srv.rc.config(), srv.rc.engines(), time.Now()), nil
+}
+
+func (srv *Manager) RegisterHealthHandler(g *echo.Group) {
+	g.GET(UrlHealth, srv.handleHealth)
+	g.GET(UrlReady, srv.handleReady)
+	g.GET(UrlReady+"/:channel", srv.handleChainReady)
+	g.GET(UrlStatus+"/:channel", srv.handleChainStatus)
+}
+
+// handleHealth is for liveness. It only checks the server is responding.
+func (srv *Manager) handleHealth(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady returns whether all the running channels are ready.
+func (srv *Manager) handleReady(ctx echo.Context) error {
+	cfg := srv.rc.config()
+	engines := srv.rc.engines()
+	now := time.Now()
+	rs := &ReadyStatus{
+		Channels: []*SyncStatus{},
+	}
+	chains := srv.allChains()
+	rs.Ready = len(chains) > 0
+	for _, c := range chains {
+		s := GetSyncStatus(c, cfg, engines, now)
+		rs.Ready = rs.Ready && s.Ready
+		rs.Channels = append(rs.Channels, s)
+	}
+	if !rs.Ready {
+		return ctx.JSON(http.StatusServiceUnavailable, rs)
+	}
+	return ctx.JSON(http.StatusOK, rs)
+}
+
+func (srv *Manager) handleChainReady(ctx echo.Context) error {
+	s, err := srv.chainSyncStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if !s.Ready {
+		return ctx.JSON(http.StatusServiceUnavailable, s)
+	}
+	return ctx.JSON(http.StatusOK, s)
+}
+
+// handleChainStatus returns sync status of the channel regardless of
+// readiness.
+func (srv *Manager) handleChainStatus(ctx echo.Context) error {
+	s, err := srv.chainSyncStatus(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, s)
+}
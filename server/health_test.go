@@ -0,0 +1,65 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncStatus_evaluate(t *testing.T) {
+	cfg := ReadyConfig{
+		MaxBlockAge:  60 * time.Second,
+		MaxHeightLag: 10,
+		MinPeers:     1,
+	}
+	newStatus := func() *SyncStatus {
+		return &SyncStatus{
+			Height:    100,
+			BlockTime: 1,
+			BlockAge:  3,
+			Consensus: &ConsensusSyncStatus{Height: 101},
+			Peers:     map[string]int{"total": 2},
+			Engines:   []EngineStatus{{Type: "python", Active: 1}},
+		}
+	}
+
+	s := newStatus()
+	s.evaluate(cfg)
+	assert.True(t, s.Ready)
+	assert.Empty(t, s.Reasons)
+
+	s = newStatus()
+	s.BlockAge = 61
+	s.evaluate(cfg)
+	assert.False(t, s.Ready)
+	assert.Len(t, s.Reasons, 1)
+
+	s = newStatus()
+	s.BlockAge = 61
+	s.evaluate(ReadyConfig{})
+	assert.True(t, s.Ready)
+
+	s = newStatus()
+	s.FastSync = &FastSyncStatus{Start: 90, Target: 105, Remain: 5}
+	s.evaluate(cfg)
+	assert.True(t, s.Ready)
+
+	s = newStatus()
+	s.FastSync = &FastSyncStatus{Start: 90, Target: 200, Remain: 100}
+	s.evaluate(cfg)
+	assert.False(t, s.Ready)
+
+	s = newStatus()
+	s.Peers["total"] = 0
+	s.Consensus = nil
+	s.Engines[0].Active = 0
+	s.evaluate(cfg)
+	assert.False(t, s.Ready)
+	assert.Len(t, s.Reasons, 3)
+
+	s = newStatus()
+	s.BlockTime = 0
+	s.evaluate(cfg)
+	assert.False(t, s.Ready)
+}
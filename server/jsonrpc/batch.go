@@ -0,0 +1,224 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	DefaultMethodCost   = 10
+	DefaultBatchWorkers = 32
+	DefaultBatchWaiters = 8
+
+	MIMEApplicationNDJSON = "application/x-ndjson"
+)
+
+// IsNDJSON returns whether the media type(value of Accept header) is for
+// newline delimited JSON.
+func IsNDJSON(value string) bool {
+	for _, v := range strings.Split(value, ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		if mt == MIMEApplicationNDJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// workerPool limits the number of batch items handled at the same time.
+// It's shared by all batch requests of the repository.
+type workerPool struct {
+	slots chan struct{}
+}
+
+func newWorkerPool(size int) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	return &workerPool{slots: make(chan struct{}, size)}
+}
+
+// run waits for an idle worker, then runs f with it. It returns false
+// without running f if the request is cancelled while waiting.
+func (p *workerPool) run(ctx context.Context, f func()) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	go func() {
+		defer func() {
+			<-p.slots
+		}()
+		f()
+	}()
+	return true
+}
+
+// SetCost sets the cost of the method in a batch request. Sum of costs of
+// items in a batch request is limited by the batch limit.
+func (mr *MethodRepository) SetCost(method string, cost int) {
+	defer mr.mtx.Unlock()
+	mr.mtx.Lock()
+
+	mr.costs[method] = cost
+}
+
+// CostOf returns the cost of the method in a batch request.
+func (mr *MethodRepository) CostOf(method string) int {
+	defer mr.mtx.RUnlock()
+	mr.mtx.RLock()
+
+	if cost, ok := mr.costs[method]; ok {
+		return cost
+	}
+	return DefaultMethodCost
+}
+
+// SetBatchWorkers sets the number of workers handling items of batch
+// requests.
+func (mr *MethodRepository) SetBatchWorkers(size int) {
+	defer mr.mtx.Unlock()
+	mr.mtx.Lock()
+
+	mr.workers = newWorkerPool(size)
+}
+
+// SetBatchWaiters sets the number of workers handling items of waiting
+// methods in batch requests. They don't use the workers for others, so
+// waiting items can't hold all of them.
+func (mr *MethodRepository) SetBatchWaiters(size int) {
+	defer mr.mtx.Unlock()
+	mr.mtx.Lock()
+
+	mr.waiters = newWorkerPool(size)
+}
+
+// SetWaiting marks the method as a waiting one, which may block until
+// something happens, like a result of the transaction.
+func (mr *MethodRepository) SetWaiting(method string) {
+	defer mr.mtx.Unlock()
+	mr.mtx.Lock()
+
+	mr.waiting[method] = true
+}
+
+// workerPoolOf returns the pool for the batch item.
+func (mr *MethodRepository) workerPoolOf(raw json.RawMessage) *workerPool {
+	var req struct {
+		Method string `json:"method"`
+	}
+	_ = json.Unmarshal(raw, &req)
+
+	defer mr.mtx.RUnlock()
+	mr.mtx.RLock()
+
+	if mr.waiting[req.Method] {
+		return mr.waiters
+	}
+	return mr.workers
+}
+
+// batchCost returns sum of costs of the items. Invalid items cost
+// DefaultMethodCost.
+func (mr *MethodRepository) batchCost(raws []json.RawMessage) int {
+	cost := 0
+	for _, raw := range raws {
+		var req struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(raw, &req); err != nil {
+			cost += DefaultMethodCost
+		} else {
+			cost += mr.CostOf(req.Method)
+		}
+	}
+	return cost
+}
+
+func (mr *MethodRepository) handleBatch(ctx *Context, raws []json.RawMessage) error {
+	if ctx.UseStream() {
+		return mr.handleBatchStream(ctx, raws)
+	}
+
+	// items not started yet are dropped if the client goes away.
+	rctx := ctx.Request().Context()
+	done := make(chan struct{}, len(raws))
+	rs := make([]*Response, len(raws))
+	for i, r := range raws {
+		i, r := i, r
+		ok := mr.workerPoolOf(r).run(rctx, func() {
+			rs[i] = mr.handle(ctx, r)
+			done <- struct{}{}
+		})
+		if !ok {
+			return rctx.Err()
+		}
+	}
+	for range raws {
+		select {
+		case <-done:
+		case <-rctx.Done():
+			return rctx.Err()
+		}
+	}
+
+	resps := make([]*Response, 0)
+	for _, r := range rs {
+		if r != nil {
+			resps = append(resps, r)
+		}
+	}
+	return ctx.Respond(http.StatusOK, resps)
+}
+
+// handleBatchStream writes responses of the items in NDJSON as soon as
+// they are ready. Responses are written in the order of completion, so
+// clients should match them with their IDs.
+func (mr *MethodRepository) handleBatchStream(ctx *Context, raws []json.RawMessage) error {
+	rctx := ctx.Request().Context()
+	ch := make(chan *Response, len(raws))
+	go func() {
+		for _, r := range raws {
+			r := r
+			ok := mr.workerPoolOf(r).run(rctx, func() {
+				ch <- mr.handle(ctx, r)
+			})
+			if !ok {
+				return
+			}
+		}
+	}()
+
+	w := ctx.Response()
+	w.Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for range raws {
+		var resp *Response
+		select {
+		case resp = <-ch:
+		case <-rctx.Done():
+			return rctx.Err()
+		}
+		if resp == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+		w.Flush()
+	}
+	return nil
+}
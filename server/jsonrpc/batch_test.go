@@ -0,0 +1,170 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/server/metric"
+)
+
+func TestMethodRepository_BatchCost(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	mr.RegisterMethod("noArgs", noArgs)
+	mr.RegisterMethod("heavy", noArgs)
+	mr.SetCost("heavy", 40)
+	assert.Equal(t, DefaultMethodCost, mr.CostOf("noArgs"))
+	assert.Equal(t, 40, mr.CostOf("heavy"))
+
+	heavy := `{"jsonrpc":"2.0","method":"heavy","id":1}`
+	light := `{"jsonrpc":"2.0","method":"noArgs","id":2}`
+	exceedLimitBatchResp := `{"jsonrpc":"2.0","error":{"code":-32600,"message":"InvalidRequest","data":"too many request"},"id":null}`
+
+	// 40*2+10*2 = 100
+	invokeBatchTest(t, mr,
+		[]string{heavy, heavy, light, light},
+		[]string{
+			`{"jsonrpc":"2.0","result":"noArgs","id":1}`,
+			`{"jsonrpc":"2.0","result":"noArgs","id":1}`,
+			`{"jsonrpc":"2.0","result":"noArgs","id":2}`,
+			`{"jsonrpc":"2.0","result":"noArgs","id":2}`,
+		})
+	// 40*3 = 120
+	invokeTest(t, mr, "["+strings.Join([]string{heavy, heavy, heavy}, ",")+"]",
+		exceedLimitBatchResp, http.StatusServiceUnavailable)
+}
+
+func TestMethodRepository_BatchWorkers(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	var running, max int32
+	mr.RegisterMethod("sleep", func(ctx *Context, params *Params) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return "ok", nil
+	})
+	mr.SetBatchWorkers(2)
+
+	req := `{"jsonrpc":"2.0","method":"sleep","id":1}`
+	reqs := make([]string, DefaultBatchLimit/DefaultMethodCost)
+	resps := make([]string, DefaultBatchLimit/DefaultMethodCost)
+	for i := range reqs {
+		reqs[i] = req
+		resps[i] = `{"jsonrpc":"2.0","result":"ok","id":1}`
+	}
+	invokeBatchTest(t, mr, reqs, resps)
+	assert.EqualValues(t, 2, max)
+}
+
+func TestMethodRepository_BatchWaiters(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	ready := make(chan struct{})
+	mr.RegisterMethod("wait", func(ctx *Context, params *Params) (interface{}, error) {
+		select {
+		case <-ready:
+			return "ok", nil
+		case <-time.After(5 * time.Second):
+			return nil, ErrorCodeServer.New("Timeout")
+		}
+	})
+	mr.RegisterMethod("notify", func(ctx *Context, params *Params) (interface{}, error) {
+		close(ready)
+		return "ok", nil
+	})
+	mr.SetWaiting("wait")
+	mr.SetBatchWorkers(2)
+	mr.SetBatchWaiters(2)
+
+	// waiting items can't hold the workers for others
+	wait := `{"jsonrpc":"2.0","method":"wait","id":1}`
+	notify := `{"jsonrpc":"2.0","method":"notify","id":2}`
+	invokeBatchTest(t, mr,
+		[]string{wait, wait, notify},
+		[]string{
+			`{"jsonrpc":"2.0","result":"ok","id":1}`,
+			`{"jsonrpc":"2.0","result":"ok","id":1}`,
+			`{"jsonrpc":"2.0","result":"ok","id":2}`,
+		})
+}
+
+func TestMethodRepository_BatchCancel(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	var count int32
+	started := make(chan struct{}, 1)
+	mr.RegisterMethod("block", func(ctx *Context, params *Params) (interface{}, error) {
+		atomic.AddInt32(&count, 1)
+		started <- struct{}{}
+		<-ctx.Request().Context().Done()
+		return "ok", nil
+	})
+	mr.SetBatchWorkers(1)
+
+	req := `{"jsonrpc":"2.0","method":"block","id":1}`
+	for _, stream := range []bool{false, true} {
+		atomic.StoreInt32(&count, 0)
+		c, _, err := prepare("[" + strings.Join([]string{req, req, req}, ",") + "]")
+		assert.NoError(t, err)
+		if stream {
+			c.Request().Header.Set(echo.HeaderAccept, MIMEApplicationNDJSON)
+		}
+		rctx, cancel := context.WithCancel(c.Request().Context())
+		c.SetRequest(c.Request().WithContext(rctx))
+		go func() {
+			<-started
+			cancel()
+		}()
+		assert.Equal(t, context.Canceled, mr.Handle(c))
+
+		// queued items are not handled
+		time.Sleep(10 * time.Millisecond)
+		assert.EqualValues(t, 1, atomic.LoadInt32(&count))
+	}
+}
+
+func TestMethodRepository_BatchStream(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	mr.RegisterMethod("hello", hello)
+	mr.RegisterMethod("noArgs", noArgs)
+
+	batch := []string{
+		`{"jsonrpc":"2.0","method":"hello","params":{"name":"icon"},"id":"1001"}`,
+		`{"jsonrpc":"2.0","method":"noArgs"}`,
+		`{"jsonrpc":"2.0","method":"mustNotFound","id":"1002"}`,
+	}
+	c, rec, err := prepare("[" + strings.Join(batch, ",") + "]")
+	assert.NoError(t, err)
+	c.Request().Header.Set(echo.HeaderAccept, MIMEApplicationNDJSON)
+	assert.NoError(t, mr.Handle(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMEApplicationNDJSON, rec.Header().Get(echo.HeaderContentType))
+
+	results := make(map[string]*Response)
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		resp := new(Response)
+		assert.NoError(t, json.Unmarshal(sc.Bytes(), resp))
+		results[resp.ID.(string)] = resp
+	}
+	assert.Len(t, results, 2)
+	assert.Equal(t, "hello, icon", results["1001"].Result)
+	assert.EqualValues(t, ErrorCodeMethodNotFound, results["1002"].Error.Code)
+}
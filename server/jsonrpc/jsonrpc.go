@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"go.opencensus.io/trace"
 	"gopkg.in/go-playground/validator.v9"
 
 	"github.com/icon-project/goloop/common/errors"
@@ -20,8 +21,9 @@ import (
 )
 
 const (
-	Version           = "2.0"
-	DefaultBatchLimit = 10
+	Version              = "2.0"
+	DefaultBatchLimit    = 100
+	DefaultSimulateLimit = 100
 )
 
 type Request struct {
@@ -36,6 +38,8 @@ type Response struct {
 	Result  interface{} `json:"result,omitempty"`
 	Error   *Error      `json:"error,omitempty"`
 	ID      interface{} `json:"id"`
+
+	schema *CompactSchema
 }
 
 const (
@@ -142,6 +146,16 @@ func (ctx *Context) BatchLimit() int {
 	return batchLimit
 }
 
+// SimulateLimit returns maximum number of transactions simulated by a
+// request.
+func (ctx *Context) SimulateLimit() int {
+	simulateLimit, ok := ctx.Get("simulateLimit").(int)
+	if !ok || simulateLimit <= 0 {
+		simulateLimit = DefaultSimulateLimit
+	}
+	return simulateLimit
+}
+
 func (ctx *Context) GetTimeout(t time.Duration) time.Duration {
 	if v, err := ctx.opts.GetInt(IconOptionsTimeout); err != nil {
 		return t
@@ -150,10 +164,56 @@ func (ctx *Context) GetTimeout(t time.Duration) time.Duration {
 	}
 }
 
+// UseMsgpack returns whether the client accepts msgpack response.
+func (ctx *Context) UseMsgpack() bool {
+	v, _ := ctx.Get("msgpack").(bool)
+	return v
+}
+
+// UseStream returns whether the client accepts responses of a batch
+// request in NDJSON.
+func (ctx *Context) UseStream() bool {
+	return !ctx.UseMsgpack() && IsNDJSON(ctx.Request().Header.Get(echo.HeaderAccept))
+}
+
+// Respond writes the response or the list of responses in the format
+// accepted by the client.
+func (ctx *Context) Respond(code int, v interface{}) error {
+	if !ctx.UseMsgpack() {
+		return ctx.JSON(code, v)
+	}
+	var bs []byte
+	var err error
+	switch obj := v.(type) {
+	case *Response:
+		bs, err = obj.MarshalMsgpack()
+	case []*Response:
+		bs, err = marshalResponses(obj)
+	default:
+		bs, err = MarshalMsgpack(obj, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return ctx.Blob(code, MIMEApplicationMsgpack, bs)
+}
+
 func (ctx *Context) Validator() echo.Validator {
 	return ctx.Echo().Validator
 }
 
+// startSpan starts a span for a request. If the HTTP request has trace
+// context, then the span becomes a child of it.
+func (ctx *Context) startSpan() *trace.Span {
+	req := ctx.Request()
+	if sc, ok := traceFormat.SpanContextFromRequest(req); ok {
+		_, span := trace.StartSpanWithRemoteParent(req.Context(), "jsonrpc", sc)
+		return span
+	}
+	_, span := trace.StartSpan(req.Context(), "jsonrpc")
+	return span
+}
+
 func (ctx *Context) MetricContext() context.Context {
 	if c, _ := ctx.Chain(); c == nil {
 		return metric.DefaultMetricContext()
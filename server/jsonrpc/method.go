@@ -3,20 +3,33 @@ package jsonrpc
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
 	"sync"
 	"time"
 
+	"github.com/labstack/echo/v4"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/tracing"
 	"github.com/icon-project/goloop/server/metric"
-	"github.com/labstack/echo/v4"
 )
 
+var traceFormat = &tracecontext.HTTPFormat{}
+
 type Handler func(ctx *Context, params *Params) (result interface{}, err error)
 
 type MethodRepository struct {
 	mtx     sync.RWMutex
 	methods map[string]Handler
 	allowed map[string]bool
+	params  map[string]reflect.Type
+	results map[string]*CompactSchema
+	costs   map[string]int
+	workers *workerPool
+	waiters *workerPool
+	waiting map[string]bool
 	mtr     *metric.JsonrpcMetric
 }
 
@@ -24,7 +37,13 @@ func NewMethodRepository(mtr *metric.JsonrpcMetric) *MethodRepository {
 	return &MethodRepository{
 		methods: make(map[string]Handler),
 		allowed: make(map[string]bool),
-		mtr: mtr,
+		params:  make(map[string]reflect.Type),
+		results: make(map[string]*CompactSchema),
+		costs:   make(map[string]int),
+		workers: newWorkerPool(DefaultBatchWorkers),
+		waiters: newWorkerPool(DefaultBatchWaiters),
+		waiting: make(map[string]bool),
+		mtr:     mtr,
 	}
 }
 
@@ -45,6 +64,31 @@ func (mr *MethodRepository) GetMethod(method string) Handler {
 	return mr.methods[method]
 }
 
+// SetParams sets the type of the parameter for the method. It's used for
+// describing the method by rpc_discover.
+func (mr *MethodRepository) SetParams(method string, params interface{}) {
+	defer mr.mtx.Unlock()
+	mr.mtx.Lock()
+
+	mr.params[method] = reflect.TypeOf(params)
+}
+
+// SetResultSchema sets the schema of the result for the method. It's used
+// for the compact form of the result in msgpack.
+func (mr *MethodRepository) SetResultSchema(method string, schema *CompactSchema) {
+	defer mr.mtx.Unlock()
+	mr.mtx.Lock()
+
+	mr.results[method] = schema
+}
+
+func (mr *MethodRepository) GetResultSchema(method string) *CompactSchema {
+	defer mr.mtx.RUnlock()
+	mr.mtx.RLock()
+
+	return mr.results[method]
+}
+
 func (mr *MethodRepository) SetAllowedNotification(method string) {
 	defer mr.mtx.Unlock()
 	mr.mtx.Lock()
@@ -65,6 +109,7 @@ func (mr *MethodRepository) handle(ctx *Context, raw json.RawMessage) *Response
 	resp := &Response{Version: Version}
 	req := new(Request)
 	start := time.Now()
+	span := ctx.startSpan()
 	defer func() {
 		method := ""
 		if req.Method != nil {
@@ -75,6 +120,8 @@ func (mr *MethodRepository) handle(ctx *Context, raw json.RawMessage) *Response
 			err = resp.Error
 		}
 		mr.mtr.OnHandle(ctx.MetricContext(), method, start, err)
+		span.AddAttributes(trace.StringAttribute("method", method))
+		tracing.EndSpan(span, err)
 	}()
 	if err := UnmarshalWithValidate(raw, req, ctx.Validator()); err != nil {
 		resp.ID = req.ID
@@ -116,6 +163,7 @@ func (mr *MethodRepository) handle(ctx *Context, raw json.RawMessage) *Response
 			resp.Result = json.RawMessage("null")
 		} else {
 			resp.Result = res
+			resp.schema = mr.GetResultSchema(*req.Method)
 		}
 	}
 	if req.ID == nil {
@@ -136,41 +184,24 @@ func (mr *MethodRepository) Handle(c echo.Context) error {
 				Error:   ErrInvalidRequest(),
 			}
 			mr.mtr.OnHandle(ctx.MetricContext(), "", time.Now(), resp.Error)
-			return c.JSON(http.StatusBadRequest, resp)
+			return ctx.Respond(http.StatusBadRequest, resp)
 		}
-		if n > ctx.BatchLimit() {
+		if mr.batchCost(raws) > ctx.BatchLimit() {
 			resp := &Response{
 				Version: Version,
 				Error:   ErrInvalidRequest("too many request"),
 			}
 			mr.mtr.OnHandle(ctx.MetricContext(), "", time.Now(), resp.Error)
-			return c.JSON(http.StatusServiceUnavailable, resp)
-		}
-		var wg sync.WaitGroup
-		wg.Add(n)
-		rs := make([]*Response, len(raws))
-		for i, r := range raws {
-			go func(r json.RawMessage, rs []*Response, i int) {
-				rs[i] = mr.handle(ctx, r)
-				wg.Done()
-			}(r, rs, i)
-		}
-		wg.Wait()
-
-		resps := make([]*Response, 0)
-		for _, r := range rs {
-			if r != nil {
-				resps = append(resps, r)
-			}
+			return ctx.Respond(http.StatusServiceUnavailable, resp)
 		}
-		return c.JSON(http.StatusOK, resps)
+		return mr.handleBatch(ctx, raws)
 	} else {
 		resp := mr.handle(ctx, raw)
 		if resp != nil {
 			if resp.Error != nil {
-				return c.JSON(http.StatusBadRequest, resp)
+				return ctx.Respond(http.StatusBadRequest, resp)
 			} else {
-				return c.JSON(http.StatusOK, resp)
+				return ctx.Respond(http.StatusOK, resp)
 			}
 		} else {
 			return c.NoContent(http.StatusOK)
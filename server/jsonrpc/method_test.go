@@ -214,3 +214,16 @@ func noArgs(ctx *Context, params *Params) (interface{}, error) {
 	}
 	return "noArgs", nil
 }
+
+func TestContext_SimulateLimit(t *testing.T) {
+	c, _, err := prepare(`{"jsonrpc":"2.0","method":"test","id":1}`)
+	assert.NoError(t, err)
+	ctx := NewContext(c)
+	assert.Equal(t, DefaultSimulateLimit, ctx.SimulateLimit())
+
+	c.Set("simulateLimit", 5)
+	assert.Equal(t, 5, ctx.SimulateLimit())
+
+	c.Set("simulateLimit", 0)
+	assert.Equal(t, DefaultSimulateLimit, ctx.SimulateLimit())
+}
@@ -0,0 +1,343 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"mime"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+)
+
+const (
+	MIMEApplicationMsgpack  = "application/msgpack"
+	MIMEApplicationXMsgpack = "application/x-msgpack"
+)
+
+// IsMsgpack returns whether the media type(value of Content-Type or
+// Accept header) is for msgpack.
+func IsMsgpack(value string) bool {
+	for _, v := range strings.Split(value, ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		if mt == MIMEApplicationMsgpack || mt == MIMEApplicationXMsgpack {
+			return true
+		}
+	}
+	return false
+}
+
+// MsgpackToJSON converts msgpack encoded request to JSON.
+// Values of the request are same as JSON request. Binary values are
+// converted to base64 strings as encoding/json does.
+func MsgpackToJSON(bs []byte) (json.RawMessage, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(bs))
+	dec.UseDecodeInterfaceLoose(true)
+	v, err := dec.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+	v, err = stringKeys(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func stringKeys(v interface{}) (interface{}, error) {
+	switch obj := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			ks, ok := k.(string)
+			if !ok {
+				return nil, errors.IllegalArgumentError.Errorf("InvalidKey(key=%v)", k)
+			}
+			if value, err := stringKeys(v); err != nil {
+				return nil, err
+			} else {
+				m[ks] = value
+			}
+		}
+		return m, nil
+	case map[string]interface{}:
+		for k, v := range obj {
+			if value, err := stringKeys(v); err != nil {
+				return nil, err
+			} else {
+				obj[k] = value
+			}
+		}
+		return obj, nil
+	case []interface{}:
+		for i, v := range obj {
+			if value, err := stringKeys(v); err != nil {
+				return nil, err
+			} else {
+				obj[i] = value
+			}
+		}
+		return obj, nil
+	default:
+		return v, nil
+	}
+}
+
+// CompactType is the type of values in compact form.
+type CompactType int
+
+const (
+	// CompactAny keeps the value as it is.
+	CompactAny CompactType = iota
+	// CompactInt converts "0x" or "-0x" prefixed hex string to integer.
+	CompactInt
+	// CompactBytes converts "0x" or "-0x" prefixed hex string to bytes.
+	CompactBytes
+	// CompactHash converts hex string with or without "0x" prefix to bytes.
+	CompactHash
+	// CompactAddress converts address to 21 bytes of the address.
+	CompactAddress
+)
+
+// CompactSchema declares types of the values in a result for compact form.
+// Type is applied to the value (or to the items if it's an array), and
+// Fields are applied to the fields if it's an object. Convert may convert
+// fields of the object depending on other fields before Fields are
+// applied. Strings of values not declared are kept as they are.
+type CompactSchema struct {
+	Type    CompactType
+	Fields  map[string]*CompactSchema
+	Convert func(obj map[string]interface{})
+}
+
+func (s *CompactSchema) fieldOf(key string) *CompactSchema {
+	if s == nil {
+		return nil
+	}
+	return s.Fields[key]
+}
+
+func (s *CompactSchema) typeOf() CompactType {
+	if s == nil {
+		return CompactAny
+	}
+	return s.Type
+}
+
+// bytesOfHex returns raw bytes of "0x" or "-0x" prefixed hex string.
+// Hex strings of odd length (ex. "0x1") are padded with leading zero, and
+// negative values are converted to two's complement big-endian bytes as
+// integers in event logs.
+func bytesOfHex(s string) ([]byte, bool) {
+	if strings.HasPrefix(s, "-") {
+		var bi big.Int
+		if err := intconv.ParseBigInt(&bi, s); err != nil {
+			return nil, false
+		}
+		return intconv.BigIntToBytes(&bi), true
+	}
+	h := s[2:]
+	if len(h)%2 == 1 {
+		h = "0" + h
+	}
+	bs, err := hex.DecodeString(h)
+	if err != nil {
+		return nil, false
+	}
+	return bs, true
+}
+
+func isHexPrefixed(s string) bool {
+	return strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "-0x")
+}
+
+// compactString converts a string in JSON result for the type.
+//   - CompactInt : integer, or two's complement big-endian bytes if it
+//     doesn't fit in int64
+//   - CompactBytes, CompactHash : raw bytes
+//   - CompactAddress : 21 bytes of the address
+//
+// Strings not matching the type are kept unchanged.
+func compactString(t CompactType, s string) interface{} {
+	switch t {
+	case CompactInt:
+		if !isHexPrefixed(s) {
+			return s
+		}
+		var bi big.Int
+		if err := intconv.ParseBigInt(&bi, s); err != nil {
+			return s
+		}
+		if bi.IsInt64() {
+			return bi.Int64()
+		}
+		return intconv.BigIntToBytes(&bi)
+	case CompactBytes, CompactHash:
+		if isHexPrefixed(s) {
+			if bs, ok := bytesOfHex(s); ok {
+				return bs
+			}
+			return s
+		}
+		if t == CompactHash && len(s) > 0 {
+			if bs, err := hex.DecodeString(s); err == nil {
+				return bs
+			}
+		}
+		return s
+	case CompactAddress:
+		if len(s) == 42 && (s[0:2] == "hx" || s[0:2] == "cx") {
+			var addr common.Address
+			if err := addr.SetStringStrict(s); err == nil {
+				return addr.Bytes()
+			}
+		}
+		return s
+	default:
+		return s
+	}
+}
+
+func compactValue(s *CompactSchema, v interface{}) interface{} {
+	switch obj := v.(type) {
+	case map[string]interface{}:
+		if s != nil && s.Convert != nil {
+			s.Convert(obj)
+		}
+		for k, v := range obj {
+			obj[k] = compactValue(s.fieldOf(k), v)
+		}
+		return obj
+	case []interface{}:
+		for i, v := range obj {
+			obj[i] = compactValue(s, v)
+		}
+		return obj
+	case string:
+		return compactString(s.typeOf(), obj)
+	case json.Number:
+		if i, err := obj.Int64(); err == nil {
+			return i
+		}
+		return obj.String()
+	default:
+		return v
+	}
+}
+
+// Compact returns compact form of JSON result for msgpack.
+// It converts hex strings and addresses to raw bytes and integers following
+// the schema, and numbers to integers. Refer doc/jsonrpc_v3_msgpack.md for
+// details.
+func Compact(v interface{}, s *CompactSchema) (interface{}, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	var obj interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return compactValue(s, obj), nil
+}
+
+// MarshalMsgpack returns compact form of the value in msgpack.
+func MarshalMsgpack(v interface{}, s *CompactSchema) ([]byte, error) {
+	obj, err := Compact(v, s)
+	if err != nil {
+		return nil, err
+	}
+	return codec.MP.MarshalToBytes(obj)
+}
+
+func (r *Response) compact() (map[string]interface{}, error) {
+	obj := map[string]interface{}{
+		"jsonrpc": r.Version,
+	}
+	if r.Error != nil {
+		e := map[string]interface{}{
+			"code":    int64(r.Error.Code),
+			"message": r.Error.Message,
+		}
+		if r.Error.Data != nil {
+			data, err := Compact(r.Error.Data, nil)
+			if err != nil {
+				return nil, err
+			}
+			e["data"] = data
+		}
+		obj["error"] = e
+	} else {
+		result, err := Compact(r.Result, r.schema)
+		if err != nil {
+			return nil, err
+		}
+		obj["result"] = result
+	}
+	switch id := r.ID.(type) {
+	case string, nil:
+		obj["id"] = id
+	default:
+		if v, err := Compact(id, nil); err != nil {
+			return nil, err
+		} else {
+			obj["id"] = v
+		}
+	}
+	return obj, nil
+}
+
+// MarshalMsgpack returns msgpack form of the response. The result is
+// converted to compact form with the schema of the method.
+func (r *Response) MarshalMsgpack() ([]byte, error) {
+	obj, err := r.compact()
+	if err != nil {
+		return nil, err
+	}
+	return codec.MP.MarshalToBytes(obj)
+}
+
+func marshalResponses(rs []*Response) ([]byte, error) {
+	objs := make([]interface{}, len(rs))
+	for i, r := range rs {
+		if obj, err := r.compact(); err != nil {
+			return nil, err
+		} else {
+			objs[i] = obj
+		}
+	}
+	return codec.MP.MarshalToBytes(objs)
+}
+
+// MsgpackResponse is used to decode msgpack response.
+type MsgpackResponse struct {
+	Version string        `msgpack:"jsonrpc"`
+	Result  interface{}   `msgpack:"result,omitempty"`
+	Error   *MsgpackError `msgpack:"error,omitempty"`
+	ID      interface{}   `msgpack:"id"`
+}
+
+type MsgpackError struct {
+	Code    ErrorCode   `msgpack:"code"`
+	Message string      `msgpack:"message"`
+	Data    interface{} `msgpack:"data,omitempty"`
+}
+
+func (e *MsgpackError) ToError() *Error {
+	return &Error{
+		Code:    e.Code,
+		Message: e.Message,
+		Data:    e.Data,
+	}
+}
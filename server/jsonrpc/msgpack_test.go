@@ -0,0 +1,171 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+func TestIsMsgpack(t *testing.T) {
+	assert.True(t, IsMsgpack("application/msgpack"))
+	assert.True(t, IsMsgpack("application/x-msgpack"))
+	assert.True(t, IsMsgpack("application/json, application/msgpack;q=0.9"))
+	assert.False(t, IsMsgpack("application/json"))
+	assert.False(t, IsMsgpack(""))
+}
+
+func TestCompact(t *testing.T) {
+	big1, _ := new(big.Int).SetString("100000000000000000000", 10)
+	addr := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	schema := &CompactSchema{
+		Fields: map[string]*CompactSchema{
+			"txHash":          {Type: CompactHash},
+			"prev_block_hash": {Type: CompactHash},
+			"height":          {Type: CompactInt},
+			"time_stamp":      {Type: CompactInt},
+			"value":           {Type: CompactInt},
+			"negative":        {Type: CompactInt},
+			"to":              {Type: CompactAddress},
+			"indexed":         {Type: CompactBytes},
+			"status":          {Type: CompactInt},
+			"data":            {Type: CompactBytes},
+		},
+	}
+	v, err := Compact(map[string]interface{}{
+		"txHash":          "0x0102",
+		"prev_block_hash": "0304",
+		"height":          "0x10",
+		"time_stamp":      1234,
+		"value":           common.NewHexInt(0).SetValue(big1),
+		"negative":        "-0x1",
+		"to":              addr,
+		"indexed":         []string{"Transfer(Address,int)", "0x0a"},
+		"dataType":        "call",
+		"status":          "0x1",
+		"data": map[string]interface{}{
+			"method": "transfer",
+			"params": map[string]interface{}{"_to": addr, "_value": "0x10"},
+		},
+		"unknown":     "0x10",
+		"unknownAddr": addr,
+	}, schema)
+	assert.NoError(t, err)
+	m := v.(map[string]interface{})
+	assert.Equal(t, []byte{1, 2}, m["txHash"])
+	assert.Equal(t, []byte{3, 4}, m["prev_block_hash"])
+	assert.Equal(t, int64(16), m["height"])
+	assert.Equal(t, int64(1234), m["time_stamp"])
+	assert.Equal(t, intconv.BigIntToBytes(big1), m["value"])
+	assert.Equal(t, int64(-1), m["negative"])
+	assert.Equal(t, addr.Bytes(), m["to"])
+	assert.Equal(t, []interface{}{"Transfer(Address,int)", []byte{10}}, m["indexed"])
+	assert.Equal(t, "call", m["dataType"])
+	assert.Equal(t, int64(1), m["status"])
+
+	// values not declared by the schema are kept
+	assert.Equal(t, map[string]interface{}{
+		"method": "transfer",
+		"params": map[string]interface{}{"_to": addr.String(), "_value": "0x10"},
+	}, m["data"])
+	assert.Equal(t, "0x10", m["unknown"])
+	assert.Equal(t, addr.String(), m["unknownAddr"])
+
+	v, err = Compact("0x10", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", v)
+}
+
+func TestCompact_EventValues(t *testing.T) {
+	values := []string{"0x1", "0x10", "0x0a", "0x", "-0x1", "-0x80", "-0x81"}
+	expected := [][]byte{
+		{0x01}, {0x10}, {0x0a}, {}, {0xff}, {0x80}, {0xff, 0x7f},
+	}
+	indexed := []string{"Event(int)"}
+	indexed = append(indexed, values...)
+	bs, err := MarshalMsgpack(map[string]interface{}{
+		"indexed": indexed,
+		"data":    values,
+	}, &CompactSchema{
+		Fields: map[string]*CompactSchema{
+			"indexed": {Type: CompactBytes},
+			"data":    {Type: CompactBytes},
+		},
+	})
+	assert.NoError(t, err)
+
+	var res struct {
+		Indexed []interface{} `msgpack:"indexed"`
+		Data    []interface{} `msgpack:"data"`
+	}
+	assert.NoError(t, msgpack.Unmarshal(bs, &res))
+	assert.Equal(t, "Event(int)", res.Indexed[0])
+	for i, exp := range expected {
+		assert.Equal(t, exp, res.Indexed[i+1], values[i])
+		assert.Equal(t, exp, res.Data[i], values[i])
+	}
+}
+
+func TestMsgpackToJSON(t *testing.T) {
+	bs, err := msgpack.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "icx_getBlockByHeight",
+		"params":  map[string]interface{}{"height": "0x1"},
+		"id":      1,
+	})
+	assert.NoError(t, err)
+	raw, err := MsgpackToJSON(bs)
+	assert.NoError(t, err)
+
+	var req map[string]interface{}
+	assert.NoError(t, json.Unmarshal(raw, &req))
+	assert.Equal(t, "icx_getBlockByHeight", req["method"])
+	assert.Equal(t, map[string]interface{}{"height": "0x1"}, req["params"])
+}
+
+func TestMethodRepository_Msgpack(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	mr.RegisterMethod("test_height", func(ctx *Context, params *Params) (interface{}, error) {
+		return map[string]interface{}{
+			"hash":   "0xff",
+			"height": "0x2",
+		}, nil
+	})
+
+	mr.SetResultSchema("test_height", &CompactSchema{
+		Fields: map[string]*CompactSchema{
+			"hash":   {Type: CompactHash},
+			"height": {Type: CompactInt},
+		},
+	})
+
+	c, rec, err := prepare(`{"jsonrpc":"2.0","method":"test_height","id":1}`)
+	assert.NoError(t, err)
+	c.Set("msgpack", true)
+	assert.NoError(t, mr.Handle(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMEApplicationMsgpack, rec.Header().Get(echo.HeaderContentType))
+
+	var resp struct {
+		Version string `msgpack:"jsonrpc"`
+		Result  struct {
+			Hash   []byte `msgpack:"hash"`
+			Height int64  `msgpack:"height"`
+		} `msgpack:"result"`
+		ID int64 `msgpack:"id"`
+	}
+	assert.NoError(t, msgpack.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, Version, resp.Version)
+	assert.Equal(t, []byte{0xff}, resp.Result.Hash)
+	assert.Equal(t, int64(2), resp.Result.Height)
+	assert.Equal(t, int64(1), resp.ID)
+}
@@ -0,0 +1,251 @@
+package jsonrpc
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	OpenRPCVersion    = "1.2.6"
+	DiscoverMethod    = "rpc_discover"
+	ParamStructByName = "by-name"
+)
+
+// OpenRPCDocument is the OpenRPC document describing methods of a
+// MethodRepository. Only the part used for describing parameters is
+// defined.
+type OpenRPCDocument struct {
+	OpenRPC    string             `json:"openrpc"`
+	Info       OpenRPCInfo        `json:"info"`
+	Methods    []*OpenRPCMethod   `json:"methods"`
+	Components *OpenRPCComponents `json:"components,omitempty"`
+}
+
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenRPCMethod struct {
+	Name           string               `json:"name"`
+	ParamStructure string               `json:"paramStructure"`
+	Params         []*ContentDescriptor `json:"params"`
+	Result         *ContentDescriptor   `json:"result"`
+	BatchCost      int                  `json:"x-batch-cost"`
+}
+
+type ContentDescriptor struct {
+	Name     string  `json:"name"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type OpenRPCComponents struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the JSON schema for a value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+func refSchema(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// typeSchemas are schemas for the validation tags of types in the document
+// of JSON-RPC v3 API.
+var typeSchemas = map[string]*Schema{
+	"T_INT":        {Type: "string", Pattern: hexInt.String()},
+	"T_HASH":       {Type: "string", Pattern: hashRegex.String()},
+	"T_ADDR_EOA":   {Type: "string", Pattern: eoaAddressRegex.String()},
+	"T_ADDR_SCORE": {Type: "string", Pattern: scoreAddressRegex.String()},
+	"T_ADDR":       {AnyOf: []*Schema{refSchema("T_ADDR_EOA"), refSchema("T_ADDR_SCORE")}},
+	"T_SIG":        {Type: "string", ContentEncoding: "base64"},
+}
+
+// Discover returns the OpenRPC document for the registered methods.
+func (mr *MethodRepository) Discover(info OpenRPCInfo) *OpenRPCDocument {
+	mr.mtx.RLock()
+	defer mr.mtx.RUnlock()
+
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    info,
+		Methods: make([]*OpenRPCMethod, 0, len(mr.methods)),
+	}
+	for name := range mr.methods {
+		if name == DiscoverMethod {
+			continue
+		}
+		m := &OpenRPCMethod{
+			Name:           name,
+			ParamStructure: ParamStructByName,
+			Params:         []*ContentDescriptor{},
+			Result:         &ContentDescriptor{Name: "result", Schema: &Schema{}},
+			BatchCost:      DefaultMethodCost,
+		}
+		if cost, ok := mr.costs[name]; ok {
+			m.BatchCost = cost
+		}
+		if t, ok := mr.params[name]; ok {
+			m.Params = paramsOf(t)
+		}
+		doc.Methods = append(doc.Methods, m)
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool {
+		return doc.Methods[i].Name < doc.Methods[j].Name
+	})
+	doc.Components = &OpenRPCComponents{Schemas: typeSchemas}
+	return doc
+}
+
+// RegisterDiscover registers rpc_discover returning the OpenRPC document
+// for the methods of the repository.
+func (mr *MethodRepository) RegisterDiscover(info OpenRPCInfo) {
+	mr.RegisterMethod(DiscoverMethod, func(ctx *Context, params *Params) (interface{}, error) {
+		var param struct{}
+		if err := params.Convert(&param); err != nil {
+			return nil, ErrorCodeInvalidParams.Wrap(err, ctx.IncludeDebug())
+		}
+		return mr.Discover(info), nil
+	})
+}
+
+type fieldDescriptor struct {
+	name     string
+	required bool
+	schema   *Schema
+}
+
+func fieldsOf(t reflect.Type) []*fieldDescriptor {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []*fieldDescriptor
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			fields = append(fields, fieldsOf(f.Type)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if p := strings.Split(tag, ",")[0]; p != "" {
+				name = p
+			}
+		}
+		tags := strings.Split(f.Tag.Get("validate"), ",")
+		fields = append(fields, &fieldDescriptor{
+			name:     name,
+			required: len(tags) > 0 && tags[0] == "required",
+			schema:   schemaOf(f.Type, tags),
+		})
+	}
+	return fields
+}
+
+func paramsOf(t reflect.Type) []*ContentDescriptor {
+	fields := fieldsOf(t)
+	params := make([]*ContentDescriptor, len(fields))
+	for i, f := range fields {
+		params[i] = &ContentDescriptor{
+			Name:     f.name,
+			Required: f.required,
+			Schema:   f.schema,
+		}
+	}
+	return params
+}
+
+func schemaForTag(tag string) *Schema {
+	switch tag {
+	case "t_int", "t_hash", "t_addr", "t_addr_eoa", "t_addr_score", "t_sig":
+		return refSchema(strings.ToUpper(tag))
+	}
+	return nil
+}
+
+// schemaOf returns the schema of the type with its validation tags.
+func schemaOf(t reflect.Type, tags []string) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s := &Schema{}
+	for i, tag := range tags {
+		switch {
+		case tag == "" || tag == "required" || tag == "optional" || tag == "omitempty":
+		case tag == "dive":
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+				s.Type = "array"
+				s.Items = schemaOf(t.Elem(), tags[i+1:])
+				return s
+			}
+		case strings.HasPrefix(tag, "gt="):
+			if v, err := strconv.Atoi(tag[3:]); err == nil && t.Kind() == reflect.Slice {
+				min := v + 1
+				s.MinItems = &min
+			}
+		case strings.HasPrefix(tag, "oneof="):
+			s.Type = "string"
+			s.Enum = strings.Fields(tag[6:])
+		case schemaForTag(tag) != nil:
+			return schemaForTag(tag)
+		case t.Kind() == reflect.String:
+			// other tags for string are the list of allowed values
+			// like "call|deploy".
+			s.Type = "string"
+			s.Enum = strings.Split(tag, "|")
+		}
+	}
+	if s.Type != "" {
+		return s
+	}
+	switch t.Kind() {
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+	case reflect.Slice, reflect.Array:
+		s.Type = "array"
+		s.Items = schemaOf(t.Elem(), nil)
+	case reflect.Map:
+		s.Type = "object"
+	case reflect.Struct:
+		s.Type = "object"
+		s.Properties = make(map[string]*Schema)
+		for _, f := range fieldsOf(t) {
+			s.Properties[f.name] = f.schema
+			if f.required {
+				s.Required = append(s.Required, f.name)
+			}
+		}
+		additional := false
+		s.AdditionalProperties = &additional
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/server/metric"
+)
+
+type testItemParam struct {
+	Value HexInt `json:"value" validate:"required,t_int"`
+}
+
+type testDiscoverParam struct {
+	Address Address         `json:"address" validate:"required,t_addr"`
+	Height  HexInt          `json:"height,omitempty" validate:"optional,t_int"`
+	Type    string          `json:"type,omitempty" validate:"optional,call|deploy"`
+	Group   string          `json:"group,omitempty" validate:"optional,oneof=normal patch"`
+	Events  []HexInt        `json:"events" validate:"gt=0,dive,t_int"`
+	Items   []testItemParam `json:"items" validate:"required,gt=0,dive"`
+	Data    interface{}     `json:"data,omitempty"`
+}
+
+func TestMethodRepository_Discover(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	handler := func(ctx *Context, params *Params) (interface{}, error) {
+		return nil, nil
+	}
+	mr.RegisterMethod("test_b", handler)
+	mr.RegisterMethod("test_a", handler)
+	mr.SetParams("test_a", testDiscoverParam{})
+	mr.RegisterDiscover(OpenRPCInfo{Title: "test", Version: "0x1"})
+
+	doc := mr.Discover(OpenRPCInfo{Title: "test", Version: "0x1"})
+	assert.Equal(t, OpenRPCVersion, doc.OpenRPC)
+	assert.Len(t, doc.Methods, 2)
+	assert.Equal(t, "test_a", doc.Methods[0].Name)
+	assert.Equal(t, "test_b", doc.Methods[1].Name)
+	assert.Empty(t, doc.Methods[1].Params)
+
+	params := doc.Methods[0].Params
+	assert.Len(t, params, 7)
+	assert.Equal(t, "address", params[0].Name)
+	assert.True(t, params[0].Required)
+	assert.Equal(t, "#/components/schemas/T_ADDR", params[0].Schema.Ref)
+	assert.Equal(t, "height", params[1].Name)
+	assert.False(t, params[1].Required)
+	assert.Equal(t, "#/components/schemas/T_INT", params[1].Schema.Ref)
+	assert.Equal(t, []string{"call", "deploy"}, params[2].Schema.Enum)
+	assert.Equal(t, []string{"normal", "patch"}, params[3].Schema.Enum)
+	assert.Equal(t, "array", params[4].Schema.Type)
+	assert.Equal(t, 1, *params[4].Schema.MinItems)
+	assert.Equal(t, "#/components/schemas/T_INT", params[4].Schema.Items.Ref)
+	assert.Equal(t, "object", params[5].Schema.Items.Type)
+	assert.Equal(t, []string{"value"}, params[5].Schema.Items.Required)
+	assert.Equal(t, &Schema{}, params[6].Schema)
+
+	for _, m := range doc.Methods {
+		for _, p := range m.Params {
+			assertRefs(t, doc, p.Schema)
+		}
+	}
+
+	c, rec, err := prepare(`{"jsonrpc":"2.0","method":"rpc_discover","id":1}`)
+	assert.NoError(t, err)
+	assert.NoError(t, mr.Handle(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Result *OpenRPCDocument `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, doc.Info, resp.Result.Info)
+	assert.Len(t, resp.Result.Methods, 2)
+}
+
+func assertRefs(t *testing.T, doc *OpenRPCDocument, s *Schema) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		name := s.Ref[len("#/components/schemas/"):]
+		assert.Contains(t, doc.Components.Schemas, name)
+	}
+	assertRefs(t, doc, s.Items)
+	for _, p := range s.Properties {
+		assertRefs(t, doc, p)
+	}
+}
@@ -57,6 +57,10 @@ var (
 		"icx_getVotesByHeight":       msRetrieve,
 		"icx_getProofForResult":      msRetrieve,
 		"icx_getProofForEvents":      msRetrieve,
+		"icx_getBlockAccumulator":    msRetrieve,
+		"icx_getBlockWitness":        msRetrieve,
+		"icx_getIScoreHistory":       msRetrieve,
+		"icx_getValidationHistory":   msRetrieve,
 		"debug_getTrace": {
 			stats.Int64("jsonrpc_get_trace", "jsonrpc debug_getTrace method", "ns"),
 			stats.Int64("jsonrpc_get_trace_avg", "moving average of jsonrpc debug_getTrace method", "ns"),
@@ -67,6 +71,16 @@ var (
 			stats.Int64("jsonrpc_estimate_step_avg", "moving average of jsonrpc debug_estimateStep method", "ns"),
 			emptyMks,
 		},
+		"debug_simulateTransactions": {
+			stats.Int64("jsonrpc_simulate_transactions", "jsonrpc debug_simulateTransactions method", "ns"),
+			stats.Int64("jsonrpc_simulate_transactions_avg", "moving average of jsonrpc debug_simulateTransactions method", "ns"),
+			emptyMks,
+		},
+		"debug_getPendingTransactions":  msRetrieve,
+		"debug_getPendingTransaction":   msRetrieve,
+		"debug_getTransactionPoolUsage": msRetrieve,
+		"debug_getStorageUsages":        msRetrieve,
+		"rpc_discover":                  msRetrieve,
 	}
 	jms    = make([]*JsonrpcMetric, 0)
 	jmsMtx sync.RWMutex
@@ -0,0 +1,46 @@
+package metric
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	msImportHeight       = stats.Int64("lcimport_height", "imported height", stats.UnitDimensionless)
+	msImportReceipts     = stats.Int64("lcimport_receipts", "verified receipts", stats.UnitDimensionless)
+	msImportMismatch     = stats.Int64("lcimport_mismatch", "mismatched results", stats.UnitDimensionless)
+	msImportStoreFailure = stats.Int64("lcimport_store_failure", "store failures", stats.UnitDimensionless)
+	lcImportMks          = []tag.Key{}
+)
+
+func RegisterLCImport() {
+	RegisterMetricView(msImportHeight, view.LastValue(), lcImportMks)
+	RegisterMetricView(msImportReceipts, view.Sum(), lcImportMks)
+	RegisterMetricView(msImportMismatch, view.Count(), lcImportMks)
+	RegisterMetricView(msImportStoreFailure, view.Count(), lcImportMks)
+}
+
+type LCImportMetric struct {
+	ctx context.Context
+}
+
+func (m *LCImportMetric) OnBlock(height int64, receipts int) {
+	stats.Record(m.ctx, msImportHeight.M(height), msImportReceipts.M(int64(receipts)))
+}
+
+func (m *LCImportMetric) OnMismatch(height int64) {
+	stats.Record(m.ctx, msImportMismatch.M(height))
+}
+
+func (m *LCImportMetric) OnStoreFailure(height int64) {
+	stats.Record(m.ctx, msImportStoreFailure.M(height))
+}
+
+func NewLCImportMetric(ctx context.Context) *LCImportMetric {
+	return &LCImportMetric{
+		ctx: ctx,
+	}
+}
@@ -189,6 +189,7 @@ func PrometheusExporter() *prometheus.Exporter {
 	RegisterNetwork()
 	RegisterTransaction()
 	RegisterJsonrpc()
+	RegisterLCImport()
 	return pe
 }
 
@@ -17,12 +17,25 @@ func JsonRpc() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			ctype := c.Request().Header.Get(echo.HeaderContentType)
-			if !strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
-				c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-			}
+			accept := c.Request().Header.Get(echo.HeaderAccept)
 			var raw json.RawMessage
-			if err := c.Bind(&raw); err != nil {
-				return jsonrpc.ErrParse()
+			if jsonrpc.IsMsgpack(ctype) {
+				bs, err := ioutil.ReadAll(c.Request().Body)
+				if err != nil {
+					return jsonrpc.ErrParse()
+				}
+				if raw, err = jsonrpc.MsgpackToJSON(bs); err != nil {
+					return jsonrpc.ErrParse()
+				}
+				c.Set("msgpack", accept == "" || accept == "*/*" || jsonrpc.IsMsgpack(accept))
+			} else {
+				if !strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
+					c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				}
+				if err := c.Bind(&raw); err != nil {
+					return jsonrpc.ErrParse()
+				}
+				c.Set("msgpack", jsonrpc.IsMsgpack(accept))
 			}
 			c.Set("raw", raw)
 			return next(c)
@@ -34,9 +34,14 @@ type Manager struct {
 	jsonrpcMessageDump    int32
 	jsonrpcIncludeDebug   int32
 	jsonrpcBatchLimit     int32
+	jsonrpcSimulateLimit  int32
+	jsonrpcBatchWorkers   int32
+	jsonrpcBatchWaiters   int32
+	mrs                   []*jsonrpc.MethodRepository
 	logger                log.Logger
 	metricsHandler        echo.HandlerFunc
 	mtr                   *metric.JsonrpcMetric
+	rc                    readyChecker
 }
 
 func NewManager(addr string,
@@ -68,9 +73,18 @@ func NewManager(addr string,
 		mtx:                   sync.RWMutex{},
 		jsonrpcDefaultChannel: jsonrpcDefaultChannel,
 		jsonrpcBatchLimit:     int32(jsonrpcBatchLimit),
+		jsonrpcSimulateLimit:  jsonrpc.DefaultSimulateLimit,
+		jsonrpcBatchWorkers:   jsonrpc.DefaultBatchWorkers,
+		jsonrpcBatchWaiters:   jsonrpc.DefaultBatchWaiters,
 		logger:                logger,
 		metricsHandler:        echo.WrapHandler(metric.PrometheusExporter()),
 		mtr:                   mtr,
+		rc: readyChecker{
+			cfg: ReadyConfig{
+				MaxBlockAge:  DefaultReadyMaxBlockAge,
+				MaxHeightLag: DefaultReadyMaxHeightLag,
+			},
+		},
 	}
 	m.SetMessageDump(jsonrpcDump)
 	m.SetIncludeDebug(jsonrpcIncludeDebug)
@@ -162,6 +176,55 @@ func (srv *Manager) BatchLimit() int {
 	return int(atomic.LoadInt32(&srv.jsonrpcBatchLimit))
 }
 
+func (srv *Manager) SetSimulateLimit(limit int) {
+	atomic.StoreInt32(&srv.jsonrpcSimulateLimit, int32(limit))
+}
+
+func (srv *Manager) SimulateLimit() int {
+	return int(atomic.LoadInt32(&srv.jsonrpcSimulateLimit))
+}
+
+// SetBatchWorkers sets the number of workers handling items of batch
+// requests. Items being handled keep running with the old workers.
+func (srv *Manager) SetBatchWorkers(size int) {
+	defer srv.mtx.Unlock()
+	srv.mtx.Lock()
+
+	atomic.StoreInt32(&srv.jsonrpcBatchWorkers, int32(size))
+	for _, mr := range srv.mrs {
+		mr.SetBatchWorkers(size)
+	}
+}
+
+func (srv *Manager) BatchWorkers() int {
+	return int(atomic.LoadInt32(&srv.jsonrpcBatchWorkers))
+}
+
+// SetBatchWaiters sets the number of workers handling items of waiting
+// methods in batch requests.
+func (srv *Manager) SetBatchWaiters(size int) {
+	defer srv.mtx.Unlock()
+	srv.mtx.Lock()
+
+	atomic.StoreInt32(&srv.jsonrpcBatchWaiters, int32(size))
+	for _, mr := range srv.mrs {
+		mr.SetBatchWaiters(size)
+	}
+}
+
+func (srv *Manager) BatchWaiters() int {
+	return int(atomic.LoadInt32(&srv.jsonrpcBatchWaiters))
+}
+
+func (srv *Manager) addMethodRepository(mr *jsonrpc.MethodRepository) {
+	defer srv.mtx.Unlock()
+	srv.mtx.Lock()
+
+	mr.SetBatchWorkers(srv.BatchWorkers())
+	mr.SetBatchWaiters(srv.BatchWaiters())
+	srv.mrs = append(srv.mrs, mr)
+}
+
 func (srv *Manager) Start() error {
 	srv.logger.Infoln("starting the server")
 	// CORS middleware
@@ -175,6 +238,9 @@ func (srv *Manager) Start() error {
 	// metric
 	srv.RegisterMetricsHandler(srv.e.Group("/metrics"))
 
+	// health, readiness and sync status
+	srv.RegisterHealthHandler(srv.e.Group(""))
+
 	return srv.e.Start(srv.addr)
 }
 
@@ -193,12 +259,14 @@ func (srv *Manager) RegisterAPIHandler(g *echo.Group) {
 		return func(ctx echo.Context) error {
 			ctx.Set("includeDebug", srv.IncludeDebug())
 			ctx.Set("batchLimit", srv.BatchLimit())
+			ctx.Set("simulateLimit", srv.SimulateLimit())
 			return next(ctx)
 		}
 	})
 
 	// v3 APIs
 	mr := v3.MethodRepository(srv.mtr)
+	srv.addMethodRepository(mr)
 	v3api := rpc.Group("/v3")
 	v3api.Use(JsonRpc(), Chunk())
 	v3api.POST("", mr.Handle, ChainInjector(srv))
@@ -206,6 +274,7 @@ func (srv *Manager) RegisterAPIHandler(g *echo.Group) {
 	v3api.POST("/:channel", mr.Handle, ChainInjector(srv))
 
 	dmr := v3.DebugMethodRepository(srv.mtr)
+	srv.addMethodRepository(dmr)
 	v3dbg := rpc.Group("/v3d")
 	v3dbg.Use(srv.CheckDebug(), JsonRpc(), Chunk())
 	v3dbg.POST("", dmr.Handle, ChainInjector(srv))
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sink
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// fileSink appends notifications to the file as NDJSON.
+type fileSink struct {
+	f *os.File
+}
+
+func (s *fileSink) Send(n *Notification) error {
+	bs, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrap(err, "FailToMarshalNotification")
+	}
+	bs = append(bs, '\n')
+	// failures of writing the file are failures of the delivery, like
+	// the disk being full.
+	if _, err := s.f.Write(bs); err != nil {
+		return newTransportError(err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return newTransportError(err)
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
+
+func newFileSink(cfg *Config, baseDir string) (Sink, error) {
+	fp := cfg.Target
+	if !filepath.IsAbs(fp) {
+		fp = filepath.Join(baseDir, fp)
+	}
+	if err := os.MkdirAll(filepath.Dir(fp), 0700); err != nil {
+		return nil, errors.Wrapf(err, "FailToMakeDirectory(path=%s)", fp)
+	}
+	f, err := os.OpenFile(fp, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "FailToOpenFile(path=%s)", fp)
+	}
+	return &fileSink{f: f}, nil
+}
+
+func init() {
+	RegisterFactory(TypeFile, newFileSink)
+}
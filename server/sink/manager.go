@@ -0,0 +1,383 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sink
+
+import (
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	keyCursorPrefix = "event_sink."
+
+	retryDelayMin = time.Second
+	retryDelayMax = time.Minute
+)
+
+// Manager delivers finalized blocks to the configured sinks.
+// Each sink has its own cursor in db.ChainProperty, which is updated
+// only after the delivery succeeds, so a notification is delivered
+// at least once across restarts.
+type Manager struct {
+	chain   module.Chain
+	log     log.Logger
+	runners []*runner
+
+	// retryDelay is the delay before the first retry of a delivery.
+	retryDelay time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type runner struct {
+	*Manager
+	cfg  *Config
+	sink Sink
+
+	lock   sync.Mutex
+	failed *Status
+}
+
+// Status is the status of a sink. A sink fails on an error other than
+// failures of the delivery, then it stops at Height until the chain is
+// restarted.
+type Status struct {
+	Name   string
+	Failed bool
+	Height int64
+	Error  error
+}
+
+// transportError is a failure of the delivery to the target, like network
+// failures and HTTP errors, which may be recovered by retrying it.
+type transportError struct {
+	error
+}
+
+func newTransportError(err error) error {
+	return &transportError{err}
+}
+
+func isTransportError(err error) bool {
+	_, ok := err.(*transportError)
+	return ok
+}
+
+func CursorKey(name string) []byte {
+	return []byte(keyCursorPrefix + name)
+}
+
+// GetCursor returns the next height to be delivered to the sink.
+// It returns 0 if there is no stored cursor.
+func GetCursor(dbase db.Database, name string) (int64, error) {
+	bk, err := dbase.GetBucket(db.ChainProperty)
+	if err != nil {
+		return 0, err
+	}
+	bs, err := bk.Get(CursorKey(name))
+	if err != nil || bs == nil {
+		return 0, err
+	}
+	var height int64
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func SetCursor(dbase db.Database, name string, height int64) error {
+	bk, err := dbase.GetBucket(db.ChainProperty)
+	if err != nil {
+		return err
+	}
+	return bk.Set(CursorKey(name), codec.BC.MustMarshalToBytes(height))
+}
+
+func (m *Manager) Start() {
+	for _, r := range m.runners {
+		m.wg.Add(1)
+		go r.run()
+	}
+}
+
+func (m *Manager) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+	for _, r := range m.runners {
+		if err := r.sink.Close(); err != nil {
+			m.log.Warnf("fail to close sink name=%s err=%+v", r.cfg.Name, err)
+		}
+	}
+}
+
+func (r *runner) startHeight() (int64, error) {
+	height, err := GetCursor(r.chain.Database(), r.cfg.Name)
+	if err != nil || height > 0 {
+		return height, err
+	}
+	if r.cfg.Height > 0 {
+		return r.cfg.Height, nil
+	}
+	blk, err := r.chain.BlockManager().GetLastBlock()
+	if err != nil {
+		return 0, err
+	}
+	return blk.Height() + 1, nil
+}
+
+func (r *runner) run() {
+	defer r.wg.Done()
+
+	var height int64
+	if !r.retry("get start height", 0, func() (err error) {
+		height, err = r.startHeight()
+		return
+	}) {
+		return
+	}
+	if gh := r.chain.GenesisStorage().Height(); height < gh {
+		height = gh
+	}
+	r.log.Infof("start sink name=%s type=%s height=%d",
+		r.cfg.Name, r.cfg.Type, height)
+
+	bm := r.chain.BlockManager()
+	for {
+		var bch <-chan module.Block
+		if !r.retry("wait block", height, func() (err error) {
+			bch, err = bm.WaitForBlock(height)
+			return
+		}) {
+			return
+		}
+		var blk module.Block
+		select {
+		case <-r.stop:
+			return
+		case blk = <-bch:
+		}
+		var n *Notification
+		if !r.retry("make notification", height, func() (err error) {
+			n, err = r.notificationOf(blk)
+			return
+		}) {
+			return
+		}
+		if n != nil && !r.retry("deliver", height, func() error {
+			return r.sink.Send(n)
+		}) {
+			return
+		}
+		height++
+		if !r.retry("store cursor", height, func() error {
+			return SetCursor(r.chain.Database(), r.cfg.Name, height)
+		}) {
+			return
+		}
+	}
+}
+
+// Status returns status of the sinks.
+func (m *Manager) Status() []Status {
+	ss := make([]Status, len(m.runners))
+	for i, r := range m.runners {
+		ss[i] = r.status()
+	}
+	return ss
+}
+
+func (r *runner) status() Status {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.failed != nil {
+		return *r.failed
+	}
+	return Status{Name: r.cfg.Name}
+}
+
+func (r *runner) fail(op string, height int64, err error) {
+	r.log.Errorf("sink failed to %s name=%s height=%d err=%+v",
+		op, r.cfg.Name, height, err)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.failed = &Status{
+		Name:   r.cfg.Name,
+		Failed: true,
+		Height: height,
+		Error:  err,
+	}
+}
+
+// retry calls f until it succeeds, waiting with exponential backoff between
+// the failures of the delivery, so transient errors don't stop the sink.
+// Other errors aren't recovered by retrying, so the sink fails at the
+// height. It returns false if f fails or the manager is stopped before f
+// succeeds.
+func (r *runner) retry(op string, height int64, f func() error) bool {
+	delay := r.retryDelay
+	for {
+		err := f()
+		if err == nil {
+			return true
+		}
+		if !isTransportError(err) {
+			r.fail(op, height, err)
+			return false
+		}
+		r.log.Warnf("fail to %s name=%s height=%d err=%v retry after %s",
+			op, r.cfg.Name, height, err, delay)
+		select {
+		case <-r.stop:
+			return false
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > retryDelayMax {
+			delay = retryDelayMax
+		}
+	}
+}
+
+// notificationOf returns nil if there is nothing to deliver for the block.
+func (r *runner) notificationOf(blk module.Block) (*Notification, error) {
+	n := &Notification{
+		Hash:      blk.ID(),
+		Height:    common.HexInt64{Value: blk.Height()},
+		PrevHash:  blk.PrevID(),
+		Timestamp: common.HexInt64{Value: blk.Timestamp()},
+	}
+	if r.cfg.Block {
+		js, err := blk.ToJSON(module.JSONVersion3)
+		if err != nil {
+			return nil, err
+		}
+		n.Block = js
+	}
+
+	needReceipts := r.cfg.Results
+	for _, f := range r.cfg.EventFilters {
+		if f.ContainedIn(blk.LogsBloom()) {
+			needReceipts = true
+		}
+	}
+	if needReceipts {
+		if err := r.fillReceipts(n, blk); err != nil {
+			return nil, err
+		}
+	}
+
+	if !r.cfg.Block && !r.cfg.Results && len(n.Events) == 0 {
+		return nil, nil
+	}
+	return n, nil
+}
+
+func (r *runner) fillReceipts(n *Notification, blk module.Block) error {
+	rl, err := r.chain.ServiceManager().ReceiptListFromResult(
+		blk.Result(), module.TransactionGroupNormal)
+	if err != nil {
+		return err
+	}
+	tit := blk.NormalTransactions().Iterator()
+	rit := rl.Iterator()
+	for idx := int32(0); tit.Has() && rit.Has(); idx++ {
+		tx, _, err := tit.Get()
+		if err != nil {
+			return err
+		}
+		rct, err := rit.Get()
+		if err != nil {
+			return err
+		}
+		if r.cfg.Results {
+			js, err := rct.ToJSON(module.JSONVersion3)
+			if err != nil {
+				return err
+			}
+			if res, ok := js.(map[string]interface{}); ok {
+				res["txHash"] = common.HexBytes(tx.ID())
+				res["txIndex"] = common.HexInt32{Value: idx}
+			}
+			n.Results = append(n.Results, js)
+		}
+		for i, f := range r.cfg.EventFilters {
+			es, logs, err := f.MatchWithLogs(rct, true)
+			if err != nil {
+				return err
+			}
+			if len(es) > 0 {
+				n.Events = append(n.Events, &Event{
+					Filter: common.HexInt32{Value: int32(i)},
+					TxHash: tx.ID(),
+					Index:  common.HexInt32{Value: idx},
+					Events: es,
+					Logs:   logs,
+				})
+			}
+		}
+		if err := tit.Next(); err != nil {
+			return err
+		}
+		if err := rit.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewManager returns a manager for the sinks. baseDir is used to resolve
+// relative paths of sink targets.
+func NewManager(c module.Chain, cfgs []*Config, baseDir string) (*Manager, error) {
+	m := &Manager{
+		chain:      c,
+		log:        c.Logger().WithFields(log.Fields{log.FieldKeyModule: "SINK"}),
+		retryDelay: retryDelayMin,
+		stop:       make(chan struct{}),
+	}
+	for _, cfg := range cfgs {
+		if err := cfg.Verify(); err != nil {
+			m.closeSinks()
+			return nil, err
+		}
+		s, err := New(cfg, baseDir)
+		if err != nil {
+			m.closeSinks()
+			return nil, errors.Wrapf(err, "FailToCreateSink(name=%s)", cfg.Name)
+		}
+		m.runners = append(m.runners, &runner{
+			Manager: m,
+			cfg:     cfg,
+			sink:    s,
+		})
+	}
+	return m, nil
+}
+
+func (m *Manager) closeSinks() {
+	for _, r := range m.runners {
+		_ = r.sink.Close()
+	}
+}
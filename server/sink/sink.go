@@ -0,0 +1,146 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sink
+
+import (
+	"encoding/json"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server"
+)
+
+const (
+	TypeFile    = "file"
+	TypeWebhook = "webhook"
+)
+
+// Config is configuration of an event sink.
+type Config struct {
+	// Name identifies the sink. The delivery cursor is stored with it,
+	// so renaming a sink makes it start over.
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Target is a file path for the file sink (relative to the chain
+	// directory) or URL for the webhook sink.
+	Target string `json:"target"`
+
+	// Timeout for a delivery in milli-second (0: uses default value)
+	Timeout int64 `json:"timeout,omitempty"`
+
+	// Height is the first height to deliver if there is no stored
+	// cursor (0: next block of the last finalized block).
+	Height int64 `json:"height,omitempty"`
+
+	Block        bool                  `json:"block,omitempty"`
+	Results      bool                  `json:"results,omitempty"`
+	EventFilters []*server.EventFilter `json:"eventFilters,omitempty"`
+}
+
+func (c *Config) Verify() error {
+	if len(c.Name) == 0 {
+		return errors.IllegalArgumentError.New("EmptySinkName")
+	}
+	if _, ok := factories[c.Type]; !ok {
+		return errors.IllegalArgumentError.Errorf("UnknownSinkType(type=%s)", c.Type)
+	}
+	if len(c.Target) == 0 {
+		return errors.IllegalArgumentError.Errorf("EmptySinkTarget(name=%s)", c.Name)
+	}
+	if c.Height < 0 || c.Timeout < 0 {
+		return errors.IllegalArgumentError.Errorf("NegativeValue(name=%s)", c.Name)
+	}
+	for i, f := range c.EventFilters {
+		if err := f.Compile(); err != nil {
+			return errors.IllegalArgumentError.Wrapf(err,
+				"InvalidEventFilter(name=%s,idx=%d)", c.Name, i)
+		}
+	}
+	return nil
+}
+
+// ParseConfigs parses JSON list of sink configurations and verifies them.
+func ParseConfigs(bs []byte) ([]*Config, error) {
+	var cfgs []*Config
+	if err := json.Unmarshal(bs, &cfgs); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidSinkConfigs")
+	}
+	names := make(map[string]bool)
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			return nil, errors.IllegalArgumentError.New("NullSinkConfig")
+		}
+		if err := cfg.Verify(); err != nil {
+			return nil, err
+		}
+		if names[cfg.Name] {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"DuplicateSinkName(name=%s)", cfg.Name)
+		}
+		names[cfg.Name] = true
+	}
+	return cfgs, nil
+}
+
+// Notification is delivered to a sink for each finalized block.
+type Notification struct {
+	Hash      common.HexBytes `json:"hash"`
+	Height    common.HexInt64 `json:"height"`
+	PrevHash  common.HexBytes `json:"prevHash"`
+	Timestamp common.HexInt64 `json:"timestamp"`
+
+	Block   interface{}   `json:"block,omitempty"`
+	Results []interface{} `json:"results,omitempty"`
+	Events  []*Event      `json:"events,omitempty"`
+}
+
+// Event has events of a transaction matched with one of event filters.
+type Event struct {
+	Filter common.HexInt32   `json:"filter"`
+	TxHash common.HexBytes   `json:"txHash"`
+	Index  common.HexInt32   `json:"index"`
+	Events []common.HexInt32 `json:"events"`
+	Logs   []module.EventLog `json:"logs"`
+}
+
+// Sink delivers notifications to the outside. Send shall return nil only
+// if the notification is delivered. Notifications may be delivered more
+// than once, so receivers are supposed to handle duplicates by height.
+type Sink interface {
+	Send(n *Notification) error
+	Close() error
+}
+
+type Factory func(cfg *Config, baseDir string) (Sink, error)
+
+var factories = map[string]Factory{}
+
+func RegisterFactory(name string, factory Factory) {
+	if _, ok := factories[name]; ok {
+		panic("duplicated sink factory")
+	}
+	factories[name] = factory
+}
+
+func New(cfg *Config, baseDir string) (Sink, error) {
+	if factory, ok := factories[cfg.Type]; ok {
+		return factory(cfg, baseDir)
+	}
+	return nil, errors.NotFoundError.Errorf("UnknownSinkType(type=%s)", cfg.Type)
+}
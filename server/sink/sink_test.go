@@ -0,0 +1,128 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+)
+
+func TestParseConfigs(t *testing.T) {
+	cfgs, err := ParseConfigs([]byte(`[
+		{"name":"f1","type":"file","target":"sink/f1.ndjson","block":true},
+		{"name":"w1","type":"webhook","target":"http://localhost:8080/hook",
+		 "eventFilters":[{"event":"Transfer(Address,Address,int)"}]}
+	]`))
+	assert.NoError(t, err)
+	assert.Len(t, cfgs, 2)
+
+	cases := []string{
+		`[{"type":"file","target":"f1"}]`,
+		`[{"name":"f1","type":"unknown","target":"f1"}]`,
+		`[{"name":"f1","type":"file"}]`,
+		`[{"name":"f1","type":"file","target":"f1"},{"name":"f1","type":"file","target":"f2"}]`,
+		`[{"name":"f1","type":"file","target":"f1","eventFilters":[{"event":"Bad"}]}]`,
+		`[null]`,
+		`{}`,
+	}
+	for _, c := range cases {
+		_, err := ParseConfigs([]byte(c))
+		assert.Error(t, err, c)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goloop-sinktest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &Config{Name: "f1", Type: TypeFile, Target: "sub/out.ndjson"}
+	for i := 0; i < 2; i++ {
+		s, err := New(cfg, dir)
+		assert.NoError(t, err)
+		err = s.Send(&Notification{
+			Hash:   []byte{byte(i)},
+			Height: common.HexInt64{Value: int64(i)},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Close())
+	}
+
+	f, err := os.Open(filepath.Join(dir, "sub", "out.ndjson"))
+	assert.NoError(t, err)
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	var heights []int64
+	for sc.Scan() {
+		var n Notification
+		assert.NoError(t, json.Unmarshal(sc.Bytes(), &n))
+		heights = append(heights, n.Height.Value)
+	}
+	assert.Equal(t, []int64{0, 1}, heights)
+}
+
+func TestCursor(t *testing.T) {
+	dbase := db.NewMapDB()
+	h, err := GetCursor(dbase, "f1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, h)
+
+	assert.NoError(t, SetCursor(dbase, "f1", 10))
+	h, err = GetCursor(dbase, "f1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, h)
+
+	h, err = GetCursor(dbase, "f2")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, h)
+}
+
+func TestRunner_Retry(t *testing.T) {
+	r := &runner{
+		Manager: &Manager{
+			log:        log.New(),
+			retryDelay: time.Millisecond,
+			stop:       make(chan struct{}),
+		},
+		cfg: &Config{Name: "f1"},
+	}
+	cnt := 0
+	ok := r.retry("test", 1, func() error {
+		if cnt++; cnt < 3 {
+			return newTransportError(errors.New("TransientError"))
+		}
+		return nil
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 3, cnt)
+	assert.False(t, r.status().Failed)
+
+	// other errors aren't retried, and the sink fails at the height
+	cnt = 0
+	ok = r.retry("test", 2, func() error {
+		cnt++
+		return errors.InvalidStateError.New("InvalidBlock")
+	})
+	assert.False(t, ok)
+	assert.Equal(t, 1, cnt)
+	st := r.status()
+	assert.True(t, st.Failed)
+	assert.EqualValues(t, 2, st.Height)
+	assert.True(t, errors.InvalidStateError.Equals(st.Error))
+
+	close(r.stop)
+	ok = r.retry("test", 1, func() error {
+		return newTransportError(errors.New("TransientError"))
+	})
+	assert.False(t, ok)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// webhookSink posts each notification as JSON to the URL.
+// Any response other than 2xx is regarded as a failure of the delivery.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) Send(n *Notification) error {
+	bs, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrap(err, "FailToMarshalNotification")
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(bs))
+	if err != nil {
+		return newTransportError(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newTransportError(errors.Errorf(
+			"WebhookFailure(url=%s,status=%s)", s.url, resp.Status))
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func newWebhookSink(cfg *Config, _ string) (Sink, error) {
+	u, err := url.Parse(cfg.Target)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidURL(url=%s)", cfg.Target)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.IllegalArgumentError.Errorf("UnsupportedScheme(url=%s)", cfg.Target)
+	}
+	timeout := DefaultWebhookTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Millisecond
+	}
+	return &webhookSink{
+		url:    cfg.Target,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func init() {
+	RegisterFactory(TypeWebhook, newWebhookSink)
+}
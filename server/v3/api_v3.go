@@ -3,6 +3,7 @@ package v3
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"sync"
@@ -13,6 +14,7 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	"github.com/icon-project/goloop/server/metric"
@@ -46,6 +48,62 @@ func MethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository {
 	mr.RegisterMethod("icx_getVotesByHeight", getVotesByHeight)
 	mr.RegisterMethod("icx_getProofForResult", getProofForResult)
 	mr.RegisterMethod("icx_getProofForEvents", getProofForEvents)
+	mr.RegisterMethod("icx_getBlockAccumulator", getBlockAccumulator)
+	mr.RegisterMethod("icx_getBlockWitness", getBlockWitness)
+	mr.RegisterMethod("icx_getIScoreHistory", getIScoreHistory)
+	mr.RegisterMethod("icx_getValidationHistory", getValidationHistory)
+
+	mr.SetParams("icx_getBlockByHeight", BlockHeightParam{})
+	mr.SetParams("icx_getBlockByHash", BlockHashParam{})
+	mr.SetParams("icx_call", CallParam{})
+	mr.SetParams("icx_getBalance", AddressParam{})
+	mr.SetParams("icx_getScoreApi", ScoreAddressParam{})
+	mr.SetParams("icx_getTotalSupply", HeightParam{})
+	mr.SetParams("icx_getTransactionResult", TransactionHashParam{})
+	mr.SetParams("icx_getTransactionByHash", TransactionHashParam{})
+	mr.SetParams("icx_sendTransaction", TransactionParam{})
+	mr.SetParams("icx_sendTransactionAndWait", TransactionParam{})
+	mr.SetParams("icx_waitTransactionResult", TransactionHashParam{})
+
+	mr.SetParams("icx_getDataByHash", DataHashParam{})
+	mr.SetParams("icx_getBlockHeaderByHeight", BlockHeightParam{})
+	mr.SetParams("icx_getVotesByHeight", BlockHeightParam{})
+	mr.SetParams("icx_getProofForResult", ProofResultParam{})
+	mr.SetParams("icx_getProofForEvents", ProofEventsParam{})
+	mr.SetParams("icx_getBlockAccumulator", BlockHeightParam{})
+	mr.SetParams("icx_getBlockWitness", BlockWitnessParam{})
+	mr.SetParams("icx_getIScoreHistory", IScoreHistoryParam{})
+	mr.SetParams("icx_getValidationHistory", ValidationHistoryParam{})
+
+	// Schemas of results for msgpack. Strings in results of other methods
+	// are kept as they are.
+	mr.SetResultSchema("icx_getLastBlock", BlockSchema)
+	mr.SetResultSchema("icx_getBlockByHeight", BlockSchema)
+	mr.SetResultSchema("icx_getBlockByHash", BlockSchema)
+	mr.SetResultSchema("icx_getBalance", IntSchema)
+	mr.SetResultSchema("icx_getTotalSupply", IntSchema)
+	mr.SetResultSchema("icx_getTransactionResult", TransactionResultSchema)
+	mr.SetResultSchema("icx_getTransactionByHash", TransactionSchema)
+	mr.SetResultSchema("icx_sendTransaction", HashSchema)
+	mr.SetResultSchema("icx_sendTransactionAndWait", TransactionResultSchema)
+	mr.SetResultSchema("icx_waitTransactionResult", TransactionResultSchema)
+
+	// Costs in a batch request for the methods reading small data. Others
+	// cost jsonrpc.DefaultMethodCost.
+	mr.SetCost("icx_getBalance", 1)
+	mr.SetCost("icx_getTotalSupply", 1)
+	mr.SetCost("icx_getTransactionResult", 1)
+	mr.SetCost("icx_getTransactionByHash", 1)
+
+	// Methods blocking until the result, handled by their own workers in
+	// a batch request.
+	mr.SetWaiting("icx_sendTransactionAndWait")
+	mr.SetWaiting("icx_waitTransactionResult")
+
+	mr.RegisterDiscover(jsonrpc.OpenRPCInfo{
+		Title:   "JSON-RPC v3 API",
+		Version: string(VersionValue),
+	})
 
 	mr.SetAllowedNotification("icx_sendTransaction")
 	mr.SetAllowedNotification("icx_sendTransactionAndWait")
@@ -435,6 +493,9 @@ func sendTransaction(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{},
 	}
 
 	sm := chain.ServiceManager()
+	if sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
 
 	var state []byte
 	var height int64
@@ -679,6 +740,206 @@ func getProofForEvents(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{
 	return proofs, nil
 }
 
+func getBlockAccumulator(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param BlockHeightParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	height, err := param.Height.ParseInt(64)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	bm := chain.BlockManager()
+	if bm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	acc, err := bm.GetBlockAccumulator(height)
+	if errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	roots := make([]common.HexBytes, len(acc.Roots))
+	for i, root := range acc.Roots {
+		roots[i] = root
+	}
+	return map[string]interface{}{
+		"height": intconv.FormatInt(acc.Height),
+		"base":   intconv.FormatInt(acc.Base),
+		"roots":  roots,
+		"root":   common.HexBytes(block.AccumulatorRootHash(acc)),
+	}, nil
+}
+
+func getBlockWitness(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param BlockWitnessParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	height, err := param.Height.ParseInt(64)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	bm := chain.BlockManager()
+	if bm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	var at int64
+	if param.At == "" {
+		blk, err := bm.GetLastBlock()
+		if err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+		at = blk.Height()
+	} else if at, err = param.At.ParseInt(64); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	witness, err := bm.GetBlockWitness(height, at)
+	if errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	hashes := make([]common.HexBytes, len(witness))
+	for i, hv := range witness {
+		hashes[i] = hv
+	}
+	return map[string]interface{}{
+		"height":  intconv.FormatInt(height),
+		"at":      intconv.FormatInt(at),
+		"witness": hashes,
+	}, nil
+}
+
+const maxHistoryLimit = 100
+
+// historyLimitOf returns the limit of the history query, which is
+// maxHistoryLimit if it's not specified.
+func historyLimitOf(v jsonrpc.HexInt) (int, error) {
+	limit := int64(maxHistoryLimit)
+	if v != "" {
+		var err error
+		if limit, err = v.Int64(); err != nil {
+			return 0, err
+		}
+	}
+	if limit <= 0 || limit > maxHistoryLimit {
+		return 0, errors.IllegalArgumentError.Errorf(
+			"InvalidLimit(limit=%d,max=%d)", limit, maxHistoryLimit)
+	}
+	return int(limit), nil
+}
+
+func getIScoreHistory(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param IScoreHistoryParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	var start int64
+	if param.StartHeight != "" {
+		var err error
+		if start, err = param.StartHeight.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	}
+	limit, err := historyLimitOf(param.Limit)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	sm := chain.ServiceManager()
+	if sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	history, err := sm.GetRewardHistory(param.Address.Address(), start, limit)
+	if errors.UnsupportedError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeMethodNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	return history, nil
+}
+
+func getValidationHistory(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param ValidationHistoryParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	var start, end int64
+	if param.StartHeight != "" {
+		var err error
+		if start, err = param.StartHeight.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	}
+	if param.EndHeight != "" {
+		var err error
+		if end, err = param.EndHeight.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	}
+	if start < 0 || end < 0 || (end > 0 && end < start) {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"InvalidRange(start=%d,end=%d)", start, end)
+	}
+	limit, err := historyLimitOf(param.Limit)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+	block, err := bm.GetLastBlock()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	history, err := sm.GetValidationHistory(block.Result(), param.Address.Address(), start, end, limit)
+	if errors.UnsupportedError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeMethodNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	return history, nil
+}
+
 // convert TransactionList to []Transaction
 func convertTransactionList(txs module.TransactionList, version module.JSONVersion) ([]interface{}, error) {
 	list := []interface{}{}
@@ -862,6 +1123,27 @@ func DebugMethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository
 
 	mr.RegisterMethod("debug_getTrace", getTrace)
 	mr.RegisterMethod("debug_estimateStep", estimateStep)
+	mr.RegisterMethod("debug_simulateTransactions", simulateTransactions)
+	mr.RegisterMethod("debug_getPendingTransactions", getPendingTransactions)
+	mr.RegisterMethod("debug_getPendingTransaction", getPendingTransaction)
+	mr.RegisterMethod("debug_getTransactionPoolUsage", getTransactionPoolUsage)
+	mr.RegisterMethod("debug_getStorageUsages", getStorageUsages)
+
+	mr.SetParams("debug_getTrace", TransactionHashParam{})
+	mr.SetParams("debug_estimateStep", TransactionParamForEstimate{})
+	mr.SetParams("debug_simulateTransactions", SimulateTransactionsParam{})
+	mr.SetParams("debug_getPendingTransactions", PendingTransactionsParam{})
+	mr.SetParams("debug_getPendingTransaction", TransactionHashParam{})
+	mr.SetParams("debug_getStorageUsages", StorageUsagesParam{})
+
+	mr.SetCost("debug_getTrace", 20)
+	mr.SetCost("debug_estimateStep", 50)
+	mr.SetCost("debug_simulateTransactions", 50)
+
+	mr.RegisterDiscover(jsonrpc.OpenRPCInfo{
+		Title:   "JSON-RPC v3 DEBUG API",
+		Version: string(VersionValue),
+	})
 
 	return mr
 }
@@ -872,6 +1154,7 @@ type traceCallback struct {
 	last    error
 	ts      time.Time
 	channel chan interface{}
+	profile module.StepProfile
 }
 
 type traceLog struct {
@@ -892,6 +1175,13 @@ func (t *traceCallback) OnLog(level module.TraceLevel, msg string) {
 	t.logs = append(t.logs, traceLog{level, msg, int64(dur)})
 }
 
+func (t *traceCallback) OnStepProfile(p module.StepProfile) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.profile = p
+}
+
 func (t *traceCallback) OnEnd(e error) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
@@ -909,6 +1199,11 @@ func (t *traceCallback) result() interface{} {
 	result := map[string]interface{}{
 		"logs": t.logs,
 	}
+	if t.profile != nil {
+		if jso, err := t.profile.ToJSON(module.JSONVersion3); err == nil {
+			result["steps"] = jso
+		}
+	}
 	if t.last == nil {
 		result["status"] = "0x1"
 	} else {
@@ -1041,11 +1336,26 @@ func estimateStep(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 	bi := common.NewBlockInfo(blk.Height()+1, newTS)
 
 	// execute transaction
+	js := params.RawMessage()
+	var ti *module.TraceInfo
+	var profiler *stepProfiler
+	if param.Profile.Value() != 0 {
+		if js, err = removeParam(js, "profile"); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+		profiler = new(stepProfiler)
+		ti = &module.TraceInfo{
+			Group:    module.TransactionGroupNormal,
+			Index:    0,
+			Callback: profiler,
+		}
+	}
 	rct, err := sm.ExecuteTransaction(
 		blk.Result(),
 		blk.NextValidators().Hash(),
-		params.RawMessage(),
+		js,
 		bi,
+		ti,
 	)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
@@ -1060,5 +1370,338 @@ func estimateStep(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 	}
 	steps := new(common.HexInt)
 	steps.Set(rct.StepUsed())
+	if profiler != nil && profiler.profile != nil {
+		jso, err := profiler.profile.ToJSON(module.JSONVersion3)
+		if err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+		result := jso.(map[string]interface{})
+		result["stepUsed"] = steps
+		return result, nil
+	}
 	return steps, nil
 }
+
+func simulateTransactions(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	var param SimulateTransactionsParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	if limit := ctx.SimulateLimit(); len(param.Transactions) > limit {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"TooManyTransactions(cnt=%d,limit=%d)",
+			len(param.Transactions), limit)
+	}
+	var raw struct {
+		Transactions []json.RawMessage `json:"transactions"`
+	}
+	if err := json.Unmarshal(params.RawMessage(), &raw); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	txs := make([][]byte, len(raw.Transactions))
+	for i, js := range raw.Transactions {
+		if param.Transactions[i].Profile != "" {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+				"ProfileNotSupported(idx=%d)", i)
+		}
+		txs[i] = js
+	}
+
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("ChannelStopped")
+	}
+
+	blk, err := getBlock(bm, param.Height)
+	if errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	// the transactions are executed as a block following the base block.
+	var ts int64
+	if param.Timestamp != "" {
+		if ts, err = param.Timestamp.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	} else if nblk, err := bm.GetBlockByHeight(blk.Height() + 1); err == nil {
+		ts = nblk.Timestamp()
+	} else {
+		ts = common.UnixMicroFromTime(time.Now())
+		if ts <= blk.Timestamp() {
+			ts = blk.Timestamp() + 1
+		}
+	}
+	bi := common.NewBlockInfo(blk.Height()+1, ts)
+
+	rcts, err := sm.SimulateTransactions(
+		blk.Result(),
+		blk.NextValidators().Hash(),
+		txs,
+		bi,
+	)
+	if err != nil {
+		if scoreresult.InvalidParameterError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	receipts := make([]interface{}, len(rcts))
+	for i, rct := range rcts {
+		jso, err := rct.ToJSON(module.JSONVersion3)
+		if err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+		result := jso.(map[string]interface{})
+		result["txIndex"] = "0x" + strconv.FormatInt(int64(i), 16)
+		receipts[i] = result
+	}
+	return map[string]interface{}{
+		"height":    "0x" + strconv.FormatInt(bi.Height(), 16),
+		"timestamp": "0x" + strconv.FormatInt(bi.Timestamp(), 16),
+		"receipts":  receipts,
+	}, nil
+}
+
+const (
+	defaultPendingTransactionsLimit = 100
+	maxPendingTransactionsLimit     = 1000
+)
+
+func transactionGroupName(g module.TransactionGroup) string {
+	if g == module.TransactionGroupPatch {
+		return "patch"
+	}
+	return "normal"
+}
+
+func pendingTransactionToJSON(ptx *module.PendingTransaction) (interface{}, error) {
+	txjs, err := ptx.Transaction.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return nil, err
+	}
+	jso := map[string]interface{}{
+		"txHash":      "0x" + hex.EncodeToString(ptx.Transaction.ID()),
+		"group":       transactionGroupName(ptx.Group),
+		"status":      ptx.Status,
+		"transaction": txjs,
+	}
+	if !ptx.Received.IsZero() {
+		jso["received"] = "0x" + strconv.FormatInt(common.UnixMicroFromTime(ptx.Received), 16)
+	}
+	if ptx.Reason != nil {
+		jso["reason"] = ptx.Reason.Error()
+	}
+	return jso, nil
+}
+
+func getPendingTransactions(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param *PendingTransactionsParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	if param == nil {
+		param = new(PendingTransactionsParam)
+	}
+	group := module.TransactionGroupNormal
+	if param.Group == "patch" {
+		group = module.TransactionGroupPatch
+	}
+	var from module.Address
+	if param.FromAddress != "" {
+		from = param.FromAddress.Address()
+	}
+	var offset int64
+	var err error
+	if param.Offset != "" {
+		if offset, err = param.Offset.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	}
+	limit := int64(defaultPendingTransactionsLimit)
+	if param.Limit != "" {
+		if limit, err = param.Limit.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	}
+	if offset < 0 || limit <= 0 || limit > maxPendingTransactionsLimit {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"InvalidRange(offset=%d,limit=%d)", offset, limit)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	sm := chain.ServiceManager()
+	if sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	ptxs, total := sm.GetPendingTransactions(group, from, int(offset), int(limit))
+	txs := make([]interface{}, len(ptxs))
+	for i, ptx := range ptxs {
+		if txs[i], err = pendingTransactionToJSON(ptx); err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+	}
+	return map[string]interface{}{
+		"total":        "0x" + strconv.FormatInt(int64(total), 16),
+		"offset":       "0x" + strconv.FormatInt(offset, 16),
+		"transactions": txs,
+	}, nil
+}
+
+func getPendingTransaction(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param TransactionHashParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	sm := chain.ServiceManager()
+	if sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	ptx, err := sm.GetPendingTransaction(param.Hash.Bytes())
+	if errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	result, err := pendingTransactionToJSON(ptx)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	return result, nil
+}
+
+func getTransactionPoolUsage(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param struct{}
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	sm := chain.ServiceManager()
+	if sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	usages := sm.GetTransactionPoolUsage()
+	result := make(map[string]interface{}, len(usages))
+	for _, u := range usages {
+		result[transactionGroupName(u.Group)] = map[string]interface{}{
+			"size": "0x" + strconv.FormatInt(int64(u.Size), 16),
+			"used": "0x" + strconv.FormatInt(int64(u.Used), 16),
+		}
+	}
+	return result, nil
+}
+
+const (
+	defaultStorageUsagesLimit = 20
+	maxStorageUsagesLimit     = 1000
+)
+
+func getStorageUsages(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param StorageUsagesParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	limit := int64(defaultStorageUsagesLimit)
+	if param.Limit != "" {
+		var err error
+		if limit, err = param.Limit.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	}
+	if limit <= 0 || limit > maxStorageUsagesLimit {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"InvalidLimit(limit=%d)", limit)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+	block, err := getBlock(bm, param.Height)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	}
+	usages, err := sm.GetStorageUsages(block.Result(), int(limit))
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	contracts := make([]interface{}, len(usages))
+	for i, u := range usages {
+		jso := map[string]interface{}{
+			"keys":  "0x" + strconv.FormatInt(u.Keys, 16),
+			"bytes": "0x" + strconv.FormatInt(u.Bytes, 16),
+		}
+		if u.Address != nil {
+			jso["address"] = u.Address
+		}
+		if len(u.DeployTxHash) > 0 {
+			jso["deployTxHash"] = "0x" + hex.EncodeToString(u.DeployTxHash)
+		}
+		contracts[i] = jso
+	}
+	return map[string]interface{}{
+		"height":    "0x" + strconv.FormatInt(block.Height(), 16),
+		"contracts": contracts,
+	}, nil
+}
+
+// stepProfiler gets step profile of the transaction for estimation
+// ignoring trace logs.
+type stepProfiler struct {
+	profile module.StepProfile
+}
+
+func (p *stepProfiler) OnLog(level module.TraceLevel, msg string) {}
+
+func (p *stepProfiler) OnEnd(e error) {}
+
+func (p *stepProfiler) OnStepProfile(profile module.StepProfile) {
+	p.profile = profile
+}
+
+func removeParam(js []byte, key string) ([]byte, error) {
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(js, &params); err != nil {
+		return nil, err
+	}
+	delete(params, key)
+	return json.Marshal(params)
+}
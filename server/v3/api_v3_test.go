@@ -0,0 +1,48 @@
+package v3
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+func invokeBatch(t *testing.T, mr *jsonrpc.MethodRepository, method string, n int) int {
+	item := `{"jsonrpc":"2.0","method":"` + method + `","id":1}`
+	body := "[" + strings.Repeat(","+item, n)[1:] + "]"
+
+	e := echo.New()
+	e.Validator = jsonrpc.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("includeDebug", false)
+	c.Set("raw", json.RawMessage(body))
+	assert.NoError(t, mr.Handle(c))
+	return rec.Code
+}
+
+func TestMethodRepository_DefaultBatchLimit(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := MethodRepository(mtr)
+	result := func(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+		return "0x1", nil
+	}
+	mr.RegisterMethod("icx_call", result)
+	mr.RegisterMethod("icx_getTransactionResult", result)
+
+	// batches of 10 items were allowed before costs of methods
+	assert.Equal(t, http.StatusOK, invokeBatch(t, mr, "icx_call", 10))
+	assert.Equal(t, http.StatusServiceUnavailable, invokeBatch(t, mr, "icx_call", 11))
+
+	// cheap reads are allowed more
+	assert.Equal(t, http.StatusOK, invokeBatch(t, mr, "icx_getTransactionResult", 100))
+	assert.Equal(t, http.StatusServiceUnavailable, invokeBatch(t, mr, "icx_getTransactionResult", 101))
+}
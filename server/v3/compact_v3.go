@@ -0,0 +1,110 @@
+package v3
+
+import (
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+// Schemas of results for compact form (msgpack).
+// Refer doc/jsonrpc_v3_msgpack.md for details.
+var (
+	IntSchema     = &jsonrpc.CompactSchema{Type: jsonrpc.CompactInt}
+	BytesSchema   = &jsonrpc.CompactSchema{Type: jsonrpc.CompactBytes}
+	HashSchema    = &jsonrpc.CompactSchema{Type: jsonrpc.CompactHash}
+	AddressSchema = &jsonrpc.CompactSchema{Type: jsonrpc.CompactAddress}
+
+	EventLogSchema = &jsonrpc.CompactSchema{
+		Fields: map[string]*jsonrpc.CompactSchema{
+			"scoreAddress": AddressSchema,
+		},
+		Convert: compactEventValues,
+	}
+
+	TransactionSchema = &jsonrpc.CompactSchema{
+		Fields: map[string]*jsonrpc.CompactSchema{
+			"version":     IntSchema,
+			"from":        AddressSchema,
+			"to":          AddressSchema,
+			"value":       IntSchema,
+			"stepLimit":   IntSchema,
+			"fee":         IntSchema,
+			"timestamp":   IntSchema,
+			"nid":         IntSchema,
+			"nonce":       IntSchema,
+			"txHash":      HashSchema,
+			"tx_hash":     HashSchema,
+			"data":        BytesSchema,
+			"blockHash":   HashSchema,
+			"blockHeight": IntSchema,
+			"txIndex":     IntSchema,
+		},
+	}
+
+	BlockSchema = &jsonrpc.CompactSchema{
+		Fields: map[string]*jsonrpc.CompactSchema{
+			"height":                     IntSchema,
+			"time_stamp":                 IntSchema,
+			"block_hash":                 HashSchema,
+			"prev_block_hash":            HashSchema,
+			"merkle_tree_root_hash":      HashSchema,
+			"peer_id":                    AddressSchema,
+			"confirmed_transaction_list": TransactionSchema,
+			"patch_transaction_list":     TransactionSchema,
+		},
+	}
+
+	TransactionResultSchema = &jsonrpc.CompactSchema{
+		Fields: map[string]*jsonrpc.CompactSchema{
+			"status":             IntSchema,
+			"to":                 AddressSchema,
+			"failure":            {Fields: map[string]*jsonrpc.CompactSchema{"code": IntSchema}},
+			"txHash":             HashSchema,
+			"txIndex":            IntSchema,
+			"blockHeight":        IntSchema,
+			"blockHash":          HashSchema,
+			"cumulativeStepUsed": IntSchema,
+			"stepUsed":           IntSchema,
+			"stepPrice":          IntSchema,
+			"scoreAddress":       AddressSchema,
+			"eventLogs":          EventLogSchema,
+			"logsBloom":          BytesSchema,
+		},
+	}
+)
+
+// compactEventValues converts values of the event log to raw bytes with
+// the types in the signature. Values are kept if they don't match the
+// signature.
+func compactEventValues(obj map[string]interface{}) {
+	indexed, _ := obj["indexed"].([]interface{})
+	data, _ := obj["data"].([]interface{})
+	if len(indexed) == 0 {
+		return
+	}
+	sig, ok := indexed[0].(string)
+	if !ok {
+		return
+	}
+	_, pts := txresult.DecomposeEventSignature(sig)
+	if len(pts)+1 != len(indexed)+len(data) {
+		return
+	}
+	values := make([]interface{}, len(pts))
+	for i := range pts {
+		var v interface{}
+		if i+1 < len(indexed) {
+			v = indexed[i+1]
+		} else {
+			v = data[i+1-len(indexed)]
+		}
+		bs, err := txresult.EventDataToBytesByType(pts[i], v)
+		if err != nil {
+			return
+		}
+		if bs != nil {
+			values[i] = bs
+		}
+	}
+	copy(indexed[1:], values)
+	copy(data, values[len(indexed)-1:])
+}
@@ -0,0 +1,309 @@
+package v3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+const (
+	testBlockResult = `{
+		"version": "2.0",
+		"height": 512,
+		"time_stamp": 1559204699330360,
+		"block_hash": "8e25acc5b5c74375079d51828760821fc6f54283656620b1d5a715edcc0770c6",
+		"prev_block_hash": "0fdf04d13229482e3533948d4582344a3d44c399e71ab12c653ae57bcbee5d90",
+		"merkle_tree_root_hash": "5c8d4e59ded657c6acbb67030929dfcaf114a268d6d58df53e7174e40db74158",
+		"peer_id": "hx4208599c8f58fed475db747504a80a311a3af63b",
+		"signature": "",
+		"confirmed_transaction_list": [
+			{
+				"version": "0x3",
+				"from": "hx84f6c686fba03bc7ca65d15ae844ee56ff24a32b",
+				"to": "cx244deea00413d85c6637e7fdd53afa697f29d08f",
+				"stepLimit": "0x3e8",
+				"timestamp": "0x58a14bfe9b904",
+				"nid": "0x1",
+				"nonce": "0x1",
+				"signature": "tCUwOb6vsaUKy+NYvmzdJYC0jm3Erd5cR6wKnVuAjzMOECC+t/oK7fG/Tz2Y3C25o0AfCmbneXpias6xco+43wE=",
+				"txHash": "0xd8da71e926052b960def61c64f325412772f8e986f888685bc87c0bc046c2d9f",
+				"dataType": "call",
+				"data": {
+					"method": "transfer",
+					"params": {
+						"_to": "hx244deea00413d85c6637e7fdd53afa697f29d08f",
+						"_value": "0x10",
+						"_data": "0x0102"
+					}
+				}
+			},
+			{
+				"version": "0x3",
+				"from": "hx84f6c686fba03bc7ca65d15ae844ee56ff24a32b",
+				"to": "hx244deea00413d85c6637e7fdd53afa697f29d08f",
+				"value": "0xde0b6b3a7640000000",
+				"stepLimit": "0x3e8",
+				"timestamp": "0x58a14bfe9b904",
+				"nid": "0x1",
+				"signature": "tCUwOb6vsaUKy+NYvmzdJYC0jm3Erd5cR6wKnVuAjzMOECC+t/oK7fG/Tz2Y3C25o0AfCmbneXpias6xco+43wE=",
+				"txHash": "0x8ef3b2a67262b9b1fe4b598059774472e9ccef401734335d87a4ba998cfd40fb",
+				"dataType": "message",
+				"data": "0x48656c6c6f"
+			},
+			{
+				"from": "hx84f6c686fba03bc7ca65d15ae844ee56ff24a32b",
+				"to": "hx244deea00413d85c6637e7fdd53afa697f29d08f",
+				"value": "0xa",
+				"fee": "0x2386f26fc10000",
+				"timestamp": "1519711213236000",
+				"nonce": "8367273",
+				"tx_hash": "b2b9e6e8ac5e6fd9ddf6b2eb0e5a1c7d8e9f0a1b2c3d4e5f60718293a4b5c6d7",
+				"signature": "tCUwOb6vsaUKy+NYvmzdJYC0jm3Erd5cR6wKnVuAjzMOECC+t/oK7fG/Tz2Y3C25o0AfCmbneXpias6xco+43wE=",
+				"method": "icx_sendTransaction"
+			}
+		]
+	}`
+
+	testTransactionResult = `{
+		"blockHash": "0x8ef3b2a67262b9b1fe4b598059774472e9ccef401734335d87a4ba998cfd40fb",
+		"blockHeight": "0x200",
+		"from": "hx84f6c686fba03bc7ca65d15ae844ee56ff24a32b",
+		"nid": "0x1",
+		"signature": "tCUwOb6vsaUKy+NYvmzdJYC0jm3Erd5cR6wKnVuAjzMOECC+t/oK7fG/Tz2Y3C25o0AfCmbneXpias6xco+43wE=",
+		"stepLimit": "0x3e8",
+		"timestamp": "0x58a14bfe9b904",
+		"to": "hx244deea00413d85c6637e7fdd53afa697f29d08f",
+		"txHash": "0xd8da71e926052b960def61c64f325412772f8e986f888685bc87c0bc046c2d9f",
+		"txIndex": "0x0",
+		"value": "0xa",
+		"version": "0x3"
+	}`
+
+	testReceiptResult = `{
+		"status": "0x1",
+		"to": "cx244deea00413d85c6637e7fdd53afa697f29d08f",
+		"txHash": "0xd8da71e926052b960def61c64f325412772f8e986f888685bc87c0bc046c2d9f",
+		"txIndex": "0x1",
+		"blockHeight": "0x200",
+		"blockHash": "0x8ef3b2a67262b9b1fe4b598059774472e9ccef401734335d87a4ba998cfd40fb",
+		"cumulativeStepUsed": "0x1e8480",
+		"stepUsed": "0x186a0",
+		"stepPrice": "0x2e90edd00",
+		"scoreAddress": "cx0000000000000000000000000000000000000001",
+		"eventLogs": [
+			{
+				"scoreAddress": "cx244deea00413d85c6637e7fdd53afa697f29d08f",
+				"indexed": [
+					"Transfer(Address,Address,int,bytes)",
+					"hx84f6c686fba03bc7ca65d15ae844ee56ff24a32b",
+					"hx244deea00413d85c6637e7fdd53afa697f29d08f"
+				],
+				"data": ["-0x81", "0x0102"]
+			},
+			{
+				"scoreAddress": "cx244deea00413d85c6637e7fdd53afa697f29d08f",
+				"indexed": ["Message(str,bool,bytes)", "0x10"],
+				"data": ["0x1", null]
+			},
+			{
+				"scoreAddress": "cx244deea00413d85c6637e7fdd53afa697f29d08f",
+				"indexed": ["Broken(int)"],
+				"data": ["0x1", "0x2"]
+			}
+		],
+		"logsBloom": "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"stepUsedDetails": {
+			"hx84f6c686fba03bc7ca65d15ae844ee56ff24a32b": "0x186a0"
+		}
+	}`
+
+	testFailureResult = `{
+		"status": "0x0",
+		"to": "cx244deea00413d85c6637e7fdd53afa697f29d08f",
+		"txHash": "0xd8da71e926052b960def61c64f325412772f8e986f888685bc87c0bc046c2d9f",
+		"txIndex": "0x1",
+		"blockHeight": "0x200",
+		"blockHash": "0x8ef3b2a67262b9b1fe4b598059774472e9ccef401734335d87a4ba998cfd40fb",
+		"cumulativeStepUsed": "0x1e8480",
+		"stepUsed": "0x186a0",
+		"stepPrice": "0x2e90edd00",
+		"eventLogs": [],
+		"logsBloom": "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"failure": {
+			"code": "0x20",
+			"message": "0x10 is out of range"
+		}
+	}`
+)
+
+// assertCompactValue asserts that the value decoded from msgpack is same
+// as the value in JSON result for the type in the schema.
+func assertCompactValue(t *testing.T, path string, s *jsonrpc.CompactSchema, exp, v interface{}) {
+	switch obj := exp.(type) {
+	case map[string]interface{}:
+		m, ok := v.(map[string]interface{})
+		if !assert.True(t, ok, "%s: not a map %T", path, v) {
+			return
+		}
+		if s == EventLogSchema {
+			assertEventValues(t, path, obj, m)
+			assertCompactValue(t, path+".scoreAddress", AddressSchema, obj["scoreAddress"], m["scoreAddress"])
+			return
+		}
+		assert.Len(t, m, len(obj), path)
+		for k, value := range obj {
+			var fs *jsonrpc.CompactSchema
+			if s != nil {
+				fs = s.Fields[k]
+			}
+			assertCompactValue(t, path+"."+k, fs, value, m[k])
+		}
+	case []interface{}:
+		items, ok := v.([]interface{})
+		if !assert.True(t, ok, "%s: not an array %T", path, v) {
+			return
+		}
+		assert.Len(t, items, len(obj), path)
+		for i, value := range obj {
+			assertCompactValue(t, path, s, value, items[i])
+		}
+	case json.Number:
+		i, err := obj.Int64()
+		assert.NoError(t, err, path)
+		assert.EqualValues(t, i, v, path)
+	case string:
+		t.Run(path, func(t *testing.T) {
+			assertCompactString(t, s, obj, v)
+		})
+	default:
+		assert.Equal(t, exp, v, path)
+	}
+}
+
+func assertCompactString(t *testing.T, s *jsonrpc.CompactSchema, exp string, v interface{}) {
+	typ := jsonrpc.CompactAny
+	if s != nil {
+		typ = s.Type
+	}
+	prefixed := strings.HasPrefix(exp, "0x") || strings.HasPrefix(exp, "-0x")
+	switch {
+	case typ == jsonrpc.CompactInt && prefixed:
+		var value big.Int
+		assert.NoError(t, intconv.ParseBigInt(&value, exp))
+		var decoded big.Int
+		switch n := v.(type) {
+		case int64:
+			decoded.SetInt64(n)
+		case uint64:
+			decoded.SetUint64(n)
+		case []byte:
+			intconv.BigIntSetBytes(&decoded, n)
+		default:
+			t.Errorf("invalid type %T for int %s", v, exp)
+		}
+		assert.Equal(t, 0, value.Cmp(&decoded), "%s != %s", exp, &decoded)
+	case (typ == jsonrpc.CompactBytes || typ == jsonrpc.CompactHash) && prefixed:
+		bs, ok := v.([]byte)
+		if assert.True(t, ok, "invalid type %T for bytes %s", v, exp) {
+			assert.Equal(t, exp, "0x"+hex.EncodeToString(bs))
+		}
+	case typ == jsonrpc.CompactHash:
+		assert.Equal(t, exp, hex.EncodeToString(v.([]byte)))
+	case typ == jsonrpc.CompactAddress && exp != "":
+		bs, ok := v.([]byte)
+		if assert.True(t, ok, "invalid type %T for address %s", v, exp) {
+			addr, err := common.NewAddress(bs)
+			assert.NoError(t, err)
+			assert.Equal(t, exp, addr.String())
+		}
+	default:
+		assert.Equal(t, exp, v)
+	}
+}
+
+// assertEventValues asserts that the values decoded from msgpack are
+// same as the values in JSON if they are decoded with the types in the
+// signature. Values not matching the signature are kept.
+func assertEventValues(t *testing.T, path string, exp, v map[string]interface{}) {
+	indexed := exp["indexed"].([]interface{})
+	data := exp["data"].([]interface{})
+	values := append(append([]interface{}{}, indexed[1:]...), data...)
+	decoded := append(append([]interface{}{}, v["indexed"].([]interface{})[1:]...), v["data"].([]interface{})...)
+	assert.Equal(t, indexed[0], v["indexed"].([]interface{})[0], path)
+
+	_, pts := txresult.DecomposeEventSignature(indexed[0].(string))
+	if len(pts) != len(values) {
+		assert.Equal(t, values, decoded, path)
+		return
+	}
+	for i, value := range values {
+		if value == nil {
+			assert.Nil(t, decoded[i], path)
+			continue
+		}
+		bs, ok := decoded[i].([]byte)
+		if assert.True(t, ok, "%s: invalid type %T for %s", path, decoded[i], value) {
+			jso, err := txresult.DecodeForJSONByType(pts[i], bs)
+			assert.NoError(t, err, path)
+			js1, _ := json.Marshal(value)
+			js2, _ := json.Marshal(jso)
+			assert.JSONEq(t, string(js1), string(js2), path)
+		}
+	}
+}
+
+func TestMethodRepository_CompactResults(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := MethodRepository(mtr)
+
+	cases := []struct {
+		method string
+		result string
+	}{
+		{"icx_getLastBlock", testBlockResult},
+		{"icx_getBlockByHeight", testBlockResult},
+		{"icx_getBlockByHash", testBlockResult},
+		{"icx_getTransactionByHash", testTransactionResult},
+		{"icx_getTransactionResult", testReceiptResult},
+		{"icx_getTransactionResult", testFailureResult},
+		{"icx_waitTransactionResult", testReceiptResult},
+		{"icx_sendTransactionAndWait", testFailureResult},
+		{"icx_sendTransaction", `"0xd8da71e926052b960def61c64f325412772f8e986f888685bc87c0bc046c2d9f"`},
+		{"icx_getBalance", `"0xde0b6b3a7640000"`},
+		{"icx_getTotalSupply", `"0x2961fff8ca4a62327800000"`},
+		// results without schema are kept as they are
+		{"icx_call", `"0x10"`},
+		{"icx_call", `{"height":"0x10","owner":"hx84f6c686fba03bc7ca65d15ae844ee56ff24a32b"}`},
+	}
+	for _, c := range cases {
+		t.Run(c.method, func(t *testing.T) {
+			dec := json.NewDecoder(strings.NewReader(c.result))
+			dec.UseNumber()
+			var result interface{}
+			assert.NoError(t, dec.Decode(&result))
+
+			schema := mr.GetResultSchema(c.method)
+			bs, err := jsonrpc.MarshalMsgpack(result, schema)
+			assert.NoError(t, err)
+
+			var decoded interface{}
+			mdec := msgpack.NewDecoder(bytes.NewReader(bs))
+			mdec.UseDecodeInterfaceLoose(true)
+			assert.NoError(t, mdec.Decode(&decoded))
+
+			assertCompactValue(t, "result", schema, result, decoded)
+		})
+	}
+}
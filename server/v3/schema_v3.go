@@ -57,6 +57,25 @@ type TransactionParamForEstimate struct {
 	Nonce       jsonrpc.HexInt  `json:"nonce,omitempty" validate:"optional,t_int"`
 	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit"`
 	Data        interface{}     `json:"data,omitempty"`
+	Profile     jsonrpc.HexInt  `json:"profile,omitempty" validate:"optional,t_int"`
+}
+
+type SimulateTransactionsParam struct {
+	Transactions []TransactionParamForEstimate `json:"transactions" validate:"required,gt=0,dive"`
+	Height       jsonrpc.HexInt                `json:"height,omitempty" validate:"optional,t_int"`
+	Timestamp    jsonrpc.HexInt                `json:"timestamp,omitempty" validate:"optional,t_int"`
+}
+
+type PendingTransactionsParam struct {
+	Group       string          `json:"group,omitempty" validate:"optional,oneof=normal patch"`
+	FromAddress jsonrpc.Address `json:"from,omitempty" validate:"optional,t_addr_eoa"`
+	Offset      jsonrpc.HexInt  `json:"offset,omitempty" validate:"optional,t_int"`
+	Limit       jsonrpc.HexInt  `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+type StorageUsagesParam struct {
+	Height jsonrpc.HexInt `json:"height,omitempty" validate:"optional,t_int"`
+	Limit  jsonrpc.HexInt `json:"limit,omitempty" validate:"optional,t_int"`
 }
 
 type TransactionParam struct {
@@ -82,6 +101,24 @@ type ProofResultParam struct {
 	Index     jsonrpc.HexInt   `json:"index" validate:"required,t_int"`
 }
 
+type BlockWitnessParam struct {
+	Height jsonrpc.HexInt `json:"height" validate:"required,t_int"`
+	At     jsonrpc.HexInt `json:"at,omitempty" validate:"optional,t_int"`
+}
+
+type IScoreHistoryParam struct {
+	Address     jsonrpc.Address `json:"address" validate:"required,t_addr_eoa"`
+	StartHeight jsonrpc.HexInt  `json:"startHeight,omitempty" validate:"optional,t_int"`
+	Limit       jsonrpc.HexInt  `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+type ValidationHistoryParam struct {
+	Address     jsonrpc.Address `json:"address" validate:"required,t_addr_eoa"`
+	StartHeight jsonrpc.HexInt  `json:"startHeight,omitempty" validate:"optional,t_int"`
+	EndHeight   jsonrpc.HexInt  `json:"endHeight,omitempty" validate:"optional,t_int"`
+	Limit       jsonrpc.HexInt  `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
 type ProofEventsParam struct {
 	BlockHash jsonrpc.HexBytes `json:"hash" validate:"required,t_hash"`
 	Index     jsonrpc.HexInt   `json:"index" validate:"required,t_int"`
@@ -86,3 +86,43 @@ func TestTransactionParamValidator(t *testing.T) {
 		assert.Fail(t, "validate fail", err.Error())
 	}
 }
+
+func TestSimulateTransactionsParamValidator(t *testing.T) {
+	validator := jsonrpc.NewValidator()
+	RegisterValidationRule(validator)
+
+	var param SimulateTransactionsParam
+	params := []byte(`
+		{
+			"transactions": [
+				{
+					"version": "0x3",
+					"from": "hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31",
+					"to": "cx059e19601bcb1424884f4ef19addc0a03de9e9cd",
+					"timestamp": "0x563a6cf330136",
+					"nid": "0x3",
+					"dataType": "call",
+					"data": { "method": "approve" }
+				},
+				{
+					"version": "0x3",
+					"from": "hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31",
+					"to": "cx059e19601bcb1424884f4ef19addc0a03de9e9cd",
+					"timestamp": "0x563a6cf330137",
+					"nid": "0x3",
+					"dataType": "call",
+					"data": { "method": "swap" }
+				}
+			],
+			"height": "0x10"
+		}
+	`)
+	assert.NoError(t, json.Unmarshal(params, &param))
+	assert.NoError(t, validator.Validate(&param))
+
+	param.Transactions[1].FromAddress = "cx059e19601bcb1424884f4ef19addc0a03de9e9cd"
+	assert.Error(t, validator.Validate(&param))
+
+	param.Transactions = nil
+	assert.Error(t, validator.Validate(&param))
+}
@@ -15,8 +15,9 @@ import (
 )
 
 type wsSession struct {
-	c     *websocket.Conn
-	chain module.Chain
+	c       *websocket.Conn
+	chain   module.Chain
+	msgpack bool
 }
 
 type wsSessionManager struct {
@@ -33,14 +34,14 @@ func newWSSessionManager(logger log.Logger) *wsSessionManager {
 	}
 }
 
-func (wm *wsSessionManager) NewSession(c *websocket.Conn, chain module.Chain) *wsSession {
+func (wm *wsSessionManager) NewSession(c *websocket.Conn, chain module.Chain, msgpack bool) *wsSession {
 	wm.Lock()
 	defer wm.Unlock()
 
 	if len(wm.sessions) >= wm.maxSession {
 		return nil
 	}
-	wss := &wsSession{c, chain}
+	wss := &wsSession{c, chain, msgpack}
 	wm.sessions = append(wm.sessions, wss)
 	return wss
 }
@@ -121,7 +122,8 @@ func (wm *wsSessionManager) initSession(ctx echo.Context, reqPtr interface{}) (*
 		return nil, err
 	}
 
-	wss := wm.NewSession(c, chain)
+	accept := ctx.Request().Header.Get(echo.HeaderAccept)
+	wss := wm.NewSession(c, chain, jsonrpc.IsMsgpack(accept))
 	if wss == nil {
 		wsResponse := WSResponse{
 			Code:    int(jsonrpc.ErrorLackOfResource),
@@ -147,10 +149,19 @@ func (wss *wsSession) response(code int, msg string) error {
 		Code:    code,
 		Message: msg,
 	}
-	return wss.WriteJSON(&wsResponse)
+	return wss.Write(&wsResponse, nil)
 }
 
-func (wss *wsSession) WriteJSON(v interface{}) error {
+// Write sends the value as JSON text message, or as compact msgpack binary
+// message with the schema if the client accepts msgpack.
+func (wss *wsSession) Write(v interface{}, s *jsonrpc.CompactSchema) error {
+	if wss.msgpack {
+		bs, err := jsonrpc.MarshalMsgpack(v, s)
+		if err != nil {
+			return err
+		}
+		return wss.c.WriteMessage(websocket.BinaryMessage, bs)
+	}
 	return wss.c.WriteJSON(v)
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
 )
 
 type BlockRequest struct {
@@ -23,6 +24,16 @@ type BlockNotification struct {
 	Events  [][][]common.HexInt32 `json:"events,omitempty"`
 }
 
+// blockNotificationSchema is the schema of BlockNotification for msgpack.
+var blockNotificationSchema = &jsonrpc.CompactSchema{
+	Fields: map[string]*jsonrpc.CompactSchema{
+		"hash":    v3.HashSchema,
+		"height":  v3.IntSchema,
+		"indexes": v3.IntSchema,
+		"events":  v3.IntSchema,
+	},
+}
+
 func (wm *wsSessionManager) RunBlockSession(ctx echo.Context) error {
 	var br BlockRequest
 	wss, err := wm.initSession(ctx, &br)
@@ -115,7 +126,7 @@ loop:
 					}
 				}
 			}
-			if err = wss.WriteJSON(&br.bn); err != nil {
+			if err = wss.Write(&br.bn, blockNotificationSchema); err != nil {
 				wm.logger.Infof("fail to write json BlockNotification err:%+v\n", err)
 				break loop
 			}
@@ -128,7 +139,7 @@ loop:
 
 func (r *BlockRequest) compile() error {
 	for i, f := range r.EventFilters {
-		if err := f.compile(); err != nil {
+		if err := f.Compile(); err != nil {
 			return fmt.Errorf("fail to compile idx:%d, err:%v", i, err)
 		}
 	}
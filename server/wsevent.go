@@ -10,6 +10,7 @@ import (
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
 	"github.com/icon-project/goloop/service/txresult"
 )
 
@@ -39,6 +40,17 @@ type EventNotification struct {
 	Logs   []module.EventLog `json:"logs,omitempty"`
 }
 
+// eventNotificationSchema is the schema of EventNotification for msgpack.
+var eventNotificationSchema = &jsonrpc.CompactSchema{
+	Fields: map[string]*jsonrpc.CompactSchema{
+		"hash":   v3.HashSchema,
+		"height": v3.IntSchema,
+		"index":  v3.IntSchema,
+		"events": v3.IntSchema,
+		"logs":   v3.EventLogSchema,
+	},
+}
+
 func (wm *wsSessionManager) RunEventSession(ctx echo.Context) error {
 	var er EventRequest
 	wss, err := wm.initSession(ctx, &er)
@@ -47,7 +59,7 @@ func (wm *wsSessionManager) RunEventSession(ctx echo.Context) error {
 	}
 	defer wm.StopSession(wss)
 
-	if err := er.compile(); err != nil {
+	if err := er.Compile(); err != nil {
 		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams), "bad event request parameter")
 		return nil
 	}
@@ -97,14 +109,14 @@ loop:
 				if err != nil {
 					break loop
 				}
-				if es, el, err := er.matchWithLogs(r, er.Logs.Value != 0); err == nil && len(es) > 0 {
+				if es, el, err := er.MatchWithLogs(r, er.Logs.Value != 0); err == nil && len(es) > 0 {
 					var en EventNotification
 					en.Height.Value = h
 					en.Hash = blk.ID()
 					en.Index.Value = index
 					en.Events = es
 					en.Logs = el
-					if err := wss.WriteJSON(&en); err != nil {
+					if err := wss.Write(&en, eventNotificationSchema); err != nil {
 						wm.logger.Infof("fail to write json EventNotification err:%+v\n", err)
 						break loop
 					}
@@ -118,7 +130,8 @@ loop:
 	return nil
 }
 
-func (f *EventFilter) compile() error {
+// Compile validates the filter and prepares it for matching.
+func (f *EventFilter) Compile() error {
 	lb := txresult.NewLogsBloom(nil)
 	if f.Addr != nil {
 		lb.AddAddressOfLog(f.Addr)
@@ -157,6 +170,12 @@ func (f *EventFilter) compile() error {
 	return nil
 }
 
+// ContainedIn returns false if the logs bloom can't have any event
+// matching the filter.
+func (f *EventFilter) ContainedIn(lb module.LogsBloom) bool {
+	return lb.Contain(f.lb)
+}
+
 // bytesEqual check equality of byte slice.
 // But it doesn't assume nil as empty bytes.
 func bytesEqual(b1 []byte, b2 []byte) bool {
@@ -169,7 +188,9 @@ func bytesEqual(b1 []byte, b2 []byte) bool {
 	return bytes.Equal(b1, b2)
 }
 
-func (f *EventFilter) matchWithLogs(r module.Receipt, includeLogs bool) ([]common.HexInt32, []module.EventLog, error) {
+// MatchWithLogs returns indexes of matching events in the receipt.
+// If includeLogs is true, it also returns the matching event logs.
+func (f *EventFilter) MatchWithLogs(r module.Receipt, includeLogs bool) ([]common.HexInt32, []module.EventLog, error) {
 	var indexes []common.HexInt32
 	var logs []module.EventLog
 	if err := f.filterFunc(r, func(idx int, log module.EventLog) {
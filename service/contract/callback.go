@@ -0,0 +1,364 @@
+package contract
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// CallbackLimitPerBlock is the maximum number of scheduled callbacks
+// executed in a block. Remaining callbacks are executed in the following
+// blocks in the order of their scheduling.
+const CallbackLimitPerBlock = 20
+
+// ScheduledCallback is a callback registered by a contract. The method of
+// the owner is called with its ID at the block of the height. Steps for the
+// call are prepaid by the owner on scheduling, and unused steps are refunded
+// after the call.
+type ScheduledCallback struct {
+	ID        int64
+	Owner     module.Address
+	Height    int64
+	Method    string
+	StepLimit *big.Int
+	StepPrice *big.Int
+}
+
+type scheduledCallbackData struct {
+	Owner     *common.Address
+	Height    int64
+	Method    string
+	StepLimit *big.Int
+	StepPrice *big.Int
+}
+
+// Deposit returns the amount of coins prepaid for the callback.
+func (cb *ScheduledCallback) Deposit() *big.Int {
+	return new(big.Int).Mul(cb.StepLimit, cb.StepPrice)
+}
+
+func (cb *ScheduledCallback) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"id":        intconv.FormatInt(cb.ID),
+		"owner":     cb.Owner,
+		"height":    intconv.FormatInt(cb.Height),
+		"method":    cb.Method,
+		"stepLimit": intconv.FormatBigInt(cb.StepLimit),
+		"stepPrice": intconv.FormatBigInt(cb.StepPrice),
+	}
+}
+
+func callbackDB(as state.AccountState) *containerdb.DictDB {
+	return scoredb.NewDictDB(as, state.VarCallbacks, 1)
+}
+
+// callbackQueueKey is the key of the list for the queue. Lists of the timer
+// use the height as the key, which is always positive.
+const callbackQueueKey = 0
+
+// callbackList is a doubly linked list of callback IDs. The timer has a list
+// for each height, and the queue has the callbacks delayed by
+// CallbackLimitPerBlock in the order of their heights. Links are kept per
+// callback, so a cancelled callback is removed from its list immediately.
+type callbackList struct {
+	key   int64
+	meta  *containerdb.DictDB
+	links *containerdb.DictDB
+}
+
+func callbackLinksDB(as state.AccountState) *containerdb.DictDB {
+	return scoredb.NewDictDB(as, state.VarCallbackLinks, 2)
+}
+
+func newCallbackList(as state.AccountState, key int64) *callbackList {
+	var meta *containerdb.DictDB
+	if key == callbackQueueKey {
+		meta = scoredb.NewDictDB(as, state.VarCallbackQueue, 1)
+	} else {
+		meta = scoredb.NewDictDB(as, state.VarCallbackTimer, 2).GetDB(key)
+	}
+	return &callbackList{
+		key:   key,
+		meta:  meta,
+		links: callbackLinksDB(as),
+	}
+}
+
+// callbackListOf returns the list having the callback of the ID.
+func callbackListOf(as state.AccountState, id int64) *callbackList {
+	return newCallbackList(as, int64Of(callbackLinksDB(as).Get(id, "list")))
+}
+
+func int64Of(v containerdb.Value) int64 {
+	if v == nil {
+		return 0
+	}
+	return v.Int64()
+}
+
+func setOrDelete(db *containerdb.DictDB, key1, key2 interface{}, v int64) error {
+	if v == 0 {
+		return db.Delete(key1, key2)
+	}
+	return db.Set(key1, key2, v)
+}
+
+func (l *callbackList) head() int64 {
+	return int64Of(l.meta.Get("head"))
+}
+
+func (l *callbackList) next(id int64) int64 {
+	return int64Of(l.links.Get(id, "next"))
+}
+
+func (l *callbackList) setMeta(name string, id int64) error {
+	if id == 0 {
+		return l.meta.Delete(name)
+	}
+	return l.meta.Set(name, id)
+}
+
+func (l *callbackList) push(id int64) error {
+	tail := int64Of(l.meta.Get("tail"))
+	if err := setOrDelete(l.links, id, "prev", tail); err != nil {
+		return err
+	}
+	if err := setOrDelete(l.links, id, "list", l.key); err != nil {
+		return err
+	}
+	if tail == 0 {
+		if err := l.setMeta("head", id); err != nil {
+			return err
+		}
+	} else {
+		if err := l.links.Set(tail, "next", id); err != nil {
+			return err
+		}
+	}
+	return l.setMeta("tail", id)
+}
+
+func (l *callbackList) remove(id int64) error {
+	prev := int64Of(l.links.Get(id, "prev"))
+	next := l.next(id)
+	if prev == 0 {
+		if err := l.setMeta("head", next); err != nil {
+			return err
+		}
+	} else {
+		if err := setOrDelete(l.links, prev, "next", next); err != nil {
+			return err
+		}
+	}
+	if next == 0 {
+		if err := l.setMeta("tail", prev); err != nil {
+			return err
+		}
+	} else {
+		if err := setOrDelete(l.links, next, "prev", prev); err != nil {
+			return err
+		}
+	}
+	for _, name := range []string{"prev", "next", "list"} {
+		if err := l.links.Delete(id, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetScheduledCallback returns the callback of the ID. It returns nil if
+// the callback is executed or cancelled.
+func GetScheduledCallback(as state.AccountState, id int64) *ScheduledCallback {
+	v := callbackDB(as).Get(id)
+	if v == nil {
+		return nil
+	}
+	var data scheduledCallbackData
+	codec.BC.MustUnmarshalFromBytes(v.Bytes(), &data)
+	return &ScheduledCallback{
+		ID:        id,
+		Owner:     data.Owner,
+		Height:    data.Height,
+		Method:    data.Method,
+		StepLimit: data.StepLimit,
+		StepPrice: data.StepPrice,
+	}
+}
+
+func setCallback(as state.AccountState, cb *ScheduledCallback) error {
+	data := &scheduledCallbackData{
+		Owner:     common.AddressToPtr(cb.Owner),
+		Height:    cb.Height,
+		Method:    cb.Method,
+		StepLimit: cb.StepLimit,
+		StepPrice: cb.StepPrice,
+	}
+	return callbackDB(as).Set(cb.ID, codec.BC.MustMarshalToBytes(data))
+}
+
+func transferDeposit(wc state.WorldContext, from, to module.Address, amount *big.Int) error {
+	if amount.Sign() == 0 {
+		return nil
+	}
+	as1 := wc.GetAccountState(from.ID())
+	balance := as1.GetBalance()
+	if balance.Cmp(amount) < 0 {
+		return scoreresult.ErrOutOfBalance
+	}
+	as1.SetBalance(new(big.Int).Sub(balance, amount))
+	as2 := wc.GetAccountState(to.ID())
+	as2.SetBalance(new(big.Int).Add(as2.GetBalance(), amount))
+	return nil
+}
+
+// ScheduleCallback registers a callback of the owner at the height, and
+// returns ID of the callback. Steps for the call are paid by the owner with
+// the current step price, and kept by the system until the callback is
+// executed or cancelled.
+func ScheduleCallback(cc CallContext, owner module.Address, height int64, method string, stepLimit *big.Int) (int64, error) {
+	if owner == nil || !owner.IsContract() {
+		return 0, scoreresult.AccessDeniedError.New("OnlyContractCanSchedule")
+	}
+	if height <= cc.BlockHeight() {
+		return 0, scoreresult.InvalidParameterError.Errorf(
+			"InvalidHeight(height=%d,current=%d)", height, cc.BlockHeight())
+	}
+	if len(method) == 0 {
+		return 0, scoreresult.InvalidParameterError.New("EmptyMethod")
+	}
+	if limit := cc.GetStepLimit(state.StepLimitTypeInvoke); stepLimit.Sign() <= 0 || stepLimit.Cmp(limit) > 0 {
+		return 0, scoreresult.InvalidParameterError.Errorf(
+			"InvalidStepLimit(limit=%d,max=%d)", stepLimit, limit)
+	}
+	cb := &ScheduledCallback{
+		Owner:     owner,
+		Height:    height,
+		Method:    method,
+		StepLimit: stepLimit,
+		StepPrice: cc.StepPrice(),
+	}
+	if err := transferDeposit(cc, owner, state.SystemAddress, cb.Deposit()); err != nil {
+		return 0, err
+	}
+
+	as := cc.GetAccountState(state.SystemID)
+	nextID := scoredb.NewVarDB(as, state.VarCallbacks, "next")
+	cb.ID = nextID.Int64() + 1
+	if err := nextID.Set(cb.ID); err != nil {
+		return 0, err
+	}
+	if err := setCallback(as, cb); err != nil {
+		return 0, err
+	}
+	if err := newCallbackList(as, height).push(cb.ID); err != nil {
+		return 0, err
+	}
+	return cb.ID, nil
+}
+
+// CancelCallback cancels the callback of the owner, and refunds the steps
+// prepaid for it.
+func CancelCallback(cc CallContext, owner module.Address, id int64) error {
+	as := cc.GetAccountState(state.SystemID)
+	cb := GetScheduledCallback(as, id)
+	if cb == nil {
+		return scoreresult.InvalidParameterError.Errorf("CallbackNotFound(id=%d)", id)
+	}
+	if !cb.Owner.Equal(owner) {
+		return scoreresult.AccessDeniedError.Errorf(
+			"NotCallbackOwner(id=%d,from=%s)", id, owner)
+	}
+	if err := callbackListOf(as, id).remove(id); err != nil {
+		return err
+	}
+	if err := callbackDB(as).Delete(id); err != nil {
+		return err
+	}
+	return transferDeposit(cc, state.SystemAddress, cb.Owner, cb.Deposit())
+}
+
+// DueCallbacks returns IDs of the callbacks to be executed in the block of
+// the height. It doesn't modify the state, so it can be used for proposing
+// a block before PrepareCallbacks.
+func DueCallbacks(as state.AccountState, height int64, limit int) []int64 {
+	var ids []int64
+	for _, key := range []int64{callbackQueueKey, height} {
+		l := newCallbackList(as, key)
+		for id := l.head(); id != 0 && len(ids) < limit; id = l.next(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// PrepareCallbacks appends the callbacks scheduled at the height to the
+// queue. It should be called at the beginning of every block.
+func PrepareCallbacks(as state.AccountState, height int64) error {
+	q := newCallbackList(as, callbackQueueKey)
+	timer := newCallbackList(as, height)
+	for id := timer.head(); id != 0; id = timer.head() {
+		if err := timer.remove(id); err != nil {
+			return err
+		}
+		if err := q.push(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PopCallback removes the callback of the ID from the head of the queue,
+// and returns it. It returns nil if the callback is cancelled.
+func PopCallback(as state.AccountState, id int64) (*ScheduledCallback, error) {
+	cb := GetScheduledCallback(as, id)
+	if cb == nil {
+		return nil, nil
+	}
+	q := newCallbackList(as, callbackQueueKey)
+	head := q.head()
+	if head == 0 {
+		return nil, errors.InvalidStateError.Errorf("CallbackNotInQueue(id=%d)", id)
+	}
+	if head != id {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidCallbackOrder(id=%d,expected=%d)", id, head)
+	}
+	if err := q.remove(id); err != nil {
+		return nil, err
+	}
+	if err := callbackDB(as).Delete(id); err != nil {
+		return nil, err
+	}
+	return cb, nil
+}
+
+// SettleCallback refunds the deposit of the executed callback except the
+// fee for the used steps. The fee is gathered to the treasury with the fee
+// of the receipt.
+func SettleCallback(wc state.WorldContext, cb *ScheduledCallback, used *big.Int) error {
+	fee := new(big.Int).Mul(used, cb.StepPrice)
+	deposit := cb.Deposit()
+	if fee.Cmp(deposit) > 0 {
+		return errors.InvalidStateError.Errorf(
+			"InvalidStepUsed(used=%d,limit=%d)", used, cb.StepLimit)
+	}
+	as := wc.GetAccountState(state.SystemID)
+	balance := as.GetBalance()
+	if balance.Cmp(deposit) < 0 {
+		return errors.InvalidStateError.Errorf(
+			"NotEnoughDeposit(balance=%d,deposit=%d)", balance, deposit)
+	}
+	as.SetBalance(new(big.Int).Sub(balance, deposit))
+	owner := wc.GetAccountState(cb.Owner.ID())
+	owner.SetBalance(new(big.Int).Add(owner.GetBalance(), new(big.Int).Sub(deposit, fee)))
+	return nil
+}
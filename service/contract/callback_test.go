@@ -0,0 +1,142 @@
+package contract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func newCallContextAt(ws state.WorldState, height int64) CallContext {
+	return NewCallContext(
+		NewContext(
+			state.NewWorldContext(ws, common.NewBlockInfo(height, 0), nil, dummyPlatformType{}),
+			nil,
+			nil,
+			newDummyChain(),
+			log.New(),
+			nil,
+		),
+		nil,
+		false,
+	)
+}
+
+func TestScheduledCallback(t *testing.T) {
+	dbase, _ := db.Open("", string(db.MapDBBackend), "map")
+	ws := state.NewWorldState(dbase, nil, nil, nil)
+	sys := ws.GetAccountState(state.SystemID)
+	assert.NoError(t, scoredb.NewVarDB(sys, state.VarStepPrice).Set(10))
+	assert.NoError(t, scoredb.NewArrayDB(sys, state.VarStepLimitTypes).Put(state.StepLimitTypeInvoke))
+	assert.NoError(t, scoredb.NewDictDB(sys, state.VarStepLimit, 1).Set(state.StepLimitTypeInvoke, 1000))
+
+	owner := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	other := common.MustNewAddressFromString("cx0000000000000000000000000000000000000002")
+	oas := ws.GetAccountState(owner.ID())
+	oas.SetBalance(big.NewInt(10000))
+
+	cc := newCallContextAt(ws, 10)
+	_, err := ScheduleCallback(cc, common.MustNewAddressFromString("hx0000000000000000000000000000000000000001"), 11, "m", big.NewInt(100))
+	assert.Error(t, err)
+	_, err = ScheduleCallback(cc, owner, 10, "m", big.NewInt(100))
+	assert.Error(t, err)
+	_, err = ScheduleCallback(cc, owner, 11, "m", big.NewInt(1001))
+	assert.Error(t, err)
+
+	var ids []int64
+	for _, h := range []int64{11, 11, 11, 12} {
+		id, err := ScheduleCallback(cc, owner, h, "m", big.NewInt(100))
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4}, ids)
+	assert.Equal(t, big.NewInt(6000), oas.GetBalance())
+	assert.Equal(t, big.NewInt(4000), sys.GetBalance())
+
+	assert.Error(t, CancelCallback(cc, other, 2))
+	assert.NoError(t, CancelCallback(cc, owner, 2))
+	assert.Error(t, CancelCallback(cc, owner, 2))
+	assert.Equal(t, big.NewInt(7000), oas.GetBalance())
+	assert.Nil(t, GetScheduledCallback(sys, 2))
+
+	// block 11 executes only one of them by the limit
+	assert.Equal(t, []int64{1, 3}, DueCallbacks(sys, 11, 5))
+	assert.Equal(t, []int64{1}, DueCallbacks(sys, 11, 1))
+	assert.NoError(t, PrepareCallbacks(sys, 11))
+	assert.Equal(t, []int64{1}, DueCallbacks(sys, 11, 1))
+	_, err = PopCallback(sys, 3)
+	assert.Error(t, err)
+
+	cb, err := PopCallback(sys, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), cb.Height)
+	assert.True(t, owner.Equal(cb.Owner))
+	assert.NoError(t, SettleCallback(cc, cb, big.NewInt(40)))
+	assert.Equal(t, big.NewInt(7600), oas.GetBalance())
+	assert.Equal(t, big.NewInt(2000), sys.GetBalance())
+	assert.Nil(t, GetScheduledCallback(sys, 1))
+
+	// block 12 executes the delayed one first
+	assert.Equal(t, []int64{3, 4}, DueCallbacks(sys, 12, 5))
+	assert.NoError(t, PrepareCallbacks(sys, 12))
+	assert.NoError(t, CancelCallback(cc, owner, 3))
+	cb, err = PopCallback(sys, 3)
+	assert.NoError(t, err)
+	assert.Nil(t, cb)
+	cb, err = PopCallback(sys, 4)
+	assert.NoError(t, err)
+	assert.NoError(t, SettleCallback(cc, cb, big.NewInt(100)))
+	assert.Equal(t, big.NewInt(8600), oas.GetBalance())
+	assert.Equal(t, 0, sys.GetBalance().Sign())
+	assert.Empty(t, DueCallbacks(sys, 13, 5))
+}
+
+func TestScheduledCallback_CancelRemovesFromList(t *testing.T) {
+	dbase, _ := db.Open("", string(db.MapDBBackend), "map")
+	ws := state.NewWorldState(dbase, nil, nil, nil)
+	sys := ws.GetAccountState(state.SystemID)
+	assert.NoError(t, scoredb.NewVarDB(sys, state.VarStepPrice).Set(10))
+	assert.NoError(t, scoredb.NewArrayDB(sys, state.VarStepLimitTypes).Put(state.StepLimitTypeInvoke))
+	assert.NoError(t, scoredb.NewDictDB(sys, state.VarStepLimit, 1).Set(state.StepLimitTypeInvoke, 1000))
+
+	owner := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	ws.GetAccountState(owner.ID()).SetBalance(big.NewInt(10000))
+
+	cc := newCallContextAt(ws, 10)
+	for i := 0; i < 4; i++ {
+		_, err := ScheduleCallback(cc, owner, 11, "m", big.NewInt(100))
+		assert.NoError(t, err)
+	}
+
+	// cancelled in the timer
+	assert.NoError(t, CancelCallback(cc, owner, 1))
+	assert.NoError(t, CancelCallback(cc, owner, 3))
+	assert.Equal(t, []int64{2, 4}, DueCallbacks(sys, 11, 5))
+	timer := newCallbackList(sys, 11)
+	assert.EqualValues(t, 2, timer.head())
+	assert.EqualValues(t, 4, timer.next(2))
+
+	// cancelled in the queue
+	assert.NoError(t, PrepareCallbacks(sys, 11))
+	assert.Zero(t, timer.head())
+	assert.NoError(t, CancelCallback(cc, owner, 4))
+	assert.Equal(t, []int64{2}, DueCallbacks(sys, 12, 5))
+	assert.NoError(t, CancelCallback(cc, owner, 2))
+	assert.Empty(t, DueCallbacks(sys, 12, 5))
+
+	q := newCallbackList(sys, callbackQueueKey)
+	assert.Zero(t, q.head())
+	assert.Nil(t, q.meta.Get("tail"))
+	for id := int64(1); id <= 4; id++ {
+		for _, name := range []string{"prev", "next", "list"} {
+			assert.Nil(t, callbackLinksDB(sys).Get(id, name))
+		}
+	}
+	assert.Equal(t, 0, sys.GetBalance().Sign())
+}
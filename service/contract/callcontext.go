@@ -56,6 +56,9 @@ type (
 		GetRedeemLogs(r txresult.Receipt) bool
 		ClearRedeemLogs()
 		DoIOTask(func())
+		StepProfile() *StepProfile
+		AddMigration(addr module.Address)
+		ApplyMigrations() error
 	}
 	callResultMessage struct {
 		status   error
@@ -97,7 +100,11 @@ type callContext struct {
 	ioStart *time.Time
 	ioTime  time.Duration
 
-	payers *stepPayers
+	payers  *stepPayers
+	profile *StepProfile
+
+	migrations []module.Address
+	migrating  map[string]bool
 
 	log *trace.Logger
 }
@@ -108,11 +115,16 @@ func prefixForFrame(id int) string {
 
 func NewCallContext(ctx Context, limit *big.Int, isQuery bool) CallContext {
 	logger := trace.LoggerOf(ctx.Logger())
+	var profile *StepProfile
 	ti := ctx.TraceInfo()
 	if ti != nil {
 		if info := ctx.TransactionInfo(); info != nil {
 			if info.Group == ti.Group && int(info.Index) == ti.Index {
 				logger = trace.NewLogger(logger.Logger, ti.Callback)
+				if cb, ok := ti.Callback.(module.StepProfileCallback); ok {
+					profile = NewStepProfile(ctx.StepsFor)
+					cb.OnStepProfile(profile)
+				}
 			}
 		}
 	}
@@ -123,6 +135,7 @@ func NewCallContext(ctx Context, limit *big.Int, isQuery bool) CallContext {
 		nextEID: initialEID,
 		nextFID: firstFID,
 		frame:   NewFrame(nil, nil, limit, isQuery, frameLogger),
+		profile: profile,
 
 		waiter: make(chan interface{}, 8),
 		log:    logger,
@@ -152,6 +165,9 @@ func (cc *callContext) pushFrame(handler ContractHandler, limit *big.Int) *callF
 	frame.fid = cc.nextFID
 	cc.nextFID += 1
 	cc.frame = frame
+	if cc.profile != nil {
+		cc.profile.OnFrameStart(frame.fid, frame.parent.fid, handler)
+	}
 	return frame
 }
 
@@ -161,6 +177,9 @@ func (cc *callContext) popFrame(success bool) *callFrame {
 
 	frame := cc.frame
 	frame.log.TSystemf("END success=%v steps=%d", success, &frame.stepUsed)
+	if cc.profile != nil {
+		cc.profile.OnFrameEnd(frame.fid, &frame.stepUsed)
+	}
 	if !frame.isQuery {
 		if success {
 			frame.parent.applyFrameLogsOf(frame)
@@ -353,6 +372,9 @@ func (cc *callContext) cleanUpFrames(target *callFrame, err error) {
 	for cc.frame != nil && cc.frame.handler != nil {
 		frame := cc.frame
 		cc.frame = frame.parent
+		if cc.profile != nil {
+			cc.profile.OnFrameEnd(frame.fid, &frame.stepUsed)
+		}
 		if ach, ok := frame.handler.(AsyncContractHandler); ok {
 			achs = append(achs, ach)
 		}
@@ -508,11 +530,21 @@ func (cc *callContext) ApplySteps(t state.StepType, n int) bool {
 
 func (cc *callContext) applyStepsInLock(t state.StepType, n int) bool {
 	steps := big.NewInt(cc.StepsFor(t, n))
-	ok := cc.frame.deductSteps(steps)
+	ok := cc.deductStepsInLock(string(t), steps)
 	cc.frame.log.TSystemf("STEP apply type=%s count=%d cost=%s total=%s", t, n, steps, &cc.frame.stepUsed)
 	return ok
 }
 
+func (cc *callContext) deductStepsInLock(t string, steps *big.Int) bool {
+	if cc.profile == nil {
+		return cc.frame.deductSteps(steps)
+	}
+	used := cc.frame.getStepUsed()
+	ok := cc.frame.deductSteps(steps)
+	cc.profile.OnSteps(cc.frame.fid, t, used.Sub(&cc.frame.stepUsed, used))
+	return ok
+}
+
 func (cc *callContext) ApplyCallSteps() error {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
@@ -531,7 +563,7 @@ func (cc *callContext) ApplyCallSteps() error {
 func (cc *callContext) DeductSteps(s *big.Int) bool {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
-	ok := cc.frame.deductSteps(s)
+	ok := cc.deductStepsInLock(StepTypeEngine, s)
 	cc.frame.log.TSystemf("STEP apply cost=%s total=%d", s, &cc.frame.stepUsed)
 	return ok
 }
@@ -615,6 +647,42 @@ func (cc *callContext) ClearRedeemLogs() {
 	}
 }
 
+func (cc *callContext) StepProfile() *StepProfile {
+	return cc.profile
+}
+
+// AddMigration reserves migration of the account to the version for the
+// current revision. It's not rolled back on failure, so the migration is
+// applied only once even if the call fails.
+func (cc *callContext) AddMigration(addr module.Address) {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	if cc.migrating == nil {
+		cc.migrating = make(map[string]bool)
+	}
+	if id := string(addr.ID()); !cc.migrating[id] {
+		cc.migrating[id] = true
+		cc.migrations = append(cc.migrations, addr)
+	}
+}
+
+// ApplyMigrations migrates reserved accounts. It should be called after
+// all changes of the execution are settled.
+func (cc *callContext) ApplyMigrations() error {
+	rev := cc.Revision()
+	for _, addr := range cc.migrations {
+		as := cc.GetAccountState(addr.ID())
+		if err := as.MigrateForRevision(rev); err != nil {
+			return err
+		}
+		cc.log.TSystemf("MIGRATE account=%s", addr)
+	}
+	cc.migrations = nil
+	cc.migrating = nil
+	return nil
+}
+
 func (cc *callContext) GetCustomLogs(name string, ot reflect.Type) CustomLogs {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
@@ -6,12 +6,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoreresult"
 	"github.com/icon-project/goloop/service/state"
 	"github.com/icon-project/goloop/service/trace"
 )
@@ -304,3 +307,38 @@ func (h *syncHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, modul
 	}
 	return nil, nil, nil
 }
+
+type migrationHandler struct {
+	*commonHandler
+	addr module.Address
+}
+
+func (h *migrationHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, module.Address) {
+	cc.AddMigration(h.addr)
+	as := cc.GetAccountState(h.addr.ID())
+	as.SetValue([]byte("k2"), []byte("v2"))
+	return scoreresult.UnknownFailureError.New("Fail"), nil, nil
+}
+
+func TestCallContext_ApplyMigrations(t *testing.T) {
+	cc := newCallContext()
+	addr := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	as := cc.GetAccountState(addr.ID())
+	as.SetValue([]byte("k1"), []byte("v1"))
+
+	handler := &migrationHandler{commonHandler: &commonHandler{}, addr: addr}
+	status, _, _, _ := cc.Call(handler, nil)
+	assert.Error(t, status)
+
+	// changes of the failed call are rolled back, but the migration is kept
+	as = cc.GetAccountState(addr.ID())
+	assert.Nil(t, as.StorageUsage())
+	err := cc.ApplyMigrations()
+	assert.NoError(t, err)
+	assert.Equal(t, &state.StorageUsage{Keys: 1, Bytes: 4}, as.StorageUsage())
+
+	// it migrates only once
+	as.SetValue([]byte("k2"), []byte("v2"))
+	assert.NoError(t, cc.ApplyMigrations())
+	assert.Equal(t, &state.StorageUsage{Keys: 2, Bytes: 8}, as.StorageUsage())
+}
@@ -2,6 +2,7 @@ package contract
 
 import (
 	"bytes"
+	gocontext "context"
 	"encoding/json"
 	"math/big"
 	"strings"
@@ -233,6 +234,14 @@ func (h *CallHandler) DoExecuteAsync(cc CallContext, ch eeproxy.CallContext, sto
 		return err
 	}
 
+	// Storage usage of the account is counted from the end of the first
+	// transaction calling it after the revision (including readonly calls
+	// and failed calls). Counting walks whole storage, so it's done once
+	// after the execution instead of here, where it may be rolled back.
+	if cc.Revision().TrackStorageUsage() && !cc.QueryMode() {
+		cc.AddMigration(h.To)
+	}
+
 	if isSystem {
 		return h.invokeSystemMethod(cc, c)
 	}
@@ -418,6 +427,12 @@ func (h *CallHandler) EEType() state.EEType {
 	return c.EEType()
 }
 
+// TraceContext returns the context for tracing invocations of execution
+// engines.
+func (h *CallHandler) TraceContext() gocontext.Context {
+	return TraceContextOf(h.cc)
+}
+
 func (h *CallHandler) GetValue(key []byte) ([]byte, error) {
 	if h.store != nil {
 		var value []byte
@@ -429,6 +444,7 @@ func (h *CallHandler) GetValue(key []byte) ([]byte, error) {
 			h.Log.TSystemf("GETVALUE key=<%x> err=%+v", key, err)
 		} else {
 			h.Log.TSystemf("GETVALUE key=<%x> value=<%x>", key, value)
+			h.onOperation(state.StepTypeGet, 0, len(value))
 		}
 		return value, err
 	} else {
@@ -452,6 +468,11 @@ func (h *CallHandler) SetValue(key []byte, value []byte) ([]byte, error) {
 			h.Log.TSystemf("SETVALUE key=<%x> value=<%x> err=%+v", key, value, err)
 		} else {
 			h.Log.TSystemf("SETVALUE key=<%x> value=<%x> old=<%x>", key, value, old)
+			if old != nil {
+				h.onOperation(state.StepTypeReplace, len(old), len(value))
+			} else {
+				h.onOperation(state.StepTypeSet, 0, len(value))
+			}
 		}
 		return old, err
 	} else {
@@ -475,6 +496,7 @@ func (h *CallHandler) DeleteValue(key []byte) ([]byte, error) {
 			h.Log.TSystemf("DELETE key=<%x> err=%+v", key, err)
 		} else {
 			h.Log.TSystemf("DELETE key=<%x> old=<%x>", key, old)
+			h.onOperation(state.StepTypeDelete, 0, len(old))
 		}
 		return old, err
 	} else {
@@ -552,9 +574,27 @@ func (h *CallHandler) OnEvent(addr module.Address, indexed, data [][]byte) error
 		return nil
 	}
 	h.cc.OnEvent(addr, indexed, data)
+	if h.cc.StepProfile() != nil {
+		size := 0
+		for _, bs := range indexed {
+			size += len(bs)
+		}
+		for _, bs := range data {
+			size += len(bs)
+		}
+		h.onOperation(state.StepTypeEventLog, 0, size)
+	}
 	return nil
 }
 
+// onOperation records the operation requested by the contract to the step
+// profile of the transaction.
+func (h *CallHandler) onOperation(t state.StepType, prev, size int) {
+	if p := h.cc.StepProfile(); p != nil {
+		p.OnOperation(h.cc.FrameID(), t, prev, size)
+	}
+}
+
 func (h *CallHandler) OnResult(status error, steps *big.Int, result *codec.TypedObj) {
 	h.TLogDone(status, steps, result)
 	h.cc.OnResult(status, steps, result, nil)
@@ -1,6 +1,7 @@
 package contract
 
 import (
+	gocontext "context"
 	"encoding/hex"
 	"strings"
 	"time"
@@ -14,6 +15,7 @@ import (
 
 const (
 	PropInitialSnapshot = "transition.initialSnapshot"
+	PropTraceContext    = "transaction.traceContext"
 )
 
 type Context interface {
@@ -101,3 +103,12 @@ func (c *context) GetEnabledEETypes() state.EETypes {
 	}
 	return c.cm.DefaultEnabledEETypes()
 }
+
+// TraceContextOf returns the context having the span of the transaction
+// for tracing. It returns background context if there is no span.
+func TraceContextOf(ctx Context) gocontext.Context {
+	if tc, ok := ctx.GetProperty(PropTraceContext).(gocontext.Context); ok {
+		return tc
+	}
+	return gocontext.Background()
+}
@@ -69,6 +69,10 @@ func (h *CommonHandler) Prepare(ctx Context) (state.WorldContext, error) {
 	return ctx.GetFuture(lq), nil
 }
 
+func (h *CommonHandler) Target() module.Address {
+	return h.To
+}
+
 func (h *CommonHandler) ApplyCallSteps(cc CallContext) error {
 	if !h.callCharged {
 		h.callCharged = true
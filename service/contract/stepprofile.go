@@ -0,0 +1,287 @@
+package contract
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// StepTypeEngine is the type for steps reported by the execution engine.
+// Engines report only the sum of steps used for the execution of the
+// contract. Steps for storage accesses and event logs are moved to their
+// own step types, and the rest remains as this type.
+const StepTypeEngine = "engine"
+
+type StepOperation struct {
+	Count int
+	Bytes int
+}
+
+// FrameProfile is the profile of used steps for a call frame.
+type FrameProfile struct {
+	ID     int
+	Parent int
+	To     module.Address
+	Method string
+
+	// Used is the steps used by the frame including its children.
+	Used big.Int
+
+	// Steps is the steps used by the frame itself for each step type.
+	Steps map[string]*big.Int
+
+	// Operations is the storage accesses and event logs requested by the
+	// contract for each step type.
+	Operations map[string]*StepOperation
+}
+
+func (f *FrameProfile) addSteps(t string, steps *big.Int) {
+	if steps.Sign() == 0 {
+		return
+	}
+	if s, ok := f.Steps[t]; ok {
+		s.Add(s, steps)
+	} else {
+		f.Steps[t] = new(big.Int).Set(steps)
+	}
+}
+
+func (f *FrameProfile) stepsJSON() map[string]interface{} {
+	jso := make(map[string]interface{}, len(f.Steps))
+	for t, s := range f.Steps {
+		if s.Sign() != 0 {
+			jso[t] = intconv.FormatBigInt(s)
+		}
+	}
+	return jso
+}
+
+func (f *FrameProfile) toJSON(used *big.Int) map[string]interface{} {
+	jso := map[string]interface{}{
+		"id":       intconv.FormatInt(int64(f.ID)),
+		"stepUsed": intconv.FormatBigInt(used),
+		"steps":    f.stepsJSON(),
+	}
+	if f.Parent != unknownFID {
+		jso["parent"] = intconv.FormatInt(int64(f.Parent))
+	}
+	if f.To != nil {
+		jso["to"] = f.To
+	}
+	if f.Method != "" {
+		jso["method"] = f.Method
+	}
+	if len(f.Operations) > 0 {
+		ops := make(map[string]interface{}, len(f.Operations))
+		for t, op := range f.Operations {
+			ops[t] = map[string]interface{}{
+				"count": intconv.FormatInt(int64(op.Count)),
+				"bytes": intconv.FormatInt(int64(op.Bytes)),
+			}
+		}
+		jso["operations"] = ops
+	}
+	return jso
+}
+
+// StepProfile records used steps of a transaction for each step type and
+// for each call frame.
+type StepProfile struct {
+	lock     sync.Mutex
+	stepsFor func(t state.StepType, n int) int64
+	frames   map[int]*FrameProfile
+}
+
+// NewStepProfile returns a new profile. stepsFor is used to get steps
+// charged by execution engines for operations. If it's nil, all steps
+// reported by engines remain as StepTypeEngine.
+func NewStepProfile(stepsFor func(t state.StepType, n int) int64) *StepProfile {
+	return &StepProfile{
+		stepsFor: stepsFor,
+		frames:   make(map[int]*FrameProfile),
+	}
+}
+
+type targetHandler interface {
+	Target() module.Address
+}
+
+type methodHandler interface {
+	GetMethodName() string
+}
+
+func (p *StepProfile) frameInLock(id int) *FrameProfile {
+	f, ok := p.frames[id]
+	if !ok {
+		f = &FrameProfile{
+			ID:         id,
+			Steps:      make(map[string]*big.Int),
+			Operations: make(map[string]*StepOperation),
+		}
+		p.frames[id] = f
+	}
+	return f
+}
+
+func (p *StepProfile) OnFrameStart(id, parent int, handler ContractHandler) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	f := p.frameInLock(id)
+	f.Parent = parent
+	if th, ok := handler.(targetHandler); ok {
+		f.To = th.Target()
+	}
+	if mh, ok := handler.(methodHandler); ok {
+		f.Method = mh.GetMethodName()
+	}
+}
+
+// OnFrameEnd records steps used by the frame. Steps of the frame are
+// deducted from engine steps of the parent, because the parent reports them
+// again as a part of its usage.
+func (p *StepProfile) OnFrameEnd(id int, used *big.Int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	f := p.frameInLock(id)
+	f.Used.Set(used)
+	if f.Parent != unknownFID {
+		parent := p.frameInLock(f.Parent)
+		parent.addSteps(StepTypeEngine, new(big.Int).Neg(used))
+	}
+}
+
+// OnSteps records steps used by the frame. Use StepTypeEngine for steps
+// without specific step type.
+func (p *StepProfile) OnSteps(id int, t string, steps *big.Int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.frameInLock(id).addSteps(t, steps)
+}
+
+// engineStepsFor returns steps charged by execution engines for the
+// operation in the same way as the engines do.
+func (p *StepProfile) engineStepsFor(t state.StepType, prev, size int) int64 {
+	cost := p.stepsFor
+	schema := cost(state.StepTypeSchema, 1)
+	switch t {
+	case state.StepTypeGet:
+		return cost(state.StepTypeGetBase, 1) + cost(state.StepTypeGet, size)
+	case state.StepTypeSet:
+		return cost(state.StepTypeSetBase, 1) + cost(state.StepTypeSet, size)
+	case state.StepTypeReplace:
+		if schema == 0 {
+			return cost(state.StepTypeReplace, size)
+		}
+		base := (cost(state.StepTypeSetBase, 1) + cost(state.StepTypeDeleteBase, 1)) / 2
+		return base + cost(state.StepTypeDelete, prev) + cost(state.StepTypeSet, size)
+	case state.StepTypeDelete:
+		return cost(state.StepTypeDeleteBase, 1) + cost(state.StepTypeDelete, size)
+	case state.StepTypeEventLog:
+		if schema == 0 {
+			return cost(state.StepTypeEventLog, size)
+		}
+		return cost(state.StepTypeLogBase, 1) + cost(state.StepTypeLog, size)
+	default:
+		return 0
+	}
+}
+
+// OnOperation records an operation requested by the contract. size is the
+// size of the value (the removed one for delete) or the event log, and prev
+// is the size of the replaced value for replace. Steps charged by the engine for the
+// operation are moved from StepTypeEngine to the type of the operation.
+func (p *StepProfile) OnOperation(id int, t state.StepType, prev, size int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	f := p.frameInLock(id)
+	if p.stepsFor != nil {
+		if steps := p.engineStepsFor(t, prev, size); steps != 0 {
+			f.addSteps(string(t), big.NewInt(steps))
+			f.addSteps(StepTypeEngine, big.NewInt(-steps))
+		}
+	}
+	op, ok := f.Operations[string(t)]
+	if !ok {
+		op = new(StepOperation)
+		f.Operations[string(t)] = op
+	}
+	op.Count += 1
+	op.Bytes += size
+}
+
+// Frames returns profiles of the frames ordered by their IDs.
+func (p *StepProfile) Frames() []*FrameProfile {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	frames := make([]*FrameProfile, 0, len(p.frames))
+	for _, f := range p.frames {
+		frames = append(frames, f)
+	}
+	sort.Slice(frames, func(i, j int) bool {
+		return frames[i].ID < frames[j].ID
+	})
+	return frames
+}
+
+// Steps returns sum of steps of all frames for each step type.
+func (p *StepProfile) Steps() map[string]*big.Int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	steps := make(map[string]*big.Int)
+	for _, f := range p.frames {
+		for t, s := range f.Steps {
+			if sum, ok := steps[t]; ok {
+				sum.Add(sum, s)
+			} else {
+				steps[t] = new(big.Int).Set(s)
+			}
+		}
+	}
+	return steps
+}
+
+// ToJSON returns the profile in JSON. Used steps of the base frame is sum
+// of steps of all frames, which is same as used steps of the transaction.
+func (p *StepProfile) ToJSON(version module.JSONVersion) (interface{}, error) {
+	total := new(big.Int)
+	steps := make(map[string]interface{})
+	for t, s := range p.Steps() {
+		total.Add(total, s)
+		if s.Sign() != 0 {
+			steps[t] = intconv.FormatBigInt(s)
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ids := make([]int, 0, len(p.frames))
+	for id := range p.frames {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	frames := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		f := p.frames[id]
+		if id == baseFID {
+			frames = append(frames, f.toJSON(total))
+		} else {
+			frames = append(frames, f.toJSON(&f.Used))
+		}
+	}
+	return map[string]interface{}{
+		"stepUsed": intconv.FormatBigInt(total),
+		"steps":    steps,
+		"frames":   frames,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package contract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func TestStepProfile_Frames(t *testing.T) {
+	p := NewStepProfile(nil)
+	to := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+
+	p.OnFrameStart(baseFID, unknownFID, &CommonHandler{To: to})
+	p.OnSteps(baseFID, string(state.StepTypeDefault), big.NewInt(100000))
+	p.OnSteps(baseFID, StepTypeEngine, big.NewInt(5000))
+
+	p.OnFrameStart(2, baseFID, &CommonHandler{To: to})
+	p.OnSteps(2, string(state.StepTypeContractCall), big.NewInt(1000))
+	p.OnSteps(2, StepTypeEngine, big.NewInt(2000))
+	p.OnOperation(2, state.StepTypeSet, 0, 10)
+	p.OnOperation(2, state.StepTypeSet, 0, 20)
+	p.OnFrameEnd(2, big.NewInt(3000))
+
+	// parent reports steps of the child again
+	p.OnSteps(baseFID, StepTypeEngine, big.NewInt(3000))
+	p.OnFrameEnd(baseFID, big.NewInt(108000))
+
+	frames := p.Frames()
+	assert.Len(t, frames, 2)
+	assert.Equal(t, baseFID, frames[0].ID)
+	assert.Equal(t, 0, frames[0].Steps[StepTypeEngine].Cmp(big.NewInt(5000)))
+	assert.Equal(t, baseFID, frames[1].Parent)
+	assert.True(t, to.Equal(frames[1].To))
+	assert.Equal(t, &StepOperation{Count: 2, Bytes: 30}, frames[1].Operations[string(state.StepTypeSet)])
+
+	steps := p.Steps()
+	assert.Equal(t, int64(100000), steps[string(state.StepTypeDefault)].Int64())
+	assert.Equal(t, int64(1000), steps[string(state.StepTypeContractCall)].Int64())
+	assert.Equal(t, int64(7000), steps[StepTypeEngine].Int64())
+
+	jso, err := p.ToJSON(module.JSONVersion3)
+	assert.NoError(t, err)
+	obj := jso.(map[string]interface{})
+	assert.Equal(t, "0x1a5e0", obj["stepUsed"])
+	fjs := obj["frames"].([]interface{})
+	assert.Len(t, fjs, 2)
+	assert.Equal(t, "0x1a5e0", fjs[0].(map[string]interface{})["stepUsed"])
+	assert.Equal(t, "0x1", fjs[1].(map[string]interface{})["parent"])
+	assert.Equal(t, "0xbb8", fjs[1].(map[string]interface{})["stepUsed"])
+}
+
+func stepsForCosts(costs map[state.StepType]int64) func(t state.StepType, n int) int64 {
+	return func(t state.StepType, n int) int64 {
+		return costs[t] * int64(n)
+	}
+}
+
+func TestStepProfile_EngineSteps(t *testing.T) {
+	cases := []struct {
+		name  string
+		costs map[state.StepType]int64
+		steps map[string]int64
+	}{
+		{
+			name: "Schema0",
+			costs: map[state.StepType]int64{
+				state.StepTypeGet:      25,
+				state.StepTypeSet:      320,
+				state.StepTypeReplace:  80,
+				state.StepTypeDelete:   -240,
+				state.StepTypeEventLog: 100,
+			},
+			steps: map[string]int64{
+				string(state.StepTypeGet):      25 * 10,
+				string(state.StepTypeSet):      320 * 10,
+				string(state.StepTypeReplace):  80 * 20,
+				string(state.StepTypeDelete):   -240 * 20,
+				string(state.StepTypeEventLog): 100 * 30,
+			},
+		},
+		{
+			name: "Schema1",
+			costs: map[state.StepType]int64{
+				state.StepTypeSchema:     1,
+				state.StepTypeGetBase:    2000,
+				state.StepTypeSetBase:    20000,
+				state.StepTypeDeleteBase: 200,
+				state.StepTypeLogBase:    5000,
+				state.StepTypeGet:        25,
+				state.StepTypeSet:        50,
+				state.StepTypeDelete:     -150,
+				state.StepTypeLog:        100,
+				state.StepTypeEventLog:   1000,
+			},
+			steps: map[string]int64{
+				string(state.StepTypeGet):      2000 + 25*10,
+				string(state.StepTypeSet):      20000 + 50*10,
+				string(state.StepTypeReplace):  10100 - 150*10 + 50*20,
+				string(state.StepTypeDelete):   200 - 150*20,
+				string(state.StepTypeEventLog): 5000 + 100*30,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := NewStepProfile(stepsForCosts(c.costs))
+			p.OnFrameStart(baseFID, unknownFID, &CommonHandler{})
+			p.OnOperation(baseFID, state.StepTypeGet, 0, 10)
+			p.OnOperation(baseFID, state.StepTypeSet, 0, 10)
+			p.OnOperation(baseFID, state.StepTypeReplace, 10, 20)
+			p.OnOperation(baseFID, state.StepTypeDelete, 0, 20)
+			p.OnOperation(baseFID, state.StepTypeEventLog, 0, 30)
+
+			var sum int64
+			for _, s := range c.steps {
+				sum += s
+			}
+			p.OnSteps(baseFID, StepTypeEngine, big.NewInt(sum+7000))
+			p.OnFrameEnd(baseFID, big.NewInt(sum+7000))
+
+			steps := p.Steps()
+			for st, s := range c.steps {
+				assert.Equal(t, s, steps[st].Int64(), st)
+			}
+			assert.Equal(t, int64(7000), steps[StepTypeEngine].Int64())
+			assert.Equal(t, &StepOperation{Count: 1, Bytes: 20},
+				p.Frames()[0].Operations[string(state.StepTypeReplace)])
+		})
+	}
+}
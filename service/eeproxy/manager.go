@@ -31,6 +31,15 @@ type Manager interface {
 	SetInstances(total, tx, query int) error
 	Loop() error
 	Close() error
+	Status() []EngineStatus
+}
+
+// EngineStatus is status of executors for an engine.
+type EngineStatus struct {
+	Type   string
+	Active int
+	Ready  int
+	Using  int
 }
 
 type Engine interface {
@@ -264,6 +273,30 @@ func (em *executorManager) SetInstances(total, tx, query int) error {
 	return nil
 }
 
+func countProxies(p *proxy) int {
+	cnt := 0
+	for ; p != nil; p = p.next {
+		cnt += 1
+	}
+	return cnt
+}
+
+func (em *executorManager) Status() []EngineStatus {
+	em.lock.Lock()
+	defer em.lock.Unlock()
+
+	res := make([]EngineStatus, len(em.engines))
+	for i, e := range em.engines {
+		res[i] = EngineStatus{
+			Type:   e.engine.Type(),
+			Active: e.active,
+			Ready:  countProxies(e.ready),
+			Using:  countProxies(e.using),
+		}
+	}
+	return res
+}
+
 func (em *executorManager) Loop() error {
 	return em.server.Loop()
 }
@@ -1,10 +1,12 @@
 package eeproxy
 
 import (
+	"context"
 	"math/big"
 	"sync"
 
 	"github.com/gofrs/uuid"
+	octrace "go.opencensus.io/trace"
 
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/service/trace"
@@ -13,6 +15,7 @@ import (
 	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/ipc"
+	"github.com/icon-project/goloop/common/tracing"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/scoreapi"
 )
@@ -92,10 +95,17 @@ type proxyManager interface {
 	kill(u string) error
 }
 
+// TraceContextProvider is implemented by CallContext supporting tracing.
+// The span for Invoke becomes a child of the span in the context.
+type TraceContextProvider interface {
+	TraceContext() context.Context
+}
+
 type callFrame struct {
 	addr module.Address
 	ctx  CallContext
 	log  *trace.Logger
+	span *octrace.Span
 
 	prev *callFrame
 }
@@ -145,6 +155,10 @@ type invokeMessage struct {
 	CID    []byte
 	EID    int
 	State  *CodeState
+
+	// TraceParent is trace context of the invocation in W3C format.
+	// It's empty if it's not sampled.
+	TraceParent string
 }
 
 type getValueMessage struct {
@@ -268,16 +282,35 @@ func (p *proxy) Invoke(
 
 	logger.Tracef("Proxy[%p].Invoke code=%s query=%v from=%v to=%v value=%v limit=%v method=%s eid=%d", p, code, isQuery, from, to, value, limit, method, eid)
 
+	var span *octrace.Span
+	if tp, ok := ctx.(TraceContextProvider); ok {
+		_, span = octrace.StartSpan(tp.TraceContext(), "eeproxy.invoke")
+		span.AddAttributes(
+			octrace.StringAttribute("type", p.scoreType),
+			octrace.StringAttribute("to", to.String()),
+			octrace.StringAttribute("method", method),
+			octrace.Int64Attribute("eid", int64(eid)),
+		)
+		m.TraceParent = tracing.TraceParent(span)
+	}
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	p.frame = &callFrame{
 		addr: to,
 		ctx:  ctx,
 		log:  p.log,
+		span: span,
 		prev: p.frame,
 	}
 	p.log = logger
-	return p.conn.Send(msgINVOKE, &m)
+	if err := p.conn.Send(msgINVOKE, &m); err != nil {
+		if span != nil {
+			tracing.EndSpan(span, err)
+		}
+		return err
+	}
+	return nil
 }
 
 func (p *proxy) GetAPI(ctx CallContext, code string) error {
@@ -407,6 +440,10 @@ func (p *proxy) HandleMessage(c ipc.Connection, msg uint, data []byte) error {
 			status = m.Status.New(msg)
 			result = nil
 		}
+		if frame.span != nil {
+			frame.span.AddAttributes(octrace.StringAttribute("stepUsed", m.StepUsed.String()))
+			tracing.EndSpan(frame.span, status)
+		}
 		frame.ctx.OnResult(status, &m.StepUsed.Int, result)
 
 		return p.tryToBeReady()
@@ -624,6 +661,9 @@ func (p *proxy) OnClose() {
 	if p.frame != nil && p.state == stateReserved {
 		frame := p.frame
 		status := errors.ExecutionFailError.New("ProxyIsClosed")
+		if frame.span != nil {
+			tracing.EndSpan(frame.span, status)
+		}
 		l.CallAfterUnlock(func() {
 			frame.ctx.OnResult(status, new(big.Int), nil)
 		})
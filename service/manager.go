@@ -141,7 +141,10 @@ func (m *manager) ProposeTransition(parent module.Transition, bi module.BlockInf
 	}
 	maxTxCount := m.chain.Regulator().MaxTxCount()
 	txSizeInBlock := m.chain.MaxBlockTxBytes()
-	normalTxs, _ := m.tm.Candidate(module.TransactionGroupNormal, wc, txSizeInBlock, maxTxCount)
+	normalTxs := normalCandidates(wc, txSizeInBlock, maxTxCount,
+		func(wc state.WorldContext, maxBytes, maxCount int) ([]module.Transaction, int) {
+			return m.tm.Candidate(module.TransactionGroupNormal, wc, maxBytes, maxCount)
+		})
 	if baseTx != nil {
 		normalTxs = append([]module.Transaction{baseTx}, normalTxs...)
 	}
@@ -158,6 +161,33 @@ func (m *manager) ProposeTransition(parent module.Transition, bi module.BlockInf
 		nil
 }
 
+// normalCandidates returns callback transactions due in the block followed by
+// candidates of normal transactions. Callbacks are a part of the block, so
+// the candidates are limited by the bytes and the count left by callbacks.
+func normalCandidates(wc state.WorldContext, maxBytes, maxCount int,
+	candidate func(wc state.WorldContext, maxBytes, maxCount int) ([]module.Transaction, int),
+) []module.Transaction {
+	if maxBytes <= 0 {
+		maxBytes = configDefaultMaxTxBytesInABlock
+	}
+	if maxCount <= 0 {
+		maxCount = configDefaultMaxTxCount
+	}
+	txs := transaction.NewCallbackTransactions(wc)
+	for _, tx := range txs {
+		maxBytes -= len(tx.Bytes())
+	}
+	maxCount -= len(txs)
+	if maxBytes <= 0 || maxCount <= 0 {
+		return txs
+	}
+	normalTxs, _ := candidate(wc, maxBytes, maxCount)
+	if len(txs) == 0 {
+		return normalTxs
+	}
+	return append(txs, normalTxs...)
+}
+
 // CreateInitialTransition creates an initial Transition with result and
 // vs validators.
 func (m *manager) CreateInitialTransition(result []byte,
@@ -535,6 +565,68 @@ func (m *manager) GetTotalSupply(result []byte) (*big.Int, error) {
 	return big.NewInt(0), nil
 }
 
+func (m *manager) GetStorageUsages(result []byte, limit int) ([]*module.ContractStorageUsage, error) {
+	wss, err := m.trc.GetWorldSnapshot(result, nil)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := state.LargestStorageUsages(wss, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*module.ContractStorageUsage, len(usages))
+	for i, u := range usages {
+		res[i] = &module.ContractStorageUsage{
+			Address:      u.Address,
+			DeployTxHash: u.DeployTxHash,
+			Keys:         u.Keys,
+			Bytes:        u.Bytes,
+		}
+		if res[i].Address == nil {
+			res[i].Address = m.contractAddressOf(u.DeployTxHash)
+		}
+	}
+	return res, nil
+}
+
+func (m *manager) GetRewardHistory(addr module.Address, start int64, limit int) (interface{}, error) {
+	hr, ok := m.plt.(base.HistoryReader)
+	if !ok {
+		return nil, errors.UnsupportedError.New("NoRewardHistory")
+	}
+	return hr.GetRewardHistory(m.db, addr, start, limit)
+}
+
+func (m *manager) GetValidationHistory(result []byte, owner module.Address, start, end int64, limit int) (interface{}, error) {
+	hr, ok := m.plt.(base.HistoryReader)
+	if !ok {
+		return nil, errors.UnsupportedError.New("NoValidationHistory")
+	}
+	wss, err := m.trc.GetWorldSnapshot(result, nil)
+	if err != nil {
+		return nil, err
+	}
+	return hr.GetValidationHistory(m.db, wss.GetExtensionSnapshot(), owner, start, end, limit)
+}
+
+// contractAddressOf returns the address of the contract deployed by the
+// transaction. It returns nil if the transaction is not found.
+func (m *manager) contractAddressOf(txHash []byte) module.Address {
+	bm := m.chain.BlockManager()
+	if len(txHash) == 0 || bm == nil {
+		return nil
+	}
+	ti, err := bm.GetTransactionInfo(txHash)
+	if err != nil {
+		return nil
+	}
+	rct, err := ti.GetReceipt()
+	if err != nil || rct == nil {
+		return nil
+	}
+	return rct.SCOREAddress()
+}
+
 func (m *manager) GetNetworkID(result []byte) (int64, error) {
 	as, err := m.getSystemByteStoreState(result)
 	if err != nil {
@@ -632,6 +724,18 @@ func (m *manager) HasTransaction(id []byte) bool {
 	return m.tm.HasTx(id)
 }
 
+func (m *manager) GetPendingTransactions(g module.TransactionGroup, from module.Address, offset, limit int) ([]*module.PendingTransaction, int) {
+	return m.tm.GetPendingTransactions(g, from, offset, limit)
+}
+
+func (m *manager) GetPendingTransaction(id []byte) (*module.PendingTransaction, error) {
+	return m.tm.GetPendingTransaction(id)
+}
+
+func (m *manager) GetTransactionPoolUsage() []module.TransactionPoolUsage {
+	return m.tm.GetPoolUsage()
+}
+
 func (m *manager) WaitForTransaction(
 	parent module.Transition,
 	bi module.BlockInfo,
@@ -670,7 +774,7 @@ func (m *manager) ImportResult(result []byte, vh []byte, src db.Database) error
 	return e.Run()
 }
 
-func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo) (module.Receipt, error) {
+func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo, ti *module.TraceInfo) (module.Receipt, error) {
 	tx, err := transaction.NewTransactionFromJSON(js)
 	if err != nil {
 		return nil, err
@@ -685,17 +789,10 @@ func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi mod
 	}
 	defer txh.Dispose()
 
-	var wc state.WorldContext
-	if wss, err := m.trc.GetWorldSnapshot(result, vh); err == nil {
-		ws, err := state.WorldStateFromSnapshot(wss)
-		if err != nil {
-			return nil, err
-		}
-		wc = state.NewWorldContext(ws, bi, nil, m.plt)
-	} else {
+	ctx, err := m.newExecutionContext(result, vh, bi, ti)
+	if err != nil {
 		return nil, err
 	}
-	ctx := contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, nil)
 	ctx.SetTransactionInfo(&state.TransactionInfo{
 		Group:     module.TransactionGroupNormal,
 		Index:     0,
@@ -708,3 +805,66 @@ func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi mod
 
 	return txh.Execute(ctx, true)
 }
+
+// newExecutionContext returns the context for executing transactions on the
+// specified state. Changes are kept only in the returned context.
+func (m *manager) newExecutionContext(result []byte, vh []byte, bi module.BlockInfo, ti *module.TraceInfo) (contract.Context, error) {
+	wss, err := m.trc.GetWorldSnapshot(result, vh)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := state.WorldStateFromSnapshot(wss)
+	if err != nil {
+		return nil, err
+	}
+	wc := state.NewWorldContext(ws, bi, nil, m.plt)
+	return contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, ti), nil
+}
+
+func (m *manager) SimulateTransactions(result []byte, vh []byte, txs [][]byte, bi module.BlockInfo) ([]module.Receipt, error) {
+	txos := make([]transaction.Transaction, len(txs))
+	for i, js := range txs {
+		tx, err := transaction.NewTransactionFromJSON(js)
+		if err != nil {
+			return nil, scoreresult.InvalidParameterError.Wrapf(err, "InvalidTransaction(idx=%d)", i)
+		}
+		if err := tx.Verify(); err != nil && !transaction.InvalidSignatureError.Equals(err) {
+			return nil, scoreresult.InvalidParameterError.Wrapf(err, "InvalidTransaction(idx=%d)", i)
+		}
+		txos[i] = tx
+	}
+
+	ctx, err := m.newExecutionContext(result, vh, bi, nil)
+	if err != nil {
+		return nil, err
+	}
+	rcts := make([]module.Receipt, len(txos))
+	cumulativeSteps := new(big.Int)
+	for i, tx := range txos {
+		txh, err := tx.GetHandler(m.cm)
+		if err != nil {
+			return nil, err
+		}
+		ctx.SetTransactionInfo(&state.TransactionInfo{
+			Group:     module.TransactionGroupNormal,
+			Index:     int32(i),
+			Hash:      tx.ID(),
+			From:      tx.From(),
+			Timestamp: tx.Timestamp(),
+			Nonce:     tx.Nonce(),
+		})
+		ctx.UpdateSystemInfo()
+		rct, err := txh.Execute(ctx, true)
+		txh.Dispose()
+		if err == nil {
+			err = m.plt.OnTransactionEnd(ctx, m.log, rct)
+		}
+		if err != nil {
+			return nil, err
+		}
+		cumulativeSteps.Add(cumulativeSteps, rct.StepUsed())
+		rct.SetCumulativeStepUsed(cumulativeSteps)
+		rcts[i] = rct
+	}
+	return rcts, nil
+}
@@ -489,6 +489,36 @@ var chainMethods = []*chainMethod{
 			scoreapi.Bool,
 		},
 	}, Revision8, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "scheduleCallback",
+		scoreapi.FlagExternal, 3,
+		[]scoreapi.Parameter{
+			{"height", scoreapi.Integer, nil, nil},
+			{"method", scoreapi.String, nil, nil},
+			{"stepLimit", scoreapi.Integer, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Integer,
+		},
+	}, Revision10, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "cancelCallback",
+		scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"id", scoreapi.Integer, nil, nil},
+		},
+		nil,
+	}, Revision10, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getCallback",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"id", scoreapi.Integer, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, Revision10, 0},
 }
 
 func (s *ChainScore) GetAPI() *scoreapi.Info {
@@ -1275,6 +1305,10 @@ func (s *ChainScore) Ex_getScoreStatus(address module.Address) (map[string]inter
 		scoreStatus["depositInfo"] = di
 	}
 
+	if usage := as.StorageUsage(); usage != nil {
+		scoreStatus["storageUsage"] = usage.ToJSON()
+	}
+
 	// blocked
 	if as.IsBlocked() == true {
 		scoreStatus["blocked"] = "0x1"
@@ -1390,3 +1424,28 @@ func (s *ChainScore) Ex_setMinimizeBlockGen(b bool) error {
 	mbg := scoredb.NewVarDB(as, state.VarMinimizeBlockGen)
 	return mbg.Set(b)
 }
+
+func (s *ChainScore) Ex_scheduleCallback(height *common.HexInt, method string, stepLimit *common.HexInt) (int64, error) {
+	if err := s.tryChargeCall(); err != nil {
+		return 0, err
+	}
+	return contract.ScheduleCallback(s.cc, s.from, height.Int64(), method, stepLimit.Value())
+}
+
+func (s *ChainScore) Ex_cancelCallback(id *common.HexInt) error {
+	if err := s.tryChargeCall(); err != nil {
+		return err
+	}
+	return contract.CancelCallback(s.cc, s.from, id.Int64())
+}
+
+func (s *ChainScore) Ex_getCallback(id *common.HexInt) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(); err != nil {
+		return nil, err
+	}
+	cb := contract.GetScheduledCallback(s.cc.GetAccountState(state.SystemID), id.Int64())
+	if cb == nil {
+		return nil, scoreresult.New(StatusNotFound, "CallbackNotFound")
+	}
+	return cb.ToJSON(), nil
+}
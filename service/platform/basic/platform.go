@@ -69,7 +69,7 @@ func (t *platform) NewBaseTransaction(wc state.WorldContext) (module.Transaction
 	return nil, nil
 }
 
-func (t *platform) OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, logger log.Logger) {
+func (t *platform) OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, bi module.BlockInfo, logger log.Logger) {
 	// do nothing
 }
 
@@ -27,13 +27,15 @@ const (
 	Revision6
 	Revision7
 	Revision8
+	Revision9
+	Revision10
 	RevisionReserved
 )
 
 const (
 	DefaultRevision = Revision4
 	MaxRevision     = RevisionReserved - 1
-	LatestRevision  = Revision8
+	LatestRevision  = Revision10
 )
 
 var revisionFlags = []module.Revision{
@@ -46,6 +48,8 @@ var revisionFlags = []module.Revision{
 	module.ExpandErrorCode,
 	module.UseChainID | module.UseMPTOnEvents,
 	module.UseCompactAPIInfo,
+	module.TrackStorageUsage,
+	module.ScheduledCallback,
 }
 
 func init() {
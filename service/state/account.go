@@ -27,6 +27,7 @@ import (
 const (
 	AccountVersion1 = iota + 1
 	AccountVersion2
+	AccountVersion3
 	AccountVersion = AccountVersion1
 )
 
@@ -42,6 +43,7 @@ type AccountSnapshot interface {
 	IsEmpty() bool
 	GetValue(k []byte) ([]byte, error)
 	StorageChangedAfter(snapshot AccountSnapshot) bool
+	StorageUsage() *StorageUsage
 
 	IsContractOwner(owner module.Address) bool
 	APIInfo() (*scoreapi.Info, error)
@@ -72,6 +74,7 @@ type AccountState interface {
 	SetBalance(v *big.Int)
 	SetValue(k, v []byte) ([]byte, error)
 	DeleteValue(k []byte) ([]byte, error)
+	StorageUsage() *StorageUsage
 	GetSnapshot() AccountSnapshot
 	Reset(snapshot AccountSnapshot) error
 	Clear()
@@ -108,6 +111,7 @@ type AccountState interface {
 const (
 	ExObjectGraph int = 1 << iota
 	ExDepositInfo
+	ExStorageUsage
 )
 
 type accountSnapshotImpl struct {
@@ -126,6 +130,10 @@ type accountSnapshotImpl struct {
 	objCache objectGraphCache
 	objGraph *objectGraph
 	deposits depositList
+	usage    StorageUsage
+
+	// flat is available if the store is same as the one in flat state.
+	flat *flatStorage
 }
 
 func (s *accountSnapshotImpl) ContractOwner() module.Address {
@@ -172,6 +180,9 @@ func (s *accountSnapshotImpl) GetValue(k []byte) ([]byte, error) {
 	if s.store == nil {
 		return nil, nil
 	}
+	if v, ok := s.flat.get(k); ok {
+		return v, nil
+	}
 	return s.store.Get(k)
 }
 
@@ -265,6 +276,9 @@ func (s *accountSnapshotImpl) Equal(object trie.Object) bool {
 		if s.deposits.Equal(s2.deposits) == false {
 			return false
 		}
+		if s.usage != s2.usage {
+			return false
+		}
 		if s.store == s2.store {
 			return true
 		}
@@ -313,6 +327,14 @@ func (s *accountSnapshotImpl) StorageChangedAfter(ass AccountSnapshot) bool {
 	return true
 }
 
+func (s *accountSnapshotImpl) StorageUsage() *StorageUsage {
+	if s.version < AccountVersion3 {
+		return nil
+	}
+	usage := s.usage
+	return &usage
+}
+
 func (s *accountSnapshotImpl) IsContractOwner(owner module.Address) bool {
 	if s.fIsContract == false {
 		return false
@@ -390,6 +412,11 @@ func (s *accountSnapshotImpl) RLPEncodeSelf(e codec.Encoder) error {
 				return err
 			}
 		}
+		if (flag & ExStorageUsage) != 0 {
+			if err := e2.Encode(&s.usage); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -402,6 +429,9 @@ func (s *accountSnapshotImpl) extensionFlag() int {
 	if s.deposits.Has() {
 		flag |= ExDepositInfo
 	}
+	if s.version >= AccountVersion3 && !s.usage.IsEmpty() {
+		flag |= ExStorageUsage
+	}
 	return flag
 }
 
@@ -462,6 +492,12 @@ func (s *accountSnapshotImpl) RLPDecodeSelf(d codec.Decoder) error {
 				return errors.Wrap(codec.ErrInvalidFormat, "Fail to decode deposits")
 			}
 		}
+
+		if (extension & ExStorageUsage) != 0 {
+			if err := d2.Decode(&s.usage); err != nil {
+				return errors.Wrap(codec.ErrInvalidFormat, "Fail to decode storage usage")
+			}
+		}
 	}
 	return nil
 }
@@ -508,6 +544,10 @@ type accountStateImpl struct {
 
 	objCache objectGraphCache
 	deposits depositList
+	usage    StorageUsage
+
+	// flat is available until the store is changed.
+	flat *flatStorage
 }
 
 func (s *accountStateImpl) markDirty() {
@@ -698,6 +738,11 @@ func (s *accountStateImpl) migrate(v int) error {
 			}
 			s.apiInfo.dirty = true
 		}
+		if s.version < AccountVersion3 && v >= AccountVersion3 {
+			if err := s.initStorageUsage(); err != nil {
+				return err
+			}
+		}
 		s.version = v
 		s.markDirty()
 	}
@@ -754,6 +799,8 @@ func (s *accountStateImpl) GetSnapshot() AccountSnapshot {
 		objGraph:      objGraph,
 		objCache:      s.objCache.Clone(),
 		deposits:      s.deposits.Clone(),
+		usage:         s.usage,
+		flat:          s.flat,
 	}
 	return s.last
 }
@@ -788,6 +835,8 @@ func (s *accountStateImpl) Reset(isnapshot AccountSnapshot) error {
 	s.nextContract = newContractState(snapshot.nextContract, s.markDirty)
 	s.objCache = snapshot.objCache.Clone()
 	s.deposits = snapshot.deposits.Clone()
+	s.usage = snapshot.usage
+	s.flat = snapshot.flat
 	if snapshot.store == nil {
 		s.store = nil
 		return nil
@@ -817,6 +866,9 @@ func (s *accountStateImpl) GetValue(k []byte) ([]byte, error) {
 	if s.store == nil {
 		return nil, nil
 	}
+	if v, ok := s.flat.get(k); ok {
+		return v, nil
+	}
 	return s.store.Get(k)
 }
 
@@ -829,6 +881,14 @@ func (s *accountStateImpl) SetValue(k, v []byte) ([]byte, error) {
 		s.attachCacheForStore()
 	}
 	if old, err := s.store.Set(k, v); err == nil {
+		if s.version >= AccountVersion3 {
+			if old == nil {
+				s.usage.add(k, v)
+			} else {
+				s.usage.Bytes += int64(len(v) - len(old))
+			}
+		}
+		s.flat = nil
 		s.markDirty()
 		return old, nil
 	} else {
@@ -841,6 +901,10 @@ func (s *accountStateImpl) DeleteValue(k []byte) ([]byte, error) {
 		return nil, nil
 	}
 	if old, err := s.store.Delete(k); err == nil && len(old) > 0 {
+		if s.version >= AccountVersion3 {
+			s.usage.remove(k, old)
+		}
+		s.flat = nil
 		s.markDirty()
 		return old, nil
 	} else {
@@ -848,6 +912,34 @@ func (s *accountStateImpl) DeleteValue(k []byte) ([]byte, error) {
 	}
 }
 
+func (s *accountStateImpl) StorageUsage() *StorageUsage {
+	if s.version < AccountVersion3 {
+		return nil
+	}
+	usage := s.usage
+	return &usage
+}
+
+// initStorageUsage counts keys and bytes in the storage for starting to
+// track the usage.
+func (s *accountStateImpl) initStorageUsage() error {
+	s.usage = StorageUsage{}
+	if s.store == nil {
+		return nil
+	}
+	for itr := s.store.GetSnapshot().Iterator(); itr.Has(); {
+		v, k, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		s.usage.add(k, v)
+		if err := itr.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *accountStateImpl) Contract() Contract {
 	if s.curContract == nil {
 		return nil
@@ -1052,7 +1144,9 @@ func newAccountROState(dbase db.Database, snapshot AccountSnapshot) AccountState
 }
 
 func accountVersionForRevision(rev module.Revision) int {
-	if rev.UseCompactAPIInfo() {
+	if rev.TrackStorageUsage() {
+		return AccountVersion3
+	} else if rev.UseCompactAPIInfo() {
 		return AccountVersion2
 	} else {
 		return AccountVersion1
@@ -8,6 +8,7 @@ import (
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
 )
 
 func TestAccountSnapshot_Equal(t *testing.T) {
@@ -229,3 +230,40 @@ func TestAccountStateImpl_SetObjGraph(t *testing.T) {
 
 	assertAccountSnapshot(t, dbase, ass, code2, next2v1, graph2v1)
 }
+
+func TestAccountState_StorageUsage(t *testing.T) {
+	database := db.NewMapDB()
+	as := newAccountState(database, nil, nil, false)
+	as.SetValue([]byte("k1"), []byte("value1"))
+	assert.Nil(t, as.StorageUsage())
+	s0 := as.GetSnapshot()
+
+	err := as.MigrateForRevision(module.UseCompactAPIInfo)
+	assert.NoError(t, err)
+	assert.Nil(t, as.StorageUsage())
+
+	err = as.MigrateForRevision(module.UseCompactAPIInfo | module.TrackStorageUsage)
+	assert.NoError(t, err)
+	assert.Equal(t, &StorageUsage{1, 8}, as.StorageUsage())
+
+	as.SetValue([]byte("k2"), []byte("v2"))
+	as.SetValue([]byte("k1"), []byte("v1"))
+	assert.Equal(t, &StorageUsage{2, 8}, as.StorageUsage())
+	as.DeleteValue([]byte("k3"))
+	as.DeleteValue([]byte("k2"))
+	assert.Equal(t, &StorageUsage{1, 4}, as.StorageUsage())
+
+	s1 := as.GetSnapshot()
+	assert.False(t, s0.Equal(s1))
+	s1.Flush()
+	s2 := new(accountSnapshotImpl)
+	err = s2.Reset(database, s1.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, s1.Equal(s2))
+	assert.Equal(t, &StorageUsage{1, 4}, s2.StorageUsage())
+
+	as.DeleteValue([]byte("k1"))
+	s3 := as.GetSnapshot()
+	assert.Equal(t, &StorageUsage{0, 0}, s3.StorageUsage())
+	assert.Equal(t, 0, s3.(*accountSnapshotImpl).extensionFlag()&ExStorageUsage)
+}
@@ -0,0 +1,959 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/ompt"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
+)
+
+// Flat state keeps accounts and storage values of the world state in a flat
+// key value store, so they can be read without traversing merkle tries.
+//
+// It consists of the disk layer for the latest finalized world state in
+// db.FlatState bucket, and diff layers in the memory for the results not
+// finalized yet. Each diff layer has changes from its parent layer, and
+// it's flattened into the disk layer on finalization.
+//
+// If the disk layer is not available for the database (ex. the database is
+// made by old version), then it's generated in background by chunks of
+// entries. Flat state is only a cache of the merkle trie, so readers fall
+// back to the trie for the values not generated yet. Whenever it's generated
+// again, the entries of the previous generations are deleted in background
+// after the generation.
+
+const (
+	flatStateManagerFlag = "flatSM"
+
+	// maxFlatDiffLayers is the maximum number of diff layers for the results
+	// not finalized yet.
+	maxFlatDiffLayers = 128
+
+	// flatGenerateBatch is the number of entries to generate at once. An
+	// account having more storage values is generated with multiple batches.
+	flatGenerateBatch = 256
+
+	flatAccountPrefix = 'a'
+	flatStoragePrefix = 's'
+)
+
+var flatMetaKey = []byte("meta")
+
+// flatLayer is a flat view of the world state for the root. Reads return
+// false if the layer doesn't know the value, then it needs to be read from
+// the merkle trie.
+type flatLayer interface {
+	Root() []byte
+	Account(key []byte) ([]byte, bool)
+	Storage(account, key []byte) ([]byte, bool)
+}
+
+// flatStateMeta is the information about the disk layer stored in the
+// database.
+// Epoch changes whenever it starts to generate again, so entries of the
+// previous generation are not used. Accounts whose keys are less than Marker
+// are generated if it's not Complete. If StorageMarker is not nil, then
+// storage values of the Marker account whose keys are less than it are
+// generated, but the account isn't available until all values are generated.
+// Flattening is true while it writes the changes to the disk, and the
+// entries are not reliable if it remains. Entries of the epochs less than
+// Pruned are deleted.
+type flatStateMeta struct {
+	Epoch         uint32
+	Root          []byte
+	Marker        []byte
+	Complete      bool
+	Flattening    bool
+	StorageMarker []byte
+	Pruned        uint32
+}
+
+func flatAccountKey(epoch uint32, account []byte) []byte {
+	k := make([]byte, 5+len(account))
+	k[0] = flatAccountPrefix
+	binary.BigEndian.PutUint32(k[1:], epoch)
+	copy(k[5:], account)
+	return k
+}
+
+func flatStorageKey(epoch uint32, account, key []byte) []byte {
+	k := make([]byte, 6+len(account)+len(key))
+	k[0] = flatStoragePrefix
+	binary.BigEndian.PutUint32(k[1:], epoch)
+	k[5] = byte(len(account))
+	copy(k[6:], account)
+	copy(k[6+len(account):], key)
+	return k
+}
+
+// flatEpochPrefixes returns prefixes of the keys of the entries in the epoch.
+func flatEpochPrefixes(epoch uint32) [][]byte {
+	return [][]byte{
+		flatAccountKey(epoch, nil),
+		flatStorageKey(epoch, nil, nil)[:5],
+	}
+}
+
+type flatDiskLayer struct {
+	lock     sync.RWMutex
+	bucket   db.Bucket
+	epoch    uint32
+	root     []byte
+	marker   []byte
+	complete bool
+	stale    bool
+}
+
+func (l *flatDiskLayer) Root() []byte {
+	return l.root
+}
+
+func (l *flatDiskLayer) coversInLock(account []byte) bool {
+	return !l.stale && (l.complete || bytes.Compare(account, l.marker) < 0)
+}
+
+func (l *flatDiskLayer) get(account []byte, key []byte) ([]byte, bool) {
+	if !l.coversInLock(account) {
+		return nil, false
+	}
+	value, err := l.bucket.Get(key)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (l *flatDiskLayer) Account(key []byte) ([]byte, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.get(key, flatAccountKey(l.epoch, key))
+}
+
+func (l *flatDiskLayer) Storage(account, key []byte) ([]byte, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.get(account, flatStorageKey(l.epoch, account, key))
+}
+
+func (l *flatDiskLayer) setMarker(marker []byte) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.marker = marker
+	l.complete = marker == nil
+}
+
+func (l *flatDiskLayer) setStale() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.stale = true
+}
+
+// flatDiffLayer has changes of the world state from the parent layer.
+// nil value means that the account or the value is deleted.
+type flatDiffLayer struct {
+	lock     sync.RWMutex
+	root     []byte
+	parent   flatLayer
+	accounts map[string][]byte
+	storage  map[string]map[string][]byte
+	stale    bool
+}
+
+func (l *flatDiffLayer) Root() []byte {
+	return l.root
+}
+
+func (l *flatDiffLayer) Account(key []byte) ([]byte, bool) {
+	l.lock.RLock()
+	if l.stale {
+		l.lock.RUnlock()
+		return nil, false
+	}
+	value, ok := l.accounts[string(key)]
+	parent := l.parent
+	l.lock.RUnlock()
+
+	if ok {
+		return value, true
+	}
+	return parent.Account(key)
+}
+
+func (l *flatDiffLayer) Storage(account, key []byte) ([]byte, bool) {
+	l.lock.RLock()
+	if l.stale {
+		l.lock.RUnlock()
+		return nil, false
+	}
+	value, ok := l.storage[string(account)][string(key)]
+	parent := l.parent
+	l.lock.RUnlock()
+
+	if ok {
+		return value, true
+	}
+	return parent.Storage(account, key)
+}
+
+func (l *flatDiffLayer) getParent() flatLayer {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.parent
+}
+
+// follows returns whether the layer is a descendant of the layer.
+func (l *flatDiffLayer) follows(ancestor flatLayer) bool {
+	for p := l.getParent(); p != ancestor; {
+		dl, ok := p.(*flatDiffLayer)
+		if !ok {
+			return false
+		}
+		p = dl.getParent()
+	}
+	return true
+}
+
+func (l *flatDiffLayer) setStale() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.stale = true
+	l.accounts = nil
+	l.storage = nil
+}
+
+// flattenTo makes the layer to read the disk layer having all of its changes.
+func (l *flatDiffLayer) flattenTo(disk *flatDiskLayer) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.parent = disk
+	l.accounts = nil
+	l.storage = nil
+}
+
+func (l *flatDiffLayer) updateStorage(database db.Database, account []byte, s1, s2 trie.Immutable) error {
+	if s1 == nil && s2 == nil {
+		return nil
+	}
+	if s1 == nil {
+		s1 = trie_manager.NewImmutable(database, nil)
+	}
+	if s2 == nil {
+		s2 = trie_manager.NewImmutable(database, nil)
+	}
+	values, ok := l.storage[string(account)]
+	if !ok {
+		values = make(map[string][]byte)
+		l.storage[string(account)] = values
+	}
+	return trie_manager.CompareImmutable(s1, s2, func(op int, key, exp, real []byte) {
+		values[string(key)] = real
+	})
+}
+
+type flatEntry struct {
+	key   []byte
+	value []byte
+}
+
+type flatStateManager struct {
+	lock       sync.Mutex
+	database   db.Database
+	bucket     db.Bucket
+	meta       flatStateMeta
+	disk       *flatDiskLayer
+	layers     map[string]*flatDiffLayer
+	generating bool
+	pruning    bool
+}
+
+func (m *flatStateManager) layerOfInLock(root []byte) flatLayer {
+	if m.disk != nil && bytes.Equal(m.disk.root, root) {
+		return m.disk
+	}
+	if l, ok := m.layers[string(root)]; ok {
+		return l
+	}
+	return nil
+}
+
+func (m *flatStateManager) layerOf(root []byte) flatLayer {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.layerOfInLock(root)
+}
+
+// update returns the layer for the world snapshot. If there is no layer for
+// the snapshot, then it makes new diff layer on the layer of the parent.
+func (m *flatStateManager) update(parent, wss *worldSnapshotImpl) (flatLayer, error) {
+	root := wss.StateHash()
+	if l := m.layerOf(root); l != nil {
+		return l, nil
+	}
+	base := m.layerOf(parent.StateHash())
+	if base == nil {
+		return nil, nil
+	}
+
+	layer := &flatDiffLayer{
+		root:     root,
+		parent:   base,
+		accounts: make(map[string][]byte),
+		storage:  make(map[string]map[string][]byte),
+	}
+	var serr error
+	err := trie_manager.CompareImmutableForObject(parent.accounts, wss.accounts, func(op int, key []byte, exp, real trie.Object) {
+		var s1, s2 trie.Immutable
+		var value []byte
+		if exp != nil {
+			s1 = exp.(*accountSnapshotImpl).store
+		}
+		if real != nil {
+			s2 = real.(*accountSnapshotImpl).store
+			value = real.Bytes()
+		}
+		layer.accounts[string(key)] = value
+		if err := layer.updateStorage(wss.database, key, s1, s2); err != nil && serr == nil {
+			serr = err
+		}
+	})
+	if err == nil {
+		err = serr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if l := m.layerOfInLock(root); l != nil {
+		return l, nil
+	}
+	if len(m.layers) >= maxFlatDiffLayers {
+		return nil, nil
+	}
+	m.layers[string(root)] = layer
+	return layer, nil
+}
+
+func (m *flatStateManager) writeMetaInLock() error {
+	bs, err := codec.BC.MarshalToBytes(&m.meta)
+	if err != nil {
+		return err
+	}
+	return m.bucket.Set(flatMetaKey, bs)
+}
+
+func (m *flatStateManager) newDiskLayerInLock() *flatDiskLayer {
+	return &flatDiskLayer{
+		bucket:   m.bucket,
+		epoch:    m.meta.Epoch,
+		root:     m.meta.Root,
+		marker:   m.meta.Marker,
+		complete: m.meta.Complete,
+	}
+}
+
+// invalidateInLock drops all layers on failure. The disk layer is generated
+// again on next finalization.
+func (m *flatStateManager) invalidateInLock(err error) error {
+	log.Warnf("Flat state is invalidated err=%+v", err)
+	if m.disk != nil {
+		m.disk.setStale()
+		m.disk = nil
+	}
+	for _, l := range m.layers {
+		l.setStale()
+	}
+	m.layers = make(map[string]*flatDiffLayer)
+	m.meta.Flattening = true
+	_ = m.writeMetaInLock()
+	return err
+}
+
+// loadInLock loads the disk layer from the database if it's for the root.
+func (m *flatStateManager) loadInLock(root []byte) (bool, error) {
+	bs, err := m.bucket.Get(flatMetaKey)
+	if err != nil || bs == nil {
+		return false, err
+	}
+	var meta flatStateMeta
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &meta); err != nil {
+		log.Warnf("Fail to decode flat state meta err=%+v", err)
+		return false, nil
+	}
+	m.meta = meta
+	if meta.Flattening || !bytes.Equal(meta.Root, root) {
+		return false, nil
+	}
+	m.disk = m.newDiskLayerInLock()
+	return true, nil
+}
+
+// regenerateInLock drops all layers, and starts to generate the disk layer
+// for the root.
+func (m *flatStateManager) regenerateInLock(root []byte) error {
+	if m.disk != nil {
+		m.disk.setStale()
+	}
+	for _, l := range m.layers {
+		l.setStale()
+	}
+	m.layers = make(map[string]*flatDiffLayer)
+	m.meta = flatStateMeta{
+		Epoch:  m.meta.Epoch + 1,
+		Root:   root,
+		Pruned: m.meta.Pruned,
+	}
+	if err := m.writeMetaInLock(); err != nil {
+		return m.invalidateInLock(err)
+	}
+	m.disk = m.newDiskLayerInLock()
+	log.Infof("Start to generate flat state root=%#x epoch=%d", root, m.meta.Epoch)
+	m.startGeneratorInLock()
+	return nil
+}
+
+func (m *flatStateManager) coversInLock(account []byte) bool {
+	return m.meta.Complete || bytes.Compare(account, m.meta.Marker) < 0
+}
+
+// coversStorageInLock returns whether the storage value is generated. It
+// includes the values of the account being generated.
+func (m *flatStateManager) coversStorageInLock(account, key []byte) bool {
+	if m.coversInLock(account) {
+		return true
+	}
+	return m.meta.StorageMarker != nil && bytes.Equal(account, m.meta.Marker) &&
+		bytes.Compare(key, m.meta.StorageMarker) < 0
+}
+
+func (m *flatStateManager) writeLayerInLock(l *flatDiffLayer) error {
+	epoch := m.meta.Epoch
+	write := func(key, value []byte) error {
+		if value == nil {
+			return m.bucket.Delete(key)
+		}
+		return m.bucket.Set(key, value)
+	}
+	for account, value := range l.accounts {
+		if !m.coversInLock([]byte(account)) {
+			continue
+		}
+		if err := write(flatAccountKey(epoch, []byte(account)), value); err != nil {
+			return err
+		}
+	}
+	for account, values := range l.storage {
+		for key, value := range values {
+			if !m.coversStorageInLock([]byte(account), []byte(key)) {
+				continue
+			}
+			if err := write(flatStorageKey(epoch, []byte(account), []byte(key)), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// finalize makes the layer for the world snapshot to be the disk layer.
+// If there is no layer for it, then it starts to generate new one.
+func (m *flatStateManager) finalize(wss *worldSnapshotImpl) error {
+	root := wss.StateHash()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.disk == nil {
+		if ok, err := m.loadInLock(root); err != nil || ok {
+			if ok {
+				m.startGeneratorInLock()
+				m.startPrunerInLock()
+			}
+			return err
+		}
+		return m.regenerateInLock(root)
+	}
+	if bytes.Equal(m.disk.root, root) {
+		return nil
+	}
+	target, ok := m.layers[string(root)]
+	if !ok {
+		return m.regenerateInLock(root)
+	}
+	var chain []*flatDiffLayer
+	for l := target; ; {
+		chain = append(chain, l)
+		parent := l.getParent()
+		if parent == flatLayer(m.disk) {
+			break
+		}
+		if l, ok = parent.(*flatDiffLayer); !ok {
+			return m.regenerateInLock(root)
+		}
+	}
+
+	m.meta.Flattening = true
+	if err := m.writeMetaInLock(); err != nil {
+		return m.invalidateInLock(err)
+	}
+	m.disk.setStale()
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := m.writeLayerInLock(chain[i]); err != nil {
+			return m.invalidateInLock(err)
+		}
+	}
+	m.meta.Root = root
+	m.meta.Flattening = false
+	if err := m.writeMetaInLock(); err != nil {
+		return m.invalidateInLock(err)
+	}
+	m.disk = m.newDiskLayerInLock()
+
+	for i, l := range chain {
+		delete(m.layers, string(l.root))
+		if i == 0 {
+			l.flattenTo(m.disk)
+		} else {
+			l.setStale()
+		}
+	}
+	for key, l := range m.layers {
+		if !l.follows(target) {
+			l.setStale()
+			delete(m.layers, key)
+		}
+	}
+	return nil
+}
+
+func (m *flatStateManager) startGeneratorInLock() {
+	if m.generating || m.meta.Complete {
+		return
+	}
+	m.generating = true
+	go m.generate()
+}
+
+// startPrunerInLock starts to delete entries of the previous epochs in
+// background if the disk layer of the current epoch is generated.
+func (m *flatStateManager) startPrunerInLock() {
+	if m.pruning || !m.meta.Complete || m.meta.Pruned >= m.meta.Epoch {
+		return
+	}
+	m.pruning = true
+	go m.prune(m.meta.Pruned, m.meta.Epoch)
+}
+
+// prune deletes entries of the epochs from start to end(exclusive). Those
+// epochs are not used any more, so it doesn't need to lock the manager
+// while deleting them.
+func (m *flatStateManager) prune(start, end uint32) {
+	var err error
+	for epoch := start; epoch < end && err == nil; epoch++ {
+		for _, prefix := range flatEpochPrefixes(epoch) {
+			if err = db.DeletePrefix(m.bucket, prefix); err != nil {
+				break
+			}
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.pruning = false
+	if err != nil {
+		log.Warnf("Fail to prune flat state epochs=[%d,%d) err=%+v", start, end, err)
+		return
+	}
+	if m.meta.Pruned < end {
+		m.meta.Pruned = end
+		if err = m.writeMetaInLock(); err != nil {
+			log.Warnf("Fail to write flat state meta err=%+v", err)
+			return
+		}
+	}
+	log.Infof("Flat state of old epochs are pruned epochs=[%d,%d)", start, end)
+	m.startPrunerInLock()
+}
+
+// flatBatch is the entries generated at once. The batch has entries of
+// the accounts from the marker of the meta to the marker of the batch.
+type flatBatch struct {
+	entries []flatEntry
+
+	// accounts are keys and values of the accounts read for the batch.
+	accounts []flatEntry
+
+	// marker and storageMarker are the position to generate next.
+	marker        []byte
+	storageMarker []byte
+}
+
+// generate writes accounts of the disk layer in the order of keys. If the
+// disk layer is changed by finalization while it makes a batch, then the
+// batch is written only if the accounts of the batch are not changed.
+// Otherwise, it makes the batch again with the new root. Finalization
+// writes changes only for the entries generated.
+func (m *flatStateManager) generate() {
+	for {
+		m.lock.Lock()
+		disk := m.disk
+		if disk == nil || m.meta.Complete {
+			m.generating = false
+			m.lock.Unlock()
+			return
+		}
+		meta := m.meta
+		m.lock.Unlock()
+
+		b, err := m.generateBatch(meta.Epoch, meta.Root, meta.Marker, meta.StorageMarker)
+		for err == nil {
+			m.lock.Lock()
+			if m.disk == nil || m.meta.Epoch != meta.Epoch {
+				// it's regenerated or invalidated
+				m.lock.Unlock()
+				break
+			}
+			if m.disk == disk {
+				err = m.writeBatchInLock(b)
+				if err == nil && b.marker == nil {
+					log.Infof("Flat state is generated root=%#x epoch=%d", m.meta.Root, meta.Epoch)
+					m.startPrunerInLock()
+				}
+				m.lock.Unlock()
+				break
+			}
+			disk = m.disk
+			root := m.meta.Root
+			m.lock.Unlock()
+
+			var ok bool
+			if ok, err = m.isValidBatch(root, meta.Marker, b); err == nil && !ok {
+				b, err = m.generateBatch(meta.Epoch, root, meta.Marker, meta.StorageMarker)
+			}
+		}
+		if err != nil {
+			m.lock.Lock()
+			m.invalidateInLock(err)
+			m.generating = false
+			m.lock.Unlock()
+			return
+		}
+	}
+}
+
+// generateBatch returns the batch of the entries from the marker. If the
+// storage marker is not nil, then it starts from the storage value of the
+// marker account. Storage values of an account are written before the
+// account, so the account is available after all of them are written.
+func (m *flatStateManager) generateBatch(epoch uint32, root, marker, storageMarker []byte) (*flatBatch, error) {
+	accounts := trie_manager.NewImmutableForObject(m.database, root, AccountType)
+	b := new(flatBatch)
+	for itr := ompt.SeekImmutableForObject(accounts, marker); itr.Has(); {
+		obj, key, err := itr.Get()
+		if err != nil {
+			return nil, err
+		}
+		if len(b.entries) >= flatGenerateBatch {
+			b.marker = key
+			return b, nil
+		}
+		b.accounts = append(b.accounts, flatEntry{key, obj.Bytes()})
+		if as := obj.(*accountSnapshotImpl); as.store != nil {
+			var start []byte
+			if bytes.Equal(key, marker) {
+				start = storageMarker
+			}
+			sitr := ompt.SeekImmutable(as.store, start)
+			if sitr == nil {
+				return nil, errors.UnsupportedError.Errorf("UnknownStorage(account=%#x)", key)
+			}
+			for sitr.Has() {
+				v, k, err := sitr.Get()
+				if err != nil {
+					return nil, err
+				}
+				if len(b.entries) >= flatGenerateBatch {
+					b.marker = key
+					b.storageMarker = k
+					return b, nil
+				}
+				b.entries = append(b.entries, flatEntry{flatStorageKey(epoch, key, k), v})
+				if err := sitr.Next(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		b.entries = append(b.entries, flatEntry{flatAccountKey(epoch, key), obj.Bytes()})
+		if err := itr.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// isValidBatch returns whether the batch generated from the marker has the
+// same entries as the batch for the root.
+func (m *flatStateManager) isValidBatch(root, marker []byte, b *flatBatch) (bool, error) {
+	accounts := trie_manager.NewImmutableForObject(m.database, root, AccountType)
+	idx := 0
+	for itr := ompt.SeekImmutableForObject(accounts, marker); itr.Has(); idx++ {
+		obj, key, err := itr.Get()
+		if err != nil {
+			return false, err
+		}
+		if b.marker != nil {
+			if c := bytes.Compare(key, b.marker); c > 0 || (c == 0 && b.storageMarker == nil) {
+				break
+			}
+		}
+		if idx >= len(b.accounts) ||
+			!bytes.Equal(key, b.accounts[idx].key) ||
+			!bytes.Equal(obj.Bytes(), b.accounts[idx].value) {
+			return false, nil
+		}
+		if err := itr.Next(); err != nil {
+			return false, err
+		}
+	}
+	return idx == len(b.accounts), nil
+}
+
+func (m *flatStateManager) writeBatchInLock(b *flatBatch) error {
+	for _, e := range b.entries {
+		if err := m.bucket.Set(e.key, e.value); err != nil {
+			return err
+		}
+	}
+	m.meta.Marker = b.marker
+	m.meta.StorageMarker = b.storageMarker
+	m.meta.Complete = b.marker == nil
+	if err := m.writeMetaInLock(); err != nil {
+		return err
+	}
+	m.disk.setMarker(b.marker)
+	return nil
+}
+
+// load returns the layer for the root. If the disk layer is not loaded yet,
+// then it loads the one in the database.
+func (m *flatStateManager) load(root []byte) (flatLayer, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if l := m.layerOfInLock(root); l != nil || m.disk != nil {
+		return l, nil
+	}
+	if _, err := m.loadInLock(root); err != nil {
+		return nil, err
+	}
+	return m.layerOfInLock(root), nil
+}
+
+func flatStateManagerOf(database db.Database) *flatStateManager {
+	value := db.GetFlag(database, flatStateManagerFlag)
+	if m, ok := value.(*flatStateManager); ok {
+		return m
+	}
+	return nil
+}
+
+func flatLayerOf(database db.Database, root []byte) flatLayer {
+	if m := flatStateManagerOf(database); m != nil {
+		return m.layerOf(root)
+	}
+	return nil
+}
+
+// flatStorage reads storage values of the account from the flat state.
+type flatStorage struct {
+	layer   flatLayer
+	account []byte
+}
+
+func (f *flatStorage) get(key []byte) ([]byte, bool) {
+	if f == nil {
+		return nil, false
+	}
+	return f.layer.Storage(f.account, key)
+}
+
+// flatAccountOf returns the account from the layer. It returns false if the
+// layer doesn't know it.
+func flatAccountOf(database db.Database, layer flatLayer, key []byte) (*accountSnapshotImpl, bool) {
+	bs, ok := layer.Account(key)
+	if !ok {
+		return nil, false
+	}
+	if bs == nil {
+		return nil, true
+	}
+	as := new(accountSnapshotImpl)
+	if err := as.Reset(database, bs); err != nil {
+		log.Errorf("Fail to decode account in flat state key=%#x err=%+v", key, err)
+		return nil, false
+	}
+	if as.store != nil {
+		as.flat = &flatStorage{layer: layer, account: key}
+	}
+	return as, true
+}
+
+// AttachFlatStateManager attaches the manager of flat state to the database,
+// and returns it. World states made with the returned database read values
+// from the flat state if it's available.
+func AttachFlatStateManager(database db.Database) (db.Database, error) {
+	bk, err := database.GetBucket(db.FlatState)
+	if err != nil {
+		return nil, err
+	}
+	m := &flatStateManager{
+		database: database,
+		bucket:   bk,
+		layers:   make(map[string]*flatDiffLayer),
+	}
+	return db.WithFlags(database, db.Flags{
+		flatStateManagerFlag: m,
+	}), nil
+}
+
+// UpdateFlatState returns the world snapshot reading values from the flat
+// state. If there is no flat state for the snapshot, then it makes new one
+// with the changes from the parent. It returns the snapshot itself if flat
+// state is not available.
+func UpdateFlatState(parent, wss WorldSnapshot) WorldSnapshot {
+	ws, ok := wss.(*worldSnapshotImpl)
+	if !ok {
+		return wss
+	}
+	m := flatStateManagerOf(ws.database)
+	if m == nil {
+		return wss
+	}
+	var layer flatLayer
+	if pws, ok := parent.(*worldSnapshotImpl); ok {
+		var err error
+		if layer, err = m.update(pws, ws); err != nil {
+			log.Warnf("Fail to update flat state err=%+v", err)
+			return wss
+		}
+	} else {
+		layer = m.layerOf(ws.StateHash())
+	}
+	if layer == nil {
+		return wss
+	}
+	return &worldSnapshotImpl{
+		database:   ws.database,
+		accounts:   ws.accounts,
+		validators: ws.validators,
+		extension:  ws.extension,
+		flat:       layer,
+	}
+}
+
+// FinalizeFlatState writes the flat state for the world snapshot to the
+// database. It drops the flat state for the others except its descendants.
+// If the flat state for the snapshot is not available, then it starts to
+// generate new one in background.
+func FinalizeFlatState(wss WorldSnapshot) {
+	ws, ok := wss.(*worldSnapshotImpl)
+	if !ok {
+		return
+	}
+	if m := flatStateManagerOf(ws.database); m != nil {
+		if err := m.finalize(ws); err != nil {
+			log.Warnf("Fail to finalize flat state err=%+v", err)
+		}
+	}
+}
+
+// VerifyFlatState checks whether the flat state for the world snapshot has
+// same accounts and storage values as the merkle trie. If the flat state
+// is not loaded yet, then it loads the one in the database. onAccount is
+// called for each account verified if it's not nil, and verification stops
+// on its error.
+func VerifyFlatState(wss WorldSnapshot, onAccount func(key []byte) error) error {
+	ws, ok := wss.(*worldSnapshotImpl)
+	if !ok {
+		return errors.InvalidStateError.Errorf("UnknownWorldSnapshot(type=%T)", wss)
+	}
+	m := flatStateManagerOf(ws.database)
+	if m == nil {
+		return errors.InvalidStateError.New("FlatStateDisabled")
+	}
+	layer, err := m.load(ws.StateHash())
+	if err != nil {
+		return err
+	}
+	if layer == nil {
+		return errors.InvalidStateError.Errorf("NoFlatState(root=%#x)", ws.StateHash())
+	}
+	if disk, ok := layer.(*flatDiskLayer); ok {
+		disk.lock.RLock()
+		complete, marker := disk.complete, disk.marker
+		disk.lock.RUnlock()
+		if !complete {
+			return errors.InvalidStateError.Errorf("FlatStateNotGenerated(marker=%#x)", marker)
+		}
+	}
+	for itr := ws.accounts.Iterator(); itr.Has(); {
+		obj, key, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		if onAccount != nil {
+			if err := onAccount(key); err != nil {
+				return err
+			}
+		}
+		value, ok := layer.Account(key)
+		if !ok {
+			return errors.InvalidStateError.Errorf("UnknownAccount(key=%#x)", key)
+		}
+		if !bytes.Equal(value, obj.Bytes()) {
+			return errors.InvalidStateError.Errorf("DifferentAccount(key=%#x,exp=%#x,real=%#x)",
+				key, obj.Bytes(), value)
+		}
+		if as := obj.(*accountSnapshotImpl); as.store != nil {
+			for sitr := as.store.Iterator(); sitr.Has(); {
+				v, k, err := sitr.Get()
+				if err != nil {
+					return err
+				}
+				value, ok := layer.Storage(key, k)
+				if !ok {
+					return errors.InvalidStateError.Errorf("UnknownValue(account=%#x,key=%#x)", key, k)
+				}
+				if !bytes.Equal(value, v) {
+					return errors.InvalidStateError.Errorf("DifferentValue(account=%#x,key=%#x,exp=%#x,real=%#x)",
+						key, k, v, value)
+				}
+				if err := sitr.Next(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := itr.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/icon-project/goloop/common/db"
+)
+
+func newFlatTestDB(t *testing.T) (db.Database, *flatStateManager) {
+	database, err := AttachFlatStateManager(db.NewMapDB())
+	if err != nil {
+		t.Fatalf("Fail to attach flat state err=%+v", err)
+	}
+	return database, flatStateManagerOf(database)
+}
+
+func waitFlatGeneration(t *testing.T, m *flatStateManager) {
+	for i := 0; i < 1000; i++ {
+		m.lock.Lock()
+		generating := m.generating
+		m.lock.Unlock()
+		if !generating {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Flat state generation is not finished")
+}
+
+func flatTestID(i int) []byte {
+	return []byte(fmt.Sprintf("account%d", i))
+}
+
+func setFlatTestAccounts(ws WorldState, from, to int, suffix string) {
+	for i := from; i < to; i++ {
+		as := ws.GetAccountState(flatTestID(i))
+		as.SetBalance(big.NewInt(int64(i + 1)))
+		if i%3 == 0 {
+			as.SetValue([]byte("key"), []byte("value"+suffix))
+			as.SetValue([]byte(fmt.Sprint("key", i)), []byte("value"+suffix))
+		}
+	}
+}
+
+func TestFlatState_Generate(t *testing.T) {
+	database, m := newFlatTestDB(t)
+	ws := NewWorldState(database, nil, nil, nil)
+	setFlatTestAccounts(ws, 0, 3*flatGenerateBatch, "")
+	wss := ws.GetSnapshot()
+	if err := wss.Flush(); err != nil {
+		t.Fatalf("Fail to flush err=%+v", err)
+	}
+	if err := VerifyFlatState(wss, nil); err == nil {
+		t.Error("Verification success without flat state")
+	}
+
+	FinalizeFlatState(wss)
+	waitFlatGeneration(t, m)
+	if !m.meta.Complete {
+		t.Fatal("Flat state is not complete")
+	}
+
+	wss2 := NewWorldSnapshot(database, wss.StateHash(), nil, nil)
+	if err := VerifyFlatState(wss2, nil); err != nil {
+		t.Errorf("Fail to verify err=%+v", err)
+	}
+
+	// values are read from the flat state
+	ws2, _ := WorldStateFromSnapshot(wss2)
+	key := addressIDToKey(flatTestID(3))
+	if err := m.bucket.Set(flatStorageKey(m.meta.Epoch, key, []byte("key")), []byte("fake")); err != nil {
+		t.Fatalf("Fail to set err=%+v", err)
+	}
+	if v, _ := ws2.GetAccountState(flatTestID(3)).GetValue([]byte("key")); !bytes.Equal(v, []byte("fake")) {
+		t.Errorf("Value isn't read from flat state value=%q", v)
+	}
+	if err := VerifyFlatState(wss2, nil); err == nil {
+		t.Error("Verification success with wrong value")
+	}
+}
+
+func TestFlatState_Layers(t *testing.T) {
+	database, m := newFlatTestDB(t)
+	ws := NewWorldState(database, nil, nil, nil)
+	setFlatTestAccounts(ws, 0, 30, "")
+	wss0 := ws.GetSnapshot()
+	wss0.Flush()
+	FinalizeFlatState(wss0)
+	waitFlatGeneration(t, m)
+
+	// two children of the finalized state
+	ws1, _ := WorldStateFromSnapshot(NewWorldSnapshot(database, wss0.StateHash(), nil, nil))
+	setFlatTestAccounts(ws1, 10, 40, "1")
+	ws1.GetAccountState(flatTestID(0)).DeleteValue([]byte("key"))
+	wss1 := UpdateFlatState(wss0, ws1.GetSnapshot())
+
+	ws2, _ := WorldStateFromSnapshot(wss0)
+	setFlatTestAccounts(ws2, 20, 25, "2")
+	wss2 := UpdateFlatState(wss0, ws2.GetSnapshot())
+
+	// grandchild of the first one
+	ws3, _ := WorldStateFromSnapshot(wss1)
+	as := ws3.GetAccountState(flatTestID(3))
+	if v, _ := as.GetValue([]byte("key")); !bytes.Equal(v, []byte("value")) {
+		t.Errorf("Unexpected value=%q", v)
+	}
+	as.SetValue([]byte("key"), []byte("value3"))
+	wss3 := UpdateFlatState(wss1, ws3.GetSnapshot())
+
+	for i, wss := range []WorldSnapshot{wss1, wss2, wss3} {
+		if wss.(*worldSnapshotImpl).flat == nil {
+			t.Errorf("No flat state for snapshot[%d]", i)
+		}
+		if err := VerifyFlatState(wss, nil); err != nil {
+			t.Errorf("Fail to verify snapshot[%d] err=%+v", i, err)
+		}
+	}
+
+	wss1.Flush()
+	FinalizeFlatState(wss1)
+	if !bytes.Equal(m.disk.root, wss1.StateHash()) {
+		t.Fatal("Disk layer isn't changed")
+	}
+	for _, wss := range []WorldSnapshot{wss1, wss3} {
+		if err := VerifyFlatState(wss, nil); err != nil {
+			t.Errorf("Fail to verify after finalization err=%+v", err)
+		}
+	}
+	if flatLayerOf(database, wss2.StateHash()) != nil {
+		t.Error("Flat state for the sibling remains")
+	}
+	// it falls back to the trie
+	v, _ := wss2.GetAccountSnapshot(flatTestID(21)).GetValue([]byte("key21"))
+	if !bytes.Equal(v, []byte("value2")) {
+		t.Errorf("Unexpected value for the dropped layer value=%q", v)
+	}
+
+	wss3.Flush()
+	FinalizeFlatState(wss3)
+	if err := VerifyFlatState(NewWorldSnapshot(database, wss3.StateHash(), nil, nil), nil); err != nil {
+		t.Errorf("Fail to verify after finalization err=%+v", err)
+	}
+
+	// the manager attached again loads the disk layer
+	database2, err := AttachFlatStateManager(database)
+	if err != nil {
+		t.Fatalf("Fail to attach err=%+v", err)
+	}
+	FinalizeFlatState(NewWorldSnapshot(database2, wss3.StateHash(), nil, nil))
+	m2 := flatStateManagerOf(database2)
+	if m2.generating || m2.meta.Epoch != m.meta.Epoch {
+		t.Errorf("Flat state is generated again epoch=%d", m2.meta.Epoch)
+	}
+	if err := VerifyFlatState(NewWorldSnapshot(database2, wss3.StateHash(), nil, nil), nil); err != nil {
+		t.Errorf("Fail to verify loaded one err=%+v", err)
+	}
+}
+
+func TestFlatState_ChangedAccounts(t *testing.T) {
+	database, m := newFlatTestDB(t)
+	ws := NewWorldState(database, nil, nil, nil)
+	setFlatTestAccounts(ws, 0, 10, "")
+	wss := ws.GetSnapshot()
+	wss.Flush()
+	FinalizeFlatState(wss)
+	waitFlatGeneration(t, m)
+
+	ws1 := NewWorldState(database, wss.StateHash(), nil, nil)
+	ss := ws1.GetSnapshot()
+	ws1.GetAccountState(flatTestID(1)).SetBalance(big.NewInt(100))
+	ws1.GetAccountState(flatTestID(3)).SetValue([]byte("key"), []byte("changed"))
+	ss2 := ws1.GetSnapshot()
+	ws1.ClearCache()
+
+	check := func(name string, balance int64, value string) {
+		if b := ws1.GetAccountState(flatTestID(1)).GetBalance(); b.Int64() != balance {
+			t.Errorf("%s: unexpected balance=%d", name, b)
+		}
+		if b := ws1.GetAccountSnapshot(flatTestID(1)).GetBalance(); b.Int64() != balance {
+			t.Errorf("%s: unexpected balance of snapshot=%d", name, b)
+		}
+		if v, _ := ws1.GetAccountState(flatTestID(3)).GetValue([]byte("key")); string(v) != value {
+			t.Errorf("%s: unexpected value=%q", name, v)
+		}
+	}
+	check("changed", 100, "changed")
+	if v, _ := ss2.GetAccountSnapshot(flatTestID(3)).GetValue([]byte("key")); string(v) != "changed" {
+		t.Errorf("Unexpected value of snapshot=%q", v)
+	}
+	if err := ws1.Reset(ss); err != nil {
+		t.Fatalf("Fail to reset err=%+v", err)
+	}
+	check("reset", 2, "value")
+}
+
+func TestFlatState_FinalizeWhileGenerating(t *testing.T) {
+	database, m := newFlatTestDB(t)
+	ws := NewWorldState(database, nil, nil, nil)
+	count := 4 * flatGenerateBatch
+	setFlatTestAccounts(ws, 0, count, "")
+	wss := ws.GetSnapshot()
+	wss.Flush()
+	FinalizeFlatState(wss)
+
+	for i := 1; i <= 3; i++ {
+		ws, _ := WorldStateFromSnapshot(wss)
+		suffix := fmt.Sprint(i)
+		setFlatTestAccounts(ws, 0, 3, suffix)
+		setFlatTestAccounts(ws, count/2, count/2+3, suffix)
+		setFlatTestAccounts(ws, count-3, count+3, suffix)
+		wss2 := UpdateFlatState(wss, ws.GetSnapshot())
+		wss2.Flush()
+		FinalizeFlatState(wss2)
+		wss = wss2
+	}
+	waitFlatGeneration(t, m)
+	if !bytes.Equal(m.meta.Root, wss.StateHash()) || !m.meta.Complete {
+		t.Fatalf("Unexpected flat state meta=%+v", m.meta)
+	}
+	if err := VerifyFlatState(wss, nil); err != nil {
+		t.Errorf("Fail to verify err=%+v", err)
+	}
+}
+
+func TestFlatState_GenerateInChunks(t *testing.T) {
+	database, m := newFlatTestDB(t)
+	ws := NewWorldState(database, nil, nil, nil)
+	setFlatTestAccounts(ws, 0, 10, "")
+	bas := ws.GetAccountState([]byte("big"))
+	count := 5 * flatGenerateBatch / 2
+	for i := 0; i < count; i++ {
+		bas.SetValue([]byte(fmt.Sprintf("key%05d", i)), []byte("value"))
+	}
+	wss := ws.GetSnapshot()
+	wss.Flush()
+
+	// generate batches manually between finalizations
+	m.lock.Lock()
+	m.generating = true
+	m.lock.Unlock()
+	FinalizeFlatState(wss)
+
+	partial := false
+	for i := 0; !m.meta.Complete; i++ {
+		meta := m.meta
+		b, err := m.generateBatch(meta.Epoch, meta.Root, meta.Marker, meta.StorageMarker)
+		if err != nil {
+			t.Fatalf("Fail to generate err=%+v", err)
+		}
+		if len(b.entries) > flatGenerateBatch {
+			t.Errorf("Too many entries in a batch count=%d", len(b.entries))
+		}
+		m.lock.Lock()
+		err = m.writeBatchInLock(b)
+		m.lock.Unlock()
+		if err != nil {
+			t.Fatalf("Fail to write err=%+v", err)
+		}
+		if m.meta.StorageMarker == nil {
+			continue
+		}
+		partial = true
+
+		// change values of the account being generated
+		ws, _ := WorldStateFromSnapshot(wss)
+		as := ws.GetAccountState([]byte("big"))
+		as.DeleteValue([]byte(fmt.Sprintf("key%05d", i)))
+		as.SetValue([]byte(fmt.Sprintf("key%05d", i+1)), []byte(fmt.Sprint("changed", i)))
+		as.SetValue([]byte(fmt.Sprintf("key%05d", count+i)), []byte("added"))
+		setFlatTestAccounts(ws, 0, 3, fmt.Sprint(i))
+		wss2 := UpdateFlatState(wss, ws.GetSnapshot())
+		wss2.Flush()
+		FinalizeFlatState(wss2)
+		wss = wss2
+	}
+	if !partial {
+		t.Error("No batch stops in the storage")
+	}
+	if err := VerifyFlatState(wss, nil); err != nil {
+		t.Errorf("Fail to verify err=%+v", err)
+	}
+}
+
+func TestFlatState_ValidBatch(t *testing.T) {
+	database, m := newFlatTestDB(t)
+	ws := NewWorldState(database, nil, nil, nil)
+	setFlatTestAccounts(ws, 0, 2*flatGenerateBatch, "")
+	wss := ws.GetSnapshot()
+	wss.Flush()
+
+	b, err := m.generateBatch(1, wss.StateHash(), nil, nil)
+	if err != nil {
+		t.Fatalf("Fail to generate err=%+v", err)
+	}
+	if b.marker == nil {
+		t.Fatal("All accounts are in a batch")
+	}
+	last := b.accounts[len(b.accounts)-1].key
+	if b.storageMarker == nil {
+		last = b.marker
+	}
+
+	check := func(name string, exp bool, update func(ws WorldState)) {
+		ws, _ := WorldStateFromSnapshot(wss)
+		update(ws)
+		wss2 := ws.GetSnapshot()
+		wss2.Flush()
+		ok, err := m.isValidBatch(wss2.StateHash(), nil, b)
+		if err != nil {
+			t.Fatalf("%s: fail to check err=%+v", name, err)
+		}
+		if ok != exp {
+			t.Errorf("%s: unexpected result=%v", name, ok)
+		}
+	}
+	check("NoChange", true, func(ws WorldState) {})
+	// accounts are stored with the hash of the ID, so look up the IDs by key
+	var before, after []byte
+	for i := 0; i < 2*flatGenerateBatch && (before == nil || after == nil); i++ {
+		c := bytes.Compare(addressIDToKey(flatTestID(i)), last)
+		if c < 0 && before == nil {
+			before = flatTestID(i)
+		} else if c > 0 && after == nil {
+			after = flatTestID(i)
+		}
+	}
+	check("ChangeAfter", true, func(ws WorldState) {
+		ws.GetAccountState(after).SetBalance(big.NewInt(1000))
+	})
+	check("ChangeIn", false, func(ws WorldState) {
+		ws.GetAccountState(before).SetBalance(big.NewInt(1000))
+	})
+	check("AddIn", false, func(ws WorldState) {
+		for i := 0; ; i++ {
+			id := []byte(fmt.Sprint("new", i))
+			if bytes.Compare(addressIDToKey(id), last) < 0 {
+				ws.GetAccountState(id).SetBalance(big.NewInt(1))
+				return
+			}
+		}
+	})
+}
+
+func waitFlatPruning(t *testing.T, m *flatStateManager) {
+	for i := 0; i < 1000; i++ {
+		m.lock.Lock()
+		pruning := m.pruning
+		m.lock.Unlock()
+		if !pruning {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Flat state pruning is not finished")
+}
+
+func TestFlatState_PruneOldEpochs(t *testing.T) {
+	database, m := newFlatTestDB(t)
+	count := 2 * flatGenerateBatch
+	var wss WorldSnapshot
+	for i := 0; i < 3; i++ {
+		// finalize unknown roots, so it's generated again
+		ws := NewWorldState(database, nil, nil, nil)
+		setFlatTestAccounts(ws, 0, count, fmt.Sprint(i))
+		wss = ws.GetSnapshot()
+		wss.Flush()
+		FinalizeFlatState(wss)
+		waitFlatGeneration(t, m)
+		waitFlatPruning(t, m)
+	}
+	if m.meta.Epoch != 3 || m.meta.Pruned != 3 || !m.meta.Complete {
+		t.Fatalf("Unexpected flat state meta=%+v", m.meta)
+	}
+
+	// only the entries of the last epoch remain
+	for epoch := uint32(1); epoch <= 3; epoch++ {
+		for i := 0; i < count; i++ {
+			key := addressIDToKey(flatTestID(i))
+			keys := [][]byte{flatAccountKey(epoch, key)}
+			if i%3 == 0 {
+				keys = append(keys, flatStorageKey(epoch, key, []byte("key")))
+			}
+			for _, k := range keys {
+				has, err := m.bucket.Has(k)
+				if err != nil {
+					t.Fatalf("Fail to read err=%+v", err)
+				}
+				if has != (epoch == 3) {
+					t.Fatalf("Unexpected entry epoch=%d key=%#x has=%v", epoch, k, has)
+				}
+			}
+		}
+	}
+	if err := VerifyFlatState(wss, nil); err != nil {
+		t.Errorf("Fail to verify err=%+v", err)
+	}
+}
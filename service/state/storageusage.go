@@ -0,0 +1,88 @@
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+)
+
+// StorageUsage is the number of keys and the total bytes of keys and values
+// stored in the storage of an account.
+type StorageUsage struct {
+	Keys  int64
+	Bytes int64
+}
+
+func (u *StorageUsage) IsEmpty() bool {
+	return u.Keys == 0 && u.Bytes == 0
+}
+
+func (u *StorageUsage) add(k, v []byte) {
+	u.Keys += 1
+	u.Bytes += int64(len(k) + len(v))
+}
+
+func (u *StorageUsage) remove(k, v []byte) {
+	u.Keys -= 1
+	u.Bytes -= int64(len(k) + len(v))
+}
+
+func (u *StorageUsage) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"keys":  intconv.FormatInt(u.Keys),
+		"bytes": intconv.FormatInt(u.Bytes),
+	}
+}
+
+// AccountStorageUsage is the storage usage of an account in the world.
+type AccountStorageUsage struct {
+	// Address is the address of the account if it's known from the key.
+	Address module.Address
+
+	// DeployTxHash is the hash of the transaction deploying the contract.
+	DeployTxHash []byte
+
+	StorageUsage
+}
+
+// LargestStorageUsages returns storage usages of the accounts using the
+// largest bytes in descending order.
+func LargestStorageUsages(wss WorldSnapshot, limit int) ([]*AccountStorageUsage, error) {
+	ws, ok := wss.(*worldSnapshotImpl)
+	if !ok {
+		return nil, errors.InvalidStateError.Errorf("UnknownWorldSnapshot(type=%T)", wss)
+	}
+	systemKey := addressIDToKey(SystemID)
+	var usages []*AccountStorageUsage
+	for itr := ws.accounts.Iterator(); itr.Has(); {
+		obj, key, err := itr.Get()
+		if err != nil {
+			return nil, err
+		}
+		ass := obj.(*accountSnapshotImpl)
+		if usage := ass.StorageUsage(); usage != nil && !usage.IsEmpty() {
+			au := &AccountStorageUsage{StorageUsage: *usage}
+			if bytes.Equal(key, systemKey) {
+				au.Address = SystemAddress
+			} else if c := ass.Contract(); c != nil {
+				au.DeployTxHash = c.DeployTxHash()
+			} else if c := ass.NextContract(); c != nil {
+				au.DeployTxHash = c.DeployTxHash()
+			}
+			usages = append(usages, au)
+		}
+		if err := itr.Next(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].Bytes > usages[j].Bytes
+	})
+	if limit >= 0 && len(usages) > limit {
+		usages = usages[:limit]
+	}
+	return usages, nil
+}
@@ -0,0 +1,634 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+)
+
+// IDs for the parts of the world other than accounts. They never collide
+// with account IDs.
+const (
+	trackWorldID      = "#world"
+	trackValidatorsID = "#validators"
+	trackExtensionID  = "#extension"
+)
+
+// keys of an account for read/write sets.
+const (
+	trackKeyBalance = "b"
+	trackKeyMeta    = "m"
+	trackKeyUsage   = "u"
+	trackKeyStorage = "s"
+)
+
+type keySet struct {
+	all  bool
+	keys map[string]bool
+}
+
+func (s *keySet) add(key string) {
+	if s.all {
+		return
+	}
+	if s.keys == nil {
+		s.keys = make(map[string]bool)
+	}
+	s.keys[key] = true
+}
+
+func (s *keySet) overlaps(s2 *keySet) bool {
+	if s.all || s2.all {
+		return true
+	}
+	if len(s.keys) > len(s2.keys) {
+		s, s2 = s2, s
+	}
+	for k := range s.keys {
+		if s2.keys[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsWrites returns whether both write sets have a key written by
+// both of them. Storage usage is not counted, because it's updated by the
+// storage values on applying the changes.
+func (s *keySet) overlapsWrites(s2 *keySet) bool {
+	if s.all || s2.all {
+		return true
+	}
+	if len(s.keys) > len(s2.keys) {
+		s, s2 = s2, s
+	}
+	for k := range s.keys {
+		if k != trackKeyUsage && s2.keys[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadWriteSet is the set of keys of the world read and written by a
+// transaction. Keys are grouped by accounts, and each account has keys
+// for its balance, its storage usage, each key of its storage and the
+// others.
+type ReadWriteSet struct {
+	reads  map[string]*keySet
+	writes map[string]*keySet
+}
+
+func newReadWriteSet() *ReadWriteSet {
+	return &ReadWriteSet{
+		reads:  make(map[string]*keySet),
+		writes: make(map[string]*keySet),
+	}
+}
+
+func keySetOf(m map[string]*keySet, id string) *keySet {
+	ks, ok := m[id]
+	if !ok {
+		ks = new(keySet)
+		m[id] = ks
+	}
+	return ks
+}
+
+// Conflicts returns whether the transaction would have different result if
+// it's executed after the prior transaction. It's true if it read something
+// written by the prior, or if both of them wrote the same key.
+func (s *ReadWriteSet) Conflicts(prior *ReadWriteSet) bool {
+	if len(prior.writes) == 0 {
+		return false
+	}
+	if _, ok := s.reads[trackWorldID]; ok {
+		return true
+	}
+	for id, ws := range prior.writes {
+		if wks, ok := s.writes[id]; ok && wks.overlapsWrites(ws) {
+			return true
+		}
+		if rs, ok := s.reads[id]; ok && rs.overlaps(ws) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCount returns the number of accounts (including validators and
+// extension) written.
+func (s *ReadWriteSet) WriteCount() int {
+	return len(s.writes)
+}
+
+// TrackedWorldState is a WorldState recording the keys read and written
+// through it. It's used for executing a transaction speculatively on a
+// snapshot and applying its changes after validation.
+type TrackedWorldState interface {
+	WorldState
+
+	// ReadWriteSet returns the keys read so far and the keys written
+	// compared to the initial state.
+	ReadWriteSet() *ReadWriteSet
+
+	// ApplyTo applies the changes of the accounts, validators and extension
+	// to the world state. For accounts, only the keys written are applied.
+	// It's valid only if the keys written are same as the initial state in
+	// the world state.
+	ApplyTo(ws WorldState) error
+}
+
+type trackedWorldState struct {
+	WorldState
+
+	mutex      sync.Mutex
+	reads      map[string]*keySet
+	storeKeys  map[string]*keySet
+	accounts   map[string]*trackedAccountState
+	validators ValidatorSnapshot
+	extension  ExtensionSnapshot
+}
+
+func (ws *trackedWorldState) read(id, key string) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	keySetOf(ws.reads, id).add(key)
+}
+
+func (ws *trackedWorldState) readAll(id string) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	keySetOf(ws.reads, id).all = true
+}
+
+func (ws *trackedWorldState) writeStorage(id, key string) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	keySetOf(ws.reads, id).add(key)
+	keySetOf(ws.storeKeys, id).add(key)
+}
+
+func (ws *trackedWorldState) writeStorageAll(id string) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	keySetOf(ws.storeKeys, id).all = true
+}
+
+func (ws *trackedWorldState) GetAccountState(id []byte) AccountState {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ids := string(id)
+	if as, ok := ws.accounts[ids]; ok {
+		return as
+	}
+	real := ws.WorldState.GetAccountState(id)
+	if real == nil {
+		return nil
+	}
+	as := &trackedAccountState{
+		AccountState: real,
+		id:           ids,
+		world:        ws,
+		base:         real.GetSnapshot(),
+	}
+	ws.accounts[ids] = as
+	return as
+}
+
+func (ws *trackedWorldState) GetAccountSnapshot(id []byte) AccountSnapshot {
+	ws.readAll(string(id))
+	return ws.WorldState.GetAccountSnapshot(id)
+}
+
+func (ws *trackedWorldState) GetValidatorState() ValidatorState {
+	ws.readAll(trackValidatorsID)
+	return ws.WorldState.GetValidatorState()
+}
+
+func (ws *trackedWorldState) GetExtensionState() ExtensionState {
+	ws.readAll(trackExtensionID)
+	return ws.WorldState.GetExtensionState()
+}
+
+func (ws *trackedWorldState) GetSnapshot() WorldSnapshot {
+	return &trackedWorldSnapshot{
+		WorldSnapshot: ws.WorldState.GetSnapshot(),
+		world:         ws,
+	}
+}
+
+func (ws *trackedWorldState) Reset(snapshot WorldSnapshot) error {
+	if tss, ok := snapshot.(*trackedWorldSnapshot); ok {
+		if tss.world != ws {
+			return errors.InvalidStateError.New("InvalidSnapshot")
+		}
+		snapshot = tss.WorldSnapshot
+	}
+	return ws.WorldState.Reset(snapshot)
+}
+
+func idOf(ids string) []byte {
+	if len(ids) == 0 {
+		return nil
+	}
+	return []byte(ids)
+}
+
+func bytesOfExtension(es ExtensionSnapshot) []byte {
+	if es == nil {
+		return nil
+	}
+	return es.Bytes()
+}
+
+func hashOfValidators(vs ValidatorSnapshot) []byte {
+	if vs == nil {
+		return nil
+	}
+	return vs.Hash()
+}
+
+func (ws *trackedWorldState) ReadWriteSet() *ReadWriteSet {
+	snapshot := ws.WorldState.GetSnapshot()
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	rws := newReadWriteSet()
+	for id, ks := range ws.reads {
+		rks := keySetOf(rws.reads, id)
+		rks.all = ks.all
+		for k := range ks.keys {
+			rks.add(k)
+		}
+	}
+	for id, as := range ws.accounts {
+		if wks := as.writes(ws.storeKeys[id]); wks != nil {
+			rws.writes[id] = wks
+		}
+	}
+	if !bytes.Equal(hashOfValidators(ws.validators),
+		hashOfValidators(snapshot.GetValidatorSnapshot())) {
+		rws.writes[trackValidatorsID] = &keySet{all: true}
+	}
+	if !bytes.Equal(bytesOfExtension(ws.extension),
+		bytesOfExtension(snapshot.GetExtensionSnapshot())) {
+		rws.writes[trackExtensionID] = &keySet{all: true}
+	}
+	return rws
+}
+
+func (ws *trackedWorldState) ApplyTo(target WorldState) error {
+	snapshot := ws.WorldState.GetSnapshot()
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	for id, as := range ws.accounts {
+		wks := as.writes(ws.storeKeys[id])
+		if wks == nil {
+			continue
+		}
+		if err := as.applyTo(target.GetAccountState(idOf(id)), wks); err != nil {
+			return err
+		}
+	}
+	vss := snapshot.GetValidatorSnapshot()
+	if !bytes.Equal(hashOfValidators(ws.validators), hashOfValidators(vss)) {
+		target.GetValidatorState().Reset(vss)
+	}
+	ess := snapshot.GetExtensionSnapshot()
+	if !bytes.Equal(bytesOfExtension(ws.extension), bytesOfExtension(ess)) {
+		target.GetExtensionState().Reset(ess)
+	}
+	return nil
+}
+
+// NewTrackedWorldState returns a new TrackedWorldState on the world state.
+// The world state shouldn't be changed except through the returned one.
+func NewTrackedWorldState(ws WorldState) TrackedWorldState {
+	snapshot := ws.GetSnapshot()
+	return &trackedWorldState{
+		WorldState: ws,
+		reads:      make(map[string]*keySet),
+		storeKeys:  make(map[string]*keySet),
+		accounts:   make(map[string]*trackedAccountState),
+		validators: snapshot.GetValidatorSnapshot(),
+		extension:  snapshot.GetExtensionSnapshot(),
+	}
+}
+
+type trackedWorldSnapshot struct {
+	WorldSnapshot
+	world *trackedWorldState
+}
+
+func (wss *trackedWorldSnapshot) GetAccountSnapshot(id []byte) AccountSnapshot {
+	wss.world.readAll(string(id))
+	return wss.WorldSnapshot.GetAccountSnapshot(id)
+}
+
+func (wss *trackedWorldSnapshot) GetValidatorSnapshot() ValidatorSnapshot {
+	wss.world.readAll(trackValidatorsID)
+	return wss.WorldSnapshot.GetValidatorSnapshot()
+}
+
+func (wss *trackedWorldSnapshot) GetExtensionSnapshot() ExtensionSnapshot {
+	wss.world.readAll(trackExtensionID)
+	return wss.WorldSnapshot.GetExtensionSnapshot()
+}
+
+func (wss *trackedWorldSnapshot) ExtensionData() []byte {
+	wss.world.readAll(trackExtensionID)
+	return wss.WorldSnapshot.ExtensionData()
+}
+
+func (wss *trackedWorldSnapshot) StateHash() []byte {
+	wss.world.readAll(trackWorldID)
+	return wss.WorldSnapshot.StateHash()
+}
+
+// trackedAccountState records reads of the account to the world. Writes
+// are found by comparing the snapshot with the initial one, so storage
+// keys are the only keys recorded for writes.
+type trackedAccountState struct {
+	AccountState
+	id    string
+	world *trackedWorldState
+	base  AccountSnapshot
+}
+
+// writes returns the keys changed from the initial state. It returns nil
+// if there is no change. Changes other than the balance and the storage
+// are regarded as changes of all keys.
+func (a *trackedAccountState) writes(storeKeys *keySet) *keySet {
+	s1, ok1 := a.base.(*accountSnapshotImpl)
+	s2, ok2 := a.AccountState.GetSnapshot().(*accountSnapshotImpl)
+	if !ok1 || !ok2 {
+		return &keySet{all: true}
+	}
+	if s1.Equal(s2) {
+		return nil
+	}
+	ks := new(keySet)
+	if s1.balance.Cmp(&s2.balance.Int) != 0 {
+		ks.add(trackKeyBalance)
+	}
+	if s1.usage != s2.usage {
+		ks.add(trackKeyUsage)
+	}
+	meta := *s1
+	meta.balance, meta.store, meta.usage = s2.balance, s2.store, s2.usage
+	if !meta.Equal(s2) {
+		return &keySet{all: true}
+	}
+	if s2.StorageChangedAfter(s1) {
+		if storeKeys == nil {
+			return &keySet{all: true}
+		}
+		if storeKeys.all {
+			ks.all = true
+		}
+		for k := range storeKeys.keys {
+			ks.add(k)
+		}
+	}
+	if !ks.all && len(ks.keys) == 0 {
+		ks.all = true
+	}
+	return ks
+}
+
+// applyTo applies the changes of the keys to the account. Storage usage of
+// the account is updated by the storage values.
+func (a *trackedAccountState) applyTo(target AccountState, ks *keySet) error {
+	if ks.all {
+		return target.Reset(a.AccountState.GetSnapshot())
+	}
+	for k := range ks.keys {
+		switch {
+		case k == trackKeyBalance:
+			target.SetBalance(a.AccountState.GetBalance())
+		case strings.HasPrefix(k, trackKeyStorage):
+			key := []byte(k[len(trackKeyStorage):])
+			value, err := a.AccountState.GetValue(key)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				_, err = target.DeleteValue(key)
+			} else {
+				_, err = target.SetValue(key, value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *trackedAccountState) readMeta() {
+	a.world.read(a.id, trackKeyMeta)
+}
+
+func storageKey(k []byte) string {
+	return trackKeyStorage + string(k)
+}
+
+func (a *trackedAccountState) Version() int {
+	a.readMeta()
+	return a.AccountState.Version()
+}
+
+func (a *trackedAccountState) MigrateForRevision(rev module.Revision) error {
+	a.readMeta()
+	version := a.AccountState.Version()
+	err := a.AccountState.MigrateForRevision(rev)
+	if a.AccountState.Version() != version {
+		// migration may read whole storage
+		a.world.readAll(a.id)
+	}
+	return err
+}
+
+func (a *trackedAccountState) GetBalance() *big.Int {
+	a.world.read(a.id, trackKeyBalance)
+	return a.AccountState.GetBalance()
+}
+
+func (a *trackedAccountState) IsContract() bool {
+	a.readMeta()
+	return a.AccountState.IsContract()
+}
+
+func (a *trackedAccountState) GetValue(k []byte) ([]byte, error) {
+	a.world.read(a.id, storageKey(k))
+	return a.AccountState.GetValue(k)
+}
+
+func (a *trackedAccountState) SetValue(k, v []byte) ([]byte, error) {
+	a.world.writeStorage(a.id, storageKey(k))
+	return a.AccountState.SetValue(k, v)
+}
+
+func (a *trackedAccountState) DeleteValue(k []byte) ([]byte, error) {
+	a.world.writeStorage(a.id, storageKey(k))
+	return a.AccountState.DeleteValue(k)
+}
+
+func (a *trackedAccountState) StorageUsage() *StorageUsage {
+	a.world.read(a.id, trackKeyUsage)
+	return a.AccountState.StorageUsage()
+}
+
+func (a *trackedAccountState) GetSnapshot() AccountSnapshot {
+	a.world.readAll(a.id)
+	return a.AccountState.GetSnapshot()
+}
+
+func (a *trackedAccountState) Reset(snapshot AccountSnapshot) error {
+	a.world.writeStorageAll(a.id)
+	return a.AccountState.Reset(snapshot)
+}
+
+func (a *trackedAccountState) Clear() {
+	a.world.writeStorageAll(a.id)
+	a.AccountState.Clear()
+}
+
+func (a *trackedAccountState) IsContractOwner(owner module.Address) bool {
+	a.readMeta()
+	return a.AccountState.IsContractOwner(owner)
+}
+
+func (a *trackedAccountState) SetContractOwner(owner module.Address) error {
+	a.readMeta()
+	return a.AccountState.SetContractOwner(owner)
+}
+
+func (a *trackedAccountState) InitContractAccount(address module.Address) bool {
+	a.readMeta()
+	return a.AccountState.InitContractAccount(address)
+}
+
+func (a *trackedAccountState) DeployContract(code []byte, eeType EEType, contentType string, params []byte, txHash []byte) ([]byte, error) {
+	a.readMeta()
+	return a.AccountState.DeployContract(code, eeType, contentType, params, txHash)
+}
+
+func (a *trackedAccountState) APIInfo() (*scoreapi.Info, error) {
+	a.readMeta()
+	return a.AccountState.APIInfo()
+}
+
+func (a *trackedAccountState) SetAPIInfo(info *scoreapi.Info) {
+	a.readMeta()
+	a.AccountState.SetAPIInfo(info)
+}
+
+func (a *trackedAccountState) ActivateNextContract() error {
+	a.readMeta()
+	return a.AccountState.ActivateNextContract()
+}
+
+func (a *trackedAccountState) AcceptContract(txHash []byte, auditTxHash []byte) error {
+	a.readMeta()
+	return a.AccountState.AcceptContract(txHash, auditTxHash)
+}
+
+func (a *trackedAccountState) RejectContract(txHash []byte, auditTxHash []byte) error {
+	a.readMeta()
+	return a.AccountState.RejectContract(txHash, auditTxHash)
+}
+
+func (a *trackedAccountState) Contract() Contract {
+	a.readMeta()
+	return a.AccountState.Contract()
+}
+
+func (a *trackedAccountState) ActiveContract() Contract {
+	a.readMeta()
+	return a.AccountState.ActiveContract()
+}
+
+func (a *trackedAccountState) NextContract() Contract {
+	a.readMeta()
+	return a.AccountState.NextContract()
+}
+
+func (a *trackedAccountState) SetDisable(b bool) {
+	a.readMeta()
+	a.AccountState.SetDisable(b)
+}
+
+func (a *trackedAccountState) IsDisabled() bool {
+	a.readMeta()
+	return a.AccountState.IsDisabled()
+}
+
+func (a *trackedAccountState) SetBlock(b bool) {
+	a.readMeta()
+	a.AccountState.SetBlock(b)
+}
+
+func (a *trackedAccountState) IsBlocked() bool {
+	a.readMeta()
+	return a.AccountState.IsBlocked()
+}
+
+func (a *trackedAccountState) ContractOwner() module.Address {
+	a.readMeta()
+	return a.AccountState.ContractOwner()
+}
+
+func (a *trackedAccountState) GetObjGraph(id []byte, flags bool) (int, []byte, []byte, error) {
+	a.readMeta()
+	return a.AccountState.GetObjGraph(id, flags)
+}
+
+func (a *trackedAccountState) SetObjGraph(id []byte, flags bool, nextHash int, objGraph []byte) error {
+	a.readMeta()
+	return a.AccountState.SetObjGraph(id, flags, nextHash, objGraph)
+}
+
+func (a *trackedAccountState) AddDeposit(dc DepositContext, value *big.Int) error {
+	a.readMeta()
+	return a.AccountState.AddDeposit(dc, value)
+}
+
+func (a *trackedAccountState) WithdrawDeposit(dc DepositContext, id []byte, value *big.Int) (*big.Int, *big.Int, error) {
+	a.readMeta()
+	return a.AccountState.WithdrawDeposit(dc, id, value)
+}
+
+func (a *trackedAccountState) PaySteps(pc PayContext, steps *big.Int) (*big.Int, *big.Int, error) {
+	a.readMeta()
+	return a.AccountState.PaySteps(pc, steps)
+}
+
+func (a *trackedAccountState) CanAcceptTx(pc PayContext) bool {
+	a.readMeta()
+	return a.AccountState.CanAcceptTx(pc)
+}
+
+func (a *trackedAccountState) CheckDeposit(pc PayContext) bool {
+	a.readMeta()
+	return a.AccountState.CheckDeposit(pc)
+}
+
+func (a *trackedAccountState) GetDepositInfo(dc DepositContext, v module.JSONVersion) (map[string]interface{}, error) {
+	a.readMeta()
+	return a.AccountState.GetDepositInfo(dc, v)
+}
@@ -0,0 +1,224 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
+)
+
+func newTrackedOn(t *testing.T, wss WorldSnapshot) TrackedWorldState {
+	ws, err := WorldStateFromSnapshot(wss)
+	if err != nil {
+		t.Fatalf("Fail to make world state err=%+v", err)
+	}
+	return NewTrackedWorldState(ws)
+}
+
+func TestTrackedWorldState_Conflicts(t *testing.T) {
+	database := db.NewMapDB()
+	ws := NewWorldState(database, nil, nil, nil)
+	id1, id2, id3 := []byte("account1"), []byte("account2"), []byte("account3")
+	k1, k2 := []byte("key1"), []byte("key2")
+	ws.GetAccountState(id1).SetBalance(big.NewInt(100))
+	ws.GetAccountState(id3).SetValue(k1, []byte("v1"))
+	wss := ws.GetSnapshot()
+
+	// writes k2 of account3
+	tx1 := newTrackedOn(t, wss)
+	tx1.GetAccountState(id3).SetValue(k2, []byte("v2"))
+	rws1 := tx1.ReadWriteSet()
+
+	// reads k1 of account3
+	tx2 := newTrackedOn(t, wss)
+	if v, _ := tx2.GetAccountState(id3).GetValue(k1); !bytes.Equal(v, []byte("v1")) {
+		t.Errorf("Unexpected value=%q", v)
+	}
+	rws2 := tx2.ReadWriteSet()
+	if rws2.Conflicts(rws1) {
+		t.Error("Reading other key conflicts")
+	}
+
+	// reads k2 of account3
+	tx3 := newTrackedOn(t, wss)
+	tx3.GetAccountState(id3).GetValue(k2)
+	if !tx3.ReadWriteSet().Conflicts(rws1) {
+		t.Error("Reading written key doesn't conflict")
+	}
+
+	// writes k1 of account3
+	tx4 := newTrackedOn(t, wss)
+	tx4.GetAccountState(id3).SetValue(k1, []byte("v3"))
+	if tx4.ReadWriteSet().Conflicts(rws1) {
+		t.Error("Writing other key of same account conflicts")
+	}
+
+	// writes k2 of account3
+	tx4 = newTrackedOn(t, wss)
+	tx4.GetAccountState(id3).SetValue(k2, []byte("v3"))
+	if !tx4.ReadWriteSet().Conflicts(rws1) {
+		t.Error("Writing same key doesn't conflict")
+	}
+
+	// deploys to account3
+	tx4 = newTrackedOn(t, wss)
+	tx4.GetAccountState(id3).InitContractAccount(common.MustNewAddressFromString("cx03"))
+	if !tx4.ReadWriteSet().Conflicts(rws1) || !rws1.Conflicts(tx4.ReadWriteSet()) {
+		t.Error("Changing other than storage doesn't conflict")
+	}
+
+	// transfer from account1 to account2
+	tx5 := newTrackedOn(t, wss)
+	as1 := tx5.GetAccountState(id1)
+	as2 := tx5.GetAccountState(id2)
+	as1.SetBalance(new(big.Int).Sub(as1.GetBalance(), big.NewInt(10)))
+	as2.SetBalance(new(big.Int).Add(as2.GetBalance(), big.NewInt(10)))
+	rws5 := tx5.ReadWriteSet()
+	if rws5.Conflicts(rws1) || rws1.Conflicts(rws5) {
+		t.Error("Independent transactions conflict")
+	}
+	if rws5.WriteCount() != 2 {
+		t.Errorf("Unexpected write count=%d", rws5.WriteCount())
+	}
+
+	// reads balance of account2
+	tx6 := newTrackedOn(t, wss)
+	tx6.GetAccountState(id2).GetBalance()
+	if !tx6.ReadWriteSet().Conflicts(rws5) {
+		t.Error("Reading written balance doesn't conflict")
+	}
+
+	// changes are reverted
+	tx7 := newTrackedOn(t, wss)
+	ss := tx7.GetSnapshot()
+	as7 := tx7.GetAccountState(id1)
+	as7.SetBalance(new(big.Int).Add(as7.GetBalance(), big.NewInt(1)))
+	if err := tx7.Reset(ss); err != nil {
+		t.Fatalf("Fail to reset err=%+v", err)
+	}
+	rws7 := tx7.ReadWriteSet()
+	if rws7.WriteCount() != 0 {
+		t.Errorf("Reverted changes are written count=%d", rws7.WriteCount())
+	}
+	if !rws7.Conflicts(rws5) {
+		t.Error("Reading reverted balance doesn't conflict")
+	}
+}
+
+func TestTrackedWorldState_ApplyTo(t *testing.T) {
+	database := db.NewMapDB()
+	ws := NewWorldState(database, nil, nil, nil)
+	id1, id2, id3 := []byte("account1"), []byte("account2"), []byte("account3")
+	k1 := []byte("key1")
+	ws.GetAccountState(id1).SetBalance(big.NewInt(100))
+	wss := ws.GetSnapshot()
+
+	transfer := func(ws WorldState, from, to []byte, value int64) {
+		as1 := ws.GetAccountState(from)
+		as2 := ws.GetAccountState(to)
+		v := big.NewInt(value)
+		as1.SetBalance(new(big.Int).Sub(as1.GetBalance(), v))
+		as2.SetBalance(new(big.Int).Add(as2.GetBalance(), v))
+	}
+	store := func(ws WorldState, id, k, v []byte) {
+		ws.GetAccountState(id).SetValue(k, v)
+	}
+
+	// serial execution
+	serial, _ := WorldStateFromSnapshot(wss)
+	transfer(serial, id1, id2, 30)
+	store(serial, id3, k1, []byte("v1"))
+	expected := serial.GetSnapshot().StateHash()
+
+	// speculative execution on the same snapshot
+	tx1 := newTrackedOn(t, wss)
+	transfer(tx1, id1, id2, 30)
+	tx2 := newTrackedOn(t, wss)
+	store(tx2, id3, k1, []byte("v1"))
+	if tx2.ReadWriteSet().Conflicts(tx1.ReadWriteSet()) {
+		t.Fatal("Independent transactions conflict")
+	}
+
+	real, _ := WorldStateFromSnapshot(wss)
+	for _, tx := range []TrackedWorldState{tx1, tx2} {
+		if err := tx.ApplyTo(real); err != nil {
+			t.Fatalf("Fail to apply err=%+v", err)
+		}
+	}
+	if hash := real.GetSnapshot().StateHash(); !bytes.Equal(hash, expected) {
+		t.Errorf("Different state hash exp=%x real=%x", expected, hash)
+	}
+}
+
+func TestTrackedWorldState_TokenTransfers(t *testing.T) {
+	database := db.NewMapDB()
+	ws := NewWorldState(database, nil, nil, nil)
+	token := []byte("token")
+	holders := [][]byte{[]byte("holder1"), []byte("holder2"), []byte("holder3"), []byte("holder4")}
+	balanceKey := func(holder []byte) []byte {
+		return append([]byte("balance|"), holder...)
+	}
+	tas := ws.GetAccountState(token)
+	tas.InitContractAccount(common.MustNewAddressFromString("cx01"))
+	if err := tas.MigrateForRevision(module.LatestRevision); err != nil {
+		t.Fatalf("Fail to migrate err=%+v", err)
+	}
+	tas.SetValue([]byte("totalSupply"), big.NewInt(200).Bytes())
+	tas.SetValue(balanceKey(holders[0]), big.NewInt(100).Bytes())
+	tas.SetValue(balanceKey(holders[2]), big.NewInt(100).Bytes())
+	wss := ws.GetSnapshot()
+
+	// transfer of the token like a token contract
+	transfer := func(ws WorldState, from, to []byte, value int64) {
+		as := ws.GetAccountState(token)
+		if !as.IsContract() {
+			t.Fatal("Token isn't a contract")
+		}
+		as.GetValue([]byte("totalSupply"))
+		v := big.NewInt(value)
+		bs, _ := as.GetValue(balanceKey(from))
+		as.SetValue(balanceKey(from), new(big.Int).Sub(new(big.Int).SetBytes(bs), v).Bytes())
+		bs, _ = as.GetValue(balanceKey(to))
+		as.SetValue(balanceKey(to), new(big.Int).Add(new(big.Int).SetBytes(bs), v).Bytes())
+	}
+
+	// serial execution
+	serial, _ := WorldStateFromSnapshot(wss)
+	transfer(serial, holders[0], holders[1], 30)
+	transfer(serial, holders[2], holders[3], 100)
+	expected := serial.GetSnapshot()
+
+	// speculative execution of transfers between disjoint holders
+	tx1 := newTrackedOn(t, wss)
+	transfer(tx1, holders[0], holders[1], 30)
+	tx2 := newTrackedOn(t, wss)
+	transfer(tx2, holders[2], holders[3], 100)
+	if tx2.ReadWriteSet().Conflicts(tx1.ReadWriteSet()) {
+		t.Fatal("Transfers between disjoint holders conflict")
+	}
+
+	real, _ := WorldStateFromSnapshot(wss)
+	for _, tx := range []TrackedWorldState{tx1, tx2} {
+		if err := tx.ApplyTo(real); err != nil {
+			t.Fatalf("Fail to apply err=%+v", err)
+		}
+	}
+	result := real.GetSnapshot()
+	if !bytes.Equal(result.StateHash(), expected.StateHash()) {
+		t.Errorf("Different state hash exp=%x real=%x", expected.StateHash(), result.StateHash())
+	}
+	usage := result.GetAccountSnapshot(token).StorageUsage()
+	if exp := expected.GetAccountSnapshot(token).StorageUsage(); usage == nil || *usage != *exp {
+		t.Errorf("Different storage usage exp=%+v real=%+v", exp, usage)
+	}
+
+	// transfer from the holder receiving by the prior one
+	tx3 := newTrackedOn(t, wss)
+	transfer(tx3, holders[1], holders[2], 10)
+	if !tx3.ReadWriteSet().Conflicts(tx1.ReadWriteSet()) {
+		t.Error("Transfer from the receiver doesn't conflict")
+	}
+}
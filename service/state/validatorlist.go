@@ -348,6 +348,25 @@ func ValidatorSnapshotFromSlice(database db.Database, vl []module.Validator) (Va
 	return vss, nil
 }
 
+// ValidatorSnapshotFromBytes returns a snapshot of serialized validators.
+// The snapshot is stored in the database on Flush.
+func ValidatorSnapshotFromBytes(database db.Database, bs []byte) (ValidatorSnapshot, error) {
+	bk, err := database.GetBucket(db.BytesByHash)
+	if err != nil {
+		return nil, err
+	}
+	vss := new(validatorSnapshot)
+	vss.bucket = bk
+	if len(bs) > 0 {
+		if _, err := codec.BC.UnmarshalFromBytes(bs, &vss.validators); err != nil {
+			return nil, errors.CriticalFormatError.Wrap(err, "InvalidValidatorList")
+		}
+		vss.serialized = bs
+		vss.dirty = true
+	}
+	return vss, nil
+}
+
 func ValidatorStateFromSnapshot(vss ValidatorSnapshot) ValidatorState {
 	snapshot, ok := vss.(*validatorSnapshot)
 	if !ok {
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/icon-project/goloop/common"
@@ -130,3 +131,43 @@ func TestEmptyValidatorList(t *testing.T) {
 		checkEmpty(t, vl)
 	}
 }
+
+func TestValidatorSnapshotFromBytes(t *testing.T) {
+	var validators []module.Validator
+	for i := 0; i < 3; i++ {
+		_, pubKey := crypto.GenerateKeyPair()
+		v, err := ValidatorFromPublicKey(pubKey.SerializeCompressed())
+		if err != nil {
+			t.Fatalf("Fail to make validator err=%+v", err)
+		}
+		validators = append(validators, v)
+	}
+	mdb := db.NewMapDB()
+	vList1, err := ValidatorSnapshotFromSlice(mdb, validators)
+	if err != nil {
+		t.Fatalf("Fail to make validatorList from slice err=%+v", err)
+	}
+
+	mdb2 := db.NewMapDB()
+	vList2, err := ValidatorSnapshotFromBytes(mdb2, vList1.Bytes())
+	if err != nil {
+		t.Fatalf("Fail to make validatorList from bytes err=%+v", err)
+	}
+	if !bytes.Equal(vList1.Hash(), vList2.Hash()) || vList2.Len() != len(validators) {
+		t.Fatalf("Different validatorList hash=%x exp=%x", vList2.Hash(), vList1.Hash())
+	}
+	if err := vList2.Flush(); err != nil {
+		t.Fatalf("Fail to flush err=%+v", err)
+	}
+	vList3, err := ValidatorSnapshotFromHash(mdb2, vList1.Hash())
+	if err != nil {
+		t.Fatalf("Fail to make validatorList from hash err=%+v", err)
+	}
+	if vList3.Len() != len(validators) {
+		t.Fatalf("Invalid length=%d exp=%d", vList3.Len(), len(validators))
+	}
+
+	if _, err := ValidatorSnapshotFromBytes(mdb2, []byte{0x01}); err == nil {
+		t.Fatal("Invalid bytes are accepted")
+	}
+}
@@ -39,6 +39,10 @@ const (
 	VarDepositIssueRate   = "deposit_issue_rate"
 	VarNextBlockVersion   = "next_block_version"
 	VarEnabledEETypes     = "enabled_ee_types"
+	VarCallbacks          = "callbacks"
+	VarCallbackTimer      = "callback_timer"
+	VarCallbackQueue      = "callback_queue"
+	VarCallbackLinks      = "callback_links"
 )
 
 const (
@@ -371,12 +375,15 @@ func tryVirtualState(ws WorldState) WorldVirtualState {
 
 func (c *worldContext) WorldStateChanged(ws WorldState) WorldContext {
 	wc := &worldContext{
-		WorldState:   ws,
-		virtualState: tryVirtualState(ws),
-		treasury:     c.treasury,
-		governance:   c.governance,
-		systemInfo:   c.systemInfo,
-		blockInfo:    c.blockInfo,
+		WorldState:      ws,
+		virtualState:    tryVirtualState(ws),
+		treasury:        c.treasury,
+		governance:      c.governance,
+		systemInfo:      c.systemInfo,
+		blockInfo:       c.blockInfo,
+		csInfo:          c.csInfo,
+		skipTransaction: c.skipTransaction,
+		platform:        c.platform,
 	}
 	return wc
 }
@@ -47,6 +47,11 @@ type worldSnapshotImpl struct {
 	accounts   trie.ImmutableForObject
 	validators ValidatorSnapshot
 	extension  ExtensionSnapshot
+
+	// flat is the flat state of the world, and dirty is the set of keys of
+	// accounts which may be changed after it.
+	flat  flatLayer
+	dirty map[string]bool
 }
 
 func (ws *worldSnapshotImpl) GetValidatorSnapshot() ValidatorSnapshot {
@@ -88,6 +93,14 @@ func (ws *worldSnapshotImpl) Database() db.Database {
 
 func (ws *worldSnapshotImpl) GetAccountSnapshot(id []byte) AccountSnapshot {
 	key := addressIDToKey(id)
+	if ws.flat != nil && !ws.dirty[string(key)] {
+		if as, ok := flatAccountOf(ws.database, ws.flat, key); ok {
+			if as == nil {
+				return nil
+			}
+			return as
+		}
+	}
 	obj, err := ws.accounts.Get(key)
 	if err != nil {
 		log.Errorf("Fail to get account for %x err=%v", key, err)
@@ -113,6 +126,10 @@ type worldStateImpl struct {
 	validators      ValidatorState
 	extension       extensionStateHolder
 
+	flat        flatLayer
+	dirty       map[string]bool
+	dirtyShared bool
+
 	nodeCacheEnabled bool
 }
 
@@ -149,6 +166,9 @@ func (ws *worldStateImpl) Reset(isnapshot WorldSnapshot) error {
 	}
 	ws.validators.Reset(snapshot.GetValidatorSnapshot())
 	ws.extension.Reset(snapshot.GetExtensionSnapshot())
+	ws.flat = snapshot.flat
+	ws.dirty = snapshot.dirty
+	ws.dirtyShared = true
 	return nil
 }
 
@@ -168,21 +188,57 @@ func (ws *worldStateImpl) GetAccountState(id []byte) AccountState {
 		return a
 	}
 	key := addressIDToKey(id)
-	obj, err := ws.accounts.Get(key)
-	if err != nil {
-		log.Errorf("Fail to get account for %x err=%+v", key, err)
-		return nil
-	}
-	var as *accountSnapshotImpl
-	if obj != nil {
-		as = obj.(*accountSnapshotImpl)
+	as, ok := ws.flatAccountInLock(key)
+	if !ok {
+		obj, err := ws.accounts.Get(key)
+		if err != nil {
+			log.Errorf("Fail to get account for %x err=%+v", key, err)
+			return nil
+		}
+		if obj != nil {
+			as = obj.(*accountSnapshotImpl)
+		}
 	}
 	ac := newAccountState(ws.database, as, key, ws.nodeCacheEnabled)
 	ws.mutableAccounts[ids] = ac
 	return ac
 }
 
+// flatAccountInLock returns the account in the flat state if it's not
+// changed after the flat state.
+func (ws *worldStateImpl) flatAccountInLock(key []byte) (*accountSnapshotImpl, bool) {
+	if ws.flat == nil || ws.dirty[string(key)] {
+		return nil, false
+	}
+	return flatAccountOf(ws.database, ws.flat, key)
+}
+
+// markDirtyInLock marks the mutable accounts as changed after the flat state
+// before they are written to the trie. The set is copied if it's shared with
+// snapshots.
+func (ws *worldStateImpl) markDirtyInLock() {
+	if ws.flat == nil {
+		return
+	}
+	for _, as := range ws.mutableAccounts {
+		key := string(as.(*accountStateImpl).key)
+		if ws.dirty[key] {
+			continue
+		}
+		if ws.dirty == nil || ws.dirtyShared {
+			dirty := make(map[string]bool, len(ws.dirty)+len(ws.mutableAccounts))
+			for k := range ws.dirty {
+				dirty[k] = true
+			}
+			ws.dirty = dirty
+			ws.dirtyShared = false
+		}
+		ws.dirty[key] = true
+	}
+}
+
 func (ws *worldStateImpl) flushAccountCacheInLock() {
+	ws.markDirtyInLock()
 	for _, as := range ws.mutableAccounts {
 		key := as.(*accountStateImpl).key
 		s := as.GetSnapshot()
@@ -239,13 +295,17 @@ func (ws *worldStateImpl) GetAccountSnapshot(id []byte) AccountSnapshot {
 	}
 
 	key := addressIDToKey(id)
-	obj, err := ws.accounts.Get(key)
-	if err != nil {
-		log.Errorf("Fail to get account for %x err=%+v", key, err)
-		return nil
-	}
-	if obj != nil {
-		return obj.(*accountSnapshotImpl)
+	if as, ok := ws.flatAccountInLock(key); ok && as != nil {
+		return as
+	} else if !ok {
+		obj, err := ws.accounts.Get(key)
+		if err != nil {
+			log.Errorf("Fail to get account for %x err=%+v", key, err)
+			return nil
+		}
+		if obj != nil {
+			return obj.(*accountSnapshotImpl)
+		}
 	}
 
 	return newAccountSnapshot(ws.database)
@@ -256,12 +316,15 @@ func (ws *worldStateImpl) GetSnapshot() WorldSnapshot {
 	defer ws.mutex.Unlock()
 
 	ws.flushAccountCacheInLock()
+	ws.dirtyShared = true
 
 	return &worldSnapshotImpl{
 		database:   ws.database,
 		accounts:   ws.accounts.GetSnapshot(),
 		validators: ws.validators.GetSnapshot(),
 		extension:  ws.extension.GetSnapshot(),
+		flat:       ws.flat,
+		dirty:      ws.dirty,
 	}
 }
 
@@ -270,6 +333,7 @@ func NewWorldState(database db.Database, stateHash []byte, vs ValidatorSnapshot,
 	ws.database = database
 	ws.accounts = trie_manager.NewMutableForObject(database, stateHash, AccountType)
 	ws.mutableAccounts = make(map[string]AccountState)
+	ws.flat = flatLayerOf(database, stateHash)
 	if vs == nil {
 		ws.validators, _ = ValidatorStateFromHash(database, nil)
 	} else {
@@ -283,6 +347,7 @@ func NewWorldSnapshot(dbase db.Database, stateHash []byte, vs ValidatorSnapshot,
 	ws := new(worldSnapshotImpl)
 	ws.database = dbase
 	ws.accounts = trie_manager.NewImmutableForObject(dbase, stateHash, AccountType)
+	ws.flat = flatLayerOf(dbase, stateHash)
 	if vs == nil {
 		vs, _ = ValidatorSnapshotFromHash(dbase, nil)
 	}
@@ -298,6 +363,8 @@ func NewWorldSnapshotWithNewValidators(dbase db.Database, snapshot WorldSnapshot
 			accounts:   ws.accounts,
 			validators: vss,
 			extension:  ws.extension,
+			flat:       ws.flat,
+			dirty:      ws.dirty,
 		}
 	} else {
 		return NewWorldSnapshot(dbase, snapshot.StateHash(), vss, ws.GetExtensionSnapshot())
@@ -312,6 +379,9 @@ func WorldStateFromSnapshot(wss WorldSnapshot) (WorldState, error) {
 		ws.mutableAccounts = make(map[string]AccountState)
 		ws.validators = ValidatorStateFromSnapshot(wss.GetValidatorSnapshot())
 		ws.extension.Reset(wss.GetExtensionSnapshot())
+		ws.flat = wss.flat
+		ws.dirty = wss.dirty
+		ws.dirtyShared = true
 		return ws, nil
 	}
 	return nil, errors.ErrIllegalArgument
@@ -0,0 +1,327 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+const DataTypeCallback = "callback"
+
+type callbackData struct {
+	ID common.HexInt64 `json:"id"`
+}
+
+type callbackV3Data struct {
+	Version   common.HexUint16 `json:"version"`
+	From      *common.Address  `json:"from,omitempty"` // it should be nil
+	TimeStamp common.HexInt64  `json:"timestamp"`
+	DataType  string           `json:"dataType"`
+	Data      callbackData     `json:"data"`
+}
+
+func (tx *callbackV3Data) calcHash() ([]byte, error) {
+	sha := bytes.NewBuffer(nil)
+	sha.Write([]byte("icx_sendTransaction"))
+
+	// data
+	sha.Write([]byte(".data."))
+	if bs, err := SerializeValue(map[string]interface{}{
+		"id": tx.Data.ID.String(),
+	}); err != nil {
+		return nil, err
+	} else {
+		sha.Write(bs)
+	}
+
+	// dataType
+	sha.Write([]byte(".dataType."))
+	sha.Write([]byte(tx.DataType))
+
+	// timestamp
+	sha.Write([]byte(".timestamp."))
+	sha.Write([]byte(tx.TimeStamp.String()))
+
+	// version
+	sha.Write([]byte(".version."))
+	sha.Write([]byte(tx.Version.String()))
+
+	return crypto.SHA3Sum256(sha.Bytes()), nil
+}
+
+// callbackV3 is the transaction executing a scheduled callback. It's made by
+// the proposer for each due callback, and placed at the beginning of the
+// block following the base transaction.
+type callbackV3 struct {
+	callbackV3Data
+
+	id    []byte
+	hash  []byte
+	bytes []byte
+}
+
+// NewCallbackTransactions returns the transactions for the callbacks to be
+// executed in the block of the world context.
+func NewCallbackTransactions(wc state.WorldContext) []module.Transaction {
+	if !wc.Revision().ScheduledCallback() {
+		return nil
+	}
+	as := wc.GetAccountState(state.SystemID)
+	ids := contract.DueCallbacks(as, wc.BlockHeight(), contract.CallbackLimitPerBlock)
+	txs := make([]module.Transaction, len(ids))
+	for i, id := range ids {
+		txs[i] = Wrap(&callbackV3{
+			callbackV3Data: callbackV3Data{
+				Version:   common.HexUint16{Value: module.TransactionVersion3},
+				TimeStamp: common.HexInt64{Value: wc.BlockTimeStamp()},
+				DataType:  DataTypeCallback,
+				Data:      callbackData{ID: common.HexInt64{Value: id}},
+			},
+		})
+	}
+	return txs
+}
+
+// CallbackIDOf returns the ID of the callback executed by the transaction.
+// It returns false if the transaction isn't for a callback.
+func CallbackIDOf(tx module.Transaction) (int64, bool) {
+	if t, ok := tx.(*transaction); ok {
+		tx = t.Transaction
+	}
+	if t, ok := tx.(*callbackV3); ok {
+		return t.Data.ID.Value, true
+	}
+	return 0, false
+}
+
+func (tx *callbackV3) Version() int {
+	return module.TransactionVersion3
+}
+
+func (tx *callbackV3) Prepare(ctx contract.Context) (state.WorldContext, error) {
+	lq := []state.LockRequest{
+		{state.WorldIDStr, state.AccountWriteLock},
+	}
+	wc := ctx.GetFuture(lq)
+	wc.WorldVirtualState().Ensure()
+
+	return wc, nil
+}
+
+func (tx *callbackV3) Execute(ctx contract.Context, estimate bool) (txresult.Receipt, error) {
+	if estimate {
+		return nil, errors.InvalidStateError.New("EstimationNotAllowed")
+	}
+	id := tx.Data.ID.Value
+	cb, err := contract.PopCallback(ctx.GetAccountState(state.SystemID), id)
+	if err != nil {
+		return nil, err
+	}
+	if cb == nil {
+		// It's cancelled by one of former callbacks in the block.
+		r := txresult.NewReceipt(ctx.Database(), ctx.Revision(), state.SystemAddress)
+		status := scoreresult.InvalidParameterError.Errorf("CallbackCancelled(id=%d)", id)
+		s, _ := scoreresult.StatusOf(status)
+		r.SetResult(s, new(big.Int), ctx.StepPrice(), nil)
+		r.SetReason(status)
+		return r, nil
+	}
+
+	cc := contract.NewCallContext(ctx, cb.StepLimit, false)
+	defer cc.Dispose()
+	logger := cc.FrameLogger()
+	logger.TSystemf("CALLBACK start id=%d to=%s method=%s", id, cb.Owner, cb.Method)
+
+	params, _ := json.Marshal(map[string]interface{}{"id": &tx.Data.ID})
+	data, _ := json.Marshal(&contract.DataCallJSON{Method: cb.Method, Params: params})
+	handler, err := ctx.ContractManager().GetHandler(state.SystemAddress, cb.Owner,
+		new(big.Int), contract.CTypeCall, data)
+	if err != nil {
+		return nil, err
+	}
+	status, used, _, addr := cc.Call(handler, cc.StepAvailable())
+	cc.DeductSteps(used)
+
+	// If it fails for system failure, then it needs to re-run this.
+	if code := errors.CodeOf(status); code == errors.ExecutionFailError ||
+		errors.IsCriticalCode(code) {
+		return nil, status
+	} else if code == scoreresult.TimeoutError {
+		// it consumes all steps if it meets timeout.
+		cc.DeductSteps(cc.StepAvailable())
+	}
+
+	stepUsed := cc.StepUsed()
+	if err := contract.SettleCallback(ctx, cb, stepUsed); err != nil {
+		return nil, err
+	}
+	if err := cc.ApplyMigrations(); err != nil {
+		return nil, err
+	}
+
+	r := txresult.NewReceipt(ctx.Database(), ctx.Revision(), cb.Owner)
+	s, _ := scoreresult.StatusOf(status)
+	if status == nil {
+		cc.GetEventLogs(r)
+	}
+	r.SetResult(s, stepUsed, cb.StepPrice, addr)
+	r.SetReason(status)
+
+	logger.TSystemf("CALLBACK done status=%s steps=%s price=%s", s, stepUsed, cb.StepPrice)
+	return r, nil
+}
+
+func (tx *callbackV3) Dispose() {
+}
+
+func (tx *callbackV3) Group() module.TransactionGroup {
+	return module.TransactionGroupNormal
+}
+
+func (tx *callbackV3) ID() []byte {
+	if tx.id == nil {
+		if bs, err := tx.callbackV3Data.calcHash(); err != nil {
+			panic(err)
+		} else {
+			tx.id = bs
+		}
+	}
+	return tx.id
+}
+
+func (tx *callbackV3) From() module.Address {
+	return state.SystemAddress
+}
+
+func (tx *callbackV3) Bytes() []byte {
+	if tx.bytes == nil {
+		if bs, err := codec.BC.MarshalToBytes(&tx.callbackV3Data); err != nil {
+			panic(err)
+		} else {
+			tx.bytes = bs
+		}
+	}
+	return tx.bytes
+}
+
+func (tx *callbackV3) Hash() []byte {
+	if tx.hash == nil {
+		tx.hash = crypto.SHA3Sum256(tx.Bytes())
+	}
+	return tx.hash
+}
+
+func (tx *callbackV3) Verify() error {
+	return nil
+}
+
+func (tx *callbackV3) ToJSON(version module.JSONVersion) (interface{}, error) {
+	jso := map[string]interface{}{
+		"version":   &tx.callbackV3Data.Version,
+		"timestamp": &tx.callbackV3Data.TimeStamp,
+		"dataType":  tx.callbackV3Data.DataType,
+		"data":      &tx.callbackV3Data.Data,
+	}
+	jso["txHash"] = common.HexBytes(tx.ID())
+	return jso, nil
+}
+
+func (tx *callbackV3) MarshalJSON() ([]byte, error) {
+	if obj, err := tx.ToJSON(module.JSONVersionLast); err != nil {
+		return nil, scoreresult.WithStatus(err, module.StatusIllegalFormat)
+	} else {
+		return json.Marshal(obj)
+	}
+}
+
+func (tx *callbackV3) ValidateNetwork(nid int) bool {
+	return true
+}
+
+func (tx *callbackV3) PreValidate(wc state.WorldContext, update bool) error {
+	return nil
+}
+
+func (tx *callbackV3) GetHandler(cm contract.ContractManager) (Handler, error) {
+	return tx, nil
+}
+
+func (tx *callbackV3) Timestamp() int64 {
+	return tx.callbackV3Data.TimeStamp.Value
+}
+
+func (tx *callbackV3) Nonce() *big.Int {
+	return nil
+}
+
+func (tx *callbackV3) To() module.Address {
+	return state.SystemAddress
+}
+
+func (tx *callbackV3) IsSkippable() bool {
+	return false
+}
+
+func checkCallbackV3JSON(jso map[string]interface{}) bool {
+	if d, ok := jso["dataType"]; !ok || d != DataTypeCallback {
+		return false
+	}
+	if v, ok := jso["version"]; !ok || v != "0x3" {
+		return false
+	}
+	return true
+}
+
+func parseCallbackV3JSON(bs []byte, raw bool) (Transaction, error) {
+	tx := new(callbackV3)
+	if err := json.Unmarshal(bs, &tx.callbackV3Data); err != nil {
+		return nil, InvalidFormat.Wrap(err, "InvalidJSON")
+	}
+	if tx.callbackV3Data.From != nil {
+		return nil, InvalidFormat.New("InvalidFromValue(NonNil)")
+	}
+	return tx, nil
+}
+
+type callbackV3Header struct {
+	Version   common.HexUint16
+	From      *common.Address // it should be nil
+	TimeStamp common.HexInt64
+	DataType  string
+}
+
+func checkCallbackV3Bytes(bs []byte) bool {
+	var vh callbackV3Header
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &vh); err != nil {
+		return false
+	}
+	return vh.From == nil && vh.DataType == DataTypeCallback
+}
+
+func parseCallbackV3Bytes(bs []byte) (Transaction, error) {
+	tx := new(callbackV3)
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &tx.callbackV3Data); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func init() {
+	RegisterFactory(&Factory{
+		Priority:    12,
+		CheckJSON:   checkCallbackV3JSON,
+		ParseJSON:   parseCallbackV3JSON,
+		CheckBinary: checkCallbackV3Bytes,
+		ParseBinary: parseCallbackV3Bytes,
+	})
+}
@@ -0,0 +1,38 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestCallbackV3_Serialize(t *testing.T) {
+	tx := &callbackV3{
+		callbackV3Data: callbackV3Data{
+			Version:   common.HexUint16{Value: module.TransactionVersion3},
+			TimeStamp: common.HexInt64{Value: 1000},
+			DataType:  DataTypeCallback,
+			Data:      callbackData{ID: common.HexInt64{Value: 7}},
+		},
+	}
+
+	tx2, err := NewTransaction(tx.Bytes())
+	assert.NoError(t, err)
+	id, ok := CallbackIDOf(tx2)
+	assert.True(t, ok)
+	assert.EqualValues(t, 7, id)
+	assert.Equal(t, tx.ID(), tx2.ID())
+
+	js, err := tx.MarshalJSON()
+	assert.NoError(t, err)
+	tx3, err := NewTransactionFromJSON(js)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID(), tx3.ID())
+	assert.Equal(t, tx.Bytes(), tx3.Bytes())
+
+	_, ok = CallbackIDOf(&transaction{&transactionV3{}})
+	assert.False(t, ok)
+}
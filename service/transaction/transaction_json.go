@@ -64,6 +64,13 @@ func calcHashOfTransactionJSON(bs []byte, version int) ([]byte, error) {
 	return crypto.SHA3Sum256(bs), nil
 }
 
+// HashOfTransactionJSON returns the hash of the transaction in JSON, which
+// is signed by the sender. Signature in the JSON is ignored, so it can be
+// used for unsigned transactions.
+func HashOfTransactionJSON(js []byte, version int) ([]byte, error) {
+	return calcHashOfTransactionJSON(js, version)
+}
+
 func (tx *transactionJSON) calcHash(version int) ([]byte, error) {
 	return calcHashOfTransactionJSON(tx.raw, version)
 }
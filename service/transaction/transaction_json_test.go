@@ -0,0 +1,48 @@
+package transaction
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/wallet"
+)
+
+func TestHashOfTransactionJSON(t *testing.T) {
+	w := wallet.New()
+	tx := map[string]interface{}{
+		"version":   "0x3",
+		"from":      w.Address().String(),
+		"to":        "hx0000000000000000000000000000000000000001",
+		"value":     "0xa",
+		"stepLimit": "0x186a0",
+		"timestamp": "0x5c42da6830136",
+		"nid":       "0x3",
+		"dataType":  "message",
+		"data":      "0x6869",
+	}
+	js, _ := json.Marshal(tx)
+	hash, err := HashOfTransactionJSON(js, Version3)
+	assert.NoError(t, err)
+
+	// signature is ignored
+	tx["signature"] = ""
+	js, _ = json.Marshal(tx)
+	hash2, err := HashOfTransactionJSON(js, Version3)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hash2)
+
+	sig, err := w.Sign(hash)
+	assert.NoError(t, err)
+	tx["signature"] = base64.StdEncoding.EncodeToString(sig)
+	js, _ = json.Marshal(tx)
+	t3, err := NewTransactionFromJSON(js)
+	assert.NoError(t, err)
+	assert.NoError(t, t3.Verify())
+	assert.Equal(t, hash, t3.ID())
+
+	_, err = HashOfTransactionJSON(js, 4)
+	assert.Error(t, err)
+}
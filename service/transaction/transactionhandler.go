@@ -281,6 +281,10 @@ func (th *transactionHandler) Execute(ctx contract.Context, estimate bool) (txre
 	logger.TSystemf("TRANSACTION charge fee=%d steps=%d price=%d", fee, stepToPay, stepPrice)
 	as.SetBalance(new(big.Int).Sub(bal, fee))
 
+	if err := cc.ApplyMigrations(); err != nil {
+		return nil, err
+	}
+
 	// Make a receipt
 	receipt := txresult.NewReceipt(ctx.Database(), ctx.Revision(), th.to)
 	s, _ := scoreresult.StatusOf(status)
@@ -247,6 +247,11 @@ func (l *transactionList) HasTx(id []byte) bool {
 	return ok
 }
 
+func (l *transactionList) Get(id []byte) *txElement {
+	tidBk, tidSlot := indexAndBucketKeyFromKey(string(id))
+	return l.idMap[tidBk][tidSlot]
+}
+
 func (l *transactionList) GetBloom() *TxBloom {
 	if l.listFront == nil {
 		return &TxBloom{}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"sync"
+	"time"
 
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -64,6 +65,34 @@ func (m *TransactionManager) Candidate(
 	return m.getTxPool(g).Candidate(wc, maxBytes, maxCount)
 }
 
+func (m *TransactionManager) currentRangeFor(g module.TransactionGroup) TimestampRange {
+	return NewTimestampRange(time.Now().UnixNano()/1000, m.tsc.TransactionThreshold(g))
+}
+
+func (m *TransactionManager) GetPendingTransactions(
+	g module.TransactionGroup, from module.Address, offset, limit int,
+) ([]*module.PendingTransaction, int) {
+	return m.getTxPool(g).Pending(from, offset, limit, m.currentRangeFor(g))
+}
+
+func (m *TransactionManager) GetPendingTransaction(id []byte) (*module.PendingTransaction, error) {
+	for _, g := range []module.TransactionGroup{
+		module.TransactionGroupNormal, module.TransactionGroupPatch,
+	} {
+		if ptx := m.getTxPool(g).PendingTx(id, m.currentRangeFor(g)); ptx != nil {
+			return ptx, nil
+		}
+	}
+	return nil, errors.NotFoundError.Errorf("NotInPool(id=%#x)", id)
+}
+
+func (m *TransactionManager) GetPoolUsage() []module.TransactionPoolUsage {
+	return []module.TransactionPoolUsage{
+		m.normalTxPool.Usage(),
+		m.patchTxPool.Usage(),
+	}
+}
+
 func (m *TransactionManager) NotifyFinalized(
 	l1 module.TransactionList, r1 module.ReceiptList,
 	l2 module.TransactionList, r2 module.ReceiptList,
@@ -208,6 +237,9 @@ func (m *TransactionManager) VerifyTx(tx transaction.Transaction) error {
 		return InvalidTransactionError.Wrap(err,
 			"Failed to verify transaction")
 	}
+	if _, ok := transaction.CallbackIDOf(tx); ok {
+		return InvalidTransactionError.New("CallbackTransactionNotAllowed")
+	}
 	return nil
 }
 func (m *TransactionManager) addInLock(tx transaction.Transaction, direct bool) error {
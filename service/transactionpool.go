@@ -109,7 +109,9 @@ func (tp *TransactionPool) DropOldTXs(bts int64) {
 				iter.err = ExpiredTransactionError.Errorf(
 					"ExpiredTransaction(diff=%s)", TimestampToDuration(bts-tx.Timestamp()))
 			}
-			tp.log.Debugf("DROP TX: id=0x%x reason=%v", tx.ID(), iter.err)
+			tp.log.WithFields(log.Fields{
+				log.FieldKeyTxID: tx.ID(),
+			}).Debugf("DROP TX: reason=%v", iter.err)
 			drops = append(drops, TxDrop{tx.ID(), iter.err})
 			tp.monitor.OnDropTx(len(tx.Bytes()), direct)
 		}
@@ -189,8 +191,10 @@ func (tp *TransactionPool) Candidate(wc state.WorldContext, maxBytes int, maxCou
 		go tp.dropTransactions(dropped)
 	}
 
-	tp.log.Infof("TransactionPool.Candidate collected=%d removed=%d poolsize=%d duration=%s",
-		len(txs), len(dropped), poolSize, time.Now().Sub(startTS))
+	tp.log.WithFields(log.Fields{
+		log.FieldKeyDuration: time.Now().Sub(startTS),
+	}).Infof("TransactionPool.Candidate collected=%d removed=%d poolsize=%d",
+		len(txs), len(dropped), poolSize)
 
 	return txs, txSize
 }
@@ -291,6 +295,92 @@ func (tp *TransactionPool) Used() int {
 	return tp.list.Len()
 }
 
+func (tp *TransactionPool) Usage() module.TransactionPoolUsage {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	return module.TransactionPoolUsage{
+		Group: tp.group,
+		Size:  tp.size,
+		Used:  tp.list.Len(),
+	}
+}
+
+func (tp *TransactionPool) pendingOf(e *txElement, tsr TimestampRange) *module.PendingTransaction {
+	tx := e.Value()
+	ptx := &module.PendingTransaction{
+		Transaction: tx,
+		Group:       tp.group,
+		Status:      module.PendingTxQueued,
+		Reason:      e.err,
+	}
+	if e.ts != 0 {
+		ptx.Received = time.Unix(0, e.ts)
+	}
+	if err := tsr.CheckTx(tx); err != nil {
+		ptx.Reason = err
+		if ExpiredTransactionError.Equals(err) {
+			ptx.Status = module.PendingTxExpired
+		} else {
+			ptx.Status = module.PendingTxInvalid
+		}
+		return ptx
+	}
+	if e.err != nil {
+		switch {
+		case transaction.NotEnoughBalanceError.Equals(e.err):
+			ptx.Status = module.PendingTxWaitingBalance
+		case ExpiredTransactionError.Equals(e.err):
+			ptx.Status = module.PendingTxExpired
+		default:
+			ptx.Status = module.PendingTxInvalid
+		}
+	}
+	return ptx
+}
+
+// Pending returns transactions in the pool in order of candidates. If from
+// is not nil, it returns transactions of the sender only. It also returns
+// the number of matched transactions.
+func (tp *TransactionPool) Pending(from module.Address, offset, limit int, tsr TimestampRange) (
+	[]*module.PendingTransaction, int,
+) {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	elements := make([]*txElement, 0, tp.list.Len())
+	for e := tp.list.Front(); e != nil; e = e.Next() {
+		if from == nil || e.Value().From().Equal(from) {
+			elements = append(elements, e)
+		}
+	}
+	total := len(elements)
+	if offset >= total {
+		return []*module.PendingTransaction{}, total
+	}
+	elements = elements[offset:]
+	if limit > 0 && len(elements) > limit {
+		elements = elements[:limit]
+	}
+	ptxs := make([]*module.PendingTransaction, len(elements))
+	for i, e := range elements {
+		ptxs[i] = tp.pendingOf(e, tsr)
+	}
+	return ptxs, total
+}
+
+// PendingTx returns the transaction in the pool with the status. It returns
+// nil if there is no transaction.
+func (tp *TransactionPool) PendingTx(id []byte, tsr TimestampRange) *module.PendingTransaction {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	if e := tp.list.Get(id); e != nil {
+		return tp.pendingOf(e, tsr)
+	}
+	return nil
+}
+
 func (tp *TransactionPool) SetTxManager(txm TxWaiterManager) {
 	tp.mutex.Lock()
 	defer tp.mutex.Unlock()
@@ -325,7 +415,9 @@ func (tp *TransactionPool) dropTransactions(txs []*txElement) {
 			if e.err == nil {
 				tp.log.Panicf("No reason to drop the tx=<%#x>", tx.ID())
 			}
-			tp.log.Debugf("DROP TX: id=0x%x reason=%v", tx.ID(), e.err)
+			tp.log.WithFields(log.Fields{
+				log.FieldKeyTxID: tx.ID(),
+			}).Debugf("DROP TX: reason=%v", e.err)
 			drops = append(drops, TxDrop{tx.ID(), e.err})
 			tp.monitor.OnDropTx(len(tx.Bytes()), direct)
 		}
@@ -4,10 +4,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/transaction"
 )
 
 type mockMonitor struct {
@@ -43,3 +46,58 @@ func TestTransactionPool_Add(t *testing.T) {
 		t.Error("Fail to add transaction with valid network ID")
 	}
 }
+
+func TestTransactionPool_Pending(t *testing.T) {
+	dbase := db.NewMapDB()
+	tsc := NewTimestampChecker()
+	tim, _ := NewTXIDManager(dbase, tsc)
+	pool := NewTransactionPool(module.TransactionGroupNormal, 5000, tim, &mockMonitor{}, log.New())
+
+	addr1 := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	addr2 := common.MustNewAddressFromString("hx2222222222222222222222222222222222222222")
+	txs := []*mockTransaction{
+		newMockTransaction([]byte("tx1"), addr1, 40),
+		newMockTransaction([]byte("tx2"), addr2, 100),
+		newMockTransaction([]byte("tx3"), addr1, 200),
+		newMockTransaction([]byte("tx4"), addr1, 300),
+	}
+	for _, tx := range txs {
+		if err := pool.Add(tx, true); err != nil {
+			t.Fatalf("Fail to add transaction err=%+v", err)
+		}
+	}
+	pool.list.Get([]byte("tx3")).err = transaction.NotEnoughBalanceError.New("OutOfBalance")
+
+	tsr := NewTimestampRange(150, 100)
+	ptxs, total := pool.Pending(nil, 1, 2, tsr)
+	assert.Equal(t, 4, total)
+	assert.Len(t, ptxs, 2)
+	assert.Equal(t, []byte("tx2"), ptxs[0].Transaction.ID())
+	assert.Equal(t, []byte("tx3"), ptxs[1].Transaction.ID())
+
+	ptxs, total = pool.Pending(addr1, 0, 10, tsr)
+	assert.Equal(t, 3, total)
+	assert.Len(t, ptxs, 3)
+	assert.Equal(t, module.PendingTxExpired, ptxs[0].Status)
+	assert.Equal(t, module.PendingTxWaitingBalance, ptxs[1].Status)
+	assert.Equal(t, module.PendingTxInvalid, ptxs[2].Status)
+	assert.True(t, FutureTransactionError.Equals(ptxs[2].Reason))
+	assert.False(t, ptxs[1].Received.IsZero())
+
+	ptxs, _ = pool.Pending(addr2, 0, 10, tsr)
+	assert.Equal(t, module.PendingTxQueued, ptxs[0].Status)
+	assert.Nil(t, ptxs[0].Reason)
+
+	ptxs, total = pool.Pending(addr2, 1, 10, tsr)
+	assert.Equal(t, 1, total)
+	assert.Len(t, ptxs, 0)
+
+	assert.Nil(t, pool.PendingTx([]byte("tx5"), tsr))
+	ptx := pool.PendingTx([]byte("tx3"), tsr)
+	assert.Equal(t, module.PendingTxWaitingBalance, ptx.Status)
+	assert.True(t, transaction.NotEnoughBalanceError.Equals(ptx.Reason))
+
+	usage := pool.Usage()
+	assert.Equal(t, 5000, usage.Size)
+	assert.Equal(t, 4, usage.Used)
+}
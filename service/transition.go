@@ -1,15 +1,19 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"sync"
 	"time"
 
+	"go.opencensus.io/trace"
+
 	"github.com/icon-project/goloop/chain/base"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/tracing"
 	"github.com/icon-project/goloop/service/scoredb"
 	"github.com/icon-project/goloop/service/transaction"
 
@@ -91,7 +95,7 @@ type transitionContext struct {
 	tim   TXIDManager
 }
 
-func (tc *transitionContext) onWorldFinalize(wss state.WorldSnapshot) {
+func (tc *transitionContext) onWorldFinalize(wss state.WorldSnapshot, bi module.BlockInfo) {
 	ass := wss.GetAccountSnapshot(state.SystemID)
 	if ass != nil && ass.StorageChangedAfter(tc.sass) {
 		regulator := tc.chain.Regulator()
@@ -111,7 +115,7 @@ func (tc *transitionContext) onWorldFinalize(wss state.WorldSnapshot) {
 		}
 		tc.sass = ass
 	}
-	tc.plt.OnExtensionSnapshotFinalization(wss.GetExtensionSnapshot(), tc.log)
+	tc.plt.OnExtensionSnapshotFinalization(wss.GetExtensionSnapshot(), bi, tc.log)
 }
 
 type transition struct {
@@ -147,6 +151,9 @@ type transition struct {
 
 	ti *module.TraceInfo
 
+	// traceCtx has the span for the execution.
+	traceCtx context.Context
+
 	ptxIDs   TXIDLogger
 	ntxIDs   TXIDLogger
 	ptxCount int
@@ -299,15 +306,62 @@ func (t *transition) startExecution(setup func() error) (canceler func() bool, e
 		return nil, err
 	}
 
+	ctx, span := trace.StartSpan(context.Background(), "transition.execute")
+	if t.bi != nil {
+		span.AddAttributes(trace.Int64Attribute("height", t.bi.Height()))
+	}
+	t.traceCtx = ctx
 	if t.syncer == nil {
-		go t.doExecute(t.step == stepExecuting)
+		go func(validated bool) {
+			t.doExecute(validated)
+			t.endExecutionSpan(span)
+		}(t.step == stepExecuting)
 	} else {
-		go t.doForceSync()
+		go func() {
+			t.doForceSync()
+			t.endExecutionSpan(span)
+		}()
 	}
 
 	return t.cancelExecution, nil
 }
 
+func (t *transition) endExecutionSpan(span *trace.Span) {
+	t.mutex.Lock()
+	step := t.step
+	t.mutex.Unlock()
+
+	if step == stepError {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnknown,
+			Message: step.String(),
+		})
+	}
+	span.AddAttributes(trace.Int64Attribute("txs", int64(t.ntxCount+t.ptxCount)))
+	span.End()
+}
+
+// traceContext returns the context having the span for the execution.
+func (t *transition) traceContext() context.Context {
+	if t.traceCtx == nil {
+		return context.Background()
+	}
+	return t.traceCtx
+}
+
+// startTxSpan starts a span for the transaction, and sets the context for
+// it to ctx, so that invocations for the transaction become children of it.
+func (t *transition) startTxSpan(ctx contract.Context, tx transaction.Transaction, idx int) *trace.Span {
+	tc, span := trace.StartSpan(t.traceContext(), "transaction")
+	span.AddAttributes(
+		trace.StringAttribute("hash", fmt.Sprintf("%#x", tx.ID())),
+		trace.Int64Attribute("group", int64(tx.Group())),
+		trace.Int64Attribute("index", int64(idx)),
+	)
+	ctx.SetProperty(contract.PropTraceContext, tc)
+	return span
+}
+
 func (t *transition) Execute(cb module.TransitionCallback) (canceler func() bool, err error) {
 	if cb == nil {
 		return nil, errors.IllegalArgumentError.New("TraceCallbackIsNil")
@@ -605,12 +659,18 @@ func (t *transition) doExecute(alreadyValidated bool) {
 
 	startTime := time.Now()
 
-	t.log.Debugf("Transition.doExecute: height=%d csi=%v", ctx.BlockHeight(), ctx.ConsensusInfo())
+	t.log.WithFields(log.Fields{
+		log.FieldKeyHeight: ctx.BlockHeight(),
+	}).Debugf("Transition.doExecute: csi=%v", ctx.ConsensusInfo())
 
 	if err := t.plt.OnExecutionBegin(ctx, t.log); err != nil {
 		t.reportExecution(err)
 		return
 	}
+	if err := t.prepareCallbacks(ctx); err != nil {
+		t.reportExecution(err)
+		return
+	}
 	patchReceipts := make([]txresult.Receipt, t.ptxCount)
 	if err := t.executeTxsSequential(t.patchTransactions, ctx, patchReceipts); err != nil {
 		t.reportExecution(err)
@@ -658,6 +718,9 @@ func (t *transition) doExecute(alreadyValidated bool) {
 	}
 
 	t.worldSnapshot = ctx.GetSnapshot()
+	if t.parent != nil {
+		t.worldSnapshot = state.UpdateFlatState(t.parent.worldSnapshot, t.worldSnapshot)
+	}
 
 	txDuration := time.Now().Sub(startTime)
 	txCount := t.ntxCount + t.ptxCount
@@ -665,7 +728,10 @@ func (t *transition) doExecute(alreadyValidated bool) {
 	t.executeDuration = txDuration
 
 	elapsedMS := float64(txDuration/time.Microsecond) / 1000
-	t.log.Infof("Transactions: %6d  Elapsed: %9.3f ms  PerTx: %7.1f µs  TPS: %9.2f",
+	t.log.WithFields(log.Fields{
+		log.FieldKeyHeight:   ctx.BlockHeight(),
+		log.FieldKeyDuration: txDuration,
+	}).Infof("Transactions: %6d  Elapsed: %9.3f ms  PerTx: %7.1f µs  TPS: %9.2f",
 		txCount, elapsedMS,
 		elapsedMS*1000/float64(txCount),
 		float64(txCount)/elapsedMS*1000)
@@ -681,6 +747,51 @@ func (t *transition) doExecute(alreadyValidated bool) {
 	t.reportExecution(nil)
 }
 
+// prepareCallbacks queues the callbacks scheduled at the height, and checks
+// whether the block has the transactions for the due callbacks at the
+// beginning. Only the base transaction may precede them.
+func (t *transition) prepareCallbacks(ctx contract.Context) error {
+	if !ctx.Revision().ScheduledCallback() {
+		return nil
+	}
+	as := ctx.GetAccountState(state.SystemID)
+	if err := contract.PrepareCallbacks(as, ctx.BlockHeight()); err != nil {
+		return err
+	}
+	due := contract.DueCallbacks(as, ctx.BlockHeight(), contract.CallbackLimitPerBlock)
+	if t.normalTransactions == nil {
+		if len(due) > 0 {
+			return errors.CriticalFormatError.Errorf(
+				"MissingCallbacks(expected=%d,real=0)", len(due))
+		}
+		return nil
+	}
+	offset, count := 0, 0
+	for i := t.normalTransactions.Iterator(); i.Has(); i.Next() {
+		tx, idx, err := i.Get()
+		if err != nil {
+			return errors.Wrap(err, "prepareCallbacks: fail to get transaction")
+		}
+		id, ok := transaction.CallbackIDOf(tx)
+		if !ok {
+			if idx == 0 && tx.From().Equal(state.SystemAddress) {
+				offset = 1
+			}
+			continue
+		}
+		if count >= len(due) || id != due[count] || idx != offset+count {
+			return errors.CriticalFormatError.Errorf(
+				"InvalidCallback(idx=%d,id=%d)", idx, id)
+		}
+		count++
+	}
+	if count != len(due) {
+		return errors.CriticalFormatError.Errorf(
+			"MissingCallbacks(expected=%d,real=%d)", len(due), count)
+	}
+	return nil
+}
+
 func (t *transition) validateTxs(l module.TransactionList, wc state.WorldContext, tsr TimestampRange) error {
 	if l == nil {
 		return nil
@@ -720,6 +831,10 @@ func (t *transition) executeTxs(l module.TransactionList, ctx contract.Context,
 		// it will skip skippable transactions
 		return t.executeTxsSequential(l, ctx, rctBuf)
 	}
+	if t.ti != nil {
+		// speculative executions would call back for discarded runs
+		return t.executeTxsSequential(l, ctx, rctBuf)
+	}
 	if cc := t.chain.ConcurrencyLevel(); cc > 1 {
 		return t.executeTxsConcurrent(cc, l, ctx, rctBuf)
 	}
@@ -740,24 +855,34 @@ func (t *transition) finalizePatchTransaction() error {
 	return t.patchTransactions.Flush()
 }
 
-func (t *transition) finalizeResult(noFlush bool, keepParent bool) error {
+func (t *transition) finalizeResult(noFlush bool, keepParent bool) (err error) {
 	var worldTS time.Time
 	startTS := time.Now()
 	if !noFlush {
+		ctx, span := trace.StartSpan(t.traceContext(), "transition.flush")
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
 		if t.syncer != nil {
 			worldTS = time.Now()
 			if err := t.syncer.Finalize(); err != nil {
 				return errors.Wrap(err, "Fail to finalize with syncer")
 			}
 		} else {
-			if err := t.worldSnapshot.Flush(); err != nil {
+			_, wspan := trace.StartSpan(ctx, "flush.world")
+			err := t.worldSnapshot.Flush()
+			tracing.EndSpan(wspan, err)
+			if err != nil {
 				return err
 			}
 			worldTS = time.Now()
-			if err := t.patchReceipts.Flush(); err != nil {
-				return err
+			_, rspan := trace.StartSpan(ctx, "flush.receipts")
+			err = t.patchReceipts.Flush()
+			if err == nil {
+				err = t.normalReceipts.Flush()
 			}
-			if err := t.normalReceipts.Flush(); err != nil {
+			tracing.EndSpan(rspan, err)
+			if err != nil {
 				return err
 			}
 		}
@@ -767,7 +892,8 @@ func (t *transition) finalizeResult(noFlush bool, keepParent bool) error {
 	}
 	finalTS := time.Now()
 
-	t.onWorldFinalize(t.worldSnapshot)
+	state.FinalizeFlatState(t.worldSnapshot)
+	t.onWorldFinalize(t.worldSnapshot, t.bi)
 	t.chain.Regulator().OnTxExecution(t.transactionCount, t.executeDuration, finalTS.Sub(startTS))
 	t.log.Infof("finalizeResult() total=%s world=%s receipts=%s",
 		finalTS.Sub(startTS), worldTS.Sub(startTS), finalTS.Sub(worldTS))
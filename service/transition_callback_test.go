@@ -0,0 +1,144 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+)
+
+func TestTransition_PrepareCallbacks(t *testing.T) {
+	database := db.NewMapDB()
+	logger := log.New()
+	cm, err := contract.NewContractManager(database, t.TempDir(), logger)
+	assert.NoError(t, err)
+
+	ws := state.NewWorldState(database, nil, nil, nil)
+	sys := ws.GetAccountState(state.SystemID)
+	assert.NoError(t, scoredb.NewVarDB(sys, state.VarRevision).Set(basic.Revision10))
+	assert.NoError(t, scoredb.NewVarDB(sys, state.VarStepPrice).Set(10))
+	assert.NoError(t, scoredb.NewArrayDB(sys, state.VarStepLimitTypes).Put(state.StepLimitTypeInvoke))
+	assert.NoError(t, scoredb.NewDictDB(sys, state.VarStepLimit, 1).Set(state.StepLimitTypeInvoke, 1000))
+	owner := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	ws.GetAccountState(owner.ID()).SetBalance(big.NewInt(10000))
+
+	chain := &testChain{}
+	newContext := func(wss state.WorldSnapshot, height int64) contract.Context {
+		ws, err := state.WorldStateFromSnapshot(wss)
+		assert.NoError(t, err)
+		wc := state.NewWorldContext(ws, common.NewBlockInfo(height, height), nil, basic.Platform)
+		return contract.NewContext(wc, cm, nil, chain, logger, nil)
+	}
+
+	// two callbacks at the height 1, and one at the height 2
+	ctx := newContext(ws.GetSnapshot(), 0)
+	cc := contract.NewCallContext(ctx, nil, false)
+	for _, h := range []int64{1, 1, 2} {
+		_, err := contract.ScheduleCallback(cc, owner, h, "m", big.NewInt(100))
+		assert.NoError(t, err)
+	}
+	wss := ctx.GetSnapshot()
+
+	cbs1 := transaction.NewCallbackTransactions(newContext(wss, 1))
+	assert.Len(t, cbs1, 2)
+	cbs2 := transaction.NewCallbackTransactions(newContext(wss, 2))
+	assert.Len(t, cbs2, 1)
+
+	cases := []struct {
+		name string
+		txs  []module.Transaction
+		ok   bool
+	}{
+		{"Valid", cbs1, true},
+		{"WrongOrder", []module.Transaction{cbs1[1], cbs1[0]}, false},
+		{"WrongID", []module.Transaction{cbs1[0], cbs2[0]}, false},
+		{"Missing", cbs1[:1], false},
+		{"NotDue", cbs2, false},
+		{"NotAtBeginning", []module.Transaction{
+			newTransferTx(t, wallet.New(), owner, 0, 1), cbs1[0], cbs1[1],
+		}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := &transition{
+				transitionContext: &transitionContext{
+					db:    database,
+					cm:    cm,
+					chain: chain,
+					log:   logger,
+					plt:   basic.Platform,
+				},
+				normalTransactions: transaction.NewTransactionListFromSlice(database, c.txs),
+			}
+			err := tr.prepareCallbacks(newContext(wss, 1))
+			if c.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.CriticalFormatError.Equals(err), "err=%+v", err)
+			}
+		})
+	}
+}
+
+func TestNormalCandidates(t *testing.T) {
+	database := db.NewMapDB()
+	logger := log.New()
+	cm, err := contract.NewContractManager(database, t.TempDir(), logger)
+	assert.NoError(t, err)
+
+	ws := state.NewWorldState(database, nil, nil, nil)
+	sys := ws.GetAccountState(state.SystemID)
+	assert.NoError(t, scoredb.NewVarDB(sys, state.VarRevision).Set(basic.Revision10))
+	assert.NoError(t, scoredb.NewVarDB(sys, state.VarStepPrice).Set(10))
+	assert.NoError(t, scoredb.NewArrayDB(sys, state.VarStepLimitTypes).Put(state.StepLimitTypeInvoke))
+	assert.NoError(t, scoredb.NewDictDB(sys, state.VarStepLimit, 1).Set(state.StepLimitTypeInvoke, 1000))
+	owner := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	ws.GetAccountState(owner.ID()).SetBalance(big.NewInt(10000))
+	wc := state.NewWorldContext(ws, common.NewBlockInfo(0, 0), nil, basic.Platform)
+	cc := contract.NewCallContext(contract.NewContext(wc, cm, nil, &testChain{}, logger, nil), nil, false)
+	for _, h := range []int64{1, 1} {
+		_, err := contract.ScheduleCallback(cc, owner, h, "m", big.NewInt(100))
+		assert.NoError(t, err)
+	}
+	ws, err = state.WorldStateFromSnapshot(cc.GetSnapshot())
+	assert.NoError(t, err)
+	wc = state.NewWorldContext(ws, common.NewBlockInfo(1, 1), nil, basic.Platform)
+	cbs := transaction.NewCallbackTransactions(wc)
+	assert.Len(t, cbs, 2)
+	size := len(cbs[0].Bytes()) + len(cbs[1].Bytes())
+
+	tx := newTransferTx(t, wallet.New(), owner, 0, 1)
+	var called bool
+	var maxBytes, maxCount int
+	candidate := func(wc state.WorldContext, b, c int) ([]module.Transaction, int) {
+		called, maxBytes, maxCount = true, b, c
+		return []module.Transaction{tx}, len(tx.Bytes())
+	}
+
+	// callbacks use the budget of the block
+	txs := normalCandidates(wc, 1000, 10, candidate)
+	assert.Equal(t, []module.Transaction{cbs[0], cbs[1], tx}, txs)
+	assert.True(t, called)
+	assert.Equal(t, 1000-size, maxBytes)
+	assert.Equal(t, 8, maxCount)
+
+	// no room for normal transactions
+	for _, c := range []struct{ bytes, count int }{{1000, 2}, {size, 10}} {
+		called = false
+		txs = normalCandidates(wc, c.bytes, c.count, candidate)
+		assert.Equal(t, cbs, txs)
+		assert.False(t, called)
+	}
+}
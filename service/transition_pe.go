@@ -3,7 +3,10 @@ package service
 import (
 	"sync"
 
+	"go.opencensus.io/trace"
+
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/tracing"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/contract"
 	"github.com/icon-project/goloop/service/state"
@@ -11,120 +14,180 @@ import (
 	"github.com/icon-project/goloop/service/txresult"
 )
 
-type executionContext struct {
-	waiter    chan struct{}
-	lastError error
-	lock      sync.Mutex
+// speculation is the result of executing a transaction on a snapshot which
+// may not have the results of all preceding transactions.
+type speculation struct {
+	// version is the number of transactions applied to the snapshot.
+	version int
+	ws      state.TrackedWorldState
+	rws     *state.ReadWriteSet
+	receipt txresult.Receipt
+	err     error
 }
 
-func (c *executionContext) Done() {
-	c.waiter <- struct{}{}
+// isValidAfter returns whether the speculation has same result as executing
+// the transaction after the transactions having the write sets.
+func (s *speculation) isValidAfter(writes []*state.ReadWriteSet) bool {
+	if s.err != nil {
+		return len(writes) == 0
+	}
+	for _, w := range writes {
+		if s.rws.Conflicts(w) {
+			return false
+		}
+	}
+	return true
 }
 
-func (c *executionContext) Ready() {
-	<-c.waiter
+// executionBase is the latest snapshot of the world with the number of
+// transactions applied to it. Speculations start from it.
+type executionBase struct {
+	lock     sync.Mutex
+	snapshot state.WorldSnapshot
+	version  int
 }
 
-func (c *executionContext) Error() error {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.lastError
+func (b *executionBase) Get() (state.WorldSnapshot, int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.snapshot, b.version
 }
 
-func (c *executionContext) Report(e error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	if c.lastError != nil {
-		c.lastError = e
-	}
+func (b *executionBase) Set(snapshot state.WorldSnapshot, version int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.snapshot = snapshot
+	b.version = version
 }
 
-func newExecutionContext(n int) *executionContext {
-	ch := make(chan struct{}, n)
-	for i := 0; i < n; i++ {
-		ch <- struct{}{}
+func (t *transition) speculate(ctx contract.Context, snapshot state.WorldSnapshot, version int, txo transaction.Transaction, idx int) *speculation {
+	sp := &speculation{version: version}
+	var span *trace.Span
+	for trial := 0; ; trial++ {
+		ws, err := state.WorldStateFromSnapshot(snapshot)
+		if err != nil {
+			sp.err = err
+			return sp
+		}
+		if ctx.NodeCacheEnabled() {
+			ws.EnableNodeCache()
+		}
+		sp.ws = state.NewTrackedWorldState(ws)
+		sctx := contract.NewContext(ctx.WorldStateChanged(sp.ws), t.cm, t.eem, t.chain, t.log, t.ti)
+		sctx.SetProperty(contract.PropInitialSnapshot, ctx.GetProperty(contract.PropInitialSnapshot))
+		if span == nil {
+			span = t.startTxSpan(sctx, txo, idx)
+		} else {
+			sctx.SetProperty(contract.PropTraceContext, trace.NewContext(t.traceContext(), span))
+		}
+
+		txh, err := txo.GetHandler(t.cm)
+		if err != nil {
+			t.log.Debugf("Fail to get handler err=%+v", err)
+			tracing.EndSpan(span, err)
+			sp.err = err
+			return sp
+		}
+		sctx.SetTransactionInfo(&state.TransactionInfo{
+			Group:     txo.Group(),
+			Index:     int32(idx),
+			Timestamp: txo.Timestamp(),
+			Nonce:     txo.Nonce(),
+			Hash:      txo.ID(),
+			From:      txo.From(),
+		})
+		sctx.UpdateSystemInfo()
+		rct, err := txh.Execute(sctx, false)
+		txh.Dispose()
+		if err == nil {
+			err = t.plt.OnTransactionEnd(sctx, t.log, rct)
+		}
+		if err == nil {
+			sp.receipt = rct
+			sp.rws = sp.ws.ReadWriteSet()
+			span.AddAttributes(trace.StringAttribute("status", rct.Status().String()))
+			span.End()
+			return sp
+		}
+		if !errors.ExecutionFailError.Equals(err) || trial == RetryCount {
+			t.log.Debugf("Fail to execute transaction err=%+v", err)
+			tracing.EndSpan(span, err)
+			sp.err = err
+			return sp
+		}
+		t.log.Warnf("RETRY TX <%#x> for err=%+v", txo.ID(), err)
 	}
-	return &executionContext{waiter: ch}
 }
 
+// executeTxsConcurrent executes transactions optimistically with the
+// given number of workers. Each worker executes a transaction on the latest
+// snapshot, and the results are applied in the order of the transactions.
+// If a transaction read or wrote something changed by the transactions
+// applied after its snapshot, then it's executed again on the current
+// state. So the result is same as executing them sequentially.
 func (t *transition) executeTxsConcurrent(level int, l module.TransactionList, ctx contract.Context, rctBuf []txresult.Receipt) error {
-	ec := newExecutionContext(level)
-
-	cnt := 0
+	txs := make([]transaction.Transaction, 0, len(rctBuf))
 	for i := l.Iterator(); i.Has(); i.Next() {
-		if err := ec.Error(); err != nil {
-			return err
-		}
-
-		if t.step == stepCanceled {
-			return ErrTransitionInterrupted
-		}
-
 		txi, _, err := i.Get()
 		if err != nil {
 			t.log.Errorf("Fail to iterate transaction list err=%+v", err)
 			return err
 		}
-		txo := txi.(transaction.Transaction)
-		txh, err := txo.GetHandler(t.cm)
-		if err != nil {
-			t.log.Debugf("Fail to handle transaction for %+v", err)
-			return err
-		}
-		wc, err2 := txh.Prepare(ctx)
-		ctx = contract.NewContext(wc, t.cm, t.eem, t.chain, t.log, t.ti)
-		if err2 != nil {
-			t.log.Debugf("Fail to prepare for %+v", err2)
-			return err2
-		}
-
-		ec.Ready()
-		go func(ctx contract.Context, wc state.WorldContext, txo transaction.Transaction, cnt int, rb *txresult.Receipt) {
-			ctx.SetTransactionInfo(&state.TransactionInfo{
-				Group:     txo.Group(),
-				Index:     int32(cnt),
-				Timestamp: txo.Timestamp(),
-				Nonce:     txo.Nonce(),
-				Hash:      txo.ID(),
-				From:      txo.From(),
-			})
-			ctx.UpdateSystemInfo()
-			wvs := ctx.WorldVirtualState()
-			for trials := RetryCount + 1; trials > 0; trials -= 1 {
-				rct, err := txh.Execute(ctx, false)
-				txh.Dispose()
-				if err == nil {
-					err = t.plt.OnTransactionEnd(ctx, t.log, rct)
-				}
-				if err == nil {
-					*rb = rct
-					break
-				}
-
-				if !errors.ExecutionFailError.Equals(err) || trials <= 1 {
-					t.log.Debugf("Fail to execute transaction err=%+v", err)
-					ec.Report(err)
-					break
-				}
-
-				t.log.Warnf("RETRY TX <%#x> for err=%+v", txo.ID(), err)
+		txs = append(txs, txi.(transaction.Transaction))
+	}
 
-				txh, err = txo.GetHandler(t.cm)
-				if err != nil {
-					t.log.Debugf("Fail to get handler err=%+v", err)
-					ec.Report(err)
-					break
+	base := &executionBase{snapshot: ctx.GetSnapshot()}
+	results := make([]chan *speculation, len(txs))
+	for i := range results {
+		results[i] = make(chan *speculation, 1)
+	}
+	next := make(chan int, len(txs))
+	for i := range txs {
+		next <- i
+	}
+	close(next)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	for w := 0; w < level; w++ {
+		go func() {
+			for idx := range next {
+				select {
+				case <-stop:
+					return
+				default:
 				}
-				ctx = contract.NewContext(wc, t.cm, t.eem, t.chain, t.log, t.ti)
+				snapshot, version := base.Get()
+				results[idx] <- t.speculate(ctx, snapshot, version, txs[idx], idx)
 			}
-			wvs.Commit()
-			ec.Done()
-		}(ctx, wc, txo, cnt, &rctBuf[cnt])
-
-		cnt++
+		}()
 	}
-	if wvs := ctx.WorldVirtualState(); wvs != nil {
-		wvs.Realize()
+
+	writes := make([]*state.ReadWriteSet, len(txs))
+	for idx, txo := range txs {
+		if t.step == stepCanceled {
+			return ErrTransitionInterrupted
+		}
+		sp := <-results[idx]
+		if !sp.isValidAfter(writes[sp.version:idx]) {
+			t.log.Tracef("REEXECUTE TX <%#x> from=%d", txo.ID(), sp.version)
+			snapshot, version := base.Get()
+			sp = t.speculate(ctx, snapshot, version, txo, idx)
+		}
+		if sp.err != nil {
+			return sp.err
+		}
+		writes[idx] = sp.rws
+		rctBuf[idx] = sp.receipt
+		if sp.rws.WriteCount() == 0 {
+			snapshot, _ := base.Get()
+			base.Set(snapshot, idx+1)
+			continue
+		}
+		if err := sp.ws.ApplyTo(ctx); err != nil {
+			return err
+		}
+		base.Set(ctx.GetSnapshot(), idx+1)
 	}
 	return nil
 }
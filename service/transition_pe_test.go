@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+type testChain struct {
+	module.Chain
+	concurrency int
+}
+
+func (c *testChain) CID() int {
+	return 1
+}
+
+func (c *testChain) ConcurrencyLevel() int {
+	return c.concurrency
+}
+
+func (c *testChain) TransactionTimeout() time.Duration {
+	return 5 * time.Second
+}
+
+func newTestTx(t *testing.T, w module.Wallet, js map[string]interface{}) module.Transaction {
+	js["version"] = "0x3"
+	js["from"] = w.Address().String()
+	js["stepLimit"] = "0x100000"
+	js["nid"] = "0x1"
+	bs, _ := json.Marshal(js)
+	hash, err := transaction.HashOfTransactionJSON(bs, transaction.Version3)
+	if err != nil {
+		t.Fatalf("Fail to make hash err=%+v", err)
+	}
+	sig, err := w.Sign(hash)
+	if err != nil {
+		t.Fatalf("Fail to sign err=%+v", err)
+	}
+	js["signature"] = base64.StdEncoding.EncodeToString(sig)
+	bs, _ = json.Marshal(js)
+	tx, err := transaction.NewTransactionFromJSON(bs)
+	if err != nil {
+		t.Fatalf("Fail to make transaction err=%+v", err)
+	}
+	return tx
+}
+
+func newTransferTx(t *testing.T, w module.Wallet, to module.Address, value int64, ts int64) module.Transaction {
+	return newTestTx(t, w, map[string]interface{}{
+		"to":        to.String(),
+		"value":     fmt.Sprintf("%#x", value),
+		"timestamp": fmt.Sprintf("%#x", ts),
+	})
+}
+
+func newCallTx(t *testing.T, w module.Wallet, to module.Address, method string, params map[string]interface{}, ts int64) module.Transaction {
+	data := map[string]interface{}{"method": method}
+	if params != nil {
+		data["params"] = params
+	}
+	return newTestTx(t, w, map[string]interface{}{
+		"to":        to.String(),
+		"timestamp": fmt.Sprintf("%#x", ts),
+		"dataType":  "call",
+		"data":      data,
+	})
+}
+
+func executeTestTxs(t *testing.T, database db.Database, wss state.WorldSnapshot, txs []module.Transaction, level int) ([]byte, []txresult.Receipt) {
+	logger := log.New()
+	cm, err := basic.Platform.NewContractManager(database, t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("Fail to make contract manager err=%+v", err)
+	}
+	tr := &transition{
+		transitionContext: &transitionContext{
+			db:    database,
+			cm:    cm,
+			chain: &testChain{},
+			log:   logger,
+			plt:   basic.Platform,
+		},
+	}
+	ws, err := state.WorldStateFromSnapshot(wss)
+	if err != nil {
+		t.Fatalf("Fail to make world state err=%+v", err)
+	}
+	wc := state.NewWorldContext(ws, common.NewBlockInfo(1, 1), nil, basic.Platform)
+	ctx := contract.NewContext(wc, cm, nil, tr.chain, logger, nil)
+	ctx.SetProperty(contract.PropInitialSnapshot, ctx.GetSnapshot())
+
+	l := transaction.NewTransactionListFromSlice(database, txs)
+	rcts := make([]txresult.Receipt, len(txs))
+	if level > 1 {
+		err = tr.executeTxsConcurrent(level, l, ctx, rcts)
+	} else {
+		err = tr.executeTxsSequential(l, ctx, rcts)
+	}
+	if err != nil {
+		t.Fatalf("Fail to execute err=%+v", err)
+	}
+	return ctx.GetSnapshot().StateHash(), rcts
+}
+
+func TestTransition_ExecuteTxsConcurrent(t *testing.T) {
+	database := db.NewMapDB()
+	ws := state.NewWorldState(database, nil, nil, nil)
+	sas := ws.GetAccountState(state.SystemID)
+	scoredb.NewVarDB(sas, state.VarStepPrice).Set(0)
+
+	const count = 8
+	wallets := make([]module.Wallet, count)
+	for i := range wallets {
+		wallets[i] = wallet.New()
+		if i < count-2 {
+			as := ws.GetAccountState(wallets[i].Address().ID())
+			as.SetBalance(big.NewInt(1000))
+		}
+	}
+	wss := ws.GetSnapshot()
+
+	var txs []module.Transaction
+	ts := int64(1)
+	for round := 0; round < 3; round++ {
+		for i := range wallets {
+			// independent transfers
+			txs = append(txs, newTransferTx(t, wallets[i], common.NewAccountAddress([]byte{byte(round), byte(i)}), 10, ts))
+			ts++
+			// transfers to the next one, and it may fail for lack of balance
+			txs = append(txs, newTransferTx(t, wallets[i], wallets[(i+1)%count].Address(), int64(100*(round+1)), ts))
+			ts++
+		}
+	}
+
+	hash1, rcts1 := executeTestTxs(t, database, wss, txs, 1)
+	for _, level := range []int{2, 4, 16} {
+		hash2, rcts2 := executeTestTxs(t, database, wss, txs, level)
+		if !bytes.Equal(hash1, hash2) {
+			t.Errorf("Different state hash level=%d exp=%x real=%x", level, hash1, hash2)
+		}
+		for i := range rcts1 {
+			if !bytes.Equal(rcts1[i].Bytes(), rcts2[i].Bytes()) {
+				t.Errorf("Different receipt level=%d idx=%d", level, i)
+			}
+		}
+	}
+
+	failures := 0
+	for _, rct := range rcts1 {
+		if rct.Status() != module.StatusSuccess {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(rcts1) {
+		t.Errorf("Unexpected number of failures=%d", failures)
+	}
+}
+
+func newChainScoreState(t *testing.T, database db.Database, gov module.Address) state.WorldSnapshot {
+	logger := log.New()
+	cm, err := basic.Platform.NewContractManager(database, t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("Fail to make contract manager err=%+v", err)
+	}
+	ws := state.NewWorldState(database, nil, nil, nil)
+	sas := ws.GetAccountState(state.SystemID)
+	scoredb.NewVarDB(sas, state.VarStepPrice).Set(0)
+	scoredb.NewVarDB(sas, state.VarGovernance).Set(gov)
+	wc := state.NewWorldContext(ws, common.NewBlockInfo(0, 0), nil, basic.Platform)
+	cc := contract.NewCallContext(contract.NewContext(wc, cm, nil, &testChain{}, logger, nil), nil, false)
+	err = contract.DeployAndInstallSystemSCORE(cc, contract.CID_CHAIN, gov, state.SystemAddress,
+		[]byte(`{"revision":"0x5"}`), []byte("genesis"))
+	if err != nil {
+		t.Fatalf("Fail to install chain score err=%+v", err)
+	}
+	return ws.GetSnapshot()
+}
+
+// newConflictingCallTxs returns calls of the chain score writing and reading
+// the same storage, mixed with transfers.
+func newConflictingCallTxs(t *testing.T, gov module.Wallet, wallets []module.Wallet) []module.Transaction {
+	var txs []module.Transaction
+	ts := int64(1)
+	for round := 0; round < 3; round++ {
+		for i, w := range wallets {
+			txs = append(txs,
+				newCallTx(t, gov, state.SystemAddress, "setTimestampThreshold",
+					map[string]interface{}{"threshold": fmt.Sprintf("%#x", round*100+i+1)}, ts),
+				newCallTx(t, w, state.SystemAddress, "getTimestampThreshold", nil, ts+1),
+				newCallTx(t, gov, state.SystemAddress, "addDeployer",
+					map[string]interface{}{"address": w.Address().String()}, ts+2),
+				// it fails without permission
+				newCallTx(t, w, state.SystemAddress, "addDeployer",
+					map[string]interface{}{"address": gov.Address().String()}, ts+3),
+				newTransferTx(t, w, gov.Address(), 1, ts+4),
+			)
+			ts += 5
+		}
+	}
+	return txs
+}
+
+func TestTransition_ExecuteTxsConcurrentCall(t *testing.T) {
+	database := db.NewMapDB()
+	gov := wallet.New()
+	wss := newChainScoreState(t, database, gov.Address())
+
+	wallets := make([]module.Wallet, 8)
+	for i := range wallets {
+		wallets[i] = wallet.New()
+	}
+	txs := newConflictingCallTxs(t, gov, wallets)
+
+	hash1, rcts1 := executeTestTxs(t, database, wss, txs, 1)
+	for _, level := range []int{2, 4, 16} {
+		hash2, rcts2 := executeTestTxs(t, database, wss, txs, level)
+		if !bytes.Equal(hash1, hash2) {
+			t.Errorf("Different state hash level=%d exp=%x real=%x", level, hash1, hash2)
+		}
+		for i := range rcts1 {
+			if !bytes.Equal(rcts1[i].Bytes(), rcts2[i].Bytes()) {
+				t.Errorf("Different receipt level=%d idx=%d", level, i)
+			}
+		}
+	}
+
+	for i, rct := range rcts1 {
+		// only calls of addDeployer by others and transfers without
+		// balance fail.
+		success := i%5 < 3
+		if success != (rct.Status() == module.StatusSuccess) {
+			t.Errorf("Unexpected status idx=%d status=%s", i, rct.Status())
+		}
+	}
+}
+
+type stepProfileCounter struct {
+	lock     sync.Mutex
+	profiles int
+}
+
+func (c *stepProfileCounter) OnLog(level module.TraceLevel, msg string) {}
+
+func (c *stepProfileCounter) OnEnd(e error) {}
+
+func (c *stepProfileCounter) OnStepProfile(p module.StepProfile) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.profiles++
+}
+
+func TestTransition_ExecuteTxsTrace(t *testing.T) {
+	database := db.NewMapDB()
+	gov := wallet.New()
+	wss := newChainScoreState(t, database, gov.Address())
+	wallets := make([]module.Wallet, 4)
+	for i := range wallets {
+		wallets[i] = wallet.New()
+	}
+	txs := newConflictingCallTxs(t, gov, wallets)
+
+	logger := log.New()
+	cm, err := basic.Platform.NewContractManager(database, t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("Fail to make contract manager err=%+v", err)
+	}
+	for _, idx := range []int{0, len(txs) / 2, len(txs) - 3} {
+		cb := new(stepProfileCounter)
+		tr := &transition{
+			transitionContext: &transitionContext{
+				db:    database,
+				cm:    cm,
+				chain: &testChain{concurrency: 4},
+				log:   logger,
+				plt:   basic.Platform,
+			},
+			ti: &module.TraceInfo{
+				Group:    module.TransactionGroupNormal,
+				Index:    idx,
+				Callback: cb,
+			},
+		}
+		ws, err := state.WorldStateFromSnapshot(wss)
+		if err != nil {
+			t.Fatalf("Fail to make world state err=%+v", err)
+		}
+		wc := state.NewWorldContext(ws, common.NewBlockInfo(1, 1), nil, basic.Platform)
+		ctx := contract.NewContext(wc, cm, nil, tr.chain, logger, tr.ti)
+		ctx.SetProperty(contract.PropInitialSnapshot, ctx.GetSnapshot())
+
+		// the transaction is executed only once for the trace
+		rcts := make([]txresult.Receipt, len(txs))
+		err = tr.executeTxs(transaction.NewTransactionListFromSlice(database, txs), ctx, rcts)
+		if err != nil {
+			t.Fatalf("Fail to execute err=%+v", err)
+		}
+		if cb.profiles != 1 {
+			t.Errorf("Invalid number of profiles idx=%d profiles=%d", idx, cb.profiles)
+		}
+	}
+}
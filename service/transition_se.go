@@ -4,7 +4,11 @@ import (
 	"math/big"
 	"time"
 
+	"go.opencensus.io/trace"
+
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/tracing"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/contract"
 	"github.com/icon-project/goloop/service/state"
@@ -36,10 +40,12 @@ func (t *transition) executeTxsSequential(l module.TransactionList, ctx contract
 		}
 		t.log.Tracef("START TX <0x%x>", txo.ID())
 		ts := time.Now()
+		span := t.startTxSpan(ctx, txo, cnt)
 		for trial := 0; ; trial++ {
 			txh, err := txo.GetHandler(t.cm)
 			if err != nil {
 				t.log.Errorf("Fail to GetHandler err=%+v", err)
+				tracing.EndSpan(span, err)
 				return err
 			}
 			ctx.SetTransactionInfo(&state.TransactionInfo{
@@ -62,17 +68,26 @@ func (t *transition) executeTxsSequential(l module.TransactionList, ctx contract
 			}
 			if !errors.ExecutionFailError.Equals(err) {
 				t.log.Warnf("Fail to execute transaction err=%+v", err)
+				tracing.EndSpan(span, err)
 				return err
 			}
 			if trial == RetryCount {
 				t.log.Warnf("Fail to execute transaction retry=%d err=%+v", trial, err)
+				tracing.EndSpan(span, err)
 				return err
 			}
-			t.log.Warnf("RETRY TX <%#x> for err=%+v", txo.ID(), err)
+			t.log.WithFields(log.Fields{
+				log.FieldKeyTxID: txo.ID(),
+			}).Warnf("RETRY TX for err=%+v", err)
 			ts = time.Now()
 		}
 		duration := time.Now().Sub(ts)
-		t.log.Tracef("END   TX <0x%x> duration=%s", txo.ID(), duration)
+		t.log.WithFields(log.Fields{
+			log.FieldKeyTxID:     txo.ID(),
+			log.FieldKeyDuration: duration,
+		}).Trace("END   TX")
+		span.AddAttributes(trace.StringAttribute("status", rctBuf[cnt].Status().String()))
+		span.End()
 		cnt++
 	}
 	return nil
@@ -221,11 +221,15 @@ func (ts *TransactionShare) handleTxRequest() {
 	tr, next := ts.popRequest()
 	for tr != nil {
 		bloom := tr.GetBloom()
-		ts.log.Debugf("handleTxRequest(bits=%d,peer=%s)", bloom.Bits, tr.peer)
+		ts.log.WithFields(log.Fields{
+			log.FieldKeyPeer: tr.peer,
+		}).Debugf("handleTxRequest(bits=%d)", bloom.Bits)
 		txs := ts.tm.FilterTransactions(module.TransactionGroupNormal, bloom, maxNumberOfTransactionsToSend)
 		sent := 0
 		if len(txs) > 0 {
-			ts.log.Infof("ShareTransactions(txs=%d,to=%s)", len(txs), tr.peer)
+			ts.log.WithFields(log.Fields{
+				log.FieldKeyPeer: tr.peer,
+			}).Infof("ShareTransactions(txs=%d)", len(txs))
 			for i, tx := range txs {
 				if err := ts.ph.Unicast(protoResponseTransaction, tx.Bytes(), tr.peer); err != nil {
 					ts.log.Debugf("Fail to send transaction at=%d err=%+v", i, err)
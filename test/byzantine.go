@@ -0,0 +1,198 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/network"
+)
+
+// VoteMessage is the content of a vote message of the consensus protocol.
+type VoteMessage struct {
+	Signature      common.Signature
+	Height         int64
+	Round          int32
+	Type           consensus.VoteType
+	BlockID        []byte
+	BlockPartSetID *consensus.PartSetID
+	Timestamp      int64
+}
+
+// DecodeVote returns the vote in the packet. It returns false if the packet
+// is not a vote message.
+func DecodeVote(pk *Packet) (*VoteMessage, bool) {
+	if pk.MPI != module.ProtoConsensus || pk.PI != consensus.ProtoVote {
+		return nil, false
+	}
+	vm := new(VoteMessage)
+	if _, err := codec.BC.UnmarshalFromBytes(pk.Data, vm); err != nil {
+		return nil, false
+	}
+	return vm, true
+}
+
+// ByzantineScript is called for each consensus packet received by the
+// byzantine node.
+type ByzantineScript func(b *ByzantineNode, pk *Packet)
+
+// ByzantineNode is a validator sending consensus messages by a script
+// instead of running consensus. It may send different messages to
+// different peers.
+type ByzantineNode struct {
+	t      *testing.T
+	w      module.Wallet
+	id     module.PeerID
+	lock   sync.Mutex
+	peers  []Peer
+	script ByzantineScript
+}
+
+func NewByzantineNode(t *testing.T, w module.Wallet) *ByzantineNode {
+	return &ByzantineNode{
+		t:  t,
+		w:  w,
+		id: network.NewPeerIDFromAddress(w.Address()),
+	}
+}
+
+func (b *ByzantineNode) ID() module.PeerID {
+	return b.id
+}
+
+func (b *ByzantineNode) Wallet() module.Wallet {
+	return b.w
+}
+
+func (b *ByzantineNode) attach(p Peer) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if indexOf(b.peers, p.ID()) < 0 {
+		b.peers = append(b.peers, p)
+	}
+}
+
+func (b *ByzantineNode) detach(p Peer) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if i := indexOf(b.peers, p.ID()); i >= 0 {
+		b.peers = append(b.peers[:i], b.peers[i+1:]...)
+	}
+}
+
+func (b *ByzantineNode) notifyPacket(pk *Packet, cb func(rebroadcast bool, err error)) {
+	b.lock.Lock()
+	script := b.script
+	b.lock.Unlock()
+
+	if script != nil && pk.MPI == module.ProtoConsensus {
+		Go(func() {
+			script(b, pk)
+		})
+	}
+}
+
+// SetScript sets the script reacting to received consensus packets.
+func (b *ByzantineNode) SetScript(script ByzantineScript) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.script = script
+}
+
+// Peers returns IDs of connected peers.
+func (b *ByzantineNode) Peers() []module.PeerID {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ids := make([]module.PeerID, len(b.peers))
+	for i, p := range b.peers {
+		ids[i] = p.ID()
+	}
+	return ids
+}
+
+// Send sends the consensus message to the peers. It sends to all connected
+// peers if to is empty.
+func (b *ByzantineNode) Send(pi module.ProtocolInfo, msg interface{}, to ...module.PeerID) {
+	bs := codec.BC.MustMarshalToBytes(msg)
+
+	b.lock.Lock()
+	var peers []Peer
+	for _, p := range b.peers {
+		if len(to) == 0 || indexOfID(to, p.ID()) >= 0 {
+			peers = append(peers, p)
+		}
+	}
+	b.lock.Unlock()
+
+	for _, p := range peers {
+		pk := &Packet{
+			SendTypeUnicast,
+			b.id,
+			p.ID(),
+			module.ProtoConsensus,
+			pi,
+			bs,
+		}
+		p.notifyPacket(pk, nil)
+	}
+}
+
+// SendVote sends a vote for the block to the peers.
+func (b *ByzantineNode) SendVote(
+	vt consensus.VoteType, height int64, round int32,
+	id []byte, psid *consensus.PartSetID, ts int64,
+	to ...module.PeerID,
+) {
+	msg := consensus.NewVoteMessage(b.w, vt, height, round, id, psid, ts)
+	b.Send(consensus.ProtoVote, msg, to...)
+}
+
+// SendConflictingVotes sends a vote for idA to peers in toA, and a vote for
+// idB to peers in toB for the same height and round.
+func (b *ByzantineNode) SendConflictingVotes(
+	vt consensus.VoteType, height int64, round int32, ts int64,
+	idA []byte, psidA *consensus.PartSetID, toA []module.PeerID,
+	idB []byte, psidB *consensus.PartSetID, toB []module.PeerID,
+) {
+	b.SendVote(vt, height, round, idA, psidA, ts, toA...)
+	b.SendVote(vt, height, round, idB, psidB, ts, toB...)
+}
+
+// SendProposal sends a proposal for the block data (header and body) and
+// its block parts to the peers. It returns the ID of the part set.
+func (b *ByzantineNode) SendProposal(
+	height int64, round int32, data []byte, polRound int32,
+	to ...module.PeerID,
+) *consensus.PartSetID {
+	ps := consensus.NewPartSetFromBytes(data)
+	assert.NotNil(b.t, ps)
+	msg := consensus.NewSignedProposalMessage(b.w, height, round, ps.ID(), polRound)
+	b.Send(consensus.ProtoProposal, msg, to...)
+	for i := 0; i < ps.Parts(); i++ {
+		b.Send(consensus.ProtoBlockPart, &consensus.BlockPartMessage{
+			Height:    height,
+			Index:     uint16(i),
+			BlockPart: ps.GetPart(i).Bytes(),
+			Nonce:     round,
+		}, to...)
+	}
+	return ps.ID()
+}
+
+func indexOfID(ids []module.PeerID, id module.PeerID) int {
+	for i := range ids {
+		if ids[i].Equal(id) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,287 @@
+package test
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/test/clock"
+)
+
+// LinkConfig is the configuration of faults for a directional link.
+type LinkConfig struct {
+	// Latency is the delay for delivering a packet.
+	Latency time.Duration
+
+	// Jitter is the maximum random delay added to Latency.
+	Jitter time.Duration
+
+	// Loss is the probability of dropping a packet.
+	Loss float64
+
+	// Duplicate is the probability of delivering a packet twice.
+	Duplicate float64
+
+	// Reorder is the probability of delaying a packet by ReorderDelay
+	// more, so that it's delivered after the packets sent later.
+	Reorder      float64
+	ReorderDelay time.Duration
+}
+
+// PacketFilter returns true if the packet from the peer to the peer needs to
+// be dropped.
+type PacketFilter func(from, to module.PeerID, pk *Packet) bool
+
+type delivery struct {
+	at  time.Time
+	seq int64
+	to  Peer
+	pk  *Packet
+	cb  func(bool, error)
+}
+
+// FaultNetwork connects peers with links injecting faults. Delayed packets
+// are delivered when the time of the clock passes, so the result only
+// depends on the seed and the sequence of sent packets.
+type FaultNetwork struct {
+	lock        sync.Mutex
+	clock       *clock.Clock
+	rand        *rand.Rand
+	defaultLink LinkConfig
+	links       map[string]*LinkConfig
+	groups      map[string]int
+	filters     []PacketFilter
+	queue       []*delivery
+	seq         int64
+}
+
+func NewFaultNetwork(cl *clock.Clock, seed int64) *FaultNetwork {
+	return &FaultNetwork{
+		clock: cl,
+		rand:  rand.New(rand.NewSource(seed)),
+		links: make(map[string]*LinkConfig),
+	}
+}
+
+func linkKey(from, to module.PeerID) string {
+	return string(from.Bytes()) + string(to.Bytes())
+}
+
+// Connect connects two peers through the faulty links of both directions.
+func (fn *FaultNetwork) Connect(p1, p2 Peer) {
+	p1.attach(&faultLink{fn, p1, p2})
+	p2.attach(&faultLink{fn, p2, p1})
+}
+
+// Interconnect connects all nodes with each other.
+func (fn *FaultNetwork) Interconnect(nodes []*Node) {
+	for i := 0; i < len(nodes); i++ {
+		for j := i + 1; j < len(nodes); j++ {
+			fn.Connect(nodes[i].NM, nodes[j].NM)
+		}
+	}
+}
+
+// SetDefaultLink sets the configuration for links without specific
+// configuration.
+func (fn *FaultNetwork) SetDefaultLink(cfg LinkConfig) {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	fn.defaultLink = cfg
+}
+
+// SetLink sets the configuration for the link from the peer to the peer.
+func (fn *FaultNetwork) SetLink(from, to module.PeerID, cfg LinkConfig) {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	fn.links[linkKey(from, to)] = &cfg
+}
+
+// ResetLink makes the link use the default configuration.
+func (fn *FaultNetwork) ResetLink(from, to module.PeerID) {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	delete(fn.links, linkKey(from, to))
+}
+
+// Partition splits the peers into the groups. Packets between peers in
+// different groups are dropped. Peers not in any group can communicate
+// with all peers.
+func (fn *FaultNetwork) Partition(groups ...[]module.PeerID) {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	fn.groups = make(map[string]int)
+	for i, g := range groups {
+		for _, id := range g {
+			fn.groups[string(id.Bytes())] = i
+		}
+	}
+}
+
+// Heal removes the partition.
+func (fn *FaultNetwork) Heal() {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	fn.groups = nil
+}
+
+// AddFilter adds the filter for dropping specific packets.
+func (fn *FaultNetwork) AddFilter(f PacketFilter) {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	fn.filters = append(fn.filters, f)
+}
+
+// ClearFilters removes all filters.
+func (fn *FaultNetwork) ClearFilters() {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	fn.filters = nil
+}
+
+// At calls f when the clock passes d from now. It's used for changing
+// links or partitions on a schedule.
+func (fn *FaultNetwork) At(d time.Duration, f func()) {
+	fn.clock.AfterFunc(d, f)
+}
+
+// Pending returns the number of packets waiting for delivery.
+func (fn *FaultNetwork) Pending() int {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	return len(fn.queue)
+}
+
+func (fn *FaultNetwork) isPartitionedInLock(from, to module.PeerID) bool {
+	if fn.groups == nil {
+		return false
+	}
+	g1, ok1 := fn.groups[string(from.Bytes())]
+	g2, ok2 := fn.groups[string(to.Bytes())]
+	return ok1 && ok2 && g1 != g2
+}
+
+func (fn *FaultNetwork) linkInLock(from, to module.PeerID) *LinkConfig {
+	if cfg, ok := fn.links[linkKey(from, to)]; ok {
+		return cfg
+	}
+	return &fn.defaultLink
+}
+
+func (fn *FaultNetwork) chanceInLock(p float64) bool {
+	return p > 0 && fn.rand.Float64() < p
+}
+
+func (fn *FaultNetwork) delayInLock(cfg *LinkConfig) time.Duration {
+	d := cfg.Latency
+	if cfg.Jitter > 0 {
+		d += time.Duration(fn.rand.Int63n(int64(cfg.Jitter) + 1))
+	}
+	if fn.chanceInLock(cfg.Reorder) {
+		d += cfg.ReorderDelay
+	}
+	return d
+}
+
+func (fn *FaultNetwork) send(from, to Peer, pk *Packet, cb func(bool, error)) {
+	fn.lock.Lock()
+
+	if fn.isPartitionedInLock(from.ID(), to.ID()) {
+		fn.lock.Unlock()
+		return
+	}
+	for _, f := range fn.filters {
+		if f(from.ID(), to.ID(), pk) {
+			fn.lock.Unlock()
+			return
+		}
+	}
+	cfg := fn.linkInLock(from.ID(), to.ID())
+	if fn.chanceInLock(cfg.Loss) {
+		fn.lock.Unlock()
+		return
+	}
+	copies := 1
+	if fn.chanceInLock(cfg.Duplicate) {
+		copies = 2
+	}
+
+	var now []*delivery
+	for i := 0; i < copies; i++ {
+		d := fn.delayInLock(cfg)
+		fn.seq += 1
+		dl := &delivery{
+			at:  fn.clock.Now().Add(d),
+			seq: fn.seq,
+			to:  to,
+			pk:  pk,
+			cb:  cb,
+		}
+		if d <= 0 {
+			now = append(now, dl)
+			continue
+		}
+		fn.queue = append(fn.queue, dl)
+		fn.clock.AfterFunc(d, fn.deliverDue)
+	}
+	fn.lock.Unlock()
+
+	for _, dl := range now {
+		dl.to.notifyPacket(dl.pk, dl.cb)
+	}
+}
+
+// deliverDue delivers all due packets in order of their delivery time, and
+// the order of sending for the same time.
+func (fn *FaultNetwork) deliverDue() {
+	fn.lock.Lock()
+	now := fn.clock.Now()
+	sort.Slice(fn.queue, func(i, j int) bool {
+		if fn.queue[i].at.Equal(fn.queue[j].at) {
+			return fn.queue[i].seq < fn.queue[j].seq
+		}
+		return fn.queue[i].at.Before(fn.queue[j].at)
+	})
+	var due []*delivery
+	for len(fn.queue) > 0 && !fn.queue[0].at.After(now) {
+		due = append(due, fn.queue[0])
+		fn.queue[0] = nil
+		fn.queue = fn.queue[1:]
+	}
+	fn.lock.Unlock()
+
+	for _, dl := range due {
+		dl.to.notifyPacket(dl.pk, dl.cb)
+	}
+}
+
+// faultLink is the link from a peer to a peer in the fault network.
+type faultLink struct {
+	fn   *FaultNetwork
+	from Peer
+	to   Peer
+}
+
+func (l *faultLink) ID() module.PeerID {
+	return l.to.ID()
+}
+
+// attach and detach do nothing, because links are made only by
+// FaultNetwork.Connect.
+func (l *faultLink) attach(p Peer) {}
+
+func (l *faultLink) detach(p Peer) {}
+
+func (l *faultLink) notifyPacket(pk *Packet, cb func(rebroadcast bool, err error)) {
+	l.fn.send(l.from, l.to, pk, cb)
+}
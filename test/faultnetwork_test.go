@@ -0,0 +1,168 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/network"
+	"github.com/icon-project/goloop/test/clock"
+)
+
+type recordingPeer struct {
+	id   module.PeerID
+	lock sync.Mutex
+	pks  []*Packet
+}
+
+func newRecordingPeer() *recordingPeer {
+	return &recordingPeer{
+		id: network.NewPeerIDFromAddress(wallet.New().Address()),
+	}
+}
+
+func (p *recordingPeer) ID() module.PeerID {
+	return p.id
+}
+
+func (p *recordingPeer) attach(p2 Peer) {}
+
+func (p *recordingPeer) detach(p2 Peer) {}
+
+func (p *recordingPeer) notifyPacket(pk *Packet, cb func(rebroadcast bool, err error)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.pks = append(p.pks, pk)
+}
+
+func (p *recordingPeer) received() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var res []string
+	for _, pk := range p.pks {
+		res = append(res, string(pk.Data))
+	}
+	return res
+}
+
+func newLinkedPeers(fn *FaultNetwork) (*recordingPeer, *recordingPeer, Peer) {
+	p1 := newRecordingPeer()
+	p2 := newRecordingPeer()
+	return p1, p2, &faultLink{fn, p1, p2}
+}
+
+func sendPacket(l Peer, data string) {
+	l.notifyPacket(&Packet{Data: []byte(data)}, nil)
+}
+
+func TestFaultNetwork_Latency(t *testing.T) {
+	cl := &clock.Clock{}
+	fn := NewFaultNetwork(cl, 1)
+	_, p2, l := newLinkedPeers(fn)
+
+	sendPacket(l, "a")
+	assert.Equal(t, []string{"a"}, p2.received())
+
+	fn.SetDefaultLink(LinkConfig{Latency: 100 * time.Millisecond})
+	sendPacket(l, "b")
+	cl.PassTime(50 * time.Millisecond)
+	sendPacket(l, "c")
+	assert.Equal(t, []string{"a"}, p2.received())
+	assert.Equal(t, 2, fn.Pending())
+
+	cl.PassTime(50 * time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, p2.received())
+	cl.PassTime(50 * time.Millisecond)
+	assert.Equal(t, []string{"a", "b", "c"}, p2.received())
+	assert.Equal(t, 0, fn.Pending())
+}
+
+func TestFaultNetwork_Reorder(t *testing.T) {
+	cl := &clock.Clock{}
+	fn := NewFaultNetwork(cl, 1)
+	p1, p2, l := newLinkedPeers(fn)
+
+	fn.SetLink(p1.ID(), p2.ID(), LinkConfig{
+		Latency:      10 * time.Millisecond,
+		Reorder:      1,
+		ReorderDelay: 10 * time.Millisecond,
+	})
+	sendPacket(l, "a")
+	fn.ResetLink(p1.ID(), p2.ID())
+	sendPacket(l, "b")
+	cl.PassTime(time.Second)
+	assert.Equal(t, []string{"b", "a"}, p2.received())
+}
+
+func TestFaultNetwork_Deterministic(t *testing.T) {
+	run := func() []string {
+		cl := &clock.Clock{}
+		fn := NewFaultNetwork(cl, 7)
+		fn.SetDefaultLink(LinkConfig{
+			Latency:   10 * time.Millisecond,
+			Jitter:    20 * time.Millisecond,
+			Loss:      0.2,
+			Duplicate: 0.2,
+		})
+		_, p2, l := newLinkedPeers(fn)
+		for _, s := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			sendPacket(l, s)
+			cl.PassTime(5 * time.Millisecond)
+		}
+		cl.PassTime(time.Second)
+		return p2.received()
+	}
+	assert.Equal(t, run(), run())
+}
+
+func TestFaultNetwork_PartitionAndFilter(t *testing.T) {
+	cl := &clock.Clock{}
+	fn := NewFaultNetwork(cl, 1)
+	p1, p2, l := newLinkedPeers(fn)
+
+	fn.Partition([]module.PeerID{p1.ID()}, []module.PeerID{p2.ID()})
+	fn.At(time.Second, fn.Heal)
+	sendPacket(l, "a")
+	cl.PassTime(time.Second)
+	sendPacket(l, "b")
+	assert.Equal(t, []string{"b"}, p2.received())
+
+	fn.AddFilter(func(from, to module.PeerID, pk *Packet) bool {
+		return string(pk.Data) == "c"
+	})
+	sendPacket(l, "c")
+	sendPacket(l, "d")
+	fn.ClearFilters()
+	sendPacket(l, "c")
+	assert.Equal(t, []string{"b", "d", "c"}, p2.received())
+}
+
+func TestByzantineNode_SendConflictingVotes(t *testing.T) {
+	cl := &clock.Clock{}
+	fn := NewFaultNetwork(cl, 1)
+	b := NewByzantineNode(t, wallet.New())
+	p1 := newRecordingPeer()
+	p2 := newRecordingPeer()
+	fn.Connect(b, p1)
+	fn.Connect(b, p2)
+
+	b.SendConflictingVotes(consensus.VoteTypePrecommit, 10, 1, 1000,
+		[]byte{0x01}, nil, []module.PeerID{p1.ID()},
+		[]byte{0x02}, nil, []module.PeerID{p2.ID()},
+	)
+	for i, p := range []*recordingPeer{p1, p2} {
+		assert.Len(t, p.pks, 1)
+		vm, ok := DecodeVote(p.pks[0])
+		assert.True(t, ok)
+		assert.Equal(t, consensus.VoteTypePrecommit, vm.Type)
+		assert.EqualValues(t, 10, vm.Height)
+		assert.EqualValues(t, 1, vm.Round)
+		assert.Equal(t, []byte{byte(i + 1)}, vm.BlockID)
+		assert.True(t, b.ID().Equal(p.pks[0].Src))
+	}
+}
@@ -17,6 +17,7 @@
 package test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/icon-project/goloop/common/errors"
@@ -28,6 +29,7 @@ type NetworkManager struct {
 	module.NetworkManager
 	t         *testing.T
 	peers     []Peer
+	lock      sync.Mutex
 	handlers  []*nmHandler
 	roles     map[string]module.Role
 	id        module.PeerID
@@ -70,8 +72,17 @@ func (n *NetworkManager) detach(p Peer) {
 	}
 }
 
+// handlersCopy returns registered handlers. Packets may be delivered
+// while reactors are unregistered on close of the node.
+func (n *NetworkManager) handlersCopy() []*nmHandler {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return append([]*nmHandler(nil), n.handlers...)
+}
+
 func (n *NetworkManager) notifyPacket(pk *Packet, cb func(rebroadcast bool, err error)) {
-	for _, h := range n.handlers {
+	for _, h := range n.handlersCopy() {
 		if pk.MPI == h.mpi {
 			reactor := h.reactor
 			Go(func() {
@@ -86,7 +97,7 @@ func (n *NetworkManager) notifyPacket(pk *Packet, cb func(rebroadcast bool, err
 }
 
 func (n *NetworkManager) notifyJoin(p Peer) {
-	for _, h := range n.handlers {
+	for _, h := range n.handlersCopy() {
 		reactor := h.reactor
 		Go(func() {
 			reactor.OnJoin(p.ID())
@@ -95,7 +106,7 @@ func (n *NetworkManager) notifyJoin(p Peer) {
 }
 
 func (n *NetworkManager) notifyLeave(p Peer) {
-	for _, h := range n.handlers {
+	for _, h := range n.handlersCopy() {
 		reactor := h.reactor
 		Go(func() {
 			reactor.OnLeave(p.ID())
@@ -120,6 +131,9 @@ func (n *NetworkManager) RegisterReactor(name string, mpi module.ProtocolInfo, r
 		piList,
 		priority,
 	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
 	n.handlers = append(n.handlers, h)
 	return h, nil
 }
@@ -129,6 +143,9 @@ func (n *NetworkManager) RegisterReactorForStreams(name string, pi module.Protoc
 }
 
 func (n *NetworkManager) UnregisterReactor(reactor module.Reactor) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
 	for i, h := range n.handlers {
 		if h.reactor == reactor {
 			last := len(n.handlers) - 1